@@ -0,0 +1,144 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+// Accumulator keeps a running total of values and products of values, e.g. the fees or rewards
+// from a long series of events, without rounding each term. The total is kept at double width
+// (the full product of two values, 128 bits for the 64-bit types and 256 bits for the 128-bit
+// types, plus an extension word), and is only scaled down and rounded when Value is called, so
+// it doesn't drift by up to an ulp per term as a running total of T would:
+//
+//	var fees Accumulator[UFix128]
+//	for _, trade := range trades {
+//		fees.AddProduct(trade.Amount, feeRate)
+//	}
+//	total, err := fees.Value(RoundHalfEven)
+//
+// The zero value is an empty total. The running total is signed, even when T is an unsigned type,
+// so it only has to be in the range of T when Value is called. It can't overflow for any
+// realistic number of terms.
+type Accumulator[T calcValue] struct {
+	acc64  accum64  // The total for the 64-bit types
+	acc128 accum128 // The total for the 128-bit types
+}
+
+// Add adds `a` to the total.
+func (acc *Accumulator[T]) Add(a T) { acc.addProduct(a, calcOne[T](), 1) }
+
+// Sub subtracts `a` from the total.
+func (acc *Accumulator[T]) Sub(a T) { acc.addProduct(a, calcOne[T](), -1) }
+
+// AddProduct adds the exact product of `a` and `b` to the total.
+func (acc *Accumulator[T]) AddProduct(a, b T) { acc.addProduct(a, b, 1) }
+
+// SubProduct subtracts the exact product of `a` and `b` from the total.
+func (acc *Accumulator[T]) SubProduct(a, b T) { acc.addProduct(a, b, -1) }
+
+// Reset sets the total back to zero.
+func (acc *Accumulator[T]) Reset() { *acc = Accumulator[T]{} }
+
+// Value returns the total, rounded as specified. Returns an error if it's out of the range of T
+// (including negative totals for the unsigned types), or an UnderflowError if a non-zero total
+// rounds to zero. The total is unchanged, so more terms can be added afterwards.
+func (acc *Accumulator[T]) Value(round RoundingMode) (T, error) {
+	var zero T
+
+	if !round.isValid() {
+		return zero, InvalidRoundingModeError{}
+	}
+
+	switch any(zero).(type) {
+	case UFix64:
+		res, sign, err := acc.acc64.value(round)
+		return unsignedTotal[T](res, res.IsZero(), sign, err)
+	case Fix64:
+		res, sign, err := acc.acc64.value(round)
+		if err != nil {
+			return zero, applySign(err, sign)
+		}
+		signed, err := res.ApplySign(sign)
+		return any(signed).(T), err
+	case UFix128:
+		res, sign, err := acc.acc128.value(round)
+		return unsignedTotal[T](res, res.IsZero(), sign, err)
+	case Fix128:
+		res, sign, err := acc.acc128.value(round)
+		if err != nil {
+			return zero, applySign(err, sign)
+		}
+		signed, err := res.ApplySign(sign)
+		return any(signed).(T), err
+	}
+
+	// Unreachable, the type constraint only allows the types above
+	debugPanic("unsupported type")
+	return zero, nil
+}
+
+// addProduct adds (or subtracts, if sign is negative) the full product of `a` and `b`.
+func (acc *Accumulator[T]) addProduct(a, b T, sign int64) {
+	switch a := any(a).(type) {
+	case UFix64:
+		acc.acc64.addProduct(a, any(b).(UFix64), sign)
+	case Fix64:
+		aUnsigned, aSign := a.Abs()
+		bUnsigned, bSign := any(b).(Fix64).Abs()
+		acc.acc64.addProduct(aUnsigned, bUnsigned, sign*aSign*bSign)
+	case UFix128:
+		acc.acc128.addProduct(a, any(b).(UFix128), sign)
+	case Fix128:
+		aUnsigned, aSign := a.Abs()
+		bUnsigned, bSign := any(b).(Fix128).Abs()
+		acc.acc128.addProduct(aUnsigned, bUnsigned, sign*aSign*bSign)
+	default:
+		// Unreachable, the type constraint only allows the types above
+		debugPanic("unsupported type")
+	}
+}
+
+// unsignedTotal returns the magnitude of a total as an unsigned T, or a NegativeOverflowError if
+// the total is negative.
+func unsignedTotal[T calcValue, U any](res U, isZero bool, sign int64, err error) (T, error) {
+	var zero T
+
+	if err != nil {
+		return zero, applySign(err, sign)
+	}
+	if sign < 0 && !isZero {
+		return zero, NegativeOverflowError{}
+	}
+
+	return any(res).(T), nil
+}
+
+// calcOne returns one as a T.
+func calcOne[T calcValue]() T {
+	var one T
+
+	switch p := any(&one).(type) {
+	case *UFix64:
+		*p = UFix64One
+	case *Fix64:
+		*p = Fix64One
+	case *UFix128:
+		*p = UFix128One
+	case *Fix128:
+		*p = Fix128One
+	}
+
+	return one
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+// This file contains the conversions between the 128-bit types and the Apache Arrow (and Parquet)
+// Decimal128 layout: a 16-byte, little-endian, two's complement integer. Since the 128-bit types
+// have 24 decimals, their raw value is exactly the unscaled value of a Decimal128(38, 24), so
+// the conversion is lossless and requires no arithmetic. In Go, the bytes correspond to
+// decimal128.New(int64(hi), lo), with hi and lo from Words.
+
+const (
+	// ArrowDecimal128Precision is the precision of the Arrow type that the 128-bit types map to.
+	ArrowDecimal128Precision = 38
+
+	// ArrowDecimal128Scale is the scale of the Arrow type that the 128-bit types map to.
+	ArrowDecimal128Scale = Fix128Decimals
+)
+
+// arrowDecimal128Max is the largest unscaled value with ArrowDecimal128Precision digits, i.e.
+// 10^38 - 1.
+var arrowDecimal128Max, _ = sub128(pow10Table128[ArrowDecimal128Precision], raw128{0, 1}, 0)
+
+// ArrowDecimal128 returns `a` in the Arrow Decimal128(38, 24) layout. Values with more than 38
+// digits (i.e. 10^14 and above) don't fit in that precision, and return a PositiveOverflowError.
+func (a UFix128) ArrowDecimal128() ([16]byte, error) {
+	if ult128(arrowDecimal128Max, raw128(a)) {
+		return [16]byte{}, PositiveOverflowError{}
+	}
+
+	var b [16]byte
+	putRaw128LE(b[:], raw128(a))
+	return b, nil
+}
+
+// ArrowDecimal128 returns `a` in the Arrow Decimal128(38, 24) layout. Values with more than 38
+// digits (i.e. ±10^14 and beyond) don't fit in that precision, and return a PositiveOverflowError
+// or NegativeOverflowError.
+func (a Fix128) ArrowDecimal128() ([16]byte, error) {
+	if slt128(arrowDecimal128Max, raw128(a)) {
+		return [16]byte{}, PositiveOverflowError{}
+	}
+	if slt128(raw128(a), neg128(arrowDecimal128Max)) {
+		return [16]byte{}, NegativeOverflowError{}
+	}
+
+	var b [16]byte
+	putRaw128LE(b[:], raw128(a))
+	return b, nil
+}
+
+// UFix128FromArrowDecimal128 converts a value in the Arrow Decimal128(38, 24) layout to a UFix128,
+// returning a NegativeOverflowError for negative values. The precision isn't checked, so any
+// value that fits in a UFix128 is accepted.
+func UFix128FromArrowDecimal128(b [16]byte) (UFix128, error) {
+	a := readRaw128LE(b[:])
+	if isNeg128(a) {
+		return UFix128Zero, NegativeOverflowError{}
+	}
+
+	return UFix128(a), nil
+}
+
+// Fix128FromArrowDecimal128 converts a value in the Arrow Decimal128(38, 24) layout to a Fix128.
+// The layouts have the same range, so this never fails.
+func Fix128FromArrowDecimal128(b [16]byte) Fix128 {
+	return Fix128(readRaw128LE(b[:]))
+}
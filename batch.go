@@ -0,0 +1,170 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// This file contains the batch parsers, for loading large numbers of values at once (e.g. a
+// column of historical amounts). They return the results in a single slice, and don't allocate
+// per value: the errors are all the empty error types, and the io-based variants convert their
+// input to strings a block at a time, rather than line by line.
+
+// ParseUFix64Column parses each string in ss as for ParseUFix64. The results are in the same order
+// as the inputs. The error slice is nil if every input was parsed successfully; otherwise it has
+// the same length as the results, with a nil error for each successful input, and the result is
+// zero for each failed one.
+func ParseUFix64Column(ss []string, round RoundingMode) ([]UFix64, []error) {
+	return ParseOptions{Rounding: round}.ParseUFix64Column(ss)
+}
+
+// ParseFix64Column parses each string in ss as for ParseFix64, see ParseUFix64Column.
+func ParseFix64Column(ss []string, round RoundingMode) ([]Fix64, []error) {
+	return ParseOptions{Rounding: round}.ParseFix64Column(ss)
+}
+
+// ParseUFix128Column parses each string in ss as for ParseUFix128, see ParseUFix64Column.
+func ParseUFix128Column(ss []string, round RoundingMode) ([]UFix128, []error) {
+	return ParseOptions{Rounding: round}.ParseUFix128Column(ss)
+}
+
+// ParseFix128Column parses each string in ss as for ParseFix128, see ParseUFix64Column.
+func ParseFix128Column(ss []string, round RoundingMode) ([]Fix128, []error) {
+	return ParseOptions{Rounding: round}.ParseFix128Column(ss)
+}
+
+// ParseUFix64Column parses each string in ss with the given options, see the ParseUFix64Column
+// function for details.
+func (opts ParseOptions) ParseUFix64Column(ss []string) ([]UFix64, []error) {
+	return parseColumn(ss, opts.ParseUFix64)
+}
+
+// ParseFix64Column parses each string in ss with the given options, see the ParseUFix64Column
+// function for details.
+func (opts ParseOptions) ParseFix64Column(ss []string) ([]Fix64, []error) {
+	return parseColumn(ss, opts.ParseFix64)
+}
+
+// ParseUFix128Column parses each string in ss with the given options, see the ParseUFix64Column
+// function for details.
+func (opts ParseOptions) ParseUFix128Column(ss []string) ([]UFix128, []error) {
+	return parseColumn(ss, opts.ParseUFix128)
+}
+
+// ParseFix128Column parses each string in ss with the given options, see the ParseUFix64Column
+// function for details.
+func (opts ParseOptions) ParseFix128Column(ss []string) ([]Fix128, []error) {
+	return parseColumn(ss, opts.ParseFix128)
+}
+
+// ReadUFix64Column reads values from r, one per line, until EOF, and parses them with the given
+// options. Lines may end in "\n" or "\r\n", and a final newline is optional. The results and
+// errors are as for ParseUFix64Column, with one entry per line; the final error is only for
+// errors reading from r, in which case the results cover the lines read before the error.
+func (opts ParseOptions) ReadUFix64Column(r io.Reader) ([]UFix64, []error, error) {
+	return readColumn(r, opts.ParseUFix64)
+}
+
+// ReadFix64Column reads values from r, one per line, see ReadUFix64Column.
+func (opts ParseOptions) ReadFix64Column(r io.Reader) ([]Fix64, []error, error) {
+	return readColumn(r, opts.ParseFix64)
+}
+
+// ReadUFix128Column reads values from r, one per line, see ReadUFix64Column.
+func (opts ParseOptions) ReadUFix128Column(r io.Reader) ([]UFix128, []error, error) {
+	return readColumn(r, opts.ParseUFix128)
+}
+
+// ReadFix128Column reads values from r, one per line, see ReadUFix64Column.
+func (opts ParseOptions) ReadFix128Column(r io.Reader) ([]Fix128, []error, error) {
+	return readColumn(r, opts.ParseFix128)
+}
+
+// column accumulates the results of a batch parse, only allocating the errors once there is one.
+type column[T any] struct {
+	values []T
+	errs   []error
+}
+
+func (c *column[T]) add(v T, err error) {
+	if err != nil && c.errs == nil {
+		c.errs = make([]error, len(c.values), cap(c.values))
+	}
+
+	c.values = append(c.values, v)
+	if c.errs != nil {
+		c.errs = append(c.errs, err)
+	}
+}
+
+func parseColumn[T any](ss []string, parse func(string) (T, error)) ([]T, []error) {
+	c := column[T]{values: make([]T, 0, len(ss))}
+	for _, s := range ss {
+		c.add(parse(s))
+	}
+
+	return c.values, c.errs
+}
+
+// readBlockSize is the initial size of the buffer used by readColumn. It grows if a single line is
+// longer than that.
+const readBlockSize = 64 << 10
+
+func readColumn[T any](r io.Reader, parse func(string) (T, error)) ([]T, []error, error) {
+	var c column[T]
+	buf := make([]byte, 0, readBlockSize)
+
+	parseLine := func(line string) {
+		if len(line) > 0 && line[len(line)-1] == '\r' {
+			line = line[:len(line)-1]
+		}
+		c.add(parse(line))
+	}
+
+	for {
+		if len(buf) == cap(buf) {
+			buf = append(buf, 0)[:len(buf)]
+		}
+
+		n, err := r.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+
+		// Parse all of the complete lines in the buffer, converting them to a string together.
+		if end := bytes.LastIndexByte(buf, '\n'); end >= 0 {
+			for block, more := string(buf[:end]), true; more; {
+				var line string
+				line, block, more = strings.Cut(block, "\n")
+				parseLine(line)
+			}
+
+			buf = buf[:copy(buf, buf[end+1:])]
+		}
+
+		if err == io.EOF {
+			if len(buf) > 0 {
+				parseLine(string(buf))
+			}
+			return c.values, c.errs, nil
+		}
+		if err != nil {
+			return c.values, c.errs, err
+		}
+	}
+}
@@ -205,3 +205,14 @@ func BenchmarkCosFix128(b *testing.B) {
 		_, _ = a.Cos()
 	}
 }
+
+func BenchmarkToFix128Slice(b *testing.B) {
+	src := make([]Fix64, 1024)
+	for i := range src {
+		src[i] = Fix64(int64(i-512) * 123456789)
+	}
+	dst := make([]Fix128, 0, len(src))
+	for i := 0; i < b.N; i++ {
+		dst = AppendFix128Slice(dst[:0], src)
+	}
+}
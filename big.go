@@ -0,0 +1,306 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+	"strconv"
+)
+
+// This file contains the conversions between the fixed-point types and the math/big types, for
+// moving between exact representations without going through strings. A big.Int holds the raw
+// (i.e. scaled) value, while a big.Rat holds the actual value, so ToBigInt(a) / Scale() ==
+// ToBigRat(a).
+
+// ToBigInt returns the raw value of `a` as a big.Int, i.e. `a` multiplied by 10^8.
+func (a UFix64) ToBigInt() *big.Int { return new(big.Int).SetUint64(uint64(a)) }
+
+// ToBigInt returns the raw value of `a` as a big.Int, i.e. `a` multiplied by 10^8.
+func (a Fix64) ToBigInt() *big.Int { return big.NewInt(int64(a)) }
+
+// ToBigInt returns the raw value of `a` as a big.Int, i.e. `a` multiplied by 10^24.
+func (a UFix128) ToBigInt() *big.Int { return bigFromMagnitude(false, uint64(a.Hi), uint64(a.Lo)) }
+
+// ToBigInt returns the raw value of `a` as a big.Int, i.e. `a` multiplied by 10^24.
+func (a Fix128) ToBigInt() *big.Int {
+	aUnsigned, sign := a.Abs()
+	return bigFromMagnitude(sign < 0, uint64(aUnsigned.Hi), uint64(aUnsigned.Lo))
+}
+
+// ToBigInt returns the raw value of `a` as a big.Int, i.e. `a` multiplied by 10^24.
+func (a UFix256) ToBigInt() *big.Int { return bigFromRaw256(raw256(a)) }
+
+// ToBigInt returns the raw value of `a` as a big.Int, i.e. `a` multiplied by 10^24.
+func (a Fix256) ToBigInt() *big.Int {
+	aUnsigned, sign := a.Abs()
+	n := bigFromRaw256(raw256(aUnsigned))
+	if sign < 0 {
+		n.Neg(n)
+	}
+
+	return n
+}
+
+// ToBigRat returns the exact value of `a` as a big.Rat.
+func (a UFix64) ToBigRat() *big.Rat { return new(big.Rat).SetFrac(a.ToBigInt(), a.Scale()) }
+
+// ToBigRat returns the exact value of `a` as a big.Rat.
+func (a Fix64) ToBigRat() *big.Rat { return new(big.Rat).SetFrac(a.ToBigInt(), a.Scale()) }
+
+// ToBigRat returns the exact value of `a` as a big.Rat.
+func (a UFix128) ToBigRat() *big.Rat { return new(big.Rat).SetFrac(a.ToBigInt(), a.Scale()) }
+
+// ToBigRat returns the exact value of `a` as a big.Rat.
+func (a Fix128) ToBigRat() *big.Rat { return new(big.Rat).SetFrac(a.ToBigInt(), a.Scale()) }
+
+// ToBigFloat returns the value of `a` as a big.Float with the given precision (in bits), rounded to
+// nearest even. Most values aren't exactly representable in binary, so this is lossy; a precision
+// of 0 uses 136 bits, which is enough for a conversion back with rounding to nearest to give the
+// original value for any of the types.
+func (a UFix64) ToBigFloat(prec uint) *big.Float {
+	return bigFloatFromRaw(a.ToBigInt(), a.Scale(), prec)
+}
+
+// ToBigFloat returns the value of `a` as a big.Float, see UFix64.ToBigFloat.
+func (a Fix64) ToBigFloat(prec uint) *big.Float {
+	return bigFloatFromRaw(a.ToBigInt(), a.Scale(), prec)
+}
+
+// ToBigFloat returns the value of `a` as a big.Float, see UFix64.ToBigFloat.
+func (a UFix128) ToBigFloat(prec uint) *big.Float {
+	return bigFloatFromRaw(a.ToBigInt(), a.Scale(), prec)
+}
+
+// ToBigFloat returns the value of `a` as a big.Float, see UFix64.ToBigFloat.
+func (a Fix128) ToBigFloat(prec uint) *big.Float {
+	return bigFloatFromRaw(a.ToBigInt(), a.Scale(), prec)
+}
+
+// UFix64FromBigInt converts a raw (i.e. scaled) value to a UFix64, so that n = 1e8 gives 1.0. It
+// returns a PositiveOverflowError or NegativeOverflowError if n is out of range.
+func UFix64FromBigInt(n *big.Int) (UFix64, error) { return fromBigInt(n, ufix64FromMagnitude) }
+
+// Fix64FromBigInt converts a raw (i.e. scaled) value to a Fix64, see UFix64FromBigInt.
+func Fix64FromBigInt(n *big.Int) (Fix64, error) { return fromBigInt(n, fix64FromMagnitude) }
+
+// UFix128FromBigInt converts a raw (i.e. scaled) value to a UFix128, so that n = 1e24 gives 1.0,
+// see UFix64FromBigInt.
+func UFix128FromBigInt(n *big.Int) (UFix128, error) { return fromBigInt(n, ufix128FromMagnitude) }
+
+// Fix128FromBigInt converts a raw (i.e. scaled) value to a Fix128, see UFix128FromBigInt.
+func Fix128FromBigInt(n *big.Int) (Fix128, error) { return fromBigInt(n, fix128FromMagnitude) }
+
+// UFix64FromBigRat converts r to a UFix64, rounding as specified. It returns the usual overflow
+// errors if r is out of range, and an UnderflowError if a non-zero r rounds to zero.
+func UFix64FromBigRat(r *big.Rat, round RoundingMode) (UFix64, error) {
+	return fromBigRat(r, Fix64Decimals, round, ufix64FromMagnitude)
+}
+
+// Fix64FromBigRat converts r to a Fix64, rounding as specified, see UFix64FromBigRat.
+func Fix64FromBigRat(r *big.Rat, round RoundingMode) (Fix64, error) {
+	return fromBigRat(r, Fix64Decimals, round, fix64FromMagnitude)
+}
+
+// UFix128FromBigRat converts r to a UFix128, rounding as specified, see UFix64FromBigRat.
+func UFix128FromBigRat(r *big.Rat, round RoundingMode) (UFix128, error) {
+	return fromBigRat(r, Fix128Decimals, round, ufix128FromMagnitude)
+}
+
+// Fix128FromBigRat converts r to a Fix128, rounding as specified, see UFix64FromBigRat.
+func Fix128FromBigRat(r *big.Rat, round RoundingMode) (Fix128, error) {
+	return fromBigRat(r, Fix128Decimals, round, fix128FromMagnitude)
+}
+
+// UFix64FromBigFloat converts f to a UFix64, rounding its exact value as specified, see
+// UFix64FromBigRat. Infinities return an overflow error.
+func UFix64FromBigFloat(f *big.Float, round RoundingMode) (UFix64, error) {
+	return fromBigFloat(f, Fix64Decimals, round, ufix64FromMagnitude)
+}
+
+// Fix64FromBigFloat converts f to a Fix64, rounding as specified, see UFix64FromBigFloat.
+func Fix64FromBigFloat(f *big.Float, round RoundingMode) (Fix64, error) {
+	return fromBigFloat(f, Fix64Decimals, round, fix64FromMagnitude)
+}
+
+// UFix128FromBigFloat converts f to a UFix128, rounding as specified, see UFix64FromBigFloat.
+func UFix128FromBigFloat(f *big.Float, round RoundingMode) (UFix128, error) {
+	return fromBigFloat(f, Fix128Decimals, round, ufix128FromMagnitude)
+}
+
+// Fix128FromBigFloat converts f to a Fix128, rounding as specified, see UFix64FromBigFloat.
+func Fix128FromBigFloat(f *big.Float, round RoundingMode) (Fix128, error) {
+	return fromBigFloat(f, Fix128Decimals, round, fix128FromMagnitude)
+}
+
+// MantScale returns `a` as a mantissa and a scale, such that `a` = mant * 10^-scale, which is how
+// arbitrary-precision decimal libraries represent their values. The mantissa is the raw value, and
+// the scale is the number of decimals of the type.
+//
+// Both directions are exact, except that the *FromMantScale constructors round values with more
+// decimals than the type supports. The ericlagergren and shopspring modules
+// (github.com/onflow/fixed-point/ericlagergren and github.com/onflow/fixed-point/shopspring)
+// provide FromDecimal and ToDecimal conversions for those decimal libraries on top of these.
+func (a UFix64) MantScale() (*big.Int, int) { return a.ToBigInt(), Fix64Decimals }
+
+// MantScale returns `a` as a mantissa and a scale, see UFix64.MantScale.
+func (a Fix64) MantScale() (*big.Int, int) { return a.ToBigInt(), Fix64Decimals }
+
+// MantScale returns `a` as a mantissa and a scale, see UFix64.MantScale.
+func (a UFix128) MantScale() (*big.Int, int) { return a.ToBigInt(), Fix128Decimals }
+
+// MantScale returns `a` as a mantissa and a scale, see UFix64.MantScale.
+func (a Fix128) MantScale() (*big.Int, int) { return a.ToBigInt(), Fix128Decimals }
+
+// UFix64FromMantScale converts the decimal value mant * 10^-scale to a UFix64, for values from
+// libraries that expose their mantissa and scale. It rounds as specified if the value has more
+// than 8 decimals, and returns the same errors as ParseUFix64 otherwise. The scale may be
+// negative.
+func UFix64FromMantScale(mant *big.Int, scale int, round RoundingMode) (UFix64, error) {
+	return ParseOptions{Rounding: round}.ParseUFix64(mantScaleString(mant, scale))
+}
+
+// Fix64FromMantScale converts mant * 10^-scale to a Fix64, see UFix64FromMantScale.
+func Fix64FromMantScale(mant *big.Int, scale int, round RoundingMode) (Fix64, error) {
+	return ParseOptions{Rounding: round}.ParseFix64(mantScaleString(mant, scale))
+}
+
+// UFix128FromMantScale converts mant * 10^-scale to a UFix128, rounding as specified if it has
+// more than 24 decimals, see UFix64FromMantScale.
+func UFix128FromMantScale(mant *big.Int, scale int, round RoundingMode) (UFix128, error) {
+	return ParseOptions{Rounding: round}.ParseUFix128(mantScaleString(mant, scale))
+}
+
+// Fix128FromMantScale converts mant * 10^-scale to a Fix128, see UFix128FromMantScale.
+func Fix128FromMantScale(mant *big.Int, scale int, round RoundingMode) (Fix128, error) {
+	return ParseOptions{Rounding: round}.ParseFix128(mantScaleString(mant, scale))
+}
+
+// mantScaleString returns mant * 10^-scale in scientific notation, so the parser can apply the
+// range checks and rounding (including for huge scales, which it handles without allocating).
+func mantScaleString(mant *big.Int, scale int) string {
+	s := mant.Append(nil, 10)
+	s = append(s, 'e')
+	return string(strconv.AppendInt(s, -int64(scale), 10))
+}
+
+// bigFromRaw256 returns the unsigned 256-bit value `a` as a big.Int.
+func bigFromRaw256(a raw256) *big.Int {
+	return bigFromUint256([4]uint64{uint64(a.Lo.Lo), uint64(a.Lo.Hi), uint64(a.Hi.Lo), uint64(a.Hi.Hi)})
+}
+
+func bigFromMagnitude(neg bool, hi, lo uint64) *big.Int {
+	n := new(big.Int).SetUint64(hi)
+	n.Lsh(n, 64).Or(n, new(big.Int).SetUint64(lo))
+	if neg {
+		n.Neg(n)
+	}
+
+	return n
+}
+
+func bigFloatFromRaw(raw, scale *big.Int, prec uint) *big.Float {
+	if prec == 0 {
+		prec = 136
+	}
+
+	// The raw value and the scale are exact (SetInt uses as much precision as they need), so the
+	// division is correctly rounded.
+	x, y := new(big.Float).SetInt(raw), new(big.Float).SetInt(scale)
+	return new(big.Float).SetPrec(prec).Quo(x, y)
+}
+
+// magnitudeFromBig splits |n| into two 64-bit words, and returns false if it doesn't fit.
+func magnitudeFromBig(n *big.Int) (hi, lo uint64, ok bool) {
+	if n.BitLen() > 128 {
+		return 0, 0, false
+	}
+
+	var buf [16]byte
+	n.FillBytes(buf[:])
+	return binary.BigEndian.Uint64(buf[:8]), binary.BigEndian.Uint64(buf[8:]), true
+}
+
+func fromBigInt[T any](n *big.Int, fromMagnitude func(hi, lo uint64, sign int64) (T, error)) (T, error) {
+	sign := int64(n.Sign())
+	hi, lo, ok := magnitudeFromBig(n)
+	if !ok {
+		var zero T
+		return zero, applySign(PositiveOverflowError{}, sign)
+	}
+
+	return fromMagnitude(hi, lo, sign)
+}
+
+func fromBigRat[T any](r *big.Rat, decimals int64, round RoundingMode,
+	fromMagnitude func(hi, lo uint64, sign int64) (T, error)) (T, error) {
+	var zero T
+
+	if !round.isValid() {
+		return zero, InvalidRoundingModeError{}
+	}
+
+	sign := int64(r.Sign())
+	num := new(big.Int).Abs(r.Num())
+	num.Mul(num, pow10Big(decimals))
+	quo, rem := num.QuoRem(num, r.Denom(), new(big.Int))
+
+	hi, lo, ok := magnitudeFromBig(quo)
+	if !ok {
+		return zero, applySign(PositiveOverflowError{}, sign)
+	}
+
+	if rem.Sign() != 0 {
+		// Reduce the remainder to a 63-bit fraction of the denominator, with a sticky bit for
+		// anything left over, as parseDecimal does. This is enough to round correctly in every
+		// mode, since it preserves whether the remainder is below, at, or above one half.
+		frac, rest := rem.QuoRem(rem.Lsh(rem, 62), r.Denom(), new(big.Int))
+		fracBits := frac.Uint64() << 1
+		if rest.Sign() != 0 {
+			fracBits |= 1
+		}
+
+		if ushouldRound64(raw64(lo), raw64(fracBits), raw64(1<<63), round.forSign(sign)) {
+			var carry uint64
+			lo, carry = bits.Add64(lo, 1, 0)
+			hi, carry = bits.Add64(hi, 0, carry)
+
+			if carry != 0 {
+				return zero, applySign(PositiveOverflowError{}, sign)
+			}
+		}
+
+		if hi == 0 && lo == 0 {
+			return zero, UnderflowError{}
+		}
+	}
+
+	return fromMagnitude(hi, lo, sign)
+}
+
+func fromBigFloat[T any](f *big.Float, decimals int64, round RoundingMode,
+	fromMagnitude func(hi, lo uint64, sign int64) (T, error)) (T, error) {
+	if f.IsInf() {
+		var zero T
+		return zero, applySign(PositiveOverflowError{}, int64(f.Sign()))
+	}
+
+	// A finite big.Float is always exactly representable as a big.Rat.
+	r, _ := f.Rat(nil)
+	return fromBigRat(r, decimals, round, fromMagnitude)
+}
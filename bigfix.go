@@ -0,0 +1,384 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"math/big"
+	"strings"
+)
+
+// BigFix is an arbitrary-precision fixed-point value with the same scale as Fix128 (24 decimals),
+// backed by a big.Int. It's an escape hatch for off-chain computations with intermediate values
+// beyond the range of Fix128: every operation it has rounds exactly as the Fix128 operation of the
+// same name would, so a result that is converted back agrees digit-for-digit with the same
+// calculation done in Fix128 (when that doesn't overflow).
+//
+// It only has the arithmetic core of the Fix128 API: comparisons, Add, Sub, Neg, Abs, Mul, Div,
+// FMD, Mod and Sqrt, conversions to and from the fixed types, and the text and JSON encodings.
+// The transcendental functions (Ln, Exp, Pow, etc.) and the binary, SQL and CBOR encodings aren't
+// provided; convert to Fix128 for those.
+//
+// BigFix values are immutable, and the zero value is 0. Unlike the fixed types, the operations
+// never overflow, so Add, Sub and Neg can't fail.
+type BigFix struct {
+	raw *big.Int
+}
+
+// BigFixFromRaw returns the BigFix whose raw value (the value scaled by 10^24) is n.
+func BigFixFromRaw(n *big.Int) BigFix { return BigFix{new(big.Int).Set(n)} }
+
+// Raw returns the raw value of `a`, i.e. `a` scaled by 10^24.
+func (a BigFix) Raw() *big.Int { return new(big.Int).Set(a.bigInt()) }
+
+// ToBigFix returns `a` as a BigFix, this conversion is always exact.
+func (a UFix64) ToBigFix() BigFix { return a.ToUFix128().ToBigFix() }
+
+// ToBigFix returns `a` as a BigFix, this conversion is always exact.
+func (a Fix64) ToBigFix() BigFix { return a.ToFix128().ToBigFix() }
+
+// ToBigFix returns `a` as a BigFix, this conversion is always exact.
+func (a UFix128) ToBigFix() BigFix { return BigFix{a.ToBigInt()} }
+
+// ToBigFix returns `a` as a BigFix, this conversion is always exact.
+func (a Fix128) ToBigFix() BigFix { return BigFix{a.ToBigInt()} }
+
+// ParseBigFix parses a decimal string into a BigFix, in any format ParseFix128 accepts, e.g.
+// "-12.345" or "1.5e40". Digits beyond 24 decimals are rounded as specified. Returns a
+// SyntaxError for invalid input, or an UnderflowError if a non-zero value rounds to zero.
+//
+// Exponents are limited to ±2^20. A non-zero value with a larger exponent is reported as a
+// PositiveOverflowError or NegativeOverflowError, rather than allocating a number with millions of
+// digits. Smaller exponents round as for ParseFix128, as long as the mantissa has fewer than 2^20
+// digits; otherwise they're reported as an OutOfDomainErrorError.
+func ParseBigFix(s string, round RoundingMode) (BigFix, error) {
+	return parseBigFix(s, round, false)
+}
+
+// parseBigFix implements ParseBigFix. In strict mode, as for ParseOptions.Strict, surrounding
+// whitespace is a SyntaxError, and inputs that would need to be rounded are an InexactError.
+func parseBigFix(s string, round RoundingMode, strict bool) (BigFix, error) {
+	if !round.isValid() {
+		return BigFix{}, InvalidRoundingModeError{}
+	}
+
+	if !strict {
+		s = strings.TrimSpace(s)
+	}
+
+	neg := false
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var ok bool
+		if exp, ok = parseExponent(s[i+1:]); !ok {
+			return BigFix{}, SyntaxError{}
+		}
+		s = s[:i]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	digits := intPart + fracPart
+	if len(digits) == 0 {
+		return BigFix{}, SyntaxError{}
+	}
+	for i := 0; i < len(digits); i++ {
+		if !isDigit(digits[i]) {
+			return BigFix{}, SyntaxError{}
+		}
+	}
+
+	if strings.TrimLeft(digits, "0") == "" {
+		return BigFix{}, nil
+	}
+
+	if exp > maxExponent {
+		if neg {
+			return BigFix{}, NegativeOverflowError{}
+		}
+		return BigFix{}, PositiveOverflowError{}
+	}
+
+	// The mantissa is scaled by 10^shift to get the raw value. Dividing by more than one digit
+	// beyond the mantissa rounds the same way as dividing by that, since the value is below a
+	// tenth of the last decimal either way. That makes the clamped value of an exponent below
+	// -maxExponent as good as the real one, unless the mantissa has enough digits to reach it.
+	shift := int64(exp) + Fix128Decimals - int64(len(fracPart))
+	if exp < -maxExponent && -shift <= int64(len(digits)) {
+		return BigFix{}, OutOfDomainErrorError{}
+	}
+
+	mant, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		mant.Neg(mant)
+	}
+
+	if shift >= 0 {
+		return BigFix{mant.Mul(mant, pow10Big(shift))}, nil
+	}
+
+	den := pow10Big(min(-shift, int64(len(digits))+1))
+	if strict && new(big.Int).Rem(mant, den).Sign() != 0 {
+		return BigFix{}, InexactError{}
+	}
+
+	raw := roundQuoBig(mant, den, round)
+	if raw.Sign() == 0 && mant.Sign() != 0 {
+		return BigFix{}, UnderflowError{}
+	}
+
+	return BigFix{raw}, nil
+}
+
+// String returns the exact decimal representation of `a`, with as few fractional digits as
+// possible, but always at least one, as for Fix128.String.
+func (a BigFix) String() string {
+	digits := new(big.Int).Abs(a.bigInt()).String()
+	if len(digits) <= Fix128Decimals {
+		digits = strings.Repeat("0", Fix128Decimals+1-len(digits)) + digits
+	}
+
+	intPart, fracPart := digits[:len(digits)-Fix128Decimals], digits[len(digits)-Fix128Decimals:]
+	fracPart = strings.TrimRight(fracPart, "0")
+	if fracPart == "" {
+		fracPart = "0"
+	}
+
+	if a.IsNeg() {
+		return "-" + intPart + "." + fracPart
+	}
+	return intPart + "." + fracPart
+}
+
+// Append appends the decimal representation of `a` to dst, as returned by String.
+func (a BigFix) Append(dst []byte) []byte { return append(dst, a.String()...) }
+
+// MarshalText implements encoding.TextMarshaler, using the same format as String.
+func (a BigFix) MarshalText() ([]byte, error) { return a.Append(nil), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler, see UFix64.UnmarshalText. Values with more
+// than 24 decimals are rejected with an InexactError rather than rounded.
+func (a *BigFix) UnmarshalText(text []byte) error {
+	res, err := parseBigFix(string(text), RoundTowardZero, true)
+	if err != nil {
+		return err
+	}
+
+	*a = res
+	return nil
+}
+
+// AppendJSON appends the JSON encoding of `a` in the given format to dst.
+func (a BigFix) AppendJSON(dst []byte, format JSONFormat) []byte {
+	return appendJSON(dst, format, a.Append)
+}
+
+// MarshalJSON implements json.Marshaler, see UFix64.MarshalJSON.
+func (a BigFix) MarshalJSON() ([]byte, error) { return a.AppendJSON(nil, DefaultJSONFormat), nil }
+
+// UnmarshalJSON implements json.Unmarshaler, see UFix64.UnmarshalJSON.
+func (a *BigFix) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, a.UnmarshalText) }
+
+// Sign returns -1, 0 or 1 depending on the sign of `a`.
+func (a BigFix) Sign() int { return a.bigInt().Sign() }
+
+// IsZero returns true if `a` is zero.
+func (a BigFix) IsZero() bool { return a.Sign() == 0 }
+
+// IsNeg returns true if `a` is negative.
+func (a BigFix) IsNeg() bool { return a.Sign() < 0 }
+
+// Cmp returns -1, 0 or 1 depending on whether `a` is less than, equal to, or greater than `b`.
+func (a BigFix) Cmp(b BigFix) int { return a.bigInt().Cmp(b.bigInt()) }
+
+// Eq returns true if `a` is equal to `b`.
+func (a BigFix) Eq(b BigFix) bool { return a.Cmp(b) == 0 }
+
+// Lt returns true if `a` is less than `b`.
+func (a BigFix) Lt(b BigFix) bool { return a.Cmp(b) < 0 }
+
+// Gt returns true if `a` is greater than `b`.
+func (a BigFix) Gt(b BigFix) bool { return a.Cmp(b) > 0 }
+
+// Lte returns true if `a` is less than or equal to `b`.
+func (a BigFix) Lte(b BigFix) bool { return a.Cmp(b) <= 0 }
+
+// Gte returns true if `a` is greater than or equal to `b`.
+func (a BigFix) Gte(b BigFix) bool { return a.Cmp(b) >= 0 }
+
+// Neg returns `-a`.
+func (a BigFix) Neg() BigFix { return BigFix{new(big.Int).Neg(a.bigInt())} }
+
+// Abs returns the absolute value of `a`.
+func (a BigFix) Abs() BigFix { return BigFix{new(big.Int).Abs(a.bigInt())} }
+
+// Add returns `a + b`.
+func (a BigFix) Add(b BigFix) BigFix { return BigFix{new(big.Int).Add(a.bigInt(), b.bigInt())} }
+
+// Sub returns `a - b`.
+func (a BigFix) Sub(b BigFix) BigFix { return BigFix{new(big.Int).Sub(a.bigInt(), b.bigInt())} }
+
+// Mul returns the product of `a` and `b`, rounded as for Fix128.Mul, or an error on underflow.
+func (a BigFix) Mul(b BigFix, round RoundingMode) (BigFix, error) {
+	return a.FMD(b, Fix128One.ToBigFix(), round)
+}
+
+// Div returns the quotient of `a` and `b`, rounded as for Fix128.Div, or an error on division by
+// zero or underflow.
+func (a BigFix) Div(b BigFix, round RoundingMode) (BigFix, error) {
+	return a.FMD(Fix128One.ToBigFix(), b, round)
+}
+
+// FMD returns `a * b / c` with a single rounding, as for Fix128.FMD, or an error on division by
+// zero or underflow.
+func (a BigFix) FMD(b, c BigFix, round RoundingMode) (BigFix, error) {
+	if !round.isValid() {
+		return BigFix{}, InvalidRoundingModeError{}
+	}
+
+	if c.IsZero() {
+		return BigFix{}, DivisionByZeroError{}
+	}
+
+	num := new(big.Int).Mul(a.bigInt(), b.bigInt())
+	res := roundQuoBig(num, c.bigInt(), round)
+
+	if res.Sign() == 0 && num.Sign() != 0 {
+		return BigFix{}, UnderflowError{}
+	}
+
+	return BigFix{res}, nil
+}
+
+// Mod returns the remainder of `a` divided by `b`, with the sign of `a`, as for Fix128.Mod, or an
+// error on division by zero. The remainder is always exact.
+func (a BigFix) Mod(b BigFix) (BigFix, error) {
+	if b.IsZero() {
+		return BigFix{}, DivisionByZeroError{}
+	}
+
+	return BigFix{new(big.Int).Rem(a.bigInt(), b.bigInt())}, nil
+}
+
+// Sqrt returns the square root of `a`, rounded as for Fix128.Sqrt, or a domain error if `a` is
+// negative.
+func (a BigFix) Sqrt(round RoundingMode) (BigFix, error) {
+	if a.IsNeg() {
+		return BigFix{}, OutOfDomainErrorError{}
+	}
+
+	if !round.isValid() {
+		return BigFix{}, InvalidRoundingModeError{}
+	}
+
+	x := scaleBig(new(big.Int).Set(a.bigInt()), Fix128Decimals)
+	return BigFix{sqrtRoundBig(x, round)}, nil
+}
+
+// ToFix128 returns `a` as a Fix128, or an error if it's out of range. This conversion is exact,
+// since the types have the same scale.
+func (a BigFix) ToFix128() (Fix128, error) { return fromBigInt(a.bigInt(), fix128FromMagnitude) }
+
+// ToUFix128 returns `a` as a UFix128, or an error if it's out of range, see ToFix128.
+func (a BigFix) ToUFix128() (UFix128, error) { return fromBigInt(a.bigInt(), ufix128FromMagnitude) }
+
+// ToFix64 rounds `a` to a Fix64 as for Fix128.ToFix64, or returns an error if it's out of range.
+func (a BigFix) ToFix64(round RoundingMode) (Fix64, error) {
+	res, err := a.ToFix128()
+	if err != nil {
+		return Fix64Zero, err
+	}
+
+	return res.ToFix64(round)
+}
+
+// ToUFix64 rounds `a` to a UFix64 as for UFix128.ToUFix64, or returns an error if it's out of
+// range.
+func (a BigFix) ToUFix64(round RoundingMode) (UFix64, error) {
+	res, err := a.ToUFix128()
+	if err != nil {
+		return UFix64Zero, err
+	}
+
+	return res.ToUFix64(round)
+}
+
+// bigInt returns the raw value of `a`, which must not be modified.
+func (a BigFix) bigInt() *big.Int {
+	if a.raw == nil {
+		return new(big.Int)
+	}
+
+	return a.raw
+}
+
+// sqrtRoundBig returns the square root of the non-negative x, rounded as specified, with the
+// same rounding as UFix128.Sqrt. The rounding mode must be valid. x is overwritten.
+func sqrtRoundBig(x *big.Int, round RoundingMode) *big.Int {
+	s := new(big.Int).Sqrt(x)
+	rem := x.Sub(x, new(big.Int).Mul(s, s))
+
+	// The result is positive, so the directed modes are equivalent to the symmetric ones. For the
+	// nearest modes, the true root is above s + 1/2 iff x > s^2 + s + 1/4, i.e. iff rem > s.
+	up := false
+	switch round.forSign(1) {
+	case RoundTowardZero:
+	case RoundAwayFromZero:
+		up = rem.Sign() != 0
+	default:
+		// RoundStochastic is treated as rounding to nearest, the same as in UFix128.Sqrt.
+		up = rem.Cmp(s) > 0
+	}
+
+	if up {
+		s.Add(s, big.NewInt(1))
+	}
+
+	return s
+}
+
+// roundQuoBig returns num / den, rounded as specified. The rounding mode must be valid.
+func roundQuoBig(num, den *big.Int, round RoundingMode) *big.Int {
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() == 0 {
+		return quo
+	}
+
+	sign := int64(num.Sign() * den.Sign())
+
+	// Reduce the remainder to a 63-bit fraction of the denominator with a sticky bit, as
+	// fromBigRat does, which is enough to round correctly in every mode.
+	absDen := new(big.Int).Abs(den)
+	rem.Abs(rem)
+	frac, rest := rem.QuoRem(rem.Lsh(rem, 62), absDen, new(big.Int))
+	fracBits := frac.Uint64() << 1
+	if rest.Sign() != 0 {
+		fracBits |= 1
+	}
+
+	// Only the parity of the quotient matters.
+	parity := raw64(quo.Bit(0))
+
+	if ushouldRound64(parity, raw64(fracBits), raw64(1<<63), round.forSign(sign)) {
+		quo.Add(quo, big.NewInt(sign))
+	}
+
+	return quo
+}
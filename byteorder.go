@@ -0,0 +1,104 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import "encoding/binary"
+
+// This file contains fixed-size binary encodings of the raw values on byte slices, in the style of
+// encoding/binary, for use in custom wire formats. For example:
+//
+//	fixedPoint.BigEndian.PutUFix128(buf[8:], amount)
+//	amount = fixedPoint.BigEndian.UFix128FromBytes(buf[8:])
+//
+// The 64-bit types use 8 bytes, and the 128-bit types use 16, in two's complement. The big-endian
+// encoding is the same as MarshalBinary. In the little-endian encoding of the 128-bit types, the
+// whole 128-bit value is little-endian, i.e. the low word comes first. Like encoding/binary, the
+// Put and FromBytes methods panic if the slice is too short.
+
+// BigEndian is the big-endian encoding of the fixed-point types.
+var BigEndian bigEndian
+
+// LittleEndian is the little-endian encoding of the fixed-point types.
+var LittleEndian littleEndian
+
+type bigEndian struct{}
+
+func (bigEndian) PutUFix64(b []byte, v UFix64)   { binary.BigEndian.PutUint64(b, uint64(v)) }
+func (bigEndian) PutFix64(b []byte, v Fix64)     { binary.BigEndian.PutUint64(b, uint64(v)) }
+func (bigEndian) PutUFix128(b []byte, v UFix128) { putRaw128BE(b, raw128(v)) }
+func (bigEndian) PutFix128(b []byte, v Fix128)   { putRaw128BE(b, raw128(v)) }
+
+func (bigEndian) AppendUFix64(b []byte, v UFix64) []byte {
+	return binary.BigEndian.AppendUint64(b, uint64(v))
+}
+func (bigEndian) AppendFix64(b []byte, v Fix64) []byte {
+	return binary.BigEndian.AppendUint64(b, uint64(v))
+}
+func (bigEndian) AppendUFix128(b []byte, v UFix128) []byte { return appendRaw128(b, raw128(v)) }
+func (bigEndian) AppendFix128(b []byte, v Fix128) []byte   { return appendRaw128(b, raw128(v)) }
+
+func (bigEndian) UFix64FromBytes(b []byte) UFix64   { return UFix64(binary.BigEndian.Uint64(b)) }
+func (bigEndian) Fix64FromBytes(b []byte) Fix64     { return Fix64(binary.BigEndian.Uint64(b)) }
+func (bigEndian) UFix128FromBytes(b []byte) UFix128 { return UFix128(readRaw128(b)) }
+func (bigEndian) Fix128FromBytes(b []byte) Fix128   { return Fix128(readRaw128(b)) }
+
+func (bigEndian) String() string { return "BigEndian" }
+
+type littleEndian struct{}
+
+func (littleEndian) PutUFix64(b []byte, v UFix64)   { binary.LittleEndian.PutUint64(b, uint64(v)) }
+func (littleEndian) PutFix64(b []byte, v Fix64)     { binary.LittleEndian.PutUint64(b, uint64(v)) }
+func (littleEndian) PutUFix128(b []byte, v UFix128) { putRaw128LE(b, raw128(v)) }
+func (littleEndian) PutFix128(b []byte, v Fix128)   { putRaw128LE(b, raw128(v)) }
+
+func (littleEndian) AppendUFix64(b []byte, v UFix64) []byte {
+	return binary.LittleEndian.AppendUint64(b, uint64(v))
+}
+func (littleEndian) AppendFix64(b []byte, v Fix64) []byte {
+	return binary.LittleEndian.AppendUint64(b, uint64(v))
+}
+func (littleEndian) AppendUFix128(b []byte, v UFix128) []byte { return appendRaw128LE(b, raw128(v)) }
+func (littleEndian) AppendFix128(b []byte, v Fix128) []byte   { return appendRaw128LE(b, raw128(v)) }
+
+func (littleEndian) UFix64FromBytes(b []byte) UFix64   { return UFix64(binary.LittleEndian.Uint64(b)) }
+func (littleEndian) Fix64FromBytes(b []byte) Fix64     { return Fix64(binary.LittleEndian.Uint64(b)) }
+func (littleEndian) UFix128FromBytes(b []byte) UFix128 { return UFix128(readRaw128LE(b)) }
+func (littleEndian) Fix128FromBytes(b []byte) Fix128   { return Fix128(readRaw128LE(b)) }
+
+func (littleEndian) String() string { return "LittleEndian" }
+
+func putRaw128BE(b []byte, a raw128) {
+	_ = b[15] // Bounds check, so nothing is written if b is too short
+	binary.BigEndian.PutUint64(b, uint64(a.Hi))
+	binary.BigEndian.PutUint64(b[8:], uint64(a.Lo))
+}
+
+func putRaw128LE(b []byte, a raw128) {
+	_ = b[15] // Bounds check, so nothing is written if b is too short
+	binary.LittleEndian.PutUint64(b, uint64(a.Lo))
+	binary.LittleEndian.PutUint64(b[8:], uint64(a.Hi))
+}
+
+func appendRaw128LE(dst []byte, a raw128) []byte {
+	dst = binary.LittleEndian.AppendUint64(dst, uint64(a.Lo))
+	return binary.LittleEndian.AppendUint64(dst, uint64(a.Hi))
+}
+
+func readRaw128LE(b []byte) raw128 {
+	_ = b[15]
+	return raw128{raw64(binary.LittleEndian.Uint64(b[8:])), raw64(binary.LittleEndian.Uint64(b))}
+}
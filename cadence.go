@@ -0,0 +1,143 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import "strings"
+
+// This file contains the bridge to Cadence's UFix64 and Fix64 types. Their values are stored the
+// same way as UFix64 and Fix64 (a 64-bit integer scaled by 10^8), so the raw values can be
+// converted directly, e.g. UFix64(v) for a cadence.UFix64 v. The helpers below cover the text
+// form, which is where the two differ: Cadence always renders all 8 fractional digits, and its
+// literals always have a decimal point, may have '_' separators, and never have an exponent.
+
+// CadenceString returns `a` exactly as Cadence renders a UFix64, with all 8 fractional digits,
+// e.g. "1.00000000" or "0.00000001".
+func (a UFix64) CadenceString() string {
+	return string(appendDecimal(nil, false, 0, uint64(a), Fix64Decimals, false))
+}
+
+// CadenceString returns `a` exactly as Cadence renders a Fix64, e.g. "-12.50000000".
+func (a Fix64) CadenceString() string {
+	aUnsigned, sign := a.Abs()
+	return string(appendDecimal(nil, sign < 0, 0, uint64(aUnsigned), Fix64Decimals, false))
+}
+
+// CadenceString converts `a` to a Cadence UFix64, rounding as specified, and returns it as Cadence
+// renders it. It returns the same errors as ToUFix64.
+func (a UFix128) CadenceString(round RoundingMode) (string, error) {
+	res, err := a.ToUFix64(round)
+	if err != nil {
+		return "", err
+	}
+
+	return res.CadenceString(), nil
+}
+
+// CadenceString converts `a` to a Cadence Fix64, rounding as specified, and returns it as Cadence
+// renders it. It returns the same errors as ToFix64.
+func (a Fix128) CadenceString(round RoundingMode) (string, error) {
+	res, err := a.ToFix64(round)
+	if err != nil {
+		return "", err
+	}
+
+	return res.CadenceString(), nil
+}
+
+// ParseCadenceUFix64 parses a Cadence UFix64 literal, or a value rendered by Cadence, e.g.
+// "1.0", "1_000.5" or "0.00000001". The input must have a decimal point, with at least one digit
+// on each side, and at most 8 fractional digits; anything else returns a SyntaxError, and values
+// out of range return a PositiveOverflowError.
+func ParseCadenceUFix64(s string) (UFix64, error) {
+	if !isCadenceLiteral(s, false) {
+		return UFix64Zero, SyntaxError{}
+	}
+
+	return textParser.ParseUFix64(strings.ReplaceAll(s, "_", ""))
+}
+
+// ParseCadenceFix64 parses a Cadence Fix64 literal, which is like a UFix64 literal (see
+// ParseCadenceUFix64) with an optional leading '-'.
+func ParseCadenceFix64(s string) (Fix64, error) {
+	if !isCadenceLiteral(s, true) {
+		return Fix64Zero, SyntaxError{}
+	}
+
+	return textParser.ParseFix64(strings.ReplaceAll(s, "_", ""))
+}
+
+// UFix128FromCadenceUFix64 parses a Cadence UFix64 literal as for ParseCadenceUFix64, and converts
+// it to a UFix128, which is always exact.
+func UFix128FromCadenceUFix64(s string) (UFix128, error) {
+	res, err := ParseCadenceUFix64(s)
+	if err != nil {
+		return UFix128Zero, err
+	}
+
+	return res.ToUFix128(), nil
+}
+
+// Fix128FromCadenceFix64 parses a Cadence Fix64 literal as for ParseCadenceFix64, and converts it
+// to a Fix128, which is always exact.
+func Fix128FromCadenceFix64(s string) (Fix128, error) {
+	res, err := ParseCadenceFix64(s)
+	if err != nil {
+		return Fix128Zero, err
+	}
+
+	return res.ToFix128(), nil
+}
+
+// isCadenceLiteral checks the syntax of a Cadence fixed-point literal: digits, a decimal point,
+// and 1 to 8 more digits, where '_' may separate digits (but not lead or trail either part).
+func isCadenceLiteral(s string, signed bool) bool {
+	if signed && len(s) > 0 && s[0] == '-' {
+		s = s[1:]
+	}
+
+	intPart, fracPart, ok := strings.Cut(s, ".")
+	if !ok {
+		return false
+	}
+
+	if _, ok := cadenceDigits(intPart); !ok {
+		return false
+	}
+
+	fracDigits, ok := cadenceDigits(fracPart)
+	return ok && fracDigits <= Fix64Decimals
+}
+
+// cadenceDigits returns the number of digits in s, if it's a valid run of digits with optional '_'
+// separators.
+func cadenceDigits(s string) (int, bool) {
+	if len(s) == 0 || s[0] == '_' || s[len(s)-1] == '_' {
+		return 0, false
+	}
+
+	n := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case isDigit(s[i]):
+			n++
+		case s[i] != '_':
+			return 0, false
+		}
+	}
+
+	return n, true
+}
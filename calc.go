@@ -0,0 +1,184 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+// calcValue is the set of types supported by Calc.
+type calcValue interface {
+	UFix64 | Fix64 | UFix128 | Fix128
+}
+
+// Calc evaluates a chain of arithmetic operations, keeping all intermediate values in extended
+// precision (fix192, which has 64 more fractional bits than Fix128), and only rounding to the
+// target type once, when Result is called. e.g.:
+//
+//	res, err := NewCalc(a).Mul(b).Add(c).Div(d).Result(RoundHalfEven)
+//
+// The first error encountered is remembered and returned by Result, any operations after an error
+// are ignored, so there's no need to check for errors after every step.
+//
+// Intermediate values are signed, even when T is an unsigned type, so a chain like
+// NewCalc(a).Sub(b).Add(c) only fails if the final result is negative. Intermediate values can have
+// a magnitude of up to 2**128 / 10**24, beyond that an overflow error is recorded.
+//
+// Note that the intermediate results of Mul and Div are rounded to the nearest fix192 value, so
+// the final result is not always correctly rounded, but the error from intermediate rounding is
+// much smaller than the precision of T.
+type Calc[T calcValue] struct {
+	mag  fix192
+	sign int64
+	err  error
+}
+
+// NewCalc starts a new calculation with the initial value `a`.
+func NewCalc[T calcValue](a T) *Calc[T] {
+	mag, sign := calcAbs(a)
+
+	return &Calc[T]{mag: mag, sign: sign}
+}
+
+// Add adds `b` to the current value.
+func (c *Calc[T]) Add(b T) *Calc[T] {
+	mag, sign := calcAbs(b)
+
+	return c.add(mag, sign)
+}
+
+// Sub subtracts `b` from the current value.
+func (c *Calc[T]) Sub(b T) *Calc[T] {
+	mag, sign := calcAbs(b)
+
+	return c.add(mag, -sign)
+}
+
+// Mul multiplies the current value by `b`.
+func (c *Calc[T]) Mul(b T) *Calc[T] {
+	if c.err != nil {
+		return c
+	}
+
+	mag, sign := calcAbs(b)
+	c.sign *= sign
+	c.mag, c.err = c.mag.umul(mag)
+	c.err = applySign(c.err, c.sign)
+
+	return c
+}
+
+// Div divides the current value by `b`.
+func (c *Calc[T]) Div(b T) *Calc[T] {
+	if c.err != nil {
+		return c
+	}
+
+	mag, sign := calcAbs(b)
+	c.sign *= sign
+	c.mag, c.err = c.mag.udiv(mag)
+	c.err = applySign(c.err, c.sign)
+
+	return c
+}
+
+// Result rounds the current value to T using the given rounding mode, and returns it along with
+// the first error encountered during the calculation (if any).
+func (c *Calc[T]) Result(round RoundingMode) (T, error) {
+	var zero T
+
+	if c.err != nil {
+		return zero, c.err
+	}
+
+	if c.mag.isZero() {
+		return zero, nil
+	}
+
+	var res any
+	var err error
+
+	switch any(zero).(type) {
+	case UFix64:
+		if c.sign < 0 {
+			return zero, NegativeOverflowError{}
+		}
+		res, err = c.mag.toUFix64(round)
+	case UFix128:
+		if c.sign < 0 {
+			return zero, NegativeOverflowError{}
+		}
+		res, err = c.mag.toUFix128(round)
+	case Fix64:
+		var mag UFix64
+		if mag, err = c.mag.toUFix64(round.forSign(c.sign)); err == nil {
+			res, err = mag.ApplySign(c.sign)
+		}
+	case Fix128:
+		var mag UFix128
+		if mag, err = c.mag.toUFix128(round.forSign(c.sign)); err == nil {
+			res, err = mag.ApplySign(c.sign)
+		}
+	}
+
+	if err != nil {
+		return zero, applySign(err, c.sign)
+	}
+
+	return res.(T), nil
+}
+
+// add adds a value with the given magnitude and sign to the current value.
+func (c *Calc[T]) add(mag fix192, sign int64) *Calc[T] {
+	if c.err != nil {
+		return c
+	}
+
+	if sign == c.sign {
+		var carry uint64
+
+		if c.mag, carry = add192(c.mag, mag, 0); carry != 0 {
+			c.err = applySign(PositiveOverflowError{}, c.sign)
+		}
+	} else if c.mag.ult(mag) {
+		c.mag, c.sign = mag.sub(c.mag), sign
+	} else {
+		c.mag = c.mag.sub(mag)
+	}
+
+	if c.mag.isZero() {
+		c.sign = 1
+	}
+
+	return c
+}
+
+// calcAbs converts `a` into its magnitude as a fix192 and its sign (1 or -1).
+func calcAbs[T calcValue](a T) (fix192, int64) {
+	switch a := any(a).(type) {
+	case UFix64:
+		return a.toFix192(), 1
+	case Fix64:
+		mag, sign := a.Abs()
+		return mag.toFix192(), sign
+	case UFix128:
+		return a.toFix192(), 1
+	case Fix128:
+		mag, sign := a.Abs()
+		return mag.toFix192(), sign
+	}
+
+	// Unreachable, the type constraint only allows the types above
+	debugPanic("unsupported type")
+	return fix192Zero, 1
+}
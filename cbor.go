@@ -0,0 +1,293 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"encoding/binary"
+	"strconv"
+)
+
+// This file implements CBOR (RFC 8949) encoding for the fixed-point types, as a decimal fraction
+// (tag 4), i.e. [exponent, mantissa] where the value is mantissa * 10^exponent. The methods match
+// the Marshaler and Unmarshaler interfaces of github.com/fxamacker/cbor, so the types can be used
+// directly with that library, but the encoding is implemented here to avoid the dependency.
+//
+// The encoding is deterministic: the exponent is always the negated number of decimals of the
+// type (-8 or -24), and the mantissa is the raw value, using the shortest integer encoding, or a
+// bignum (tag 2 or 3) without leading zero bytes if it doesn't fit in 64 bits. Decoding accepts
+// any decimal fraction whose value can be represented exactly.
+
+// MarshalCBOR encodes `a` as a CBOR decimal fraction.
+func (a UFix64) MarshalCBOR() ([]byte, error) {
+	return appendCBOR(nil, false, raw256{Lo: raw128{Lo: raw64(a)}}, Fix64Decimals), nil
+}
+
+// MarshalCBOR encodes `a` as a CBOR decimal fraction.
+func (a Fix64) MarshalCBOR() ([]byte, error) {
+	aUnsigned, sign := a.Abs()
+	return appendCBOR(nil, sign < 0, raw256{Lo: raw128{Lo: raw64(aUnsigned)}}, Fix64Decimals), nil
+}
+
+// MarshalCBOR encodes `a` as a CBOR decimal fraction.
+func (a UFix128) MarshalCBOR() ([]byte, error) {
+	return appendCBOR(nil, false, raw256{Lo: raw128(a)}, Fix128Decimals), nil
+}
+
+// MarshalCBOR encodes `a` as a CBOR decimal fraction.
+func (a Fix128) MarshalCBOR() ([]byte, error) {
+	aUnsigned, sign := a.Abs()
+	return appendCBOR(nil, sign < 0, raw256{Lo: raw128(aUnsigned)}, Fix128Decimals), nil
+}
+
+// MarshalCBOR encodes `a` as a CBOR decimal fraction.
+func (a UFix256) MarshalCBOR() ([]byte, error) {
+	return appendCBOR(nil, false, raw256(a), Fix256Decimals), nil
+}
+
+// MarshalCBOR encodes `a` as a CBOR decimal fraction.
+func (a Fix256) MarshalCBOR() ([]byte, error) {
+	aUnsigned, sign := a.Abs()
+	return appendCBOR(nil, sign < 0, raw256(aUnsigned), Fix256Decimals), nil
+}
+
+// UnmarshalCBOR decodes a CBOR decimal fraction into `a`. It returns an InvalidEncodingError if
+// the data isn't a single well-formed decimal fraction, and the usual errors (e.g. overflow, or
+// InexactError) if the value can't be represented exactly. `a` is unchanged on error.
+func (a *UFix64) UnmarshalCBOR(data []byte) error {
+	return unmarshalCBOR(data, a, textParser.ParseUFix64)
+}
+
+// UnmarshalCBOR decodes a CBOR decimal fraction into `a`, see UFix64.UnmarshalCBOR.
+func (a *Fix64) UnmarshalCBOR(data []byte) error {
+	return unmarshalCBOR(data, a, textParser.ParseFix64)
+}
+
+// UnmarshalCBOR decodes a CBOR decimal fraction into `a`, see UFix64.UnmarshalCBOR.
+func (a *UFix128) UnmarshalCBOR(data []byte) error {
+	return unmarshalCBOR(data, a, textParser.ParseUFix128)
+}
+
+// UnmarshalCBOR decodes a CBOR decimal fraction into `a`, see UFix64.UnmarshalCBOR.
+func (a *Fix128) UnmarshalCBOR(data []byte) error {
+	return unmarshalCBOR(data, a, textParser.ParseFix128)
+}
+
+// UnmarshalCBOR decodes a CBOR decimal fraction into `a`, see UFix64.UnmarshalCBOR.
+func (a *UFix256) UnmarshalCBOR(data []byte) error {
+	return unmarshalCBOR(data, a, textParser.ParseUFix256)
+}
+
+// UnmarshalCBOR decodes a CBOR decimal fraction into `a`, see UFix64.UnmarshalCBOR.
+func (a *Fix256) UnmarshalCBOR(data []byte) error {
+	return unmarshalCBOR(data, a, textParser.ParseFix256)
+}
+
+// CBOR major types and tags
+const (
+	cborUint     = 0
+	cborNegInt   = 1
+	cborBytes    = 2
+	cborArray    = 4
+	cborTag      = 6
+	cborTagPos   = 2 // Positive bignum
+	cborTagNeg   = 3 // Negative bignum
+	cborTagFrac  = 4 // Decimal fraction
+	cborMaxShort = 23
+)
+
+// appendCBORHead appends the head of a CBOR data item, i.e. the major type and argument, using
+// the shortest possible encoding.
+func appendCBORHead(dst []byte, major byte, arg uint64) []byte {
+	major <<= 5
+
+	switch {
+	case arg <= cborMaxShort:
+		return append(dst, major|byte(arg))
+	case arg <= 0xff:
+		return append(dst, major|24, byte(arg))
+	case arg <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(dst, major|25), uint16(arg))
+	case arg <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(dst, major|26), uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64(append(dst, major|27), arg)
+	}
+}
+
+// appendCBOR appends the decimal fraction for the magnitude `a` with the given sign and number of
+// decimals.
+func appendCBOR(dst []byte, neg bool, a raw256, decimals int) []byte {
+	dst = appendCBORHead(dst, cborTag, cborTagFrac)
+	dst = appendCBORHead(dst, cborArray, 2)
+	dst = appendCBORHead(dst, cborNegInt, uint64(decimals-1))
+
+	// Negative integers are encoded as -1 - n, so encode the magnitude minus one.
+	major, tag := byte(cborUint), uint64(cborTagPos)
+	if neg {
+		a, _ = sub256(a, raw256{Lo: raw128{Lo: 1}}, 0)
+		major, tag = cborNegInt, cborTagNeg
+	}
+
+	if isZero128(a.Hi) && a.Lo.Hi == 0 {
+		return appendCBORHead(dst, major, uint64(a.Lo.Lo))
+	}
+
+	var buf [32]byte
+	binary.BigEndian.PutUint64(buf[:8], uint64(a.Hi.Hi))
+	binary.BigEndian.PutUint64(buf[8:16], uint64(a.Hi.Lo))
+	binary.BigEndian.PutUint64(buf[16:24], uint64(a.Lo.Hi))
+	binary.BigEndian.PutUint64(buf[24:], uint64(a.Lo.Lo))
+
+	n := 32 - int(leadingZeroBits256(a))/8
+
+	dst = appendCBORHead(dst, cborTag, tag)
+	dst = appendCBORHead(dst, cborBytes, uint64(n))
+	return append(dst, buf[32-n:]...)
+}
+
+// cborDecoder reads CBOR data items from a byte slice.
+type cborDecoder struct {
+	data []byte
+}
+
+// head reads the head of the next data item, returning its major type and argument. Indefinite
+// lengths aren't supported, as they're never needed here.
+func (d *cborDecoder) head() (byte, uint64, bool) {
+	if len(d.data) == 0 {
+		return 0, 0, false
+	}
+
+	major, info := d.data[0]>>5, d.data[0]&0x1f
+	d.data = d.data[1:]
+
+	if info <= cborMaxShort {
+		return major, uint64(info), true
+	}
+
+	if info > 27 {
+		return 0, 0, false
+	}
+
+	n := 1 << (info - 24)
+	if len(d.data) < n {
+		return 0, 0, false
+	}
+
+	var arg uint64
+	for _, b := range d.data[:n] {
+		arg = arg<<8 | uint64(b)
+	}
+	d.data = d.data[n:]
+
+	return major, arg, true
+}
+
+// integer reads an integer or a bignum of up to 256 bits, returning its sign and magnitude.
+func (d *cborDecoder) integer() (neg bool, mag raw256, ok bool) {
+	major, arg, ok := d.head()
+	if !ok {
+		return false, raw256Zero, false
+	}
+
+	switch {
+	case major == cborUint || major == cborNegInt:
+		mag.Lo.Lo = raw64(arg)
+	case major == cborTag && (arg == cborTagPos || arg == cborTagNeg):
+		major = cborUint
+		if arg == cborTagNeg {
+			major = cborNegInt
+		}
+
+		bytesMajor, n, ok := d.head()
+		if !ok || bytesMajor != cborBytes || n > uint64(len(d.data)) {
+			return false, raw256Zero, false
+		}
+
+		content := d.data[:n]
+		d.data = d.data[n:]
+
+		for len(content) > 0 && content[0] == 0 {
+			content = content[1:]
+		}
+		if len(content) > 32 {
+			return false, raw256Zero, false
+		}
+
+		for _, b := range content {
+			mag = shiftLeft256(mag, 8)
+			mag.Lo.Lo |= raw64(b)
+		}
+	default:
+		return false, raw256Zero, false
+	}
+
+	if major == cborNegInt {
+		// The value is -1 - n, so the magnitude is n + 1.
+		var carry uint64
+		mag, carry = add256(mag, raw256{Lo: raw128{Lo: 1}}, 0)
+		if carry != 0 {
+			return false, raw256Zero, false
+		}
+		neg = true
+	}
+
+	return neg, mag, true
+}
+
+func unmarshalCBOR[T any](data []byte, dst *T, parse func(string) (T, error)) error {
+	d := cborDecoder{data}
+
+	if major, arg, ok := d.head(); !ok || major != cborTag || arg != cborTagFrac {
+		return InvalidEncodingError{}
+	}
+	if major, arg, ok := d.head(); !ok || major != cborArray || arg != 2 {
+		return InvalidEncodingError{}
+	}
+
+	expNeg, exp, ok := d.integer()
+	if !ok || !isZero128(exp.Hi) || exp.Lo.Hi != 0 || exp.Lo.Lo > maxExponent {
+		return InvalidEncodingError{}
+	}
+
+	neg, mag, ok := d.integer()
+	if !ok || len(d.data) != 0 {
+		return InvalidEncodingError{}
+	}
+
+	// Converting the value through its exact decimal form reuses all of the range and exactness
+	// checks of the parser.
+	var buf [maxDecimalLen256 + 8]byte
+	var s []byte
+	if isZero128(mag.Hi) {
+		s = appendDecimal(buf[:0], neg, uint64(mag.Lo.Hi), uint64(mag.Lo.Lo), 0, false)
+	} else {
+		s = appendDecimal256(buf[:0], neg, mag, 0)
+	}
+	s = append(s, 'e')
+	if expNeg {
+		s = append(s, '-')
+	}
+	s = strconv.AppendUint(s, uint64(exp.Lo.Lo), 10)
+
+	res, err := parse(string(s))
+	if err != nil {
+		return err
+	}
+
+	*dst = res
+	return nil
+}
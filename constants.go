@@ -8,9 +8,12 @@ const Fix64Zero = Fix64(0)
 const UFix64One = UFix64(1 * Fix64Scale) // 1 in fix64
 const Fix64One = Fix64(1 * Fix64Scale) // 1 in fix64
 const Fix64OneLeadingZeros = 37 // Number of leading zero bits for Fix64One
+const Fix64Decimals = 8 // Number of decimal places for Fix64 and UFix64
 const UFix64Max = UFix64(0xffffffffffffffff) // Max value for UFix64
 const Fix64Max = Fix64(0x7fffffffffffffff) // Max value for Fix64
 const Fix64Min = Fix64(0x8000000000000000) // Min value for Fix64
+const UFix64Iota = UFix64(1) // Smallest positive value for UFix64 (1e-8)
+const Fix64Iota = Fix64(1) // Smallest positive value for Fix64 (1e-8)
 
 // Basic constants for Fix128 and UFix128
 const Fix128Scale = 1E+24 // NOTE: Bigger than uint64! Mostly here as documentation...
@@ -19,9 +22,12 @@ var Fix128Zero = Fix128{Hi: 0x0000000000000000, Lo: 0x0000000000000000}
 var UFix128One = UFix128{Hi: 0x000000000000d3c2, Lo: 0x1bcecceda1000000}
 var Fix128One = Fix128{Hi: 0x000000000000d3c2, Lo: 0x1bcecceda1000000}
 const Fix128OneLeadingZeros = 48 // Number of leading zero bits for Fix128One
+const Fix128Decimals = 24 // Number of decimal places for Fix128 and UFix128
 var UFix128Max = UFix128{Hi: 0xffffffffffffffff, Lo: 0xffffffffffffffff}
 var Fix128Max = Fix128{Hi: 0x7fffffffffffffff, Lo: 0xffffffffffffffff}
 var Fix128Min = Fix128{Hi: 0x8000000000000000, Lo: 0x0000000000000000}
+var UFix128Iota = UFix128{Hi: 0x0000000000000000, Lo: 0x0000000000000001}
+var Fix128Iota = Fix128{Hi: 0x0000000000000000, Lo: 0x0000000000000001}
 
 // Transcendental constants
 const Fix64Pi = Fix64(0x0000000012b9b0a1)
@@ -0,0 +1,115 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+// Condition is a set of exceptional conditions that can occur during an arithmetic operation,
+// used by Context to decide which conditions are reported as errors and to record which
+// conditions have occurred.
+type Condition uint32
+
+const (
+	// Inexact is signalled when the result had to be rounded. Note that an overflow or underflow
+	// is always inexact as well.
+	Inexact Condition = 1 << iota
+	// Overflow is signalled when the magnitude of the result is too large to be represented.
+	// When not trapped, the result saturates to the largest (or smallest) representable value.
+	Overflow
+	// Underflow is signalled when a non-zero result is too small to be represented. When not
+	// trapped, the result is zero.
+	Underflow
+
+	// DefaultTraps is the set of conditions that are reported as errors by a Context returned
+	// from NewContext, matching the behaviour of the plain arithmetic methods.
+	DefaultTraps = Overflow | Underflow
+)
+
+// Context bundles a rounding mode with a policy for handling exceptional conditions, so the
+// rounding mode doesn't need to be repeated at every call site, e.g.:
+//
+//	ctx := NewContext(RoundHalfEven)
+//	c, err := ctx.MulUFix64(a, b)
+//
+// Conditions in Traps are reported as errors, other conditions are silently handled (see the
+// Condition constants for details). In both cases, the condition is recorded in Flags, which is
+// never cleared by the Context itself; callers can check Flags after a sequence of operations and
+// reset it to zero as needed. Division by zero and domain errors are always reported as errors.
+//
+// If WrapErrors is set, errors are wrapped in an *OpError recording the operation and operands.
+//
+// A Context records flags, so it must not be used concurrently from multiple goroutines.
+type Context struct {
+	Rounding   RoundingMode
+	Traps      Condition
+	Flags      Condition
+	WrapErrors bool
+}
+
+// NewContext returns a Context with the given rounding mode that traps DefaultTraps.
+func NewContext(round RoundingMode) *Context {
+	return &Context{Rounding: round, Traps: DefaultTraps}
+}
+
+// contextResult applies the context policy to the result of an operation that returned `res`, and
+// reported whether the result was exact (and any error) in `exact` and `err`. `max` and `min` are
+// the values used when an untrapped overflow occurs.
+func contextResult[T any](ctx *Context, res T, exact bool, err error, max, min T) (T, error) {
+	var cond Condition
+	var zero T
+
+	switch err.(type) {
+	case nil:
+		if exact {
+			return res, nil
+		}
+		cond = Inexact
+	case PositiveOverflowError:
+		cond, res = Overflow|Inexact, max
+	case NegativeOverflowError:
+		cond, res = Overflow|Inexact, min
+	case UnderflowError:
+		cond, res = Underflow|Inexact, zero
+	default:
+		return res, err
+	}
+
+	ctx.Flags |= cond
+
+	switch trapped := cond & ctx.Traps; {
+	case trapped == 0:
+		return res, nil
+	case trapped&Overflow != 0 || trapped&Underflow != 0:
+		return zero, err
+	default:
+		return zero, InexactError{}
+	}
+}
+
+// wrapOpError wraps `err` in an *OpError if `ctx` is configured to do so.
+func wrapOpError[T any](ctx *Context, err error, op string, operands ...T) error {
+	if err == nil || !ctx.WrapErrors {
+		return err
+	}
+
+	// Copy the operands here, rather than taking ...any, so the common (no error) path doesn't
+	// need to allocate.
+	values := make([]any, len(operands))
+	for i, operand := range operands {
+		values[i] = operand
+	}
+
+	return &OpError{Op: op, Operands: values, Err: err}
+}
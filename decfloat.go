@@ -0,0 +1,214 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// DecFloat is a decimal floating-point value, Mant × 10^Exp, for intermediate calculations (e.g.
+// analytics over values of very different magnitudes) where range matters more than a fixed
+// scale. The mantissa keeps the full 128 bits of precision of a Fix128, and each operation rounds
+// its exact result once, to as many digits as fit in the mantissa.
+//
+// DecFloat values aren't normalized: the same value can have several representations (e.g.
+// 1.5 × 10^3 and 15 × 10^2), so they shouldn't be compared with ==. Convert the final result
+// back with ToFix128.
+type DecFloat struct {
+	Mant Fix128
+	Exp  int32
+}
+
+// decFloatMaxShift bounds the exponent differences handled exactly, see Add and ToFix128. A
+// Fix128 mantissa has at most 39 digits, so a value shifted further than that is entirely below
+// the rounding position, and only its sign matters.
+const decFloatMaxShift = 80
+
+// NewDecFloat returns the DecFloat with the value mant × 10^exp.
+func NewDecFloat(mant Fix128, exp int32) DecFloat { return DecFloat{Mant: mant, Exp: exp} }
+
+// ToDecFloat returns `a` as a DecFloat, this conversion is always exact.
+func (a Fix128) ToDecFloat() DecFloat { return DecFloat{Mant: a} }
+
+// IsZero returns true if `a` is zero.
+func (a DecFloat) IsZero() bool { return a.Mant.IsZero() }
+
+// IsNeg returns true if `a` is negative.
+func (a DecFloat) IsNeg() bool { return a.Mant.IsNeg() }
+
+// String returns `a` in scientific notation, e.g. "1.5e3", or just the mantissa if the exponent
+// is zero.
+func (a DecFloat) String() string {
+	if a.Exp == 0 {
+		return a.Mant.String()
+	}
+
+	return a.Mant.String() + "e" + strconv.Itoa(int(a.Exp))
+}
+
+// ToFix128 returns `a` as a Fix128, rounding as specified if it has more than 24 decimals. This is
+// exact whenever `a` is representable as a Fix128. Returns an error if `a` is out of range, or an
+// UnderflowError if a non-zero value rounds to zero.
+func (a DecFloat) ToFix128(round RoundingMode) (Fix128, error) {
+	if a.IsZero() {
+		if !round.isValid() {
+			return Fix128Zero, InvalidRoundingModeError{}
+		}
+		return Fix128Zero, nil
+	}
+
+	// Beyond the max shift, the value is either far too large, or far below the last decimal,
+	// where it rounds the same way as at the max shift.
+	exp := max(min(int64(a.Exp), decFloatMaxShift), -decFloatMaxShift)
+
+	num, den := a.Mant.ToBigInt(), big.NewInt(1)
+	if exp >= 0 {
+		num.Mul(num, pow10Big(exp))
+	} else {
+		den = pow10Big(-exp)
+	}
+
+	return fromBigRat(new(big.Rat).SetFrac(num, den), 0, round, fix128FromMagnitude)
+}
+
+// Neg returns `-a`. This is exact, except for a mantissa of Fix128Min, whose negation is rounded
+// to one less digit.
+func (a DecFloat) Neg() DecFloat {
+	if res, err := a.Mant.Neg(); err == nil {
+		return DecFloat{Mant: res, Exp: a.Exp}
+	}
+
+	// Can't fail: the result has the magnitude of a valid mantissa.
+	res, _ := decFloatFromRat(new(big.Int).Neg(a.Mant.ToBigInt()), nil, a.exp(), RoundNearestHalfEven)
+	return res
+}
+
+// Add returns `a + b`, rounded as specified, or an error if the exponent of the result is out of
+// range.
+func (a DecFloat) Add(b DecFloat, round RoundingMode) (DecFloat, error) {
+	if !round.isValid() {
+		return DecFloat{}, InvalidRoundingModeError{}
+	}
+
+	if b.IsZero() {
+		return a, nil
+	}
+	if a.IsZero() {
+		return b, nil
+	}
+
+	// Align the exponents, by scaling up the value with the larger exponent, so the sum is exact.
+	// A much smaller value only affects the rounding (through its sign), so it can be replaced by
+	// a single unit just past the max shift.
+	if a.Exp < b.Exp {
+		a, b = b, a
+	}
+
+	small, exp := b.Mant.ToBigInt(), b.exp()
+	if shift := a.exp() - exp; shift > decFloatMaxShift {
+		small.SetInt64(int64(small.Sign()))
+		exp = a.exp() - decFloatMaxShift - 1
+	}
+
+	num := a.Mant.ToBigInt()
+	num.Mul(num, pow10Big(a.exp()-exp)).Add(num, small)
+
+	return decFloatFromRat(num, nil, exp, round)
+}
+
+// Sub returns `a - b`, rounded as specified, see Add.
+func (a DecFloat) Sub(b DecFloat, round RoundingMode) (DecFloat, error) {
+	return a.Add(b.Neg(), round)
+}
+
+// Mul returns `a * b`, rounded as specified, or an error if the exponent of the result is out of
+// range.
+func (a DecFloat) Mul(b DecFloat, round RoundingMode) (DecFloat, error) {
+	num := a.Mant.ToBigInt()
+	num.Mul(num, b.Mant.ToBigInt())
+
+	return decFloatFromRat(num, nil, a.exp()+b.exp(), round)
+}
+
+// Div returns `a / b`, rounded as specified, or an error on division by zero or if the exponent
+// of the result is out of range.
+func (a DecFloat) Div(b DecFloat, round RoundingMode) (DecFloat, error) {
+	if b.IsZero() {
+		return DecFloat{}, DivisionByZeroError{}
+	}
+
+	return decFloatFromRat(a.Mant.ToBigInt(), b.Mant.ToBigInt(), a.exp()-b.exp(), round)
+}
+
+// exp returns the exponent of the raw mantissa, i.e. `a` = raw(a.Mant) × 10^a.exp().
+func (a DecFloat) exp() int64 { return int64(a.Exp) - Fix128Decimals }
+
+// decFloatFromRat rounds the exact value num / den × 10^exp (where den is 1 if nil) to the
+// DecFloat with the most digits in its mantissa.
+func decFloatFromRat(num, den *big.Int, exp int64, round RoundingMode) (DecFloat, error) {
+	if !round.isValid() {
+		return DecFloat{}, InvalidRoundingModeError{}
+	}
+
+	if num.Sign() == 0 {
+		return DecFloat{}, nil
+	}
+
+	// Estimate the number of digits to drop from the quotient (or to add, if it isn't an integer)
+	// for it to fit in 127 bits, using a lower bound on its bit length. The estimate is never too
+	// large, and is at most a couple of digits short, which the overflow check below corrects.
+	bits := int64(num.BitLen()) - 128
+	if den != nil {
+		bits -= int64(den.BitLen())
+	}
+	shift := bits * 30102 / 100000
+	if bits < 0 {
+		shift -= 1
+	}
+	if den == nil {
+		den = big.NewInt(1)
+		shift = max(shift, 0)
+	}
+
+	for ; ; shift++ {
+		r := new(big.Rat)
+		if shift >= 0 {
+			r.SetFrac(num, new(big.Int).Mul(den, pow10Big(shift)))
+		} else {
+			r.SetFrac(new(big.Int).Mul(num, pow10Big(-shift)), den)
+		}
+
+		mant, err := fromBigRat(r, 0, round, fix128FromMagnitude)
+		if err == (PositiveOverflowError{}) || err == (NegativeOverflowError{}) {
+			continue
+		}
+		if err != nil {
+			return DecFloat{}, err
+		}
+
+		switch exp += shift + Fix128Decimals; {
+		case exp > math.MaxInt32:
+			return DecFloat{}, applySign(PositiveOverflowError{}, int64(r.Sign()))
+		case exp < math.MinInt32:
+			return DecFloat{}, UnderflowError{}
+		}
+
+		return DecFloat{Mant: mant, Exp: int32(exp)}, nil
+	}
+}
@@ -0,0 +1,232 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"math"
+	"time"
+)
+
+// This file contains the conversions between the fixed-point types and time.Duration, with the
+// fixed-point value in seconds. A Duration has nanosecond resolution (9 decimals), so converting
+// it to a 64-bit type rounds away the last digit, and converting a 128-bit value to a Duration
+// rounds away the last 15 digits; all other conversions are exact. MulDuration multiplies a rate
+// per second by a Duration, for streaming payments and vesting schedules.
+
+// UFix64FromDuration returns d in seconds as a UFix64, rounding the nanoseconds as specified.
+// Negative durations return a NegativeOverflowError, and non-zero durations that round to zero
+// return an UnderflowError.
+func UFix64FromDuration(d time.Duration, round RoundingMode) (UFix64, error) {
+	return fromDuration(d, Fix64Decimals, round, ufix64FromMagnitude)
+}
+
+// Fix64FromDuration returns d in seconds as a Fix64, rounding as specified, see
+// UFix64FromDuration.
+func Fix64FromDuration(d time.Duration, round RoundingMode) (Fix64, error) {
+	return fromDuration(d, Fix64Decimals, round, fix64FromMagnitude)
+}
+
+// UFix128FromDuration returns d in seconds as a UFix128. This is exact, so it only fails for
+// negative durations, with a NegativeOverflowError.
+func UFix128FromDuration(d time.Duration) (UFix128, error) {
+	return fromDuration(d, Fix128Decimals, RoundTowardZero, ufix128FromMagnitude)
+}
+
+// Fix128FromDuration returns d in seconds as a Fix128. Every Duration fits, so this is always
+// exact.
+func Fix128FromDuration(d time.Duration) Fix128 {
+	return must(fromDuration(d, Fix128Decimals, RoundTowardZero, fix128FromMagnitude))
+}
+
+// ToDuration returns `a`, in seconds, as a time.Duration. This is exact, but a Duration can only
+// hold about 292 years, so larger values return a PositiveOverflowError.
+func (a UFix64) ToDuration() (time.Duration, error) {
+	return toDuration(0, uint64(a), 1, Fix64Decimals, RoundTowardZero)
+}
+
+// ToDuration returns `a`, in seconds, as a time.Duration, see UFix64.ToDuration.
+func (a Fix64) ToDuration() (time.Duration, error) {
+	aUnsigned, sign := a.Abs()
+	return toDuration(0, uint64(aUnsigned), sign, Fix64Decimals, RoundTowardZero)
+}
+
+// ToDuration returns `a`, in seconds, as a time.Duration, rounded to whole nanoseconds as
+// specified. Non-zero values that round to zero return an UnderflowError, and values outside of
+// the range of a Duration (about 292 years) return an overflow error.
+func (a UFix128) ToDuration(round RoundingMode) (time.Duration, error) {
+	return toDuration(uint64(a.Hi), uint64(a.Lo), 1, Fix128Decimals, round)
+}
+
+// ToDuration returns `a`, in seconds, as a time.Duration, rounding as specified, see
+// UFix128.ToDuration.
+func (a Fix128) ToDuration(round RoundingMode) (time.Duration, error) {
+	aUnsigned, sign := a.Abs()
+	return toDuration(uint64(aUnsigned.Hi), uint64(aUnsigned.Lo), sign, Fix128Decimals, round)
+}
+
+// MulDuration returns `a`, a rate per second, multiplied by the duration d, i.e.
+// a * nanoseconds / 1e9. The full product is kept in a 128-bit intermediate, so the result is
+// rounded exactly once, as specified. Negative results return a NegativeOverflowError, and
+// non-zero results that round to zero return an UnderflowError.
+func (a UFix64) MulDuration(d time.Duration, round RoundingMode) (UFix64, error) {
+	dUnsigned, sign := absInt64(int64(d))
+
+	res, err := a.mulNanoseconds(dUnsigned, round.forSign(sign))
+	if err != nil {
+		return UFix64Zero, applySign(err, sign)
+	}
+
+	if sign < 0 && !res.IsZero() {
+		return UFix64Zero, NegativeOverflowError{}
+	}
+
+	return res, nil
+}
+
+// MulDuration returns `a`, a rate per second, multiplied by the duration d, see
+// UFix64.MulDuration.
+func (a Fix64) MulDuration(d time.Duration, round RoundingMode) (Fix64, error) {
+	aUnsigned, sign := a.Abs()
+	dUnsigned, signMul := absInt64(int64(d))
+	sign *= signMul
+
+	res, err := aUnsigned.mulNanoseconds(dUnsigned, round.forSign(sign))
+	if err != nil {
+		return Fix64Zero, applySign(err, sign)
+	}
+
+	return res.ApplySign(sign)
+}
+
+// MulDuration returns `a`, a rate per second, multiplied by the duration d, see
+// UFix64.MulDuration. The full product is kept in a 192-bit intermediate.
+func (a UFix128) MulDuration(d time.Duration, round RoundingMode) (UFix128, error) {
+	dUnsigned, sign := absInt64(int64(d))
+
+	res, err := a.mulNanoseconds(dUnsigned, round.forSign(sign))
+	if err != nil {
+		return UFix128Zero, applySign(err, sign)
+	}
+
+	if sign < 0 && !res.IsZero() {
+		return UFix128Zero, NegativeOverflowError{}
+	}
+
+	return res, nil
+}
+
+// MulDuration returns `a`, a rate per second, multiplied by the duration d, see
+// UFix128.MulDuration.
+func (a Fix128) MulDuration(d time.Duration, round RoundingMode) (Fix128, error) {
+	aUnsigned, sign := a.Abs()
+	dUnsigned, signMul := absInt64(int64(d))
+	sign *= signMul
+
+	res, err := aUnsigned.mulNanoseconds(dUnsigned, round.forSign(sign))
+	if err != nil {
+		return Fix128Zero, applySign(err, sign)
+	}
+
+	return res.ApplySign(sign)
+}
+
+// nanosecondsPerSecond is the divisor of MulDuration.
+const nanosecondsPerSecond = 1e9
+
+// mulNanoseconds returns a * ns / 1e9, rounded as specified.
+func (a UFix64) mulNanoseconds(ns uint64, round RoundingMode) (UFix64, error) {
+	if a.IsZero() || ns == 0 {
+		return UFix64Zero, nil
+	}
+
+	hi, lo := mul64(raw64(a), raw64(ns))
+	res, _, err := udivRound64(hi, lo, nanosecondsPerSecond, round)
+	return res, err
+}
+
+// mulNanoseconds returns a * ns / 1e9, rounded as specified.
+func (a UFix128) mulNanoseconds(ns uint64, round RoundingMode) (UFix128, error) {
+	if a.IsZero() || ns == 0 {
+		return UFix128Zero, nil
+	}
+
+	hi, mid, lo := mul128By64(raw128(a), raw64(ns))
+	res, _, err := udivRound128(raw128{raw64Zero, hi}, raw128{mid, lo}, raw128{raw64Zero, nanosecondsPerSecond}, round)
+	return res, err
+}
+
+// durationDecimals is the number of decimals of a time.Duration in seconds.
+const durationDecimals = 9
+
+// fromDuration converts d, in nanoseconds, to a type with the given number of decimals.
+func fromDuration[T any](d time.Duration, decimals int, round RoundingMode,
+	fromMagnitude func(hi, lo uint64, sign int64) (T, error)) (T, error) {
+	var zero T
+
+	if !round.isValid() {
+		return zero, InvalidRoundingModeError{}
+	}
+
+	mag, sign := absInt64(int64(d))
+
+	if decimals < durationDecimals {
+		_, res := roundDecimal(0, mag, durationDecimals, decimals, round.forSign(sign))
+		if res == 0 && mag != 0 {
+			return zero, UnderflowError{}
+		}
+
+		return fromMagnitude(0, res, sign)
+	}
+
+	hi, lo := mul64(raw64(mag), raw64(pow10Table128[decimals-durationDecimals].Lo))
+	return fromMagnitude(uint64(hi), uint64(lo), sign)
+}
+
+// toDuration converts the magnitude (hi, lo), which has the given number of decimals, to a
+// Duration.
+func toDuration(hi, lo uint64, sign int64, decimals int, round RoundingMode) (time.Duration, error) {
+	if !round.isValid() {
+		return 0, InvalidRoundingModeError{}
+	}
+
+	if decimals > durationDecimals {
+		resHi, resLo := roundDecimal(hi, lo, decimals, durationDecimals, round.forSign(sign))
+		if resHi == 0 && resLo == 0 && (hi != 0 || lo != 0) {
+			return 0, UnderflowError{}
+		}
+
+		hi, lo = resHi, resLo
+	} else {
+		mulHi, mulLo := mul64(raw64(lo), raw64(pow10Table128[durationDecimals-decimals].Lo))
+		hi, lo = uint64(mulHi), uint64(mulLo)
+	}
+
+	if sign < 0 {
+		if hi != 0 || lo > -math.MinInt64 {
+			return 0, NegativeOverflowError{}
+		}
+
+		// Negate before converting, so that -2^63 doesn't overflow.
+		return time.Duration(-lo), nil
+	}
+
+	if hi != 0 || lo > math.MaxInt64 {
+		return 0, PositiveOverflowError{}
+	}
+
+	return time.Duration(lo), nil
+}
@@ -0,0 +1,498 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// This file contains the implementations of the standard encoding interfaces for the
+// fixed-point types.
+
+var (
+	_ encoding.TextMarshaler   = UFix64Zero
+	_ encoding.TextMarshaler   = Fix64Zero
+	_ encoding.TextMarshaler   = UFix128Zero
+	_ encoding.TextMarshaler   = Fix128Zero
+	_ encoding.TextMarshaler   = UFix256Zero
+	_ encoding.TextMarshaler   = Fix256Zero
+	_ encoding.TextUnmarshaler = (*UFix64)(nil)
+	_ encoding.TextUnmarshaler = (*Fix64)(nil)
+	_ encoding.TextUnmarshaler = (*UFix128)(nil)
+	_ encoding.TextUnmarshaler = (*Fix128)(nil)
+	_ encoding.TextUnmarshaler = (*UFix256)(nil)
+	_ encoding.TextUnmarshaler = (*Fix256)(nil)
+
+	_ json.Marshaler   = UFix64Zero
+	_ json.Marshaler   = Fix64Zero
+	_ json.Marshaler   = UFix128Zero
+	_ json.Marshaler   = Fix128Zero
+	_ json.Marshaler   = UFix256Zero
+	_ json.Marshaler   = Fix256Zero
+	_ json.Unmarshaler = (*UFix64)(nil)
+	_ json.Unmarshaler = (*Fix64)(nil)
+	_ json.Unmarshaler = (*UFix128)(nil)
+	_ json.Unmarshaler = (*Fix128)(nil)
+	_ json.Unmarshaler = (*UFix256)(nil)
+	_ json.Unmarshaler = (*Fix256)(nil)
+
+	_ encoding.BinaryMarshaler   = UFix64Zero
+	_ encoding.BinaryMarshaler   = Fix64Zero
+	_ encoding.BinaryMarshaler   = UFix128Zero
+	_ encoding.BinaryMarshaler   = Fix128Zero
+	_ encoding.BinaryMarshaler   = UFix256Zero
+	_ encoding.BinaryMarshaler   = Fix256Zero
+	_ encoding.BinaryUnmarshaler = (*UFix64)(nil)
+	_ encoding.BinaryUnmarshaler = (*Fix64)(nil)
+	_ encoding.BinaryUnmarshaler = (*UFix128)(nil)
+	_ encoding.BinaryUnmarshaler = (*Fix128)(nil)
+	_ encoding.BinaryUnmarshaler = (*UFix256)(nil)
+	_ encoding.BinaryUnmarshaler = (*Fix256)(nil)
+
+	_ gob.GobEncoder = UFix64Zero
+	_ gob.GobEncoder = Fix64Zero
+	_ gob.GobEncoder = UFix128Zero
+	_ gob.GobEncoder = Fix128Zero
+	_ gob.GobEncoder = UFix256Zero
+	_ gob.GobEncoder = Fix256Zero
+	_ gob.GobDecoder = (*UFix64)(nil)
+	_ gob.GobDecoder = (*Fix64)(nil)
+	_ gob.GobDecoder = (*UFix128)(nil)
+	_ gob.GobDecoder = (*Fix128)(nil)
+	_ gob.GobDecoder = (*UFix256)(nil)
+	_ gob.GobDecoder = (*Fix256)(nil)
+)
+
+// textParser parses text encodings. It's strict, so decoding never silently changes a value.
+var textParser = ParseOptions{Strict: true}
+
+// MarshalText implements encoding.TextMarshaler, using the same canonical decimal form as String.
+func (a UFix64) MarshalText() ([]byte, error) { return a.Append(nil), nil }
+
+// MarshalText implements encoding.TextMarshaler, see UFix64.MarshalText.
+func (a Fix64) MarshalText() ([]byte, error) { return a.Append(nil), nil }
+
+// MarshalText implements encoding.TextMarshaler, see UFix64.MarshalText.
+func (a UFix128) MarshalText() ([]byte, error) { return a.Append(nil), nil }
+
+// MarshalText implements encoding.TextMarshaler, see UFix64.MarshalText.
+func (a Fix128) MarshalText() ([]byte, error) { return a.Append(nil), nil }
+
+// MarshalText implements encoding.TextMarshaler, see UFix64.MarshalText.
+func (a UFix256) MarshalText() ([]byte, error) { return a.Append(nil), nil }
+
+// MarshalText implements encoding.TextMarshaler, see UFix64.MarshalText.
+func (a Fix256) MarshalText() ([]byte, error) { return a.Append(nil), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler. It accepts any decimal string that can be
+// represented exactly (including scientific notation), and returns an error, leaving `a`
+// unchanged, for anything else.
+func (a *UFix64) UnmarshalText(text []byte) error {
+	res, err := textParser.ParseUFix64(string(text))
+	if err != nil {
+		return err
+	}
+
+	*a = res
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, see UFix64.UnmarshalText.
+func (a *Fix64) UnmarshalText(text []byte) error {
+	res, err := textParser.ParseFix64(string(text))
+	if err != nil {
+		return err
+	}
+
+	*a = res
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, see UFix64.UnmarshalText.
+func (a *UFix128) UnmarshalText(text []byte) error {
+	res, err := textParser.ParseUFix128(string(text))
+	if err != nil {
+		return err
+	}
+
+	*a = res
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, see UFix64.UnmarshalText.
+func (a *Fix128) UnmarshalText(text []byte) error {
+	res, err := textParser.ParseFix128(string(text))
+	if err != nil {
+		return err
+	}
+
+	*a = res
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, see UFix64.UnmarshalText.
+func (a *UFix256) UnmarshalText(text []byte) error {
+	res, err := textParser.ParseUFix256(string(text))
+	if err != nil {
+		return err
+	}
+
+	*a = res
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, see UFix64.UnmarshalText.
+func (a *Fix256) UnmarshalText(text []byte) error {
+	res, err := textParser.ParseFix256(string(text))
+	if err != nil {
+		return err
+	}
+
+	*a = res
+	return nil
+}
+
+// JSONFormat selects how values are encoded as JSON.
+type JSONFormat uint8
+
+const (
+	// JSONString encodes values as JSON strings, e.g. "12.5". This is the default, since many JSON
+	// consumers (notably JavaScript) decode all numbers as float64, which silently loses precision.
+	JSONString JSONFormat = iota
+
+	// JSONNumber encodes values as JSON numbers, e.g. 12.5. The number is still exact, but it's up
+	// to the consumer to decode it without going through a float.
+	JSONNumber
+)
+
+// DefaultJSONFormat is the format used by MarshalJSON. It should only be changed during program
+// initialization, as it isn't safe to change while values are being encoded. Use AppendJSON to
+// choose the format for a single value instead.
+var DefaultJSONFormat = JSONString
+
+// AppendJSON appends the JSON encoding of `a` in the given format to dst.
+func (a UFix64) AppendJSON(dst []byte, format JSONFormat) []byte {
+	return appendJSON(dst, format, a.Append)
+}
+
+// AppendJSON appends the JSON encoding of `a` in the given format to dst.
+func (a Fix64) AppendJSON(dst []byte, format JSONFormat) []byte {
+	return appendJSON(dst, format, a.Append)
+}
+
+// AppendJSON appends the JSON encoding of `a` in the given format to dst.
+func (a UFix128) AppendJSON(dst []byte, format JSONFormat) []byte {
+	return appendJSON(dst, format, a.Append)
+}
+
+// AppendJSON appends the JSON encoding of `a` in the given format to dst.
+func (a Fix128) AppendJSON(dst []byte, format JSONFormat) []byte {
+	return appendJSON(dst, format, a.Append)
+}
+
+// AppendJSON appends the JSON encoding of `a` in the given format to dst.
+func (a UFix256) AppendJSON(dst []byte, format JSONFormat) []byte {
+	return appendJSON(dst, format, a.Append)
+}
+
+// AppendJSON appends the JSON encoding of `a` in the given format to dst.
+func (a Fix256) AppendJSON(dst []byte, format JSONFormat) []byte {
+	return appendJSON(dst, format, a.Append)
+}
+
+// MarshalJSON implements json.Marshaler, using DefaultJSONFormat.
+func (a UFix64) MarshalJSON() ([]byte, error)  { return a.AppendJSON(nil, DefaultJSONFormat), nil }
+func (a Fix64) MarshalJSON() ([]byte, error)   { return a.AppendJSON(nil, DefaultJSONFormat), nil }
+func (a UFix128) MarshalJSON() ([]byte, error) { return a.AppendJSON(nil, DefaultJSONFormat), nil }
+func (a Fix128) MarshalJSON() ([]byte, error)  { return a.AppendJSON(nil, DefaultJSONFormat), nil }
+func (a UFix256) MarshalJSON() ([]byte, error) { return a.AppendJSON(nil, DefaultJSONFormat), nil }
+func (a Fix256) MarshalJSON() ([]byte, error)  { return a.AppendJSON(nil, DefaultJSONFormat), nil }
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts both JSON strings and JSON numbers,
+// regardless of DefaultJSONFormat, and like UnmarshalText, it returns an error for any value that
+// can't be represented exactly, rather than rounding it. A JSON null leaves `a` unchanged.
+func (a *UFix64) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, a.UnmarshalText) }
+
+// UnmarshalJSON implements json.Unmarshaler, see UFix64.UnmarshalJSON.
+func (a *Fix64) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, a.UnmarshalText) }
+
+// UnmarshalJSON implements json.Unmarshaler, see UFix64.UnmarshalJSON.
+func (a *UFix128) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, a.UnmarshalText) }
+
+// UnmarshalJSON implements json.Unmarshaler, see UFix64.UnmarshalJSON.
+func (a *Fix128) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, a.UnmarshalText) }
+
+// UnmarshalJSON implements json.Unmarshaler, see UFix64.UnmarshalJSON.
+func (a *UFix256) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, a.UnmarshalText) }
+
+// UnmarshalJSON implements json.Unmarshaler, see UFix64.UnmarshalJSON.
+func (a *Fix256) UnmarshalJSON(data []byte) error { return unmarshalJSON(data, a.UnmarshalText) }
+
+func appendJSON(dst []byte, format JSONFormat, appendValue func([]byte) []byte) []byte {
+	// The decimal form never contains characters that need to be escaped.
+	if format == JSONNumber {
+		return appendValue(dst)
+	}
+
+	dst = append(dst, '"')
+	dst = appendValue(dst)
+	return append(dst, '"')
+}
+
+func unmarshalJSON(data []byte, unmarshalText func([]byte) error) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		return unmarshalText([]byte(s))
+	}
+
+	return unmarshalText(data)
+}
+
+// The binary encoding is the raw value as a fixed-size, big-endian, two's complement integer: 8
+// bytes for the 64-bit types, and 16 bytes (Hi then Lo) for the 128-bit types. Every value has
+// exactly one encoding, so it's suitable for hashing and for comparing values byte-wise for
+// equality. (But not for ordering signed values, see SortKey.) The 256-bit types use 32 bytes, with
+// the high half first.
+
+// MarshalBinary implements encoding.BinaryMarshaler using the canonical 8-byte encoding.
+func (a UFix64) MarshalBinary() ([]byte, error) {
+	return binary.BigEndian.AppendUint64(nil, uint64(a)), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler using the canonical 8-byte encoding.
+func (a Fix64) MarshalBinary() ([]byte, error) {
+	return binary.BigEndian.AppendUint64(nil, uint64(a)), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler using the canonical 16-byte encoding.
+func (a UFix128) MarshalBinary() ([]byte, error) {
+	return appendRaw128(nil, raw128(a)), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler using the canonical 16-byte encoding.
+func (a Fix128) MarshalBinary() ([]byte, error) {
+	return appendRaw128(nil, raw128(a)), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler using the canonical 32-byte encoding.
+func (a UFix256) MarshalBinary() ([]byte, error) {
+	return appendRaw128(appendRaw128(nil, a.Hi), a.Lo), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler using the canonical 32-byte encoding.
+func (a Fix256) MarshalBinary() ([]byte, error) {
+	return appendRaw128(appendRaw128(nil, a.Hi), a.Lo), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It returns an InvalidEncodingError if
+// the data isn't exactly 8 bytes long.
+func (a *UFix64) UnmarshalBinary(data []byte) error {
+	if len(data) != 8 {
+		return InvalidEncodingError{}
+	}
+
+	*a = UFix64(binary.BigEndian.Uint64(data))
+	return nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It returns an InvalidEncodingError if
+// the data isn't exactly 8 bytes long.
+func (a *Fix64) UnmarshalBinary(data []byte) error {
+	if len(data) != 8 {
+		return InvalidEncodingError{}
+	}
+
+	*a = Fix64(binary.BigEndian.Uint64(data))
+	return nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It returns an InvalidEncodingError if
+// the data isn't exactly 16 bytes long.
+func (a *UFix128) UnmarshalBinary(data []byte) error {
+	if len(data) != 16 {
+		return InvalidEncodingError{}
+	}
+
+	*a = UFix128(readRaw128(data))
+	return nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It returns an InvalidEncodingError if
+// the data isn't exactly 16 bytes long.
+func (a *Fix128) UnmarshalBinary(data []byte) error {
+	if len(data) != 16 {
+		return InvalidEncodingError{}
+	}
+
+	*a = Fix128(readRaw128(data))
+	return nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It returns an InvalidEncodingError if
+// the data isn't exactly 32 bytes long.
+func (a *UFix256) UnmarshalBinary(data []byte) error {
+	if len(data) != 32 {
+		return InvalidEncodingError{}
+	}
+
+	*a = UFix256{Hi: readRaw128(data), Lo: readRaw128(data[16:])}
+	return nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It returns an InvalidEncodingError if
+// the data isn't exactly 32 bytes long.
+func (a *Fix256) UnmarshalBinary(data []byte) error {
+	if len(data) != 32 {
+		return InvalidEncodingError{}
+	}
+
+	*a = Fix256{Hi: readRaw128(data), Lo: readRaw128(data[16:])}
+	return nil
+}
+
+func appendRaw128(dst []byte, a raw128) []byte {
+	dst = binary.BigEndian.AppendUint64(dst, uint64(a.Hi))
+	return binary.BigEndian.AppendUint64(dst, uint64(a.Lo))
+}
+
+func readRaw128(data []byte) raw128 {
+	return raw128{raw64(binary.BigEndian.Uint64(data)), raw64(binary.BigEndian.Uint64(data[8:]))}
+}
+
+// GobEncode implements gob.GobEncoder using the canonical binary encoding. (The gob package
+// would fall back to MarshalBinary anyway, but implementing it explicitly makes the wire format
+// part of the API.)
+func (a UFix64) GobEncode() ([]byte, error) { return a.MarshalBinary() }
+
+// GobEncode implements gob.GobEncoder, see UFix64.GobEncode.
+func (a Fix64) GobEncode() ([]byte, error) { return a.MarshalBinary() }
+
+// GobEncode implements gob.GobEncoder, see UFix64.GobEncode.
+func (a UFix128) GobEncode() ([]byte, error) { return a.MarshalBinary() }
+
+// GobEncode implements gob.GobEncoder, see UFix64.GobEncode.
+func (a Fix128) GobEncode() ([]byte, error) { return a.MarshalBinary() }
+
+// GobEncode implements gob.GobEncoder, see UFix64.GobEncode.
+func (a UFix256) GobEncode() ([]byte, error) { return a.MarshalBinary() }
+
+// GobEncode implements gob.GobEncoder, see UFix64.GobEncode.
+func (a Fix256) GobEncode() ([]byte, error) { return a.MarshalBinary() }
+
+// GobDecode implements gob.GobDecoder using the canonical binary encoding.
+func (a *UFix64) GobDecode(data []byte) error { return a.UnmarshalBinary(data) }
+
+// GobDecode implements gob.GobDecoder, see UFix64.GobDecode.
+func (a *Fix64) GobDecode(data []byte) error { return a.UnmarshalBinary(data) }
+
+// GobDecode implements gob.GobDecoder, see UFix64.GobDecode.
+func (a *UFix128) GobDecode(data []byte) error { return a.UnmarshalBinary(data) }
+
+// GobDecode implements gob.GobDecoder, see UFix64.GobDecode.
+func (a *Fix128) GobDecode(data []byte) error { return a.UnmarshalBinary(data) }
+
+// GobDecode implements gob.GobDecoder, see UFix64.GobDecode.
+func (a *UFix256) GobDecode(data []byte) error { return a.UnmarshalBinary(data) }
+
+// GobDecode implements gob.GobDecoder, see UFix64.GobDecode.
+func (a *Fix256) GobDecode(data []byte) error { return a.UnmarshalBinary(data) }
+
+// MarshalYAML implements the Marshaler interface of gopkg.in/yaml.v2 and gopkg.in/yaml.v3, encoding
+// `a` as a string in its decimal form, so YAML readers never treat it as a float.
+func (a UFix64) MarshalYAML() (any, error)  { return a.String(), nil }
+func (a Fix64) MarshalYAML() (any, error)   { return a.String(), nil }
+func (a UFix128) MarshalYAML() (any, error) { return a.String(), nil }
+func (a Fix128) MarshalYAML() (any, error)  { return a.String(), nil }
+func (a UFix256) MarshalYAML() (any, error) { return a.String(), nil }
+func (a Fix256) MarshalYAML() (any, error)  { return a.String(), nil }
+
+// UnmarshalYAML implements the Unmarshaler interface of gopkg.in/yaml.v2 (which gopkg.in/yaml.v3
+// also supports), so that fixed-point values can be decoded without depending on either package.
+// Both quoted and unquoted scalars are accepted, but like UnmarshalText, the value must be exact.
+func (a *UFix64) UnmarshalYAML(unmarshal func(any) error) error {
+	return unmarshalYAML(unmarshal, a.UnmarshalText)
+}
+
+// UnmarshalYAML implements the yaml.v2 Unmarshaler interface, see UFix64.UnmarshalYAML.
+func (a *Fix64) UnmarshalYAML(unmarshal func(any) error) error {
+	return unmarshalYAML(unmarshal, a.UnmarshalText)
+}
+
+// UnmarshalYAML implements the yaml.v2 Unmarshaler interface, see UFix64.UnmarshalYAML.
+func (a *UFix128) UnmarshalYAML(unmarshal func(any) error) error {
+	return unmarshalYAML(unmarshal, a.UnmarshalText)
+}
+
+// UnmarshalYAML implements the yaml.v2 Unmarshaler interface, see UFix64.UnmarshalYAML.
+func (a *Fix128) UnmarshalYAML(unmarshal func(any) error) error {
+	return unmarshalYAML(unmarshal, a.UnmarshalText)
+}
+
+// UnmarshalYAML implements the yaml.v2 Unmarshaler interface, see UFix64.UnmarshalYAML.
+func (a *UFix256) UnmarshalYAML(unmarshal func(any) error) error {
+	return unmarshalYAML(unmarshal, a.UnmarshalText)
+}
+
+// UnmarshalYAML implements the yaml.v2 Unmarshaler interface, see UFix64.UnmarshalYAML.
+func (a *Fix256) UnmarshalYAML(unmarshal func(any) error) error {
+	return unmarshalYAML(unmarshal, a.UnmarshalText)
+}
+
+func unmarshalYAML(unmarshal func(any) error, unmarshalText func([]byte) error) error {
+	// Scalars can always be decoded into a string, which keeps the exact text of the value.
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+
+	return unmarshalText([]byte(s))
+}
+
+// SortKey returns an encoding of `a` whose lexicographic (i.e. bytes.Compare) order matches the
+// numeric order of the values, for use as a key in ordered key-value stores. For the unsigned
+// types it's the same as MarshalBinary; for the signed types, the sign bit is flipped so negative
+// values sort before positive ones.
+func (a UFix64) SortKey() []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(a))
+}
+
+// SortKey returns an order-preserving encoding of `a`, see UFix64.SortKey.
+func (a Fix64) SortKey() []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(a)^signBit64)
+}
+
+// SortKey returns an order-preserving encoding of `a`, see UFix64.SortKey.
+func (a UFix128) SortKey() []byte {
+	return appendRaw128(nil, raw128(a))
+}
+
+// SortKey returns an order-preserving encoding of `a`, see UFix64.SortKey.
+func (a Fix128) SortKey() []byte {
+	return appendRaw128(nil, raw128{a.Hi ^ signBit64, a.Lo})
+}
+
+const signBit64 = 1 << 63
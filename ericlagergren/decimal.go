@@ -0,0 +1,90 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package ericlagergren converts between the fixedPoint types and decimal.Big from
+// github.com/ericlagergren/decimal. It's a separate module, so the fixedPoint package itself
+// doesn't depend on the decimal library.
+//
+// Conversions to decimal.Big are always exact. Conversions from decimal.Big are exact whenever
+// the value fits, and otherwise round explicitly, with the given rounding mode, if it has more
+// fractional digits than the target type (8 for UFix64 and Fix64, 24 for UFix128 and Fix128).
+package ericlagergren
+
+import (
+	"math/big"
+
+	"github.com/ericlagergren/decimal"
+	fixedPoint "github.com/onflow/fixed-point"
+)
+
+// Value is the set of fixed-point types that can be converted to and from decimal.Big.
+type Value interface {
+	fixedPoint.UFix64 | fixedPoint.Fix64 | fixedPoint.UFix128 | fixedPoint.Fix128
+
+	MantScale() (*big.Int, int)
+}
+
+// ToDecimal sets z (which may be nil) to the value of `a` and returns it. The result has the
+// scale 8 or 24 (i.e. the decimals of the type), so it keeps any trailing zeros.
+func ToDecimal[T Value](z *decimal.Big, a T) *decimal.Big {
+	if z == nil {
+		z = new(decimal.Big)
+	}
+
+	return z.SetBigMantScale(a.MantScale())
+}
+
+// FromDecimal converts x to a T, rounding as specified if it has more fractional digits than T
+// supports. It returns the same errors as the *FromMantScale constructors in fixedPoint: an
+// overflow error if x is out of the range of T (including negative values for the unsigned
+// types, and infinities), an UnderflowError if a non-zero x rounds to zero, or an
+// InvalidRoundingModeError. NaN values return an OutOfDomainErrorError.
+func FromDecimal[T Value](x *decimal.Big, round fixedPoint.RoundingMode) (T, error) {
+	var zero T
+
+	switch {
+	case x.IsNaN(0):
+		return zero, fixedPoint.OutOfDomainErrorError{}
+	case x.IsInf(1):
+		return zero, fixedPoint.PositiveOverflowError{}
+	case x.IsInf(-1):
+		return zero, fixedPoint.NegativeOverflowError{}
+	}
+
+	// With the scale set to zero, the value is the (signed) unscaled coefficient.
+	mant := new(decimal.Big).Copy(x).SetScale(0).Int(nil)
+	scale := x.Scale()
+
+	var res any
+	var err error
+
+	switch any(zero).(type) {
+	case fixedPoint.UFix64:
+		res, err = fixedPoint.UFix64FromMantScale(mant, scale, round)
+	case fixedPoint.Fix64:
+		res, err = fixedPoint.Fix64FromMantScale(mant, scale, round)
+	case fixedPoint.UFix128:
+		res, err = fixedPoint.UFix128FromMantScale(mant, scale, round)
+	case fixedPoint.Fix128:
+		res, err = fixedPoint.Fix128FromMantScale(mant, scale, round)
+	}
+
+	if err != nil {
+		return zero, err
+	}
+
+	return res.(T), nil
+}
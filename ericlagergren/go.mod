@@ -0,0 +1,10 @@
+module github.com/onflow/fixed-point/ericlagergren
+
+go 1.23.2
+
+require (
+	github.com/ericlagergren/decimal v0.0.0-20211103172832-aca2edc11f73
+	github.com/onflow/fixed-point v0.0.0-00010101000000-000000000000
+)
+
+replace github.com/onflow/fixed-point => ../
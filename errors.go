@@ -16,6 +16,27 @@
 
 package fixedPoint
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Sentinel errors for each category of failure. The concrete errors returned by this package (e.g.
+// PositiveOverflowError) all match one of these with errors.Is, even when wrapped (e.g. in an
+// OpError), so callers can write errors.Is(err, ErrOverflow) without caring about the details.
+// Use errors.As with the concrete types to tell positive and negative overflow apart.
+var (
+	ErrOverflow            = errors.New("overflow")
+	ErrUnderflow           = errors.New("underflow")
+	ErrDivisionByZero      = errors.New("division by zero")
+	ErrDomain              = errors.New("input out of domain")
+	ErrInexact             = errors.New("inexact result")
+	ErrInvalidRoundingMode = errors.New("invalid rounding mode")
+	ErrSyntax              = errors.New("invalid syntax")
+	ErrInvalidEncoding     = errors.New("invalid encoding")
+)
+
 // PositiveOverflowError is reported when the value is positive and has a magnitude that is
 // too large to be represented using the given bit length.
 type PositiveOverflowError struct{}
@@ -26,6 +47,9 @@ func (PositiveOverflowError) Error() string {
 	return "overflow"
 }
 
+func (PositiveOverflowError) Is(target error) bool { return target == ErrOverflow }
+func (PositiveOverflowError) ErrorCode() ErrorCode { return ErrorCodeOverflow }
+
 // NegativeOverflowError is reported when the value is negative and has a magnitude that is
 // too large to be represented using the given bit length.
 type NegativeOverflowError struct{}
@@ -36,6 +60,9 @@ func (NegativeOverflowError) Error() string {
 	return "negative overflow"
 }
 
+func (NegativeOverflowError) Is(target error) bool { return target == ErrOverflow }
+func (NegativeOverflowError) ErrorCode() ErrorCode { return ErrorCodeNegativeOverflow }
+
 // UnderflowError is reported when the magnitude of the value is too small to be represented
 // using the given bit length.
 type UnderflowError struct{}
@@ -46,6 +73,9 @@ func (UnderflowError) Error() string {
 	return "underflow"
 }
 
+func (UnderflowError) Is(target error) bool { return target == ErrUnderflow }
+func (UnderflowError) ErrorCode() ErrorCode { return ErrorCodeUnderflow }
+
 type DivisionByZeroError struct{}
 
 var _ error = DivisionByZeroError{}
@@ -54,6 +84,15 @@ func (DivisionByZeroError) Error() string {
 	return "division by zero"
 }
 
+func (DivisionByZeroError) Is(target error) bool { return target == ErrDivisionByZero }
+func (DivisionByZeroError) ErrorCode() ErrorCode { return ErrorCodeDivisionByZero }
+
+// OutOfDomainErrorError is reported when an input is outside the domain where the function is
+// defined, including poles: Ln(0), LogBase of zero or with a base of zero or one, Pow(0, b) with a
+// negative b, Sqrt of a negative value, converting a NaN from a float64, and the dot product of
+// slices with different lengths. The trigonometric functions are defined for all inputs, and never
+// report it. Note that results that are defined, but too large or too small to be represented, are
+// reported as overflow or underflow errors instead.
 type OutOfDomainErrorError struct{}
 
 var _ error = OutOfDomainErrorError{}
@@ -62,6 +101,150 @@ func (OutOfDomainErrorError) Error() string {
 	return "input out of domain"
 }
 
+func (OutOfDomainErrorError) Is(target error) bool { return target == ErrDomain }
+func (OutOfDomainErrorError) ErrorCode() ErrorCode { return ErrorCodeDomain }
+
+// InexactError is reported by operations that require an exact result, when the result can't be
+// represented without rounding.
+type InexactError struct{}
+
+var _ error = InexactError{}
+
+func (InexactError) Error() string {
+	return "inexact result"
+}
+
+func (InexactError) Is(target error) bool { return target == ErrInexact }
+func (InexactError) ErrorCode() ErrorCode { return ErrorCodeInexact }
+
+// InvalidRoundingModeError is reported when an operation is given a RoundingMode that isn't one of
+// the defined constants. Operations that don't return an error (e.g. MulWrap) treat an invalid
+// rounding mode as RoundTowardZero instead, and panic in debug builds.
+type InvalidRoundingModeError struct{}
+
+var _ error = InvalidRoundingModeError{}
+
+func (InvalidRoundingModeError) Error() string {
+	return "invalid rounding mode"
+}
+
+func (InvalidRoundingModeError) Is(target error) bool { return target == ErrInvalidRoundingMode }
+func (InvalidRoundingModeError) ErrorCode() ErrorCode { return ErrorCodeInvalidRoundingMode }
+
+// SyntaxError is reported when parsing a string that isn't a valid decimal number.
+type SyntaxError struct{}
+
+var _ error = SyntaxError{}
+
+func (SyntaxError) Error() string {
+	return "invalid syntax"
+}
+
+func (SyntaxError) Is(target error) bool { return target == ErrSyntax }
+func (SyntaxError) ErrorCode() ErrorCode { return ErrorCodeSyntax }
+
+// InvalidEncodingError is reported when decoding a binary encoding that is malformed, e.g. because
+// it has the wrong length.
+type InvalidEncodingError struct{}
+
+var _ error = InvalidEncodingError{}
+
+func (InvalidEncodingError) Error() string {
+	return "invalid encoding"
+}
+
+func (InvalidEncodingError) Is(target error) bool { return target == ErrInvalidEncoding }
+func (InvalidEncodingError) ErrorCode() ErrorCode { return ErrorCodeInvalidEncoding }
+
+// ErrorCode is a small, stable numeric code for each error reported by this package, for passing
+// errors across FFI and RPC boundaries without relying on error strings. The values are part of
+// the public API and will never change; new codes will only ever be added at the end.
+type ErrorCode uint8
+
+const (
+	ErrorCodeNone                ErrorCode = 0
+	ErrorCodeOverflow            ErrorCode = 1
+	ErrorCodeNegativeOverflow    ErrorCode = 2
+	ErrorCodeUnderflow           ErrorCode = 3
+	ErrorCodeDivisionByZero      ErrorCode = 4
+	ErrorCodeDomain              ErrorCode = 5
+	ErrorCodeInexact             ErrorCode = 6
+	ErrorCodeInvalidRoundingMode ErrorCode = 7
+	ErrorCodeSyntax              ErrorCode = 8
+	ErrorCodeInvalidEncoding     ErrorCode = 9
+
+	// ErrorCodeUnknown is returned by ErrorCodeOf for errors that didn't come from this package.
+	ErrorCodeUnknown ErrorCode = 255
+)
+
+// ErrorCodeOf returns the ErrorCode for `err` (which may be wrapped, e.g. in an OpError),
+// ErrorCodeNone if `err` is nil, or ErrorCodeUnknown if it isn't an error from this package.
+func ErrorCodeOf(err error) ErrorCode {
+	if err == nil {
+		return ErrorCodeNone
+	}
+
+	var coded interface{ ErrorCode() ErrorCode }
+	if errors.As(err, &coded) {
+		return coded.ErrorCode()
+	}
+
+	return ErrorCodeUnknown
+}
+
+// err returns the error for the code, or nil for ErrorCodeNone. The internal arithmetic reports
+// failures as codes where the result is only tested for success (e.g. the checked operators), and
+// converts them to errors at the API boundary.
+func (c ErrorCode) err() error {
+	switch c {
+	case ErrorCodeNone:
+		return nil
+	case ErrorCodeOverflow:
+		return PositiveOverflowError{}
+	case ErrorCodeNegativeOverflow:
+		return NegativeOverflowError{}
+	case ErrorCodeUnderflow:
+		return UnderflowError{}
+	case ErrorCodeDivisionByZero:
+		return DivisionByZeroError{}
+	case ErrorCodeDomain:
+		return OutOfDomainErrorError{}
+	case ErrorCodeInexact:
+		return InexactError{}
+	case ErrorCodeInvalidRoundingMode:
+		return InvalidRoundingModeError{}
+	case ErrorCodeSyntax:
+		return SyntaxError{}
+	case ErrorCodeInvalidEncoding:
+		return InvalidEncodingError{}
+	default:
+		debugPanic("ErrorCode.err: unknown error code")
+		return nil
+	}
+}
+
+// OpError wraps an error with the name of the operation that failed and its operands, for
+// diagnostics. It unwraps to the original error, so errors.Is and errors.As work as usual. Errors
+// are only wrapped on request (see Context.WrapErrors), the plain methods return bare errors.
+type OpError struct {
+	Op       string
+	Operands []any
+	Err      error
+}
+
+var _ error = &OpError{}
+
+func (e *OpError) Error() string {
+	operands := make([]string, len(e.Operands))
+	for i, operand := range e.Operands {
+		operands[i] = fmt.Sprint(operand)
+	}
+
+	return e.Op + "(" + strings.Join(operands, ", ") + "): " + e.Err.Error()
+}
+
+func (e *OpError) Unwrap() error { return e.Err }
+
 func applySign(e error, sign int64) error {
 	if _, isUnderflowErr := e.(PositiveOverflowError); isUnderflowErr && sign < 0 {
 		return NegativeOverflowError{}
@@ -69,3 +252,31 @@ func applySign(e error, sign int64) error {
 
 	return e
 }
+
+// applySignCode is applySign for an ErrorCode.
+func applySignCode(c ErrorCode, sign int64) ErrorCode {
+	if c == ErrorCodeOverflow && sign < 0 {
+		return ErrorCodeNegativeOverflow
+	}
+
+	return c
+}
+
+// debugPanic panics with the given message, but only in debug builds (i.e. when built with the
+// fixedpoint_debug build tag). It's used to flag violations of internal invariants, which should be
+// impossible to reach with any input. In production builds, the caller must recover on its own,
+// typically by returning a zero result, so a bug in the library can't crash the program.
+func debugPanic(msg string) {
+	if debugBuild {
+		panic(msg)
+	}
+}
+
+// must returns `v`, or panics if `err` is non-nil. It's used to implement the Must* helpers.
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
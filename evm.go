@@ -0,0 +1,202 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"encoding/binary"
+	"math/big"
+)
+
+// This file contains the conversions to and from the integer scales used by Solidity contracts:
+// WAD (18 decimals, the scale of ETH and most ERC-20 tokens) and RAY (27 decimals, used for
+// high-precision rates), as well as 256-bit EVM words with any number of decimals. Conversions are
+// exact where the target has enough decimals, and rounded with an explicit rounding mode where it
+// doesn't.
+
+const (
+	// WADDecimals is the number of decimals of a WAD, i.e. 1.0 is 10^18.
+	WADDecimals = 18
+
+	// RAYDecimals is the number of decimals of a RAY, i.e. 1.0 is 10^27.
+	RAYDecimals = 27
+)
+
+// ToWAD returns `a` as a WAD, i.e. scaled by 10^18. This is always exact.
+func (a UFix64) ToWAD() *big.Int { return scaleBig(a.ToBigInt(), WADDecimals-Fix64Decimals) }
+
+// ToWAD returns `a` as a WAD, i.e. scaled by 10^18. This is always exact.
+func (a Fix64) ToWAD() *big.Int { return scaleBig(a.ToBigInt(), WADDecimals-Fix64Decimals) }
+
+// ToWAD returns `a` as a WAD, i.e. scaled by 10^18, rounding away the last 6 decimals as
+// specified. Non-zero values that round to zero return an UnderflowError.
+func (a UFix128) ToWAD(round RoundingMode) (*big.Int, error) {
+	return roundToDecimals(false, uint64(a.Hi), uint64(a.Lo), Fix128Decimals, WADDecimals, round)
+}
+
+// ToWAD returns `a` as a WAD, rounding as specified, see UFix128.ToWAD.
+func (a Fix128) ToWAD(round RoundingMode) (*big.Int, error) {
+	aUnsigned, sign := a.Abs()
+	return roundToDecimals(sign < 0, uint64(aUnsigned.Hi), uint64(aUnsigned.Lo), Fix128Decimals, WADDecimals, round)
+}
+
+// ToRAY returns `a` as a RAY, i.e. scaled by 10^27. This is always exact.
+func (a UFix64) ToRAY() *big.Int { return scaleBig(a.ToBigInt(), RAYDecimals-Fix64Decimals) }
+
+// ToRAY returns `a` as a RAY, i.e. scaled by 10^27. This is always exact.
+func (a Fix64) ToRAY() *big.Int { return scaleBig(a.ToBigInt(), RAYDecimals-Fix64Decimals) }
+
+// ToRAY returns `a` as a RAY, i.e. scaled by 10^27. This is always exact.
+func (a UFix128) ToRAY() *big.Int { return scaleBig(a.ToBigInt(), RAYDecimals-Fix128Decimals) }
+
+// ToRAY returns `a` as a RAY, i.e. scaled by 10^27. This is always exact.
+func (a Fix128) ToRAY() *big.Int { return scaleBig(a.ToBigInt(), RAYDecimals-Fix128Decimals) }
+
+// UFix64FromWAD converts a WAD to a UFix64, rounding as specified. It returns the same errors as
+// UFix64FromMantScale.
+func UFix64FromWAD(n *big.Int, round RoundingMode) (UFix64, error) {
+	return UFix64FromMantScale(n, WADDecimals, round)
+}
+
+// Fix64FromWAD converts a WAD to a Fix64, rounding as specified, see UFix64FromWAD.
+func Fix64FromWAD(n *big.Int, round RoundingMode) (Fix64, error) {
+	return Fix64FromMantScale(n, WADDecimals, round)
+}
+
+// UFix128FromWAD converts a WAD to a UFix128. This is exact, so it only fails if n is out of
+// range.
+func UFix128FromWAD(n *big.Int) (UFix128, error) {
+	return UFix128FromMantScale(n, WADDecimals, RoundTowardZero)
+}
+
+// Fix128FromWAD converts a WAD to a Fix128, see UFix128FromWAD.
+func Fix128FromWAD(n *big.Int) (Fix128, error) {
+	return Fix128FromMantScale(n, WADDecimals, RoundTowardZero)
+}
+
+// UFix64FromRAY converts a RAY to a UFix64, rounding as specified, see UFix64FromWAD.
+func UFix64FromRAY(n *big.Int, round RoundingMode) (UFix64, error) {
+	return UFix64FromMantScale(n, RAYDecimals, round)
+}
+
+// Fix64FromRAY converts a RAY to a Fix64, rounding as specified, see UFix64FromWAD.
+func Fix64FromRAY(n *big.Int, round RoundingMode) (Fix64, error) {
+	return Fix64FromMantScale(n, RAYDecimals, round)
+}
+
+// UFix128FromRAY converts a RAY to a UFix128, rounding away the last 3 decimals as specified, see
+// UFix64FromWAD.
+func UFix128FromRAY(n *big.Int, round RoundingMode) (UFix128, error) {
+	return UFix128FromMantScale(n, RAYDecimals, round)
+}
+
+// Fix128FromRAY converts a RAY to a Fix128, rounding as specified, see UFix128FromRAY.
+func Fix128FromRAY(n *big.Int, round RoundingMode) (Fix128, error) {
+	return Fix128FromMantScale(n, RAYDecimals, round)
+}
+
+// scaleBig returns n * 10^shift, modifying n.
+func scaleBig(n *big.Int, shift int64) *big.Int {
+	return n.Mul(n, pow10Big(shift))
+}
+
+// toMantissa returns the raw magnitude (hi, lo), which has typeDecimals decimals, scaled to the
+// given number of decimals, rounding as specified if there are fewer.
+func toMantissa(neg bool, hi, lo uint64, typeDecimals, decimals int, round RoundingMode) (*big.Int, error) {
+	if decimals < typeDecimals {
+		return roundToDecimals(neg, hi, lo, typeDecimals, decimals, round)
+	}
+
+	return scaleBig(bigFromMagnitude(neg, hi, lo), int64(decimals-typeDecimals)), nil
+}
+
+// roundToDecimals rounds the raw magnitude (hi, lo), which has typeDecimals decimals, to the given
+// number of decimals (which must be fewer), and returns the scaled result with its sign.
+func roundToDecimals(neg bool, hi, lo uint64, typeDecimals, decimals int, round RoundingMode) (*big.Int, error) {
+	if !round.isValid() {
+		return nil, InvalidRoundingModeError{}
+	}
+
+	sign := int64(1)
+	if neg {
+		sign = -1
+	}
+
+	resHi, resLo := roundDecimal(hi, lo, typeDecimals, decimals, round.forSign(sign))
+	if resHi == 0 && resLo == 0 && (hi != 0 || lo != 0) {
+		return nil, UnderflowError{}
+	}
+
+	return bigFromMagnitude(neg, resHi, resLo), nil
+}
+
+// ToUint256 returns `a` scaled to the given number of decimals, as a 256-bit EVM word (least
+// significant word first, the same layout as uint256.Int from github.com/holiman/uint256). If
+// there are fewer than 8 decimals, `a` is rounded as specified, and non-zero values that round to
+// zero return an UnderflowError. If the result doesn't fit in 256 bits, which is only possible with
+// more than 62 decimals, it returns a PositiveOverflowError.
+func (a UFix64) ToUint256(decimals uint8, round RoundingMode) ([4]uint64, error) {
+	return toUint256(0, uint64(a), Fix64Decimals, int(decimals), round)
+}
+
+// ToUint256 returns `a` scaled to the given number of decimals, as a 256-bit EVM word, see
+// UFix64.ToUint256. Values are rounded if there are fewer than 24 decimals.
+func (a UFix128) ToUint256(decimals uint8, round RoundingMode) ([4]uint64, error) {
+	return toUint256(uint64(a.Hi), uint64(a.Lo), Fix128Decimals, int(decimals), round)
+}
+
+// UFix64FromUint256 converts a 256-bit EVM word (as returned by ToUint256), scaled by the given
+// number of decimals, to a UFix64, rounding as specified. It returns the same errors as
+// UFix64FromMantScale.
+func UFix64FromUint256(w [4]uint64, decimals uint8, round RoundingMode) (UFix64, error) {
+	return UFix64FromMantScale(bigFromUint256(w), int(decimals), round)
+}
+
+// UFix128FromUint256 converts a 256-bit EVM word, scaled by the given number of decimals, to a
+// UFix128, rounding as specified, see UFix64FromUint256.
+func UFix128FromUint256(w [4]uint64, decimals uint8, round RoundingMode) (UFix128, error) {
+	return UFix128FromMantScale(bigFromUint256(w), int(decimals), round)
+}
+
+func toUint256(hi, lo uint64, typeDecimals, decimals int, round RoundingMode) ([4]uint64, error) {
+	n, err := toMantissa(false, hi, lo, typeDecimals, decimals, round)
+	if err != nil {
+		return [4]uint64{}, err
+	}
+
+	if n.BitLen() > 256 {
+		return [4]uint64{}, PositiveOverflowError{}
+	}
+
+	var buf [32]byte
+	n.FillBytes(buf[:])
+
+	var w [4]uint64
+	for i := range w {
+		w[i] = binary.BigEndian.Uint64(buf[24-8*i:])
+	}
+
+	return w, nil
+}
+
+func bigFromUint256(w [4]uint64) *big.Int {
+	var buf [32]byte
+	for i := range w {
+		binary.BigEndian.PutUint64(buf[24-8*i:], w[i])
+	}
+
+	return new(big.Int).SetBytes(buf[:])
+}
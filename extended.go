@@ -0,0 +1,268 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+// Fix192 constants, see Fix192.
+var Fix192Zero = Fix192(fix192Zero)
+var Fix192One = Fix192(fix192One)
+
+// ToFix192 returns `a` as a Fix192 value, this conversion is always exact.
+func (a UFix64) ToFix192() Fix192 { return Fix192(a.toFix192()) }
+
+// ToFix192 returns `a` as a Fix192 value, this conversion is always exact.
+func (a Fix64) ToFix192() Fix192 { return Fix192(a.toFix192()) }
+
+// ToFix192 returns `a` as a Fix192 value, or an error if `a` is larger than the largest Fix192
+// value (which has the same integer range as Fix128).
+func (a UFix128) ToFix192() (Fix192, error) {
+	if isNeg64(a.Hi) {
+		return Fix192Zero, PositiveOverflowError{}
+	}
+
+	return Fix192(a.toFix192()), nil
+}
+
+// ToFix192 returns `a` as a Fix192 value, this conversion is always exact.
+func (a Fix128) ToFix192() Fix192 { return Fix192(a.toFix192()) }
+
+// ToUFix64 rounds `a` to a UFix64 using the given rounding mode. Returns an error if `a` is
+// negative or too large, or an UnderflowError if a non-zero value rounds to zero.
+func (a Fix192) ToUFix64(round RoundingMode) (UFix64, error) {
+	if a.IsNeg() {
+		return UFix64Zero, NegativeOverflowError{}
+	}
+
+	return fix192(a).toUFix64(round)
+}
+
+// ToFix64 rounds `a` to a Fix64 using the given rounding mode. Returns an error if `a` is out of
+// range, or an UnderflowError if a non-zero value rounds to zero.
+func (a Fix192) ToFix64(round RoundingMode) (Fix64, error) {
+	mag, sign := fix192(a).abs()
+
+	res, err := mag.toUFix64(round.forSign(sign))
+
+	if err != nil {
+		return Fix64Zero, applySign(err, sign)
+	}
+
+	return res.ApplySign(sign)
+}
+
+// ToUFix128 rounds `a` to a UFix128 using the given rounding mode. Returns an error if `a` is
+// negative or too large, or an UnderflowError if a non-zero value rounds to zero.
+func (a Fix192) ToUFix128(round RoundingMode) (UFix128, error) {
+	if a.IsNeg() {
+		return UFix128Zero, NegativeOverflowError{}
+	}
+
+	return fix192(a).toUFix128(round)
+}
+
+// ToFix128 rounds `a` to a Fix128 using the given rounding mode. Returns an error if `a` is out
+// of range, or an UnderflowError if a non-zero value rounds to zero.
+func (a Fix192) ToFix128(round RoundingMode) (Fix128, error) {
+	mag, sign := fix192(a).abs()
+
+	res, err := mag.toUFix128(round.forSign(sign))
+
+	if err != nil {
+		return Fix128Zero, applySign(err, sign)
+	}
+
+	return res.ApplySign(sign)
+}
+
+// Eq returns true if `a` is equal to `b`.
+func (a Fix192) Eq(b Fix192) bool { return fix192(a).isEqual(fix192(b)) }
+
+// Lt returns true if `a` is less than `b`.
+func (a Fix192) Lt(b Fix192) bool {
+	// Flipping the sign bits maps the signed range onto the unsigned range in the same order.
+	a.Hi ^= 1 << 63
+	b.Hi ^= 1 << 63
+
+	return fix192(a).ult(fix192(b))
+}
+
+// Gt returns true if `a` is greater than `b`.
+func (a Fix192) Gt(b Fix192) bool { return b.Lt(a) }
+
+// Lte returns true if `a` is less than or equal to `b`.
+func (a Fix192) Lte(b Fix192) bool { return !a.Gt(b) }
+
+// Gte returns true if `a` is greater than or equal to `b`.
+func (a Fix192) Gte(b Fix192) bool { return !a.Lt(b) }
+
+// IsZero returns true if `a` is zero.
+func (a Fix192) IsZero() bool { return fix192(a).isZero() }
+
+// IsNeg returns true if `a` is negative.
+func (a Fix192) IsNeg() bool { return isNeg64(a.Hi) }
+
+// Neg returns `-a`, or an error if `a` is the most negative Fix192 value.
+func (a Fix192) Neg() (Fix192, error) {
+	res := Fix192(fix192(a).neg())
+
+	if !a.IsZero() && res.IsNeg() == a.IsNeg() {
+		return Fix192Zero, NegativeOverflowError{}
+	}
+
+	return res, nil
+}
+
+// Add returns `a + b`, or an error on overflow.
+func (a Fix192) Add(b Fix192) (Fix192, error) {
+	res := Fix192(fix192(a).add(fix192(b)))
+
+	// Overflow is only possible when both inputs have the same sign, and shows up as a result
+	// with the opposite sign.
+	if a.IsNeg() == b.IsNeg() && res.IsNeg() != a.IsNeg() {
+		return Fix192Zero, applySign(PositiveOverflowError{}, fix192Sign(a))
+	}
+
+	return res, nil
+}
+
+// Sub returns `a - b`, or an error on overflow.
+func (a Fix192) Sub(b Fix192) (Fix192, error) {
+	res := Fix192(fix192(a).sub(fix192(b)))
+
+	if a.IsNeg() != b.IsNeg() && res.IsNeg() != a.IsNeg() {
+		return Fix192Zero, applySign(PositiveOverflowError{}, fix192Sign(a))
+	}
+
+	return res, nil
+}
+
+// Mul returns `a * b`, rounded to the nearest Fix192 value (ties away from zero), or an error on
+// overflow. Products too small to represent are returned as zero.
+func (a Fix192) Mul(b Fix192) (Fix192, error) {
+	res, err := fix192(a).smul(fix192(b))
+
+	return Fix192(res), err
+}
+
+// Div returns `a / b`, rounded to the nearest Fix192 value (ties away from zero), or an error on
+// overflow or division by zero. Quotients too small to represent are returned as zero.
+func (a Fix192) Div(b Fix192) (Fix192, error) {
+	res, err := fix192(a).sdiv(fix192(b))
+
+	return Fix192(res), err
+}
+
+// Poly evaluates the polynomial with the given coefficients at `a` using Horner's method, with
+// coeffs[0] being the coefficient of the highest power, i.e. coeffs[0]*a^n + ... + coeffs[n].
+// Returns zero if coeffs is empty.
+func (a Fix192) Poly(coeffs []Fix192) (Fix192, error) {
+	if len(coeffs) == 0 {
+		return Fix192Zero, nil
+	}
+
+	accum := coeffs[0]
+
+	for _, c := range coeffs[1:] {
+		var err error
+
+		if accum, err = accum.Mul(a); err != nil {
+			return Fix192Zero, err
+		}
+
+		if accum, err = accum.Add(c); err != nil {
+			return Fix192Zero, err
+		}
+	}
+
+	return accum, nil
+}
+
+// Ln returns the natural logarithm of `a`, or a domain error if `a` is zero or negative.
+func (a Fix192) Ln() (Fix192, error) {
+	if a.IsNeg() {
+		return Fix192Zero, OutOfDomainErrorError{}
+	}
+
+	res, err := fix192(a).ln()
+
+	return Fix192(res), err
+}
+
+// Exp returns e^a, or an error if the result is too large or too small to represent.
+func (a Fix192) Exp() (Fix192, error) {
+	res, err := fix192(a).exp()
+
+	if err != nil {
+		return Fix192Zero, err
+	}
+
+	// The result is unsigned, it has to be checked that it also fits in the signed range.
+	res, err = res.applySign(1)
+
+	return Fix192(res), err
+}
+
+// Pow returns `a^b`, or an error on overflow or underflow, or a domain error if `a` is negative,
+// or if `a` is zero and `b` is negative. 0^0 is 1.
+func (a Fix192) Pow(b Fix192) (Fix192, error) {
+	if b.IsZero() {
+		return Fix192One, nil
+	}
+
+	if a.IsNeg() {
+		return Fix192Zero, OutOfDomainErrorError{}
+	}
+
+	if a.IsZero() {
+		if b.IsNeg() {
+			return Fix192Zero, OutOfDomainErrorError{}
+		}
+		return Fix192Zero, nil
+	}
+
+	res, err := fix192(a).pow(fix192(b))
+
+	if err != nil {
+		return Fix192Zero, err
+	}
+
+	res, err = res.applySign(1)
+
+	return Fix192(res), err
+}
+
+// Sin returns the sine of `a` (in radians).
+func (a Fix192) Sin() (Fix192, error) {
+	res, err := fix192(a).sin()
+
+	return Fix192(res), err
+}
+
+// Cos returns the cosine of `a` (in radians).
+func (a Fix192) Cos() (Fix192, error) {
+	res, err := fix192(a).cos()
+
+	return Fix192(res), err
+}
+
+// fix192Sign returns -1 if `a` is negative, and 1 otherwise.
+func fix192Sign(a Fix192) int64 {
+	if a.IsNeg() {
+		return -1
+	}
+
+	return 1
+}
@@ -46,10 +46,48 @@ func (a Fix128) Lte(b Fix128) bool   { return !a.Gt(b) }
 func (a UFix128) Gte(b UFix128) bool { return !a.Lt(b) }
 func (a Fix128) Gte(b Fix128) bool   { return !a.Lt(b) }
 
+// ApproxEq returns true if `a` and `b` differ by at most `tol`. Unlike subtracting and comparing
+// the result, this can't overflow, even when `a` and `b` are far apart or have opposite signs.
+func (a UFix128) ApproxEq(b, tol UFix128) bool      { return tol.Gte(a.distance(b)) }
+func (a Fix128) ApproxEq(b Fix128, tol UFix128) bool { return tol.Gte(a.distance(b)) }
+
+// distance returns the absolute difference between `a` and `b`, which is always representable as
+// an unsigned value (even for signed inputs).
+func (a UFix128) distance(b UFix128) UFix128 {
+	if a.Lt(b) {
+		a, b = b, a
+	}
+
+	diff, _ := sub128(raw128(a), raw128(b), 0)
+
+	return UFix128(diff)
+}
+
+func (a Fix128) distance(b Fix128) UFix128 {
+	if a.Lt(b) {
+		a, b = b, a
+	}
+
+	// The true difference is non-negative and less than 2**64, so an unsigned subtraction of the
+	// raw values gives the right answer, even if it wraps around.
+	diff, _ := sub128(raw128(a), raw128(b), 0)
+
+	return UFix128(diff)
+}
+
 // IsNeg returns true if `a` is negative.
 func (a Fix128) IsNeg() bool { return isNeg128(raw128(a)) }
 
-// Neg returns the additive inverse of `a` (i.e. -a), or a negative overflow error
+// Neg returns the additive inverse of `a` (i.e. -a), or a negative overflow error. For unsigned
+// values, zero is the only value with a representable inverse, so any other value is an error.
+func (a UFix128) Neg() (UFix128, error) {
+	if a.IsZero() {
+		return UFix128Zero, nil
+	}
+
+	return UFix128Zero, NegativeOverflowError{}
+}
+
 func (a Fix128) Neg() (Fix128, error) {
 	if a == Fix128Min {
 		// Special case: negating the minimum value will overflow.
@@ -59,6 +97,25 @@ func (a Fix128) Neg() (Fix128, error) {
 	return Fix128(neg128(raw128(a))), nil
 }
 
+// IsInteger returns true if `a` is a whole number, i.e. it has no fractional part.
+func (a UFix128) IsInteger() bool { return isZero128(mod128(raw128(a), raw128(UFix128One))) }
+func (a Fix128) IsInteger() bool {
+	// The raw value of a negative number modulo the scale isn't meaningful, so check the magnitude.
+	aUnsigned, _ := a.Abs()
+
+	return aUnsigned.IsInteger()
+}
+
+// HasFraction returns true if `a` has a non-zero fractional part.
+func (a UFix128) HasFraction() bool { return !a.IsInteger() }
+func (a Fix128) HasFraction() bool  { return !a.IsInteger() }
+
+// Iota returns the smallest positive value representable by the type of `a` (i.e. UFix128Iota or
+// Fix128Iota), regardless of the value of `a`. Useful in generic code, where the constants can't be
+// named directly.
+func (UFix128) Iota() UFix128 { return UFix128Iota }
+func (Fix128) Iota() Fix128   { return Fix128Iota }
+
 // IsZero returns true if `a` is zero.
 func (a UFix128) IsZero() bool { return isZero128(raw128(a)) }
 func (a Fix128) IsZero() bool  { return isZero128(raw128(a)) }
@@ -122,6 +179,75 @@ func (a Fix128) Sub(b Fix128) (Fix128, error) {
 	return res, nil
 }
 
+// AddSigned returns the sum of the unsigned value `a` and the signed value `delta`, or an error on
+// overflow, or negative overflow if the result would be negative. Useful for applying a signed
+// change to an unsigned quantity (e.g. a balance) without juggling Abs and ApplySign.
+func (a UFix128) AddSigned(delta Fix128) (UFix128, error) {
+	mag, sign := delta.Abs()
+
+	if sign < 0 {
+		return a.Sub(mag)
+	}
+
+	return a.Add(mag)
+}
+
+// SubSigned returns the difference of the unsigned value `a` and the signed value `delta`, or an
+// error on overflow, or negative overflow if the result would be negative.
+func (a UFix128) SubSigned(delta Fix128) (UFix128, error) {
+	mag, sign := delta.Abs()
+
+	if sign < 0 {
+		return a.Add(mag)
+	}
+
+	return a.Sub(mag)
+}
+
+// NextUp returns the smallest representable value greater than `a`, or an error on overflow if
+// `a` is the largest representable value.
+func (a UFix128) NextUp() (UFix128, error) {
+	res, carry := add128(raw128(a), raw128Zero, 1)
+
+	if carry != 0 {
+		return UFix128Zero, PositiveOverflowError{}
+	}
+
+	return UFix128(res), nil
+}
+
+func (a Fix128) NextUp() (Fix128, error) {
+	if a == Fix128Max {
+		return Fix128Zero, PositiveOverflowError{}
+	}
+
+	res, _ := add128(raw128(a), raw128Zero, 1)
+
+	return Fix128(res), nil
+}
+
+// NextDown returns the largest representable value less than `a`, or an error on negative
+// overflow if `a` is the smallest representable value.
+func (a UFix128) NextDown() (UFix128, error) {
+	res, borrow := sub128(raw128(a), raw128Zero, 1)
+
+	if borrow != 0 {
+		return UFix128Zero, NegativeOverflowError{}
+	}
+
+	return UFix128(res), nil
+}
+
+func (a Fix128) NextDown() (Fix128, error) {
+	if a == Fix128Min {
+		return Fix128Zero, NegativeOverflowError{}
+	}
+
+	res, _ := sub128(raw128(a), raw128Zero, 1)
+
+	return Fix128(res), nil
+}
+
 // Abs returns the absolute value of `a` as an unsigned value, with a sign value as an int64.
 // Note that this method works properly for Fix128Min, which can NOT be represented as a positive Fix128.
 func (a Fix128) Abs() (UFix128, int64) {
@@ -134,29 +260,60 @@ func (a Fix128) Abs() (UFix128, int64) {
 	return UFix128(a), 1
 }
 
+// CmpAbs compares the magnitudes of `a` and `b`, returning -1 if |a| < |b|, 0 if |a| == |b|, and
+// +1 if |a| > |b|. Note that this works properly for Fix128Min, which has a larger magnitude
+// than any other Fix128 value.
+func (a Fix128) CmpAbs(b Fix128) int {
+	aUnsigned, _ := a.Abs()
+	bUnsigned, _ := b.Abs()
+
+	return compare(aUnsigned, bUnsigned)
+}
+
 // ApplySign converts a UFix128 to a Fix128, applying the sign specified by the input.
 func (a UFix128) ApplySign(sign int64) (Fix128, error) {
+	res, code := a.applySignCode(sign)
+
+	return res, code.err()
+}
+
+// applySignCode implements ApplySign, reporting any failure as an ErrorCode rather than an error.
+func (a UFix128) applySignCode(sign int64) (Fix128, ErrorCode) {
 	if sign == 1 {
 		if a.Gt(UFix128(Fix128Max)) {
-			return Fix128Zero, PositiveOverflowError{}
+			return Fix128Zero, ErrorCodeOverflow
 		}
-		return Fix128(a), nil
+		return Fix128(a), ErrorCodeNone
 	} else {
 		// Special case: if the result's sign should be negative and the converted
 		// value is the minimum representable value, we can just return the minimum
 		// value. We need to do this because the comparison against FixMax will fail
 		// below, even thought would be a valid result.
 		if isEqual128(raw128(a), raw128(Fix128Min)) {
-			return Fix128Min, nil
+			return Fix128Min, ErrorCodeNone
 		}
 		if a.Gt(UFix128(Fix128Max)) {
-			return Fix128Zero, NegativeOverflowError{}
+			return Fix128Zero, ErrorCodeNegativeOverflow
 		}
 
-		return Fix128(neg128(raw128(a))), nil
+		return Fix128(neg128(raw128(a))), ErrorCodeNone
 	}
 }
 
+// ToFix128 converts a UFix128 to a Fix128, or returns an error on overflow if `a` is larger than
+// Fix128Max. Use this instead of a plain type conversion, which silently wraps large values.
+func (a UFix128) ToFix128() (Fix128, error) { return a.ApplySign(1) }
+
+// ToUFix128 converts a Fix128 to a UFix128, or returns an error on negative overflow if `a` is
+// negative. Use this instead of a plain type conversion, which silently wraps negative values.
+func (a Fix128) ToUFix128() (UFix128, error) {
+	if a.IsNeg() {
+		return UFix128Zero, NegativeOverflowError{}
+	}
+
+	return UFix128(a), nil
+}
+
 // Mul returns the product of `a` and `b`, or an error on overflow or underflow.
 func (a UFix128) Mul(b UFix128, round RoundingMode) (UFix128, error) {
 	// It might seem strange to implement multiplication in terms of fused multiply-divide,
@@ -188,55 +345,171 @@ func (a Fix128) Div(b Fix128, round RoundingMode) (Fix128, error) {
 	return a.FMD(Fix128One, b, round)
 }
 
-// FMD returns a*b/c without intermediate rounding, or an error on division by zero, overflow, or underflow.
-func (a UFix128) FMD(b, c UFix128, round RoundingMode) (UFix128, error) {
-	// Must come before the check for a or b == 0 so we flag 0.0/0.0 as an error.
-	if c.IsZero() {
-		return UFix128Zero, DivisionByZeroError{}
-	}
+// MulDefault and DivDefault are compatibility wrappers for callers written against the older
+// API, where Mul and Div didn't take a rounding mode and always truncated the result. New code
+// should call Mul and Div with an explicit rounding mode.
 
-	if a.IsZero() || b.IsZero() {
-		return UFix128Zero, nil
+// MulDefault returns the product of `a` and `b` rounded toward zero, or an error on overflow or
+// underflow.
+func (a UFix128) MulDefault(b UFix128) (UFix128, error) { return a.Mul(b, RoundTowardZero) }
+func (a Fix128) MulDefault(b Fix128) (Fix128, error)    { return a.Mul(b, RoundTowardZero) }
+
+// DivDefault returns the quotient of `a` and `b` rounded toward zero, or an error on division by
+// zero, overflow, or underflow.
+func (a UFix128) DivDefault(b UFix128) (UFix128, error) { return a.Div(b, RoundTowardZero) }
+func (a Fix128) DivDefault(b Fix128) (Fix128, error)    { return a.Div(b, RoundTowardZero) }
+
+// MulExact returns the product of `a` and `b`, or an error on overflow, underflow, or if the
+// product can't be represented exactly.
+func (a UFix128) MulExact(b UFix128) (UFix128, error) {
+	res, exact, err := a.fmd(b, UFix128One, RoundTowardZero)
+
+	if err == nil && !exact {
+		return UFix128Zero, InexactError{}
 	}
 
-	hi, lo := mul128(raw128(a), raw128(b))
+	return res, err
+}
 
-	// If the hi part is >= the divisor the result can't fit in 64 bits.
-	if UFix128(hi).Gte(c) {
-		return UFix128Zero, PositiveOverflowError{}
+// MulExact returns the product of `a` and `b`, or an error on overflow, underflow, or if the
+// product can't be represented exactly.
+func (a Fix128) MulExact(b Fix128) (Fix128, error) {
+	res, exact, err := a.fmd(b, Fix128One, RoundTowardZero)
+
+	if err == nil && !exact {
+		return Fix128Zero, InexactError{}
 	}
 
-	quo, rem := div128(hi, lo, raw128(c))
+	return res, err
+}
 
-	if ushouldRound128(quo, rem, raw128(c), round) {
-		var carry uint64
-		quo, carry = add128(quo, raw128Zero, 1)
+// DivExact returns the quotient of `a` and `b`, or an error on division by zero, overflow,
+// underflow, or if the quotient can't be represented exactly.
+func (a UFix128) DivExact(b UFix128) (UFix128, error) {
+	res, exact, err := a.fmd(UFix128One, b, RoundTowardZero)
 
-		// Make sure we don't "round up" to a value outside of the range of UFix128!
-		if carry != 0 {
-			return UFix128Zero, PositiveOverflowError{}
-		}
+	if err == nil && !exact {
+		return UFix128Zero, InexactError{}
 	}
 
-	// We can't get here if `a == 0` or `b == 0` because we checked that first. So,
-	// a quotient of 0 means the result is too small to represent, i.e. underflow.
-	// Note that we check this AFTER rounding.
-	if isZero128(quo) {
-		return UFix128Zero, UnderflowError{}
+	return res, err
+}
+
+// DivExact returns the quotient of `a` and `b`, or an error on division by zero, overflow,
+// underflow, or if the quotient can't be represented exactly.
+func (a Fix128) DivExact(b Fix128) (Fix128, error) {
+	res, exact, err := a.fmd(Fix128One, b, RoundTowardZero)
+
+	if err == nil && !exact {
+		return Fix128Zero, InexactError{}
 	}
 
-	return UFix128(quo), nil
+	return res, err
+}
+
+// MulX returns the product of `a` and `b` like Mul, along with a flag that is true if the product
+// was exact (i.e. no rounding was needed), or an error on overflow or underflow.
+func (a UFix128) MulX(b UFix128, round RoundingMode) (UFix128, bool, error) {
+	return a.fmd(b, UFix128One, round)
+}
+
+// MulX returns the product of `a` and `b` like Mul, along with a flag that is true if the product
+// was exact (i.e. no rounding was needed), or an error on overflow or underflow.
+func (a Fix128) MulX(b Fix128, round RoundingMode) (Fix128, bool, error) {
+	return a.fmd(b, Fix128One, round)
+}
+
+// DivX returns the quotient of `a` and `b` like Div, along with a flag that is true if the quotient
+// was exact (i.e. no rounding was needed), or an error on division by zero, overflow, or underflow.
+func (a UFix128) DivX(b UFix128, round RoundingMode) (UFix128, bool, error) {
+	return a.fmd(UFix128One, b, round)
+}
+
+// DivX returns the quotient of `a` and `b` like Div, along with a flag that is true if the quotient
+// was exact (i.e. no rounding was needed), or an error on division by zero, overflow, or underflow.
+func (a Fix128) DivX(b Fix128, round RoundingMode) (Fix128, bool, error) {
+	return a.fmd(Fix128One, b, round)
+}
+
+// FMDX returns `a*b/c` like FMD, along with a flag that is true if the result was exact (i.e. no
+// rounding was needed), or an error on division by zero, overflow, or underflow.
+func (a UFix128) FMDX(b, c UFix128, round RoundingMode) (UFix128, bool, error) {
+	return a.fmd(b, c, round)
+}
+
+// FMDX returns `a*b/c` like FMD, along with a flag that is true if the result was exact (i.e. no
+// rounding was needed), or an error on division by zero, overflow, or underflow.
+func (a Fix128) FMDX(b, c Fix128, round RoundingMode) (Fix128, bool, error) {
+	return a.fmd(b, c, round)
+}
+
+// FMD returns a*b/c without intermediate rounding, or an error on division by zero, overflow, or underflow.
+func (a UFix128) FMD(b, c UFix128, round RoundingMode) (UFix128, error) {
+	res, _, err := a.fmd(b, c, round)
+
+	return res, err
 }
 
 // FMD returns `a*b/c` without intermediate rounding, or an error on division by zero, overflow, or underflow.
 func (a Fix128) FMD(b, c Fix128, round RoundingMode) (Fix128, error) {
+	res, _, err := a.fmd(b, c, round)
+
+	return res, err
+}
+
+// fmd implements FMD, additionally returning true if the result is exact (i.e. no rounding was
+// needed).
+func (a UFix128) fmd(b, c UFix128, round RoundingMode) (UFix128, bool, error) {
+	res, exact, code := a.fmdCode(b, c, round)
+
+	return res, exact, code.err()
+}
+
+// fmdCode implements fmd, reporting any failure as an ErrorCode rather than an error, for the
+// checked operators.
+func (a UFix128) fmdCode(b, c UFix128, round RoundingMode) (UFix128, bool, ErrorCode) {
+	if !round.isValid() {
+		return UFix128Zero, false, ErrorCodeInvalidRoundingMode
+	}
+
+	// Must come before the check for a or b == 0 so we flag 0.0/0.0 as an error.
+	if c.IsZero() {
+		return UFix128Zero, false, ErrorCodeDivisionByZero
+	}
+
+	if a.IsZero() || b.IsZero() {
+		return UFix128Zero, true, ErrorCodeNone
+	}
+
+	hi, lo := mul128(raw128(a), raw128(b))
+
+	// We can't get here if `a == 0` or `b == 0` because we checked that first, so the product
+	// is non-zero as udivRoundCode128 requires.
+	return udivRoundCode128(hi, lo, raw128(c), round)
+}
+
+// fmd implements FMD, additionally returning true if the result is exact (i.e. no rounding was
+// needed).
+func (a Fix128) fmd(b, c Fix128, round RoundingMode) (Fix128, bool, error) {
+	res, exact, code := a.fmdCode(b, c, round)
+
+	return res, exact, code.err()
+}
+
+// fmdCode implements fmd, reporting any failure as an ErrorCode rather than an error, for the
+// checked operators.
+func (a Fix128) fmdCode(b, c Fix128, round RoundingMode) (Fix128, bool, ErrorCode) {
+	if !round.isValid() {
+		return Fix128Zero, false, ErrorCodeInvalidRoundingMode
+	}
+
 	// Must come before the check for `a` or `b` == 0 so we flag 0.0/0.0 as an error.
 	if c.IsZero() {
-		return Fix128Zero, DivisionByZeroError{}
+		return Fix128Zero, false, ErrorCodeDivisionByZero
 	}
 
 	if a.IsZero() || b.IsZero() {
-		return Fix128Zero, nil
+		return Fix128Zero, true, ErrorCodeNone
 	}
 
 	// Determine the sign of the result based on the signs of a, b, and c.
@@ -250,7 +523,95 @@ func (a Fix128) FMD(b, c Fix128, round RoundingMode) (Fix128, error) {
 	sign *= signMul
 
 	// Compute the result using unsigned arithmetic.
-	res, err := aUnsigned.FMD(bUnsigned, cUnsigned, round)
+	res, exact, code := aUnsigned.fmdCode(bUnsigned, cUnsigned, round.forSign(sign))
+
+	if code != ErrorCodeNone {
+		return Fix128Zero, false, applySignCode(code, sign)
+	}
+
+	signedRes, code := res.applySignCode(sign)
+
+	return signedRes, exact, code
+}
+
+// FMA returns `a*b + c` without intermediate rounding, or an error on overflow or underflow.
+func (a UFix128) FMA(b, c UFix128, round RoundingMode) (UFix128, error) {
+	if !round.isValid() {
+		return UFix128Zero, InvalidRoundingModeError{}
+	}
+
+	if a.IsZero() || b.IsZero() {
+		return c, nil
+	}
+
+	hi, lo := mul128(raw128(a), raw128(b))
+
+	// Scale up `c` so that it can be added directly to the double-width product.
+	cHi, cLo := mul128(raw128(c), raw128(UFix128One))
+
+	var carry uint64
+	lo, carry = add128(lo, cLo, 0)
+	hi, carry = add128(hi, cHi, carry)
+
+	if carry != 0 {
+		return UFix128Zero, PositiveOverflowError{}
+	}
+
+	res, _, err := udivRound128(hi, lo, raw128(UFix128One), round)
+
+	return res, err
+}
+
+// FMA returns `a*b + c` without intermediate rounding, or an error on overflow, negative overflow,
+// or underflow.
+func (a Fix128) FMA(b, c Fix128, round RoundingMode) (Fix128, error) {
+	if !round.isValid() {
+		return Fix128Zero, InvalidRoundingModeError{}
+	}
+
+	if a.IsZero() || b.IsZero() {
+		return c, nil
+	}
+
+	// We do all of the work on the magnitudes of the product and `c`, keeping track of the sign
+	// separately. This ensures that the rounding is symmetric, the same as in FMD.
+	aUnsigned, aSign := a.Abs()
+	bUnsigned, bSign := b.Abs()
+	cUnsigned, cSign := c.Abs()
+
+	sign := aSign * bSign
+
+	hi, lo := mul128(raw128(aUnsigned), raw128(bUnsigned))
+	cHi, cLo := mul128(raw128(cUnsigned), raw128(UFix128One))
+
+	if sign == cSign {
+		// Same signs, add the magnitudes.
+		var carry uint64
+		lo, carry = add128(lo, cLo, 0)
+		hi, carry = add128(hi, cHi, carry)
+
+		if carry != 0 {
+			return Fix128Zero, applySign(PositiveOverflowError{}, sign)
+		}
+	} else {
+		// Different signs, subtract the smaller magnitude from the larger one, and use the sign
+		// of the larger one.
+		if ult128(hi, cHi) || (isEqual128(hi, cHi) && ult128(lo, cLo)) {
+			hi, lo, cHi, cLo = cHi, cLo, hi, lo
+			sign = cSign
+		}
+
+		var borrow uint64
+		lo, borrow = sub128(lo, cLo, 0)
+		hi, _ = sub128(hi, cHi, borrow)
+
+		// The terms cancelled out exactly.
+		if isZero128(hi) && isZero128(lo) {
+			return Fix128Zero, nil
+		}
+	}
+
+	res, _, err := udivRound128(hi, lo, raw128(UFix128One), round.forSign(sign))
 
 	if err != nil {
 		return Fix128Zero, applySign(err, sign)
@@ -259,7 +620,159 @@ func (a Fix128) FMD(b, c Fix128, round RoundingMode) (Fix128, error) {
 	return res.ApplySign(sign)
 }
 
-// Mod returns the remainder of `a` divided by `b`, or an error on division by zero.
+// Dot2 returns `a*b + c*d` without intermediate rounding, or an error on overflow or underflow.
+func (a UFix128) Dot2(b, c, d UFix128, round RoundingMode) (UFix128, error) {
+	return SumOfProductsUFix128([][2]UFix128{{a, b}, {c, d}}, round)
+}
+
+// Dot2 returns `a*b + c*d` without intermediate rounding, or an error on overflow, negative
+// overflow, or underflow.
+func (a Fix128) Dot2(b, c, d Fix128, round RoundingMode) (Fix128, error) {
+	return SumOfProductsFix128([][2]Fix128{{a, b}, {c, d}}, round)
+}
+
+// SumOfProductsUFix128 returns the sum of the products of each pair of values, with a single
+// rounding at the end, or an error on overflow or underflow.
+func SumOfProductsUFix128(pairs [][2]UFix128, round RoundingMode) (UFix128, error) {
+	var acc accum128
+
+	for _, pair := range pairs {
+		acc.addProduct(pair[0], pair[1], 1)
+	}
+
+	res, _, err := acc.value(round)
+
+	return res, err
+}
+
+// SumOfProductsFix128 returns the sum of the products of each pair of values, with a single
+// rounding at the end, or an error on overflow, negative overflow, or underflow. Intermediate
+// sums are allowed to exceed the range of Fix128, provided the final result is in range.
+func SumOfProductsFix128(pairs [][2]Fix128, round RoundingMode) (Fix128, error) {
+	var acc accum128
+
+	for _, pair := range pairs {
+		aUnsigned, aSign := pair[0].Abs()
+		bUnsigned, bSign := pair[1].Abs()
+
+		acc.addProduct(aUnsigned, bUnsigned, aSign*bSign)
+	}
+
+	res, sign, err := acc.value(round)
+
+	if err != nil {
+		return Fix128Zero, applySign(err, sign)
+	}
+
+	return res.ApplySign(sign)
+}
+
+// udivRound128 divides the double-width, NON-ZERO value (hi, lo) by `y`, rounding the result as
+// specified, or returns an error if the result overflows or underflows. The boolean result is true
+// if the division was exact (i.e. no rounding was needed). This is the core of FMD, and the other
+// operations that need to scale down a wide intermediate value with a single rounding.
+func udivRound128(hi, lo, y raw128, round RoundingMode) (UFix128, bool, error) {
+	res, exact, code := udivRoundCode128(hi, lo, y, round)
+
+	return res, exact, code.err()
+}
+
+// udivRoundCode128 implements udivRound128, reporting any failure as an ErrorCode rather than an
+// error.
+func udivRoundCode128(hi, lo, y raw128, round RoundingMode) (UFix128, bool, ErrorCode) {
+	if !round.isValid() {
+		return UFix128Zero, false, ErrorCodeInvalidRoundingMode
+	}
+
+	// If the hi part is >= the divisor the result can't fit in 64 bits.
+	if !ult128(hi, y) {
+		return UFix128Zero, false, ErrorCodeOverflow
+	}
+
+	quo, rem := div128(hi, lo, y)
+	exact := isZero128(rem)
+
+	if ushouldRound128(quo, rem, y, round) {
+		var carry uint64
+		quo, carry = add128(quo, raw128Zero, 1)
+
+		// Make sure we don't "round up" to a value outside of the range of UFix128!
+		if carry != 0 {
+			return UFix128Zero, false, ErrorCodeOverflow
+		}
+	}
+
+	// The numerator is non-zero, so a quotient of 0 means the result is too small to
+	// represent, i.e. underflow. Note that we check this AFTER rounding.
+	if isZero128(quo) {
+		return UFix128Zero, false, ErrorCodeUnderflow
+	}
+
+	return UFix128(quo), exact, ErrorCodeNone
+}
+
+// accum128 is a signed accumulator for products of fixed-point values. Each product is added
+// at full, double-width precision (i.e. before scaling down and rounding), and the running total
+// is extended by a signed overflow word so that it can't overflow for any realistic number of
+// terms. The total is only scaled down and rounded when value() is called.
+type accum128 struct {
+	hi, lo raw128 // The low two words of the total, in two's-complement form
+	ext    int64 // The extension word (i.e. the sign and any carries out of the top word)
+}
+
+// addProduct adds (or subtracts, if sign is negative) the full product of `a` and `b`.
+func (acc *accum128) addProduct(a, b UFix128, sign int64) {
+	hi, lo := mul128(raw128(a), raw128(b))
+
+	var carry uint64
+
+	if sign < 0 {
+		acc.lo, carry = sub128(acc.lo, lo, 0)
+		acc.hi, carry = sub128(acc.hi, hi, carry)
+		acc.ext -= int64(carry)
+	} else {
+		acc.lo, carry = add128(acc.lo, lo, 0)
+		acc.hi, carry = add128(acc.hi, hi, carry)
+		acc.ext += int64(carry)
+	}
+}
+
+// value returns the magnitude of the total scaled down by the scale factor and rounded as
+// specified, along with the sign of the total, or an error if the magnitude overflows or
+// underflows. (The error is always unsigned, callers should use applySign() as needed.)
+func (acc *accum128) value(round RoundingMode) (UFix128, int64, error) {
+	// Must come before the check for a zero total, so the error doesn't depend on the values.
+	if !round.isValid() {
+		return UFix128Zero, 1, InvalidRoundingModeError{}
+	}
+
+	hi, lo, ext, sign := acc.hi, acc.lo, acc.ext, int64(1)
+
+	if ext < 0 {
+		// Negate all three words to get the magnitude of the total.
+		var borrow uint64
+		lo, borrow = sub128(raw128Zero, lo, 0)
+		hi, borrow = sub128(raw128Zero, hi, borrow)
+		ext = -ext - int64(borrow)
+		sign = -1
+	}
+
+	if ext != 0 {
+		return UFix128Zero, sign, PositiveOverflowError{}
+	}
+
+	if isZero128(hi) && isZero128(lo) {
+		return UFix128Zero, sign, nil
+	}
+
+	res, _, err := udivRound128(hi, lo, raw128(UFix128One), round.forSign(sign))
+
+	return res, sign, err
+}
+
+// Mod returns the remainder of `a` divided by `b`, or an error on division by zero. Note that
+// the remainder is always exactly representable, so unlike the other operations, Mod never rounds
+// and doesn't take a rounding mode.
 func (a UFix128) Mod(b UFix128) (UFix128, error) {
 	if b.IsZero() {
 		return UFix128Zero, DivisionByZeroError{}
@@ -289,14 +802,432 @@ func (a Fix128) Mod(b Fix128) (Fix128, error) {
 	return rem.ApplySign(aSign)
 }
 
+// MulPow10 returns `a` multiplied by 10^n, or an error on overflow. The decimal point is shifted
+// with an integer multiplication, so the result is always exact.
+func (a UFix128) MulPow10(n uint) (UFix128, error) {
+	if a.IsZero() {
+		return UFix128Zero, nil
+	}
+
+	p, ok := pow10Raw128(uint64(n))
+
+	if !ok {
+		return UFix128Zero, PositiveOverflowError{}
+	}
+
+	hi, lo := mul128(raw128(a), p)
+
+	if !isZero128(hi) {
+		return UFix128Zero, PositiveOverflowError{}
+	}
+
+	return UFix128(lo), nil
+}
+
+// MulPow10 returns `a` multiplied by 10^n, or an error on overflow. The result is always exact.
+func (a Fix128) MulPow10(n uint) (Fix128, error) {
+	aUnsigned, sign := a.Abs()
+
+	res, err := aUnsigned.MulPow10(n)
+
+	if err != nil {
+		return Fix128Zero, applySign(err, sign)
+	}
+
+	return res.ApplySign(sign)
+}
+
+// DivPow10 returns `a` divided by 10^n, rounded using the given rounding mode, or an error on
+// underflow.
+func (a UFix128) DivPow10(n uint, round RoundingMode) (UFix128, error) {
+	if !round.isValid() {
+		return UFix128Zero, InvalidRoundingModeError{}
+	}
+
+	if a.IsZero() {
+		return UFix128Zero, nil
+	}
+
+	p, ok := pow10Raw128(uint64(n))
+
+	if ok {
+		res, _, err := udivRound128(raw128Zero, raw128(a), p, round)
+		return res, err
+	}
+
+	// 10^n doesn't fit in the raw type, so the quotient is zero and the remainder (a) is less
+	// than half of the divisor. Only the modes that round away from zero can round up.
+	roundUp := false
+
+	switch round {
+	case RoundAwayFromZero, RoundCeil:
+		roundUp = true
+	case RoundStochastic:
+		// The probability of rounding up is a/10^n, which is split into a/10^k (for the largest
+		// power k that fits) times a 1-in-10 chance for each of the remaining powers.
+		k := uint64(len(pow10Table128) - 1)
+		ten, _ := pow10Raw128(1)
+		roundUp = ushouldRound128(raw128Zero, raw128(a), pow10Table128[k], round)
+
+		for ; roundUp && k < uint64(n); k++ {
+			roundUp = ushouldRound128(raw128Zero, raw128(UFix128Iota), ten, round)
+		}
+	}
+
+	if roundUp {
+		return UFix128Iota, nil
+	}
+
+	return UFix128Zero, UnderflowError{}
+}
+
+// DivPow10 returns `a` divided by 10^n, rounded using the given rounding mode, or an error on
+// underflow.
+func (a Fix128) DivPow10(n uint, round RoundingMode) (Fix128, error) {
+	aUnsigned, sign := a.Abs()
+
+	res, err := aUnsigned.DivPow10(n, round.forSign(sign))
+
+	if err != nil {
+		return Fix128Zero, applySign(err, sign)
+	}
+
+	return res.ApplySign(sign)
+}
+
+// Double returns `a` multiplied by two, or an error on overflow. It's implemented as a shift,
+// so it's much cheaper than Mul and the result is always exact.
+func (a UFix128) Double() (UFix128, error) {
+	if isNeg128(raw128(a)) {
+		// The top bit is set, so it would be shifted out.
+		return UFix128Zero, PositiveOverflowError{}
+	}
+
+	return UFix128(shiftLeft128(raw128(a), 1)), nil
+}
+
+// Double returns `a` multiplied by two, or an error on overflow. The result is always exact.
+func (a Fix128) Double() (Fix128, error) {
+	res := shiftLeft128(raw128(a), 1)
+
+	// The shift overflows if it changes the sign bit.
+	if isNeg128(res) != a.IsNeg() {
+		if a.IsNeg() {
+			return Fix128Zero, NegativeOverflowError{}
+		}
+		return Fix128Zero, PositiveOverflowError{}
+	}
+
+	return Fix128(res), nil
+}
+
+// Halve returns `a` divided by two, rounded using the given rounding mode, or an error on
+// underflow. Like Double, it's implemented as a shift instead of a full division.
+func (a UFix128) Halve(round RoundingMode) (UFix128, error) {
+	if !round.isValid() {
+		return UFix128Zero, InvalidRoundingModeError{}
+	}
+
+	if a.IsZero() {
+		return UFix128Zero, nil
+	}
+
+	quo := ushiftRight128(raw128(a), 1)
+	two := shiftLeft128(raw128(UFix128Iota), 1)
+	rem, _ := sub128(raw128(a), shiftLeft128(quo, 1), 0)
+
+	if ushouldRound128(quo, rem, two, round) {
+		// Can't overflow, quo is at most half the maximum value.
+		quo, _ = add128(quo, raw128Zero, 1)
+	}
+
+	if isZero128(quo) {
+		return UFix128Zero, UnderflowError{}
+	}
+
+	return UFix128(quo), nil
+}
+
+// Halve returns `a` divided by two, rounded using the given rounding mode, or an error on
+// underflow.
+func (a Fix128) Halve(round RoundingMode) (Fix128, error) {
+	aUnsigned, sign := a.Abs()
+
+	res, err := aUnsigned.Halve(round.forSign(sign))
+
+	if err != nil {
+		return Fix128Zero, err
+	}
+
+	return res.ApplySign(sign)
+}
+
+// == Checked Operators ==
+//
+// The checked variants below return a boolean instead of an error, which is cheaper to test in
+// hot loops. A false result means the operation failed for any reason (overflow, underflow,
+// division by zero, etc.), and the returned value should be ignored. Callers that need to know
+// WHY an operation failed should use the error-returning methods instead.
+
+// AddChecked returns the sum of `a` and `b`, and false on overflow.
+func (a UFix128) AddChecked(b UFix128) (UFix128, bool) {
+	sum, carry := add128(raw128(a), raw128(b), 0)
+
+	return UFix128(sum), carry == 0
+}
+
+// AddChecked returns the sum of `a` and `b`, and false on overflow or negative overflow.
+func (a Fix128) AddChecked(b Fix128) (Fix128, bool) {
+	sum, _ := add128(raw128(a), raw128(b), 0)
+
+	res := Fix128(sum)
+
+	// Overflow happened if both operands have the same sign, and the result has a different sign.
+	return res, a.IsNeg() != b.IsNeg() || a.IsNeg() == res.IsNeg()
+}
+
+// SubChecked returns the difference of `a` and `b`, and false on negative overflow.
+func (a UFix128) SubChecked(b UFix128) (UFix128, bool) {
+	diff, borrow := sub128(raw128(a), raw128(b), 0)
+
+	return UFix128(diff), borrow == 0
+}
+
+// SubChecked returns the difference of `a` and `b`, and false on overflow or negative overflow.
+func (a Fix128) SubChecked(b Fix128) (Fix128, bool) {
+	diff, _ := sub128(raw128(a), raw128(b), 0)
+
+	res := Fix128(diff)
+
+	// Overflow happened if the operands have different signs, and the result has a different
+	// sign from `a`.
+	return res, a.IsNeg() == b.IsNeg() || a.IsNeg() == res.IsNeg()
+}
+
+// MulChecked returns the product of `a` and `b`, and false on overflow or underflow.
+func (a UFix128) MulChecked(b UFix128, round RoundingMode) (UFix128, bool) {
+	res, _, code := a.fmdCode(b, UFix128One, round)
+
+	return res, code == ErrorCodeNone
+}
+
+// MulChecked returns the product of `a` and `b`, and false on overflow or underflow.
+func (a Fix128) MulChecked(b Fix128, round RoundingMode) (Fix128, bool) {
+	res, _, code := a.fmdCode(b, Fix128One, round)
+
+	return res, code == ErrorCodeNone
+}
+
+// DivChecked returns the quotient of `a` and `b`, and false on division by zero, overflow, or
+// underflow.
+func (a UFix128) DivChecked(b UFix128, round RoundingMode) (UFix128, bool) {
+	res, _, code := a.fmdCode(UFix128One, b, round)
+
+	return res, code == ErrorCodeNone
+}
+
+// DivChecked returns the quotient of `a` and `b`, and false on division by zero, overflow, or
+// underflow.
+func (a Fix128) DivChecked(b Fix128, round RoundingMode) (Fix128, bool) {
+	res, _, code := a.fmdCode(Fix128One, b, round)
+
+	return res, code == ErrorCodeNone
+}
+
+// == Wrapping Operators ==
+//
+// The wrapping variants below never fail, instead they return the raw result modulo 2^N, where N
+// is the bit width of the type (i.e. the result "wraps around" using two's-complement semantics,
+// exactly like Go's built-in integer operators). These are useful for checksums and hash-derived values, but
+// are almost certainly NOT what you want for financial calculations!
+
+// AddWrap returns the sum of `a` and `b`, wrapping around on overflow.
+func (a UFix128) AddWrap(b UFix128) UFix128 {
+	sum, _ := add128(raw128(a), raw128(b), 0)
+
+	return UFix128(sum)
+}
+
+// AddWrap returns the sum of `a` and `b`, wrapping around on overflow or negative overflow.
+func (a Fix128) AddWrap(b Fix128) Fix128 {
+	sum, _ := add128(raw128(a), raw128(b), 0)
+
+	return Fix128(sum)
+}
+
+// SubWrap returns the difference of `a` and `b`, wrapping around on negative overflow.
+func (a UFix128) SubWrap(b UFix128) UFix128 {
+	diff, _ := sub128(raw128(a), raw128(b), 0)
+
+	return UFix128(diff)
+}
+
+// SubWrap returns the difference of `a` and `b`, wrapping around on overflow or negative overflow.
+func (a Fix128) SubWrap(b Fix128) Fix128 {
+	diff, _ := sub128(raw128(a), raw128(b), 0)
+
+	return Fix128(diff)
+}
+
+// MulWrap returns the product of `a` and `b`, rounded as specified, keeping only the low bits
+// of the raw result if the product overflows. Underflow results in zero. An invalid rounding mode
+// is treated as RoundTowardZero, and panics in debug builds.
+func (a UFix128) MulWrap(b UFix128, round RoundingMode) UFix128 {
+	if !round.isValid() {
+		debugPanic("MulWrap: invalid rounding mode")
+	}
+
+	hi, lo := mul128(raw128(a), raw128(b))
+
+	// The full quotient might not fit in the result, but we only need the low bits of it. Removing
+	// all multiples of the scale factor from the high part of the product removes exactly the
+	// multiples of 2^N from the quotient, and ensures that the division below can't overflow.
+	hi = mod128(hi, raw128(UFix128One))
+
+	quo, rem := div128(hi, lo, raw128(UFix128One))
+
+	if ushouldRound128(quo, rem, raw128(UFix128One), round) {
+		quo, _ = add128(quo, raw128Zero, 1)
+	}
+
+	return UFix128(quo)
+}
+
+// MulWrap returns the product of `a` and `b`, rounded as specified, keeping only the low bits
+// of the two's-complement representation if the product overflows. Underflow results in zero.
+// An invalid rounding mode is treated as RoundTowardZero, and panics in debug builds.
+func (a Fix128) MulWrap(b Fix128, round RoundingMode) Fix128 {
+	aUnsigned, aSign := a.Abs()
+	bUnsigned, bSign := b.Abs()
+	sign := aSign * bSign
+
+	res := raw128(aUnsigned.MulWrap(bUnsigned, round.forSign(sign)))
+
+	if sign < 0 {
+		res = neg128(res)
+	}
+
+	return Fix128(res)
+}
+
+// == Must Operators ==
+//
+// The Must variants below panic instead of returning an error. They are intended for
+// initializing package-level variables and for test fixtures, where the inputs are known ahead
+// of time and error handling would just be noise. Don't use them on untrusted inputs!
+
+// MustAdd returns the sum of `a` and `b`, and panics on overflow.
+func (a UFix128) MustAdd(b UFix128) UFix128 { return must(a.Add(b)) }
+func (a Fix128) MustAdd(b Fix128) Fix128    { return must(a.Add(b)) }
+
+// MustSub returns the difference of `a` and `b`, and panics on overflow or negative overflow.
+func (a UFix128) MustSub(b UFix128) UFix128 { return must(a.Sub(b)) }
+func (a Fix128) MustSub(b Fix128) Fix128    { return must(a.Sub(b)) }
+
+// MustMul returns the product of `a` and `b`, and panics on overflow or underflow.
+func (a UFix128) MustMul(b UFix128, round RoundingMode) UFix128 { return must(a.Mul(b, round)) }
+func (a Fix128) MustMul(b Fix128, round RoundingMode) Fix128    { return must(a.Mul(b, round)) }
+
+// MustDiv returns the quotient of `a` and `b`, and panics on division by zero, overflow, or underflow.
+func (a UFix128) MustDiv(b UFix128, round RoundingMode) UFix128 { return must(a.Div(b, round)) }
+func (a Fix128) MustDiv(b Fix128, round RoundingMode) Fix128    { return must(a.Div(b, round)) }
+
+// MustFMD returns `a*b/c`, and panics on division by zero, overflow, or underflow.
+func (a UFix128) MustFMD(b, c UFix128, round RoundingMode) UFix128 { return must(a.FMD(b, c, round)) }
+func (a Fix128) MustFMD(b, c Fix128, round RoundingMode) Fix128    { return must(a.FMD(b, c, round)) }
+
+// == Context Operators ==
+//
+// The Context variants below use the rounding mode and trap settings of the Context, see
+// Context for details.
+
+// AddUFix128 returns the sum of `a` and `b` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) AddUFix128(a, b UFix128) (UFix128, error) {
+	res, err := a.Add(b)
+	res, err = contextResult(ctx, res, true, err, UFix128Max, UFix128Zero)
+	return res, wrapOpError(ctx, err, "Add", a, b)
+}
+
+// AddFix128 returns the sum of `a` and `b` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) AddFix128(a, b Fix128) (Fix128, error) {
+	res, err := a.Add(b)
+	res, err = contextResult(ctx, res, true, err, Fix128Max, Fix128Min)
+	return res, wrapOpError(ctx, err, "Add", a, b)
+}
+
+// SubUFix128 returns the difference of `a` and `b` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) SubUFix128(a, b UFix128) (UFix128, error) {
+	res, err := a.Sub(b)
+	res, err = contextResult(ctx, res, true, err, UFix128Max, UFix128Zero)
+	return res, wrapOpError(ctx, err, "Sub", a, b)
+}
+
+// SubFix128 returns the difference of `a` and `b` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) SubFix128(a, b Fix128) (Fix128, error) {
+	res, err := a.Sub(b)
+	res, err = contextResult(ctx, res, true, err, Fix128Max, Fix128Min)
+	return res, wrapOpError(ctx, err, "Sub", a, b)
+}
+
+// MulUFix128 returns the product of `a` and `b` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) MulUFix128(a, b UFix128) (UFix128, error) {
+	res, exact, err := a.MulX(b, ctx.Rounding)
+	res, err = contextResult(ctx, res, exact, err, UFix128Max, UFix128Zero)
+	return res, wrapOpError(ctx, err, "Mul", a, b)
+}
+
+// MulFix128 returns the product of `a` and `b` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) MulFix128(a, b Fix128) (Fix128, error) {
+	res, exact, err := a.MulX(b, ctx.Rounding)
+	res, err = contextResult(ctx, res, exact, err, Fix128Max, Fix128Min)
+	return res, wrapOpError(ctx, err, "Mul", a, b)
+}
+
+// DivUFix128 returns the quotient of `a` and `b` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) DivUFix128(a, b UFix128) (UFix128, error) {
+	res, exact, err := a.DivX(b, ctx.Rounding)
+	res, err = contextResult(ctx, res, exact, err, UFix128Max, UFix128Zero)
+	return res, wrapOpError(ctx, err, "Div", a, b)
+}
+
+// DivFix128 returns the quotient of `a` and `b` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) DivFix128(a, b Fix128) (Fix128, error) {
+	res, exact, err := a.DivX(b, ctx.Rounding)
+	res, err = contextResult(ctx, res, exact, err, Fix128Max, Fix128Min)
+	return res, wrapOpError(ctx, err, "Div", a, b)
+}
+
+// FMDUFix128 returns `a*b/c` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) FMDUFix128(a, b, c UFix128) (UFix128, error) {
+	res, exact, err := a.FMDX(b, c, ctx.Rounding)
+	res, err = contextResult(ctx, res, exact, err, UFix128Max, UFix128Zero)
+	return res, wrapOpError(ctx, err, "FMD", a, b, c)
+}
+
+// FMDFix128 returns `a*b/c` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) FMDFix128(a, b, c Fix128) (Fix128, error) {
+	res, exact, err := a.FMDX(b, c, ctx.Rounding)
+	res, err = contextResult(ctx, res, exact, err, Fix128Max, Fix128Min)
+	return res, wrapOpError(ctx, err, "FMD", a, b, c)
+}
+
 // Sqrt returns the square root of `a` using Newton-Rhaphson. Note that this
 // method returns an error result for consistency with other methods,
-// but can't actually ever fail...
+// but can't actually ever fail... RoundStochastic is treated as rounding
+// to nearest.
 func (a UFix128) Sqrt(round RoundingMode) (UFix128, error) {
+	if !round.isValid() {
+		return UFix128Zero, InvalidRoundingModeError{}
+	}
+
 	if a.IsZero() {
 		return UFix128Zero, nil
 	}
 
+	// The result is always positive, so we can treat the directed rounding modes as their
+	// symmetric equivalents.
+	round = round.forSign(1)
+
 	// Count the number of leading zero bits in `a`, this is a cheap way of estimating
 	// the order of magnitude of the input.
 	n := leadingZeroBits128(raw128(a))
@@ -413,7 +1344,31 @@ func (a UFix128) Sqrt(round RoundingMode) (UFix128, error) {
 	return UFix128(est), nil
 }
 
-func (a UFix128) Ln() (Fix128, error) {
+// Sqrt returns the square root of `a`, or a domain error if `a` is negative.
+func (a Fix128) Sqrt(round RoundingMode) (Fix128, error) {
+	if a.IsNeg() {
+		return Fix128Zero, OutOfDomainErrorError{}
+	}
+
+	res, err := UFix128(a).Sqrt(round)
+
+	if err != nil {
+		return Fix128Zero, err
+	}
+
+	// The square root is never larger than max(a, 1), so this can't overflow.
+	return res.ApplySign(1)
+}
+
+// Ln returns the natural logarithm of `a`, rounded to nearest (ties away from zero), or an error
+// if `a` is zero.
+func (a UFix128) Ln() (Fix128, error) { return a.LnRound(RoundNearestHalfAway) }
+
+// LnRound is the same as Ln, but rounds the result using the given rounding mode. Note that the
+// result is rounded from an approximation with much higher precision than Fix128, rather than
+// from the exact result, so in rare cases where the exact result is extremely close to a
+// representable value, the directed rounding modes might pick the wrong side.
+func (a UFix128) LnRound(round RoundingMode) (Fix128, error) {
 	// TODO: x192.ln() provides a ton of precision that we don't need, it
 	// would be ideal if we could pass an error limit to it so it could
 	// stop early when we don't need the full precision.
@@ -423,7 +1378,7 @@ func (a UFix128) Ln() (Fix128, error) {
 		return Fix128Zero, err
 	}
 
-	res, err := res192.toFix128(RoundNearestHalfAway)
+	res, err := res192.toFix128(round)
 
 	// TODO: Should this catch underflow?
 	if _, ok := err.(UnderflowError); ok {
@@ -434,9 +1389,49 @@ func (a UFix128) Ln() (Fix128, error) {
 	return res, err
 }
 
+// LogBase returns the logarithm of `a` in the given base, rounded using the given rounding mode.
+// Returns a domain error if `a` is zero, or if `base` is zero or one (where the logarithm is
+// undefined). The same caveat as LnRound applies to the directed rounding modes.
+func (a UFix128) LogBase(base UFix128, round RoundingMode) (Fix128, error) {
+	if base.IsZero() || base == UFix128One {
+		return Fix128Zero, OutOfDomainErrorError{}
+	}
+
+	lnA, err := a.toFix192().ln()
+
+	if err != nil {
+		return Fix128Zero, err
+	}
+
+	lnBase, err := base.toFix192().ln()
+
+	if err != nil {
+		return Fix128Zero, err
+	}
+
+	res192, err := lnA.sdiv(lnBase)
+
+	if err != nil {
+		return Fix128Zero, err
+	}
+
+	res, err := res192.toFix128(round)
+
+	// Same as Ln, treat underflows as zero.
+	if _, ok := err.(UnderflowError); ok {
+		return Fix128Zero, nil
+	}
+
+	return res, err
+}
+
 // Exp(a) returns `e^a`, or an error on overflow or underflow. Note that although the
 // input is a Fix128, the output is a UFix128, since `e^a` is always positive.
-func (a Fix128) Exp() (UFix128, error) {
+func (a Fix128) Exp() (UFix128, error) { return a.ExpRound(RoundNearestHalfAway) }
+
+// ExpRound is the same as Exp, but rounds the result using the given rounding mode. The same
+// caveat as LnRound applies to the directed rounding modes.
+func (a Fix128) ExpRound(round RoundingMode) (UFix128, error) {
 	// If `a` is 0, return 1.
 	if a.IsZero() {
 		return UFix128One, nil
@@ -456,10 +1451,16 @@ func (a Fix128) Exp() (UFix128, error) {
 		return UFix128Zero, err
 	}
 
-	return res192.toUFix128(RoundNearestHalfAway)
+	return res192.toUFix128(round)
 }
 
-func (a UFix128) Pow(b Fix128) (UFix128, error) {
+// Pow returns `a^b`, rounded to nearest (ties away from zero), or an error on overflow or
+// underflow, or a domain error if `a` is zero and `b` is negative.
+func (a UFix128) Pow(b Fix128) (UFix128, error) { return a.PowRound(b, RoundNearestHalfAway) }
+
+// PowRound is the same as Pow, but rounds the result using the given rounding mode. The same
+// caveat as LnRound applies to the directed rounding modes.
+func (a UFix128) PowRound(b Fix128, round RoundingMode) (UFix128, error) {
 	// We accept 0^0 as 1.
 	if b.IsZero() {
 		return UFix128One, nil
@@ -468,7 +1469,7 @@ func (a UFix128) Pow(b Fix128) (UFix128, error) {
 	if a.IsZero() {
 		if b.IsNeg() {
 			// 0^negative is undefined, so we return an error.
-			return UFix128Zero, DivisionByZeroError{} // 0^negative is undefined
+			return UFix128Zero, OutOfDomainErrorError{}
 		} else {
 			// 0^positive is 0.
 			return UFix128Zero, nil
@@ -494,15 +1495,15 @@ func (a UFix128) Pow(b Fix128) (UFix128, error) {
 		return UFix128Zero, err
 	}
 
-	return res192.toUFix128(RoundNearestHalfAway)
+	return res192.toUFix128(round)
 }
 
-func trigResult128(res192 fix192, err error) (Fix128, error) {
+func trigResult128(res192 fix192, err error, round RoundingMode) (Fix128, error) {
 	if err != nil {
 		return Fix128Zero, err
 	}
 
-	res, err := res192.toFix128(RoundNearestHalfAway)
+	res, err := res192.toFix128(round)
 
 	switch err.(type) {
 	case nil:
@@ -516,16 +1517,26 @@ func trigResult128(res192 fix192, err error) (Fix128, error) {
 	}
 }
 
-func (a Fix128) Sin() (Fix128, error) {
+// Sin returns the sine of `a` (in radians), rounded to nearest (ties away from zero).
+func (a Fix128) Sin() (Fix128, error) { return a.SinRound(RoundNearestHalfAway) }
+
+// SinRound is the same as Sin, but rounds the result using the given rounding mode. The same
+// caveat as LnRound applies to the directed rounding modes.
+func (a Fix128) SinRound(round RoundingMode) (Fix128, error) {
 	x192 := a.toFix192()
 	res192, err := x192.sin()
 
-	return trigResult128(res192, err)
+	return trigResult128(res192, err, round)
 }
 
-func (a Fix128) Cos() (Fix128, error) {
+// Cos returns the cosine of `a` (in radians), rounded to nearest (ties away from zero).
+func (a Fix128) Cos() (Fix128, error) { return a.CosRound(RoundNearestHalfAway) }
+
+// CosRound is the same as Cos, but rounds the result using the given rounding mode. The same
+// caveat as LnRound applies to the directed rounding modes.
+func (a Fix128) CosRound(round RoundingMode) (Fix128, error) {
 	x192 := a.toFix192()
 	res192, err := x192.cos()
 
-	return trigResult128(res192, err)
+	return trigResult128(res192, err, round)
 }
@@ -18,11 +18,26 @@ package fixedPoint
 
 import (
 	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"flag"
+	"fmt"
+	"hash"
+	"io"
+	"iter"
+	"math"
+	"math/big"
 	"os/exec"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 )
 
 type OneArgTestCase128 struct {
@@ -844,6 +859,25 @@ func TestModFix128(t *testing.T) {
 	t.Log(testState.operation+testState.outType, testState.successCount, "passed,", testState.failureCount, "failed")
 }
 
+// TestDiv192By128Regression checks a division where both quotient estimates in the 192-by-128-bit
+// division are too high, which used to give a quotient one too high: 2^191 / (2^127 + 2^64 - 2).
+func TestDiv192By128Regression(t *testing.T) {
+
+	t.Parallel()
+
+	y := raw128{0x8000000000000000, 0xfffffffffffffffe}
+	quo, rem := div128(raw128{0, 0x8000000000000000}, raw128Zero, y)
+	if quo != (raw128{0, 0xfffffffffffffffe}) || rem != (raw128{3, 0xfffffffffffffffc}) {
+		t.Errorf("div128(2^191, %v) = %v, %v; want 2^64 - 2, 2^66 - 4", y, quo, rem)
+	}
+
+	// The same division through the public API: FMD doesn't scale, so this is 2^127 * 2^64 / y.
+	a, b := UFix128{0x8000000000000000, 0}, UFix128{1, 0}
+	if res, err := a.FMD(b, UFix128(y), RoundTowardZero); err != nil || res != (UFix128{0, 0xfffffffffffffffe}) {
+		t.Errorf("%v.FMD(%v, %v) = %v, %v; want 2^64 - 2 iota", a, b, UFix128(y), res, err)
+	}
+}
+
 func TestRoundUpUFix128(t *testing.T) {
 
 	t.Parallel()
@@ -1198,3 +1232,4121 @@ func TestCosFix128(t *testing.T) {
 	}
 	t.Log(testState.operation+testState.outType, testState.successCount, "passed,", testState.failureCount, "failed")
 }
+
+// The 128-bit counterpart of edgeValues64.
+var edgeValues128 = []raw128{
+	{0x0000000000000000, 0x0000000000000000},
+	{0x0000000000000000, 0x0000000000000001},
+	{0x0000000000000000, 0x0000000000000002},
+	{0x000000000000d3c2, 0x1bcecceda0ffffff},
+	{0x000000000000d3c2, 0x1bcecceda1000000}, // 1.0
+	{0x000000000000d3c2, 0x1bcecceda1000001},
+	{0x000000000001a784, 0x379d99db42000000}, // 2.0
+	{0x0000000000000000, 0xffffffffffffffff},
+	{0x0000000000000001, 0x0000000000000000},
+	{0x7fffffffffffffff, 0xfffffffffffffffe},
+	{0x7fffffffffffffff, 0xffffffffffffffff},
+	{0x8000000000000000, 0x0000000000000000},
+	{0x8000000000000000, 0x0000000000000001},
+	{0xffffffffffff2c3d, 0xe43133125f000000}, // -1.0 as Fix128
+	{0xffffffffffffffff, 0xfffffffffffffffe},
+	{0xffffffffffffffff, 0xffffffffffffffff},
+}
+
+func TestCheckedUFix128(t *testing.T) {
+
+	t.Parallel()
+
+	check := func(op string, x, y raw128, res UFix128, ok bool, expected UFix128, err error) {
+		if ok != (err == nil) || (ok && res != expected) {
+			t.Errorf("%sCheckedUFix128 (0x%016x, 0x%016x)(0x%016x, 0x%016x) = (0x%016x, 0x%016x), %v; want (0x%016x, 0x%016x), %v",
+				op, x.Hi, x.Lo, y.Hi, y.Lo, res.Hi, res.Lo, ok, expected.Hi, expected.Lo, err)
+		}
+	}
+
+	for _, x := range edgeValues128 {
+		for _, y := range edgeValues128 {
+			a := UFix128(x)
+			b := UFix128(y)
+
+			res, ok := a.AddChecked(b)
+			expected, err := a.Add(b)
+			check("Add", x, y, res, ok, expected, err)
+
+			res, ok = a.SubChecked(b)
+			expected, err = a.Sub(b)
+			check("Sub", x, y, res, ok, expected, err)
+
+			res, ok = a.MulChecked(b, RoundHalfEven)
+			expected, err = a.Mul(b, RoundHalfEven)
+			check("Mul", x, y, res, ok, expected, err)
+
+			res, ok = a.DivChecked(b, RoundUp)
+			expected, err = a.Div(b, RoundUp)
+			check("Div", x, y, res, ok, expected, err)
+		}
+	}
+}
+
+func TestCheckedFix128(t *testing.T) {
+
+	t.Parallel()
+
+	check := func(op string, x, y raw128, res Fix128, ok bool, expected Fix128, err error) {
+		if ok != (err == nil) || (ok && res != expected) {
+			t.Errorf("%sCheckedFix128 (0x%016x, 0x%016x)(0x%016x, 0x%016x) = (0x%016x, 0x%016x), %v; want (0x%016x, 0x%016x), %v",
+				op, x.Hi, x.Lo, y.Hi, y.Lo, res.Hi, res.Lo, ok, expected.Hi, expected.Lo, err)
+		}
+	}
+
+	for _, x := range edgeValues128 {
+		for _, y := range edgeValues128 {
+			a := Fix128(x)
+			b := Fix128(y)
+
+			res, ok := a.AddChecked(b)
+			expected, err := a.Add(b)
+			check("Add", x, y, res, ok, expected, err)
+
+			res, ok = a.SubChecked(b)
+			expected, err = a.Sub(b)
+			check("Sub", x, y, res, ok, expected, err)
+
+			res, ok = a.MulChecked(b, RoundHalfEven)
+			expected, err = a.Mul(b, RoundHalfEven)
+			check("Mul", x, y, res, ok, expected, err)
+
+			res, ok = a.DivChecked(b, RoundUp)
+			expected, err = a.Div(b, RoundUp)
+			check("Div", x, y, res, ok, expected, err)
+		}
+	}
+}
+
+// fix128ScaleBig is Fix128Scale as a big.Int, which doesn't fit in any of Go's integer types.
+var fix128ScaleBig, _ = new(big.Int).SetString("1000000000000000000000000", 10)
+
+// bigFromRaw128 converts a raw 128-bit value to a big.Int, interpreting it as signed or unsigned.
+func bigFromRaw128(x raw128, signed bool) *big.Int {
+	res := new(big.Int).SetUint64(uint64(x.Hi))
+	res.Lsh(res, 64)
+	res.Or(res, new(big.Int).SetUint64(uint64(x.Lo)))
+
+	if signed && isNeg128(x) {
+		res.Sub(res, new(big.Int).Lsh(big.NewInt(1), 128))
+	}
+
+	return res
+}
+
+// raw128FromBig converts a non-negative big.Int smaller than 2^128 to a raw 128-bit value.
+func raw128FromBig(x *big.Int) raw128 {
+	lo := new(big.Int).And(x, new(big.Int).SetUint64(0xffffffffffffffff))
+	hi := new(big.Int).Rsh(x, 64)
+
+	return raw128{raw64(hi.Uint64()), raw64(lo.Uint64())}
+}
+
+func TestWrapFix128(t *testing.T) {
+
+	t.Parallel()
+
+	modulus := new(big.Int).Lsh(big.NewInt(1), 128)
+
+	expected := func(x, y raw128, signed bool, op func(z, a, b *big.Int) *big.Int) raw128 {
+		res := op(new(big.Int), bigFromRaw128(x, signed), bigFromRaw128(y, signed))
+		res.Mod(res, modulus)
+
+		return raw128FromBig(res)
+	}
+
+	mulTruncate := func(z, a, b *big.Int) *big.Int { return z.Quo(z.Mul(a, b), fix128ScaleBig) }
+
+	for _, x := range edgeValues128 {
+		for _, y := range edgeValues128 {
+			if res, want := raw128(UFix128(x).AddWrap(UFix128(y))), expected(x, y, false, (*big.Int).Add); res != want {
+				t.Errorf("AddWrapUFix128 (0x%016x, 0x%016x)(0x%016x, 0x%016x) = (0x%016x, 0x%016x), want (0x%016x, 0x%016x)",
+					x.Hi, x.Lo, y.Hi, y.Lo, res.Hi, res.Lo, want.Hi, want.Lo)
+			}
+			if res, want := raw128(Fix128(x).SubWrap(Fix128(y))), expected(x, y, true, (*big.Int).Sub); res != want {
+				t.Errorf("SubWrapFix128 (0x%016x, 0x%016x)(0x%016x, 0x%016x) = (0x%016x, 0x%016x), want (0x%016x, 0x%016x)",
+					x.Hi, x.Lo, y.Hi, y.Lo, res.Hi, res.Lo, want.Hi, want.Lo)
+			}
+			if res, want := raw128(UFix128(x).MulWrap(UFix128(y), RoundTowardZero)), expected(x, y, false, mulTruncate); res != want {
+				t.Errorf("MulWrapUFix128 (0x%016x, 0x%016x)(0x%016x, 0x%016x) = (0x%016x, 0x%016x), want (0x%016x, 0x%016x)",
+					x.Hi, x.Lo, y.Hi, y.Lo, res.Hi, res.Lo, want.Hi, want.Lo)
+			}
+			if res, want := raw128(Fix128(x).MulWrap(Fix128(y), RoundTowardZero)), expected(x, y, true, mulTruncate); res != want {
+				t.Errorf("MulWrapFix128 (0x%016x, 0x%016x)(0x%016x, 0x%016x) = (0x%016x, 0x%016x), want (0x%016x, 0x%016x)",
+					x.Hi, x.Lo, y.Hi, y.Lo, res.Hi, res.Lo, want.Hi, want.Lo)
+			}
+		}
+	}
+}
+
+func TestFMAFix128(t *testing.T) {
+
+	t.Parallel()
+
+	for _, round := range allRoundingModes {
+		for _, x := range edgeValues128 {
+			for _, y := range edgeValues128 {
+				for _, z := range edgeValues128 {
+					for _, signed := range []bool{false, true} {
+						num := new(big.Int).Mul(bigFromRaw128(x, signed), bigFromRaw128(y, signed))
+						num.Add(num, new(big.Int).Mul(bigFromRaw128(z, signed), fix128ScaleBig))
+
+						want, wantErr := refRange(refQuo(num, fix128ScaleBig, round), 128, signed, num.Sign() != 0)
+
+						var res raw128
+						var err error
+
+						if signed {
+							var r Fix128
+							r, err = Fix128(x).FMA(Fix128(y), Fix128(z), round)
+							res = raw128(r)
+						} else {
+							var r UFix128
+							r, err = UFix128(x).FMA(UFix128(y), UFix128(z), round)
+							res = raw128(r)
+						}
+
+						if res != raw128FromBig(want) || err != wantErr {
+							t.Errorf("FMA (signed: %v, round: %v) (0x%016x, 0x%016x)(0x%016x, 0x%016x)(0x%016x, 0x%016x) = (0x%016x, 0x%016x), %v; want %v, %v",
+								signed, round, x.Hi, x.Lo, y.Hi, y.Lo, z.Hi, z.Lo, res.Hi, res.Lo, err, want, wantErr)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDot2Fix128(t *testing.T) {
+
+	t.Parallel()
+
+	for _, w := range edgeValues128 {
+		for _, x := range edgeValues128 {
+			for _, y := range edgeValues128 {
+				for _, z := range edgeValues128 {
+					for _, signed := range []bool{false, true} {
+						num := new(big.Int).Mul(bigFromRaw128(w, signed), bigFromRaw128(x, signed))
+						num.Add(num, new(big.Int).Mul(bigFromRaw128(y, signed), bigFromRaw128(z, signed)))
+
+						want, wantErr := refRange(refQuo(num, fix128ScaleBig, RoundHalfEven), 128, signed, num.Sign() != 0)
+
+						var res raw128
+						var err error
+
+						if signed {
+							var r Fix128
+							r, err = Fix128(w).Dot2(Fix128(x), Fix128(y), Fix128(z), RoundHalfEven)
+							res = raw128(r)
+						} else {
+							var r UFix128
+							r, err = UFix128(w).Dot2(UFix128(x), UFix128(y), UFix128(z), RoundHalfEven)
+							res = raw128(r)
+						}
+
+						if res != raw128FromBig(want) || err != wantErr {
+							t.Fatalf("Dot2 (signed: %v) (%v, %v, %v, %v) = (0x%016x, 0x%016x), %v; want %v, %v",
+								signed, w, x, y, z, res.Hi, res.Lo, err, want, wantErr)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestExactFix128(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		for _, y := range edgeValues128 {
+			for _, signed := range []bool{false, true} {
+				for _, op := range []string{"MulExact", "DivExact"} {
+					num := new(big.Int).Mul(bigFromRaw128(x, signed), bigFromRaw128(y, signed))
+					den := fix128ScaleBig
+
+					if op == "DivExact" {
+						num = new(big.Int).Mul(bigFromRaw128(x, signed), fix128ScaleBig)
+						den = bigFromRaw128(y, signed)
+					}
+
+					var res raw128
+					var err error
+
+					switch {
+					case signed && op == "MulExact":
+						r, e := Fix128(x).MulExact(Fix128(y))
+						res, err = raw128(r), e
+					case signed && op == "DivExact":
+						r, e := Fix128(x).DivExact(Fix128(y))
+						res, err = raw128(r), e
+					case op == "MulExact":
+						r, e := UFix128(x).MulExact(UFix128(y))
+						res, err = raw128(r), e
+					default:
+						r, e := UFix128(x).DivExact(UFix128(y))
+						res, err = raw128(r), e
+					}
+
+					var want *big.Int
+					var wantErr error
+
+					if den.Sign() == 0 {
+						want, wantErr = big.NewInt(0), DivisionByZeroError{}
+					} else {
+						want, wantErr = refRange(refQuo(num, den, RoundTowardZero), 128, signed, num.Sign() != 0)
+
+						if wantErr == nil && new(big.Int).Rem(num, den).Sign() != 0 {
+							want, wantErr = big.NewInt(0), InexactError{}
+						}
+					}
+
+					if res != raw128FromBig(want) || err != wantErr {
+						t.Errorf("%s (signed: %v) (%v, %v) = (0x%016x, 0x%016x), %v; want %v, %v",
+							op, signed, x, y, res.Hi, res.Lo, err, want, wantErr)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestRoundingConversions(t *testing.T) {
+
+	t.Parallel()
+
+	// 1.5e-8 and -1.5e-8 in Fix128
+	pos := Fix128{0, 15000000000000000}
+	neg, _ := pos.Neg()
+
+	tests := []struct {
+		a        Fix128
+		round    RoundingMode
+		expected Fix64
+	}{
+		{pos, RoundFloor, 1},
+		{pos, RoundCeil, 2},
+		{neg, RoundFloor, Fix64(neg64(2))},
+		{neg, RoundCeil, Fix64(neg64(1))},
+		{pos, RoundHalfDown, 1},
+		{pos, RoundHalfOdd, 1},
+		{neg, RoundHalfDown, Fix64(neg64(1))},
+		{neg, RoundHalfOdd, Fix64(neg64(1))},
+		{Fix128{0, 25000000000000000}, RoundHalfDown, 2},
+		{Fix128{0, 25000000000000000}, RoundHalfOdd, 3},
+		{Fix128{0, 25000000000000001}, RoundHalfDown, 3},
+	}
+
+	for _, tc := range tests {
+		res, err := tc.a.ToFix64(tc.round)
+
+		if res != tc.expected || err != nil {
+			t.Errorf("ToFix64 (0x%016x, 0x%016x) with %v = 0x%016x, %v; want 0x%016x",
+				tc.a.Hi, tc.a.Lo, tc.round, uint64(res), err, uint64(tc.expected))
+		}
+	}
+
+	for _, x := range edgeValues128 {
+		floor, _ := UFix128(x).Sqrt(RoundFloor)
+		down, _ := UFix128(x).Sqrt(RoundTowardZero)
+		ceil, _ := UFix128(x).Sqrt(RoundCeil)
+		up, _ := UFix128(x).Sqrt(RoundAwayFromZero)
+
+		if floor != down || ceil != up {
+			t.Errorf("Sqrt (0x%016x, 0x%016x) with directed rounding = %v, %v; want %v, %v", x.Hi, x.Lo, floor, ceil, down, up)
+		}
+	}
+}
+
+func TestContext128(t *testing.T) {
+
+	t.Parallel()
+
+	ctx := NewContext(RoundHalfEven)
+	for _, x := range edgeValues128 {
+		for _, y := range edgeValues128 {
+			res, err := ctx.FMDFix128(Fix128(x), Fix128(y), Fix128(y))
+			want, wantErr := Fix128(x).FMD(Fix128(y), Fix128(y), RoundHalfEven)
+			if res != want || err != wantErr {
+				t.Errorf("FMDFix128(%v, %v, %v) = %v, %v; want %v, %v", x, y, y, res, err, want, wantErr)
+			}
+		}
+	}
+
+	ctx = &Context{Rounding: RoundTowardZero}
+	if res, err := ctx.MulFix128(Fix128Max, Fix128Min); res != Fix128Min || err != nil || ctx.Flags != Overflow|Inexact {
+		t.Errorf("MulFix128(max, min) = %v, %v, flags %b; want min, nil, %b", res, err, ctx.Flags, Overflow|Inexact)
+	}
+
+	ctx = &Context{Rounding: RoundTowardZero, Traps: DefaultTraps | Inexact}
+	if _, err := ctx.SubUFix128(UFix128Zero, UFix128{0, 1}); err != (NegativeOverflowError{}) || ctx.Flags != Overflow|Inexact {
+		t.Errorf("SubUFix128(0, 1e-24) = %v, flags %b; want NegativeOverflowError, %b", err, ctx.Flags, Overflow|Inexact)
+	}
+}
+
+func TestCalcFix128(t *testing.T) {
+
+	t.Parallel()
+
+	three, _ := UFix128One.Add(UFix128One)
+	three, _ = three.Add(UFix128One)
+
+	// (1/3)*3 rounds to exactly one, since the intermediate value keeps extra precision
+	if res, err := NewCalc(UFix128One).Div(three).Mul(three).Result(RoundHalfEven); res != UFix128One || err != nil {
+		t.Errorf("1/3*3 = %v, %v; want 1, nil", res, err)
+	}
+
+	// The intermediate value can exceed the range of the result type
+	if res, err := NewCalc(Fix128Max).Add(Fix128Max).Sub(Fix128Max).Result(RoundTowardZero); res != Fix128Max || err != nil {
+		t.Errorf("max+max-max = %v, %v; want max, nil", res, err)
+	}
+
+	// But the result can't
+	negOne, _ := Fix128One.Neg()
+	if _, err := NewCalc(Fix128Min).Mul(negOne).Result(RoundTowardZero); err != (PositiveOverflowError{}) {
+		t.Errorf("-min = %v; want PositiveOverflowError", err)
+	}
+
+	// Negative results can't be represented by unsigned types
+	if _, err := NewCalc(UFix128Zero).Sub(UFix128One).Result(RoundTowardZero); err != (NegativeOverflowError{}) {
+		t.Errorf("0-1 = %v; want NegativeOverflowError", err)
+	}
+
+	// The first error is sticky
+	if _, err := NewCalc(UFix128One).Div(UFix128Zero).Add(UFix128One).Mul(UFix128Max).Result(RoundTowardZero); err != (DivisionByZeroError{}) {
+		t.Errorf("1/0+1 = %v; want DivisionByZeroError", err)
+	}
+	if _, err := NewCalc(UFix128Max).Mul(UFix128Max).Div(UFix128Max).Result(RoundTowardZero); err != (PositiveOverflowError{}) {
+		t.Errorf("max*max/max = %v; want PositiveOverflowError", err)
+	}
+
+	// Directed rounding is applied to the signed result
+	third, _ := NewCalc(negOne).Div(Fix128(three)).Result(RoundFloor)
+	want, _ := negOne.Div(Fix128(three), RoundFloor)
+	if third != want {
+		t.Errorf("-1/3 (floor) = %v; want %v", third, want)
+	}
+}
+
+func TestSaturatingConversions(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		for _, round := range allRoundingModes {
+			want, err := UFix128(x).ToUFix64(round)
+			switch err.(type) {
+			case PositiveOverflowError:
+				want = UFix64Max
+			case UnderflowError:
+				want = UFix64Zero
+			}
+			if res := UFix128(x).ToUFix64Sat(round); res != want {
+				t.Errorf("ToUFix64Sat(%v, %v) = 0x%016x; want 0x%016x", x, round, res, want)
+			}
+
+			swant, err := Fix128(x).ToFix64(round)
+			switch err.(type) {
+			case PositiveOverflowError:
+				swant = Fix64Max
+			case NegativeOverflowError:
+				swant = Fix64Min
+			case UnderflowError:
+				swant = Fix64Zero
+			}
+			if res := Fix128(x).ToFix64Sat(round); res != swant {
+				t.Errorf("ToFix64Sat(%v, %v) = 0x%016x; want 0x%016x", x, round, res, swant)
+			}
+		}
+	}
+
+	if res := UFix128Max.ToUFix64Sat(RoundTowardZero); res != UFix64Max {
+		t.Errorf("ToUFix64Sat(max) = 0x%016x; want max", res)
+	}
+	if res := Fix128Min.ToFix64Sat(RoundTowardZero); res != Fix64Min {
+		t.Errorf("ToFix64Sat(min) = 0x%016x; want min", res)
+	}
+	if res := (Fix128{0, 1}).ToFix64Sat(RoundTowardZero); res != Fix64Zero {
+		t.Errorf("ToFix64Sat(1e-24) = 0x%016x; want 0", res)
+	}
+}
+
+func TestCrossWidthFix128(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		for _, y := range edgeValues64 {
+			for _, round := range allRoundingModes {
+				res, err := Fix128(x).MulFix64(Fix64(y), round)
+				want, wantErr := Fix128(x).Mul(Fix64(y).ToFix128(), round)
+				if res != want || err != wantErr {
+					t.Errorf("MulFix64(%v, 0x%016x, %v) = %v, %v; want %v, %v", x, y, round, res, err, want, wantErr)
+				}
+
+				ures, err := UFix128(x).DivUFix64(UFix64(y), round)
+				uwant, wantErr := UFix128(x).Div(UFix64(y).ToUFix128(), round)
+				if ures != uwant || err != wantErr {
+					t.Errorf("DivUFix64(%v, 0x%016x, %v) = %v, %v; want %v, %v", x, y, round, ures, err, uwant, wantErr)
+				}
+			}
+
+			res, err := Fix128(x).SubFix64(Fix64(y))
+			want, wantErr := Fix128(x).Sub(Fix64(y).ToFix128())
+			if res != want || err != wantErr {
+				t.Errorf("SubFix64(%v, 0x%016x) = %v, %v; want %v, %v", x, y, res, err, want, wantErr)
+			}
+
+			ures, err := UFix128(x).AddUFix64(UFix64(y))
+			uwant, wantErr := UFix128(x).Add(UFix64(y).ToUFix128())
+			if ures != uwant || err != wantErr {
+				t.Errorf("AddUFix64(%v, 0x%016x) = %v, %v; want %v, %v", x, y, ures, err, uwant, wantErr)
+			}
+		}
+	}
+}
+
+func TestSignConversions128(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		want, wantErr := refRange(bigFromRaw128(x, false), 128, true, false)
+		if res, err := UFix128(x).ToFix128(); raw128(res) != raw128FromBig(want) || err != wantErr {
+			t.Errorf("ToFix128(%v) = %v, %v; want %v, %v", x, res, err, want, wantErr)
+		}
+
+		want, wantErr = refRange(bigFromRaw128(x, true), 128, false, false)
+		if res, err := Fix128(x).ToUFix128(); raw128(res) != raw128FromBig(want) || err != wantErr {
+			t.Errorf("ToUFix128(%v) = %v, %v; want %v, %v", x, res, err, want, wantErr)
+		}
+	}
+}
+
+func TestCrossTypeCmp(t *testing.T) {
+
+	t.Parallel()
+
+	// Compare everything in a common scale (1e-24), since the 64-bit values need to be scaled up
+	scale64 := big.NewInt(Fix128Scale / Fix64Scale)
+
+	for _, x := range edgeValues128 {
+		for _, y := range edgeValues128 {
+			want := bigFromRaw128(x, false).Cmp(bigFromRaw128(y, true))
+			if res := UFix128(x).CmpFix128(Fix128(y)); res != want {
+				t.Errorf("UFix128.CmpFix128(%v, %v) = %d; want %d", x, y, res, want)
+			}
+			if res := Fix128(y).CmpUFix128(UFix128(x)); res != -want {
+				t.Errorf("Fix128.CmpUFix128(%v, %v) = %d; want %d", y, x, res, -want)
+			}
+		}
+
+		for _, y := range edgeValues64 {
+			y128 := new(big.Int).Mul(bigFromRaw64(y, true), scale64)
+			want := bigFromRaw128(x, true).Cmp(y128)
+			if res := Fix128(x).CmpFix64(Fix64(y)); res != want {
+				t.Errorf("Fix128.CmpFix64(%v, 0x%016x) = %d; want %d", x, y, res, want)
+			}
+			if res := Fix64(y).CmpFix128(Fix128(x)); res != -want {
+				t.Errorf("Fix64.CmpFix128(0x%016x, %v) = %d; want %d", y, x, res, -want)
+			}
+
+			y128 = new(big.Int).Mul(bigFromRaw64(y, false), scale64)
+			want = bigFromRaw128(x, false).Cmp(y128)
+			if res := UFix128(x).CmpUFix64(UFix64(y)); res != want {
+				t.Errorf("UFix128.CmpUFix64(%v, 0x%016x) = %d; want %d", x, y, res, want)
+			}
+			if res := UFix64(y).CmpUFix128(UFix128(x)); res != -want {
+				t.Errorf("UFix64.CmpUFix128(0x%016x, %v) = %d; want %d", y, x, res, -want)
+			}
+		}
+	}
+
+	for _, x := range edgeValues64 {
+		for _, y := range edgeValues64 {
+			want := bigFromRaw64(x, false).Cmp(bigFromRaw64(y, true))
+			if res := UFix64(x).CmpFix64(Fix64(y)); res != want {
+				t.Errorf("UFix64.CmpFix64(0x%016x, 0x%016x) = %d; want %d", x, y, res, want)
+			}
+			if res := Fix64(y).CmpUFix64(UFix64(x)); res != -want {
+				t.Errorf("Fix64.CmpUFix64(0x%016x, 0x%016x) = %d; want %d", y, x, res, -want)
+			}
+		}
+	}
+}
+
+func TestCmpAbsFix128(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		for _, y := range edgeValues128 {
+			want := bigFromRaw128(x, true).CmpAbs(bigFromRaw128(y, true))
+			if res := Fix128(x).CmpAbs(Fix128(y)); res != want {
+				t.Errorf("CmpAbs(%v, %v) = %d; want %d", x, y, res, want)
+			}
+		}
+	}
+}
+
+func TestApproxEqFix128(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		for _, y := range edgeValues128 {
+			for _, tol := range edgeValues128 {
+				for _, signed := range []bool{false, true} {
+					diff := new(big.Int).Sub(bigFromRaw128(x, signed), bigFromRaw128(y, signed))
+					want := diff.Abs(diff).Cmp(bigFromRaw128(tol, false)) <= 0
+
+					var res bool
+					if signed {
+						res = Fix128(x).ApproxEq(Fix128(y), UFix128(tol))
+					} else {
+						res = UFix128(x).ApproxEq(UFix128(y), UFix128(tol))
+					}
+
+					if res != want {
+						t.Errorf("ApproxEq (signed: %v) (%v, %v, %v) = %v; want %v", signed, x, y, tol, res, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestWithinUlps(t *testing.T) {
+
+	t.Parallel()
+
+	tests := []struct {
+		res  bool
+		want bool
+	}{
+		{UFix64(5).WithinUlps(7, 2), true},
+		{UFix64(5).WithinUlps(8, 2), false},
+		{Fix64(neg64(1)).WithinUlps(1, 2), true},
+		{Fix64Min.WithinUlps(Fix64Max, ^uint64(0)), true},
+		{Fix64Min.WithinUlps(Fix64Max, ^uint64(0)-1), false},
+		{UFix128{0, 5}.WithinUlps(UFix128{1, 5}, ^uint64(0)), false},
+		{UFix128{1, 0}.WithinUlps(UFix128{0, 1}, ^uint64(0)), true},
+		{Fix128Min.WithinUlps(Fix128Max, ^uint64(0)), false},
+		{Fix128{^raw64Zero, ^raw64Zero}.WithinUlps(Fix128{0, 1}, 2), true},
+		{Fix128{^raw64Zero, ^raw64Zero}.WithinUlps(Fix128{0, 1}, 1), false},
+	}
+
+	for i, tc := range tests {
+		if tc.res != tc.want {
+			t.Errorf("test %d: WithinUlps = %v; want %v", i, tc.res, tc.want)
+		}
+	}
+}
+
+func TestNextUpDownFix128(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		up, upErr := refRange(new(big.Int).Add(bigFromRaw128(x, true), big.NewInt(1)), 128, true, false)
+		if res, err := Fix128(x).NextUp(); raw128(res) != raw128FromBig(up) || err != upErr {
+			t.Errorf("NextUp(%v) = %v, %v; want %v, %v", x, res, err, up, upErr)
+		}
+
+		down, downErr := refRange(new(big.Int).Sub(bigFromRaw128(x, false), big.NewInt(1)), 128, false, false)
+		if res, err := UFix128(x).NextDown(); raw128(res) != raw128FromBig(down) || err != downErr {
+			t.Errorf("NextDown(%v) = %v, %v; want %v, %v", x, res, err, down, downErr)
+		}
+	}
+}
+
+func TestIota(t *testing.T) {
+
+	t.Parallel()
+
+	if res, _ := UFix64Zero.NextUp(); UFix64Zero.Iota() != res {
+		t.Errorf("UFix64.Iota() = %v; want %v", UFix64Zero.Iota(), res)
+	}
+	if res, _ := Fix64Zero.NextUp(); Fix64Zero.Iota() != res {
+		t.Errorf("Fix64.Iota() = %v; want %v", Fix64Zero.Iota(), res)
+	}
+	if res, _ := UFix128Zero.NextUp(); UFix128Zero.Iota() != res {
+		t.Errorf("UFix128.Iota() = %v; want %v", UFix128Zero.Iota(), res)
+	}
+	if res, _ := Fix128Zero.NextUp(); Fix128Zero.Iota() != res {
+		t.Errorf("Fix128.Iota() = %v; want %v", Fix128Zero.Iota(), res)
+	}
+
+	// 1e-8 is 1e16 times the 128-bit iota
+	if res := UFix64Iota.ToUFix128(); res != (UFix128{0, 1e16}) {
+		t.Errorf("UFix64Iota.ToUFix128() = %v; want 1e16 * UFix128Iota", res)
+	}
+}
+
+func TestIsIntegerFix128(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		for _, signed := range []bool{false, true} {
+			want := new(big.Int).Rem(bigFromRaw128(x, signed), fix128ScaleBig).Sign() == 0
+
+			var isInt, hasFrac bool
+			if signed {
+				isInt, hasFrac = Fix128(x).IsInteger(), Fix128(x).HasFraction()
+			} else {
+				isInt, hasFrac = UFix128(x).IsInteger(), UFix128(x).HasFraction()
+			}
+
+			if isInt != want || hasFrac == want {
+				t.Errorf("IsInteger (signed: %v) (%v) = %v, HasFraction = %v; want %v", signed, x, isInt, hasFrac, want)
+			}
+		}
+	}
+}
+
+// Abs has the same semantics for both widths: it returns the magnitude as an unsigned value along
+// with the sign, and can't fail (even for Fix128Min, whose magnitude isn't representable as a Fix128).
+func TestAbsFix128(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		mag, sign := Fix128(x).Abs()
+
+		want := bigFromRaw128(x, true)
+		wantSign := int64(1)
+		if want.Sign() < 0 {
+			wantSign = -1
+		}
+
+		if raw128(mag) != raw128FromBig(new(big.Int).Abs(want)) || sign != wantSign {
+			t.Errorf("Abs(%v) = %v, %d; want %v, %d", x, mag, sign, new(big.Int).Abs(want), wantSign)
+		}
+
+		// ApplySign is the inverse of Abs
+		if res, err := mag.ApplySign(sign); res != Fix128(x) || err != nil {
+			t.Errorf("ApplySign(Abs(%v)) = %v, %v; want %v", x, res, err, x)
+		}
+	}
+}
+
+func TestInvalidRoundingMode(t *testing.T) {
+
+	t.Parallel()
+
+	invalid := RoundingMode(-1)
+	two, _ := UFix128One.Add(UFix128One)
+
+	errs := []error{}
+	_, err := UFix64One.Mul(UFix64One, invalid)
+	errs = append(errs, err)
+	_, err = Fix128One.Div(Fix128One, invalid)
+	errs = append(errs, err)
+	_, err = two.Sqrt(invalid)
+	errs = append(errs, err)
+	_, err = two.ToUFix64(invalid)
+	errs = append(errs, err)
+	_, err = two.LnRound(invalid)
+	errs = append(errs, err)
+	_, err = NewCalc(two).Div(two).Result(invalid)
+	errs = append(errs, err)
+	_, err = NewCalc(Fix64One).Result(RoundStochastic + 1)
+	errs = append(errs, err)
+
+	// The rounding mode is checked before the shortcuts for zero operands, so the error doesn't
+	// depend on the values.
+	_, err = UFix64Zero.FMA(UFix64One, UFix64One, invalid)
+	errs = append(errs, err)
+	_, err = Fix64One.FMA(Fix64Zero, Fix64One, invalid)
+	errs = append(errs, err)
+	_, err = UFix128Zero.FMD(UFix128One, UFix128One, invalid)
+	errs = append(errs, err)
+	_, err = Fix128One.Mul(Fix128Zero, invalid)
+	errs = append(errs, err)
+	_, err = UFix128Zero.FMA(UFix128One, UFix128One, invalid)
+	errs = append(errs, err)
+	_, err = Fix128Zero.FMA(Fix128One, Fix128One, invalid)
+	errs = append(errs, err)
+	_, err = UFix256Zero.FMD(UFix256One, UFix256One, invalid)
+	errs = append(errs, err)
+	_, err = Fix256Zero.Div(Fix256One, invalid)
+	errs = append(errs, err)
+	_, err = Fix256Zero.FMA(Fix256One, Fix256One, invalid)
+	errs = append(errs, err)
+	_, err = SumOfProductsUFix64(nil, invalid)
+	errs = append(errs, err)
+	_, err = UFix64Zero.Dot2(UFix64Zero, UFix64Zero, UFix64Zero, invalid)
+	errs = append(errs, err)
+	_, err = SumOfProductsFix64([][2]Fix64{{Fix64One, Fix64Zero}}, invalid)
+	errs = append(errs, err)
+	_, err = SumOfProductsUFix128(nil, invalid)
+	errs = append(errs, err)
+	_, err = Fix128Zero.Dot2(Fix128Zero, Fix128Zero, Fix128Zero, invalid)
+	errs = append(errs, err)
+
+	for i, err := range errs {
+		if err != (InvalidRoundingModeError{}) {
+			t.Errorf("case %d: err = %v; want InvalidRoundingModeError", i, err)
+		}
+	}
+
+	// Operations that can't return an error fall back to truncation, and panic in debug builds
+	const ulp128 = "0.000000000000000000000001"
+	wraps := []func() bool{
+		func() bool { return UFix64(1).MulWrap(MustParseUFix64("0.5"), invalid).IsZero() },
+		func() bool { return Fix64(1).MulWrap(MustParseFix64("0.5"), invalid).IsZero() },
+		func() bool { return MustParseUFix128(ulp128).MulWrap(MustParseUFix128("0.5"), invalid).IsZero() },
+		func() bool { return MustParseFix128(ulp128).MulWrap(MustParseFix128("0.5"), invalid).IsZero() },
+		func() bool { return MustParseUFix256(ulp128).MulWrap(MustParseUFix256("0.5"), invalid).IsZero() },
+		func() bool { return MustParseFix256(ulp128).MulWrap(MustParseFix256("0.5"), invalid).IsZero() },
+		func() bool { return MustParseUFix128("1.000000009").ToUFix64Sat(invalid) == UFix64One },
+		func() bool { return MustParseFix128("-1.000000009").ToFix64Sat(invalid) == MustParseFix64("-1") },
+	}
+	for i, wrap := range wraps {
+		func() {
+			defer func() {
+				if r := recover(); (r != nil) != debugBuild {
+					t.Errorf("case %d: invalid rounding mode panicked: %v; want %v", i, r, debugBuild)
+				}
+			}()
+
+			if !wrap() {
+				t.Errorf("case %d: result with an invalid rounding mode wasn't truncated", i)
+			}
+		}()
+	}
+}
+
+func TestPow10Fix128(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		for _, n := range []uint{0, 1, 24, 37, 38, 39, 40, 80} {
+			p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+
+			for _, signed := range []bool{false, true} {
+				a := bigFromRaw128(x, signed)
+
+				want, wantErr := refRange(new(big.Int).Mul(a, p), 128, signed, false)
+				var res raw128
+				var err error
+				if signed {
+					r, e := Fix128(x).MulPow10(n)
+					res, err = raw128(r), e
+				} else {
+					r, e := UFix128(x).MulPow10(n)
+					res, err = raw128(r), e
+				}
+				if res != raw128FromBig(want) || err != wantErr {
+					t.Errorf("MulPow10(%v, %d) signed=%v = %v, %v; want %v, %v", x, n, signed, res, err, want, wantErr)
+				}
+
+				for _, round := range allRoundingModes {
+					want, wantErr := refRange(refQuo(a, p, round), 128, signed, a.Sign() != 0)
+					if signed {
+						r, e := Fix128(x).DivPow10(n, round)
+						res, err = raw128(r), e
+					} else {
+						r, e := UFix128(x).DivPow10(n, round)
+						res, err = raw128(r), e
+					}
+					if res != raw128FromBig(want) || err != wantErr {
+						t.Errorf("DivPow10(%v, %d, %v) signed=%v = %v, %v; want %v, %v", x, n, round, signed, res, err, want, wantErr)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDoubleHalveFix128(t *testing.T) {
+
+	t.Parallel()
+
+	two := big.NewInt(2)
+
+	for _, x := range edgeValues128 {
+		for _, signed := range []bool{false, true} {
+			a := bigFromRaw128(x, signed)
+
+			want, wantErr := refRange(new(big.Int).Mul(a, two), 128, signed, false)
+			var res raw128
+			var err error
+			if signed {
+				r, e := Fix128(x).Double()
+				res, err = raw128(r), e
+			} else {
+				r, e := UFix128(x).Double()
+				res, err = raw128(r), e
+			}
+			if res != raw128FromBig(want) || err != wantErr {
+				t.Errorf("Double(%v) signed=%v = %v, %v; want %v, %v", x, signed, res, err, want, wantErr)
+			}
+
+			for _, round := range allRoundingModes {
+				want, wantErr := refRange(refQuo(a, two, round), 128, signed, a.Sign() != 0)
+				if signed {
+					r, e := Fix128(x).Halve(round)
+					res, err = raw128(r), e
+				} else {
+					r, e := UFix128(x).Halve(round)
+					res, err = raw128(r), e
+				}
+				if res != raw128FromBig(want) || err != wantErr {
+					t.Errorf("Halve(%v, %v) signed=%v = %v, %v; want %v, %v", x, round, signed, res, err, want, wantErr)
+				}
+			}
+		}
+	}
+}
+
+// checkMetadata checks the metadata methods of a fixed-point type against each other, using only
+// the FixedPoint interface.
+func checkMetadata[T FixedPoint[T]](t *testing.T, name string, scale *big.Int, decimals int) {
+	var zero T
+
+	if zero.Scale().Cmp(scale) != 0 || zero.Decimals() != decimals {
+		t.Errorf("%s: Scale() = %v, Decimals() = %d; want %v, %d", name, zero.Scale(), zero.Decimals(), scale, decimals)
+	}
+
+	if _, err := zero.MaxValue().Add(zero.Iota()); err == nil {
+		t.Errorf("%s: MaxValue() + Iota() didn't overflow", name)
+	}
+	if _, err := zero.MinValue().Sub(zero.Iota()); err == nil {
+		t.Errorf("%s: MinValue() - Iota() didn't overflow", name)
+	}
+
+	if res, err := zero.One().Mul(zero.One(), RoundTowardZero); err != nil || !res.Eq(zero.One()) {
+		t.Errorf("%s: One() * One() = %v, %v; want One()", name, res, err)
+	}
+	if !zero.One().IsInteger() || zero.One().IsZero() {
+		t.Errorf("%s: One() isn't a non-zero integer", name)
+	}
+}
+
+func TestTypeMetadata(t *testing.T) {
+
+	t.Parallel()
+
+	checkMetadata[UFix64](t, "UFix64", big.NewInt(1e8), 8)
+	checkMetadata[Fix64](t, "Fix64", big.NewInt(1e8), 8)
+	checkMetadata[UFix128](t, "UFix128", fix128ScaleBig, 24)
+	checkMetadata[Fix128](t, "Fix128", fix128ScaleBig, 24)
+
+	// The result of Scale() is a fresh value that can be modified by the caller
+	UFix128Zero.Scale().SetInt64(0)
+	if UFix128Zero.Scale().Cmp(fix128ScaleBig) != 0 {
+		t.Errorf("UFix128.Scale() was modified through a previous result")
+	}
+}
+
+func TestStringFix128(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		if want, res := refString(bigFromRaw128(x, false), 24), UFix128(x).String(); res != want {
+			t.Errorf("UFix128(%#v).String() = %q; want %q", x, res, want)
+		}
+		if want, res := refString(bigFromRaw128(x, true), 24), Fix128(x).String(); res != want {
+			t.Errorf("Fix128(%#v).String() = %q; want %q", x, res, want)
+		}
+	}
+
+	if res, want := Fix128Min.String(), "-170141183460469.231731687303715884105728"; res != want {
+		t.Errorf("Fix128Min.String() = %q; want %q", res, want)
+	}
+	if res, want := UFix128Iota.String(), "0.000000000000000000000001"; res != want {
+		t.Errorf("UFix128Iota.String() = %q; want %q", res, want)
+	}
+}
+
+func TestParseFix128(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		for _, extra := range parseExtraDigits {
+			for _, round := range allRoundingModes {
+				s, want, wantErr := refParse(bigFromRaw128(x, false), 24, extra, round, 128, false)
+				if res, err := ParseUFix128(s, round); raw128(res) != raw128FromBig(want) || err != wantErr {
+					t.Errorf("ParseUFix128(%q, %v) = %v, %v; want %v, %v", s, round, res, err, want, wantErr)
+				}
+
+				s, want, wantErr = refParse(bigFromRaw128(x, true), 24, extra, round, 128, true)
+				if res, err := ParseFix128(s, round); raw128(res) != raw128FromBig(want) || err != wantErr {
+					t.Errorf("ParseFix128(%q, %v) = %v, %v; want %v, %v", s, round, res, err, want, wantErr)
+				}
+			}
+		}
+
+		if res, err := ParseUFix128(UFix128(x).String(), RoundTowardZero); res != UFix128(x) || err != nil {
+			t.Errorf("ParseUFix128(%q) = %v, %v; want %v", UFix128(x).String(), res, err, x)
+		}
+		if res, err := ParseFix128(Fix128(x).String(), RoundTowardZero); res != Fix128(x) || err != nil {
+			t.Errorf("ParseFix128(%q) = %v, %v; want %v", Fix128(x).String(), res, err, x)
+		}
+	}
+
+	if _, err := ParseFix128("-170141183460469.231731687303715884105729", RoundTowardZero); err != (NegativeOverflowError{}) {
+		t.Errorf("ParseFix128(Fix128Min - iota) = %v; want NegativeOverflowError", err)
+	}
+	if _, err := ParseUFix128("340282366920938463463374607431768211456", RoundTowardZero); err != (PositiveOverflowError{}) {
+		t.Errorf("ParseUFix128(2^128) = %v; want PositiveOverflowError", err)
+	}
+}
+
+// Not run in parallel, since AllocsPerRun panics in parallel tests
+func TestAppend(t *testing.T) {
+	for _, x := range edgeValues128 {
+		if res := string(Fix128(x).Append([]byte("x="))); res != "x="+Fix128(x).String() {
+			t.Errorf("Fix128(%v).Append() = %q; want %q", x, res, "x="+Fix128(x).String())
+		}
+		if res := string(UFix128(x).Append(nil)); res != UFix128(x).String() {
+			t.Errorf("UFix128(%v).Append() = %q; want %q", x, res, UFix128(x).String())
+		}
+		if res := string(Fix64(x.Lo).Append(nil)); res != Fix64(x.Lo).String() {
+			t.Errorf("Fix64(%v).Append() = %q; want %q", x.Lo, res, Fix64(x.Lo).String())
+		}
+		if res := string(UFix64(x.Lo).Append(nil)); res != UFix64(x.Lo).String() {
+			t.Errorf("UFix64(%v).Append() = %q; want %q", x.Lo, res, UFix64(x.Lo).String())
+		}
+	}
+
+	// Appending to a buffer with enough capacity doesn't allocate
+	buf := make([]byte, 0, 64)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = Fix128Min.Append(buf[:0])
+		buf = UFix64Max.Append(buf[:0])
+	})
+	if allocs != 0 {
+		t.Errorf("Append allocated %v times per run; want 0", allocs)
+	}
+}
+
+func TestFormatFix128(t *testing.T) {
+
+	t.Parallel()
+
+	for _, tc := range []struct {
+		format string
+		value  any
+		want   string
+	}{
+		{"%v", Fix128Min, "-170141183460469.231731687303715884105728"},
+		{"%.2f", UFix128Max, "340282366920938.46"},
+		{"%.30f", UFix128One, "1.000000000000000000000000000000"},
+		{"%.3e", UFix128Max, "3.403e+14"},
+		{"%e", UFix128Iota, "1e-24"},
+		{"%.1e", Fix128Min, "-1.7e+14"},
+		{"%x", UFix128One, "000000000000d3c21bcecceda1000000"},
+		{"%#x", Fix128Min, "0x80000000000000000000000000000000"},
+		{"%25.1f", UFix128One, "                      1.0"},
+	} {
+		if res := fmt.Sprintf(tc.format, tc.value); res != tc.want {
+			t.Errorf("Sprintf(%q, %v) = %q; want %q", tc.format, tc.value, res, tc.want)
+		}
+	}
+}
+
+func TestGoString(t *testing.T) {
+
+	t.Parallel()
+
+	for _, tc := range []struct {
+		value any
+		want  string
+	}{
+		{UFix64One, "fixedPoint.UFix64(0x0000000005f5e100) /* 1.0 */"},
+		{Fix64Min, "fixedPoint.Fix64(0x8000000000000000) /* -92233720368.54775808 */"},
+		{UFix128One, "fixedPoint.UFix128{Hi: 0x000000000000d3c2, Lo: 0x1bcecceda1000000} /* 1.0 */"},
+		{Fix128(raw128{0xffffffffffffffff, 0xffffffffffffffff}), "fixedPoint.Fix128{Hi: 0xffffffffffffffff, Lo: 0xffffffffffffffff} /* -0.000000000000000000000001 */"},
+	} {
+		if res := fmt.Sprintf("%#v", tc.value); res != tc.want {
+			t.Errorf("Sprintf(%%#v, %v) = %q; want %q", tc.value, res, tc.want)
+		}
+		if res := tc.value.(fmt.GoStringer).GoString(); res != tc.want {
+			t.Errorf("%v.GoString() = %q; want %q", tc.value, res, tc.want)
+		}
+	}
+
+	// The output is valid Go syntax, for pasting into tests
+	if x := (UFix128{Hi: 0x000000000000d3c2, Lo: 0x1bcecceda1000000} /* 1.0 */); x != UFix128One {
+		t.Errorf("UFix128 literal = %v; want %v", x, UFix128One)
+	}
+}
+
+func TestTextMarshaling(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		text, err := Fix128(x).MarshalText()
+		if err != nil || string(text) != Fix128(x).String() {
+			t.Errorf("Fix128(%v).MarshalText() = %q, %v; want %q", x, text, err, Fix128(x).String())
+		}
+
+		var f128 Fix128
+		if err := f128.UnmarshalText(text); err != nil || f128 != Fix128(x) {
+			t.Errorf("UnmarshalText(%q) = %v, %v; want %v", text, f128, err, Fix128(x))
+		}
+
+		var u128 UFix128
+		text, _ = UFix128(x).MarshalText()
+		if err := u128.UnmarshalText(text); err != nil || u128 != UFix128(x) {
+			t.Errorf("UnmarshalText(%q) = %v, %v; want %v", text, u128, err, UFix128(x))
+		}
+
+		var f64 Fix64
+		text, _ = Fix64(x.Lo).MarshalText()
+		if err := f64.UnmarshalText(text); err != nil || f64 != Fix64(x.Lo) {
+			t.Errorf("UnmarshalText(%q) = %v, %v; want %v", text, f64, err, Fix64(x.Lo))
+		}
+
+		var u64 UFix64
+		text, _ = UFix64(x.Lo).MarshalText()
+		if err := u64.UnmarshalText(text); err != nil || u64 != UFix64(x.Lo) {
+			t.Errorf("UnmarshalText(%q) = %v, %v; want %v", text, u64, err, UFix64(x.Lo))
+		}
+	}
+
+	// Decoding is strict, and leaves the value unchanged on error
+	u64 := UFix64One
+	for _, text := range []string{"0.000000001", " 1", "-1", "abc", ""} {
+		if err := u64.UnmarshalText([]byte(text)); err == nil || u64 != UFix64One {
+			t.Errorf("UnmarshalText(%q) = %v, %v; want an error", text, u64, err)
+		}
+	}
+
+	// The text form is used for map keys
+	m := map[UFix64]string{UFix64One: "one", UFix64Iota: "iota"}
+	data, err := json.Marshal(m)
+	if want := `{"0.00000001":"iota","1.0":"one"}`; err != nil || string(data) != want {
+		t.Errorf("json.Marshal(map) = %s, %v; want %s", data, err, want)
+	}
+
+	var decoded map[UFix64]string
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded) != 2 || decoded[UFix64Iota] != "iota" {
+		t.Errorf("json.Unmarshal(%s) = %v, %v", data, decoded, err)
+	}
+}
+
+// Not run in parallel, since it changes DefaultJSONFormat
+func TestJSONMarshaling(t *testing.T) {
+
+	type amounts struct {
+		A UFix64
+		B Fix64
+		C UFix128
+		D Fix128
+	}
+
+	v := amounts{UFix64One, Fix64(neg64(1)), UFix128Max, Fix128Min}
+
+	data, err := json.Marshal(v)
+	want := `{"A":"1.0","B":"-0.00000001","C":"340282366920938.463463374607431768211455","D":"-170141183460469.231731687303715884105728"}`
+	if err != nil || string(data) != want {
+		t.Errorf("json.Marshal() = %s, %v; want %s", data, err, want)
+	}
+
+	var decoded amounts
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded != v {
+		t.Errorf("json.Unmarshal(%s) = %v, %v; want %v", data, decoded, err, v)
+	}
+
+	DefaultJSONFormat = JSONNumber
+	data, err = json.Marshal(v)
+	DefaultJSONFormat = JSONString
+
+	want = `{"A":1.0,"B":-0.00000001,"C":340282366920938.463463374607431768211455,"D":-170141183460469.231731687303715884105728}`
+	if err != nil || string(data) != want {
+		t.Errorf("json.Marshal() with JSONNumber = %s, %v; want %s", data, err, want)
+	}
+
+	decoded = amounts{}
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded != v {
+		t.Errorf("json.Unmarshal(%s) = %v, %v; want %v", data, decoded, err, v)
+	}
+
+	if res := string(UFix64One.AppendJSON([]byte("x="), JSONNumber)); res != "x=1.0" {
+		t.Errorf("AppendJSON(JSONNumber) = %q; want %q", res, "x=1.0")
+	}
+
+	// Both forms are accepted, null is ignored, and inexact values are rejected
+	for _, tc := range []struct {
+		data string
+		want UFix64
+		ok   bool
+	}{
+		{`"1.5"`, UFix64One + UFix64One/2, true},
+		{`1.5`, UFix64One + UFix64One/2, true},
+		{`15e-1`, UFix64One + UFix64One/2, true},
+		{`"1"`, UFix64One, true},
+		{`null`, UFix64Iota, true},
+		{`0.000000001`, UFix64Iota, false},
+		{`"0.000000001"`, UFix64Iota, false},
+		{`-1`, UFix64Iota, false},
+		{`true`, UFix64Iota, false},
+		{`"1`, UFix64Iota, false},
+	} {
+		res := UFix64Iota
+		if err := res.UnmarshalJSON([]byte(tc.data)); (err == nil) != tc.ok || res != tc.want {
+			t.Errorf("UnmarshalJSON(%s) = %v, %v; want %v (ok = %v)", tc.data, res, err, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestBinaryMarshaling(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		data, err := Fix128(x).MarshalBinary()
+		want := bigFromRaw128(x, false).FillBytes(make([]byte, 16))
+		if err != nil || string(data) != string(want) {
+			t.Errorf("Fix128(%v).MarshalBinary() = %x, %v; want %x", x, data, err, want)
+		}
+
+		var f128 Fix128
+		if err := f128.UnmarshalBinary(data); err != nil || f128 != Fix128(x) {
+			t.Errorf("Fix128.UnmarshalBinary(%x) = %v, %v; want %v", data, f128, err, Fix128(x))
+		}
+
+		var u128 UFix128
+		data, _ = UFix128(x).MarshalBinary()
+		if err := u128.UnmarshalBinary(data); err != nil || u128 != UFix128(x) {
+			t.Errorf("UFix128.UnmarshalBinary(%x) = %v, %v; want %v", data, u128, err, UFix128(x))
+		}
+
+		data, err = UFix64(x.Lo).MarshalBinary()
+		want = bigFromRaw64(uint64(x.Lo), false).FillBytes(make([]byte, 8))
+		if err != nil || string(data) != string(want) {
+			t.Errorf("UFix64(%v).MarshalBinary() = %x, %v; want %x", x.Lo, data, err, want)
+		}
+
+		var u64 UFix64
+		if err := u64.UnmarshalBinary(data); err != nil || u64 != UFix64(x.Lo) {
+			t.Errorf("UFix64.UnmarshalBinary(%x) = %v, %v; want %v", data, u64, err, UFix64(x.Lo))
+		}
+
+		var f64 Fix64
+		data, _ = Fix64(x.Lo).MarshalBinary()
+		if err := f64.UnmarshalBinary(data); err != nil || f64 != Fix64(x.Lo) {
+			t.Errorf("Fix64.UnmarshalBinary(%x) = %v, %v; want %v", data, f64, err, Fix64(x.Lo))
+		}
+	}
+
+	// The length must be exact, and the value is unchanged on error
+	u64, f128 := UFix64One, Fix128One
+	for _, n := range []int{0, 7, 9, 15, 17} {
+		if err := u64.UnmarshalBinary(make([]byte, n)); n != 8 && (err != (InvalidEncodingError{}) || u64 != UFix64One) {
+			t.Errorf("UFix64.UnmarshalBinary(%d bytes) = %v, %v; want InvalidEncodingError", n, u64, err)
+		}
+		if err := f128.UnmarshalBinary(make([]byte, n)); err != (InvalidEncodingError{}) || f128 != Fix128One {
+			t.Errorf("Fix128.UnmarshalBinary(%d bytes) = %v, %v; want InvalidEncodingError", n, f128, err)
+		}
+	}
+}
+
+func TestSortKey(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		for _, y := range edgeValues128 {
+			if res, want := bytes.Compare(Fix128(x).SortKey(), Fix128(y).SortKey()), compare(Fix128(x), Fix128(y)); res != want {
+				t.Errorf("Fix128 SortKey order of %v, %v = %d; want %d", x, y, res, want)
+			}
+			if res, want := bytes.Compare(UFix128(x).SortKey(), UFix128(y).SortKey()), compare(UFix128(x), UFix128(y)); res != want {
+				t.Errorf("UFix128 SortKey order of %v, %v = %d; want %d", x, y, res, want)
+			}
+			if res, want := bytes.Compare(Fix64(x.Lo).SortKey(), Fix64(y.Lo).SortKey()), compare(Fix64(x.Lo), Fix64(y.Lo)); res != want {
+				t.Errorf("Fix64 SortKey order of %v, %v = %d; want %d", x.Lo, y.Lo, res, want)
+			}
+			if res, want := bytes.Compare(UFix64(x.Lo).SortKey(), UFix64(y.Lo).SortKey()), compare(UFix64(x.Lo), UFix64(y.Lo)); res != want {
+				t.Errorf("UFix64 SortKey order of %v, %v = %d; want %d", x.Lo, y.Lo, res, want)
+			}
+		}
+	}
+}
+
+func TestSQL(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		v, err := Fix128(x).Value()
+		if err != nil || v != Fix128(x).String() {
+			t.Errorf("Fix128(%v).Value() = %v, %v; want %q", x, v, err, Fix128(x).String())
+		}
+
+		var f128 Fix128
+		if err := f128.Scan(v); err != nil || f128 != Fix128(x) {
+			t.Errorf("Fix128.Scan(%v) = %v, %v; want %v", v, f128, err, Fix128(x))
+		}
+
+		var u128 UFix128
+		v, _ = UFix128(x).Value()
+		if err := u128.Scan([]byte(v.(string))); err != nil || u128 != UFix128(x) {
+			t.Errorf("UFix128.Scan(%v) = %v, %v; want %v", v, u128, err, UFix128(x))
+		}
+	}
+
+	for _, tc := range []struct {
+		src  any
+		want UFix64
+		ok   bool
+	}{
+		{"1.5", UFix64One + UFix64One/2, true},
+		{[]byte("1.50000000000"), UFix64One + UFix64One/2, true},
+		{int64(3), 3 * UFix64One, true},
+		{int64(-3), UFix64Iota, false},
+		{1.5, UFix64Iota, false},
+		{nil, UFix64Iota, false},
+		{"0.000000001", UFix64Iota, false},
+	} {
+		res := UFix64Iota
+		if err := res.Scan(tc.src); (err == nil) != tc.ok || res != tc.want {
+			t.Errorf("UFix64.Scan(%#v) = %v, %v; want %v (ok = %v)", tc.src, res, err, tc.want, tc.ok)
+		}
+	}
+
+	var f64 Fix64
+	if err := f64.Scan(1.5); !errors.Is(err, ErrInvalidEncoding) || err.Error() != "cannot scan float64 into fixedPoint.Fix64: invalid encoding" {
+		t.Errorf("Fix64.Scan(1.5) = %v; want an invalid encoding error", err)
+	}
+
+	// Null types
+	n := NullFix128{Fix128: Fix128One, Valid: true}
+	if v, err := n.Value(); err != nil || v != "1.0" {
+		t.Errorf("NullFix128.Value() = %v, %v; want 1.0", v, err)
+	}
+	if err := n.Scan(nil); err != nil || n.Valid || n.Fix128 != Fix128Zero {
+		t.Errorf("NullFix128.Scan(nil) = %+v, %v; want invalid", n, err)
+	}
+	if v, err := n.Value(); err != nil || v != nil {
+		t.Errorf("NullFix128{}.Value() = %v, %v; want nil", v, err)
+	}
+	if err := n.Scan("-2"); err != nil || !n.Valid || n.Fix128 != Fix128(raw128FromBig(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), new(big.Int).Mul(big.NewInt(2), fix128ScaleBig)))) {
+		t.Errorf("NullFix128.Scan(-2) = %+v, %v; want -2", n, err)
+	}
+
+	var nu NullUFix64
+	if err := nu.Scan("12"); err != nil || !nu.Valid || nu.UFix64 != 12*UFix64One {
+		t.Errorf("NullUFix64.Scan(12) = %+v, %v; want 12", nu, err)
+	}
+	if err := nu.Scan("-1"); err == nil {
+		t.Errorf("NullUFix64.Scan(-1) = %+v; want an error", nu)
+	}
+}
+
+func TestCBOR(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		data, err := Fix128(x).MarshalCBOR()
+		var f128 Fix128
+		if err != nil || f128.UnmarshalCBOR(data) != nil || f128 != Fix128(x) {
+			t.Errorf("Fix128(%v) CBOR round trip through %x = %v, %v", x, data, f128, err)
+		}
+
+		data, err = UFix128(x).MarshalCBOR()
+		var u128 UFix128
+		if err != nil || u128.UnmarshalCBOR(data) != nil || u128 != UFix128(x) {
+			t.Errorf("UFix128(%v) CBOR round trip through %x = %v, %v", x, data, u128, err)
+		}
+
+		data, err = Fix64(x.Lo).MarshalCBOR()
+		var f64 Fix64
+		if err != nil || f64.UnmarshalCBOR(data) != nil || f64 != Fix64(x.Lo) {
+			t.Errorf("Fix64(%v) CBOR round trip through %x = %v, %v", x.Lo, data, f64, err)
+		}
+
+		data, err = UFix64(x.Lo).MarshalCBOR()
+		var u64 UFix64
+		if err != nil || u64.UnmarshalCBOR(data) != nil || u64 != UFix64(x.Lo) {
+			t.Errorf("UFix64(%v) CBOR round trip through %x = %v, %v", x.Lo, data, u64, err)
+		}
+	}
+
+	// Known encodings (checked against RFC 8949 section 3.4.4)
+	for _, tc := range []struct {
+		value interface{ MarshalCBOR() ([]byte, error) }
+		want  string
+	}{
+		{UFix64Zero, "c4822700"},
+		{UFix64One, "c482271a05f5e100"},
+		{Fix64(neg64(1)), "c4822720"},
+		{Fix64Min, "c482273b7fffffffffffffff"},
+		{UFix128Iota, "c4823701"},
+		{UFix128One, "c48237c24ad3c21bcecceda1000000"},
+		{Fix128Min, "c48237c3507fffffffffffffffffffffffffffffff"},
+	} {
+		if data, err := tc.value.MarshalCBOR(); err != nil || fmt.Sprintf("%x", data) != tc.want {
+			t.Errorf("%v.MarshalCBOR() = %x, %v; want %s", tc.value, data, err, tc.want)
+		}
+	}
+
+	// Any exact decimal fraction is accepted, including non-canonical forms
+	for _, tc := range []struct {
+		data string
+		want UFix64
+		err  error
+	}{
+		{"c482211903e9", UFix64(1001000000), nil},         // 1001e-2
+		{"c4820001", UFix64One, nil},                      // 1e0
+		{"c4820102", 20 * UFix64One, nil},                 // 2e1
+		{"c48227c2420001", UFix64(1), nil},                // 1e-8 as a bignum with a leading zero
+		{"c4822801", UFix64Zero, InexactError{}},          // 1e-9
+		{"c4822720", UFix64Zero, NegativeOverflowError{}}, // -1e-8
+		{"c482001bffffffffffffffff", UFix64Zero, PositiveOverflowError{}},
+		{"c482271a05f5e10000", UFix64Zero, InvalidEncodingError{}}, // Trailing data
+		{"c482271a05f5e1", UFix64Zero, InvalidEncodingError{}},     // Truncated
+		{"c58227", UFix64Zero, InvalidEncodingError{}},             // Wrong tag
+		{"c4832700", UFix64Zero, InvalidEncodingError{}},           // Wrong array length
+		{"c482f900", UFix64Zero, InvalidEncodingError{}},           // Not an integer
+		{"", UFix64Zero, InvalidEncodingError{}},
+	} {
+		data, _ := hex.DecodeString(tc.data)
+		res := UFix64Zero
+		if err := res.UnmarshalCBOR(data); err != tc.err || res != tc.want {
+			t.Errorf("UFix64.UnmarshalCBOR(%s) = %v, %v; want %v, %v", tc.data, res, err, tc.want, tc.err)
+		}
+	}
+}
+
+func TestGob(t *testing.T) {
+
+	t.Parallel()
+
+	type amounts struct {
+		A UFix64
+		B Fix64
+		C UFix128
+		D Fix128
+		E []Fix128
+		F map[string]UFix64
+	}
+
+	v := amounts{UFix64One, Fix64Min, UFix128Max, Fix128Min, []Fix128{Fix128One, Fix128Iota}, map[string]UFix64{"fee": UFix64Iota}}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		t.Fatalf("gob Encode() = %v", err)
+	}
+
+	var decoded amounts
+	if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+		t.Fatalf("gob Decode() = %v", err)
+	}
+
+	if decoded.A != v.A || decoded.B != v.B || decoded.C != v.C || decoded.D != v.D ||
+		len(decoded.E) != 2 || decoded.E[0] != Fix128One || decoded.E[1] != Fix128Iota || decoded.F["fee"] != UFix64Iota {
+		t.Errorf("gob round trip = %#v; want %#v", decoded, v)
+	}
+
+	if data, err := UFix128One.GobEncode(); err != nil || len(data) != 16 {
+		t.Errorf("UFix128One.GobEncode() = %x, %v; want 16 bytes", data, err)
+	}
+	if err := new(UFix64).GobDecode([]byte{1}); err != (InvalidEncodingError{}) {
+		t.Errorf("UFix64.GobDecode(1 byte) = %v; want InvalidEncodingError", err)
+	}
+}
+
+func TestFlagValue(t *testing.T) {
+
+	t.Parallel()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	minStake := UFix64One
+	var rate Fix128
+	fs.Var(&minStake, "min-stake", "minimum stake")
+	fs.Var(&rate, "rate", "rate")
+
+	if err := fs.Parse([]string{"-min-stake=1350000.0", "-rate", " -0.000000000000000000000001 "}); err != nil {
+		t.Fatalf("Parse() = %v", err)
+	}
+	if minStake != 1350000*UFix64One {
+		t.Errorf("min-stake = %v; want 1350000.0", minStake)
+	}
+	if rate != Fix128(raw128{0xffffffffffffffff, 0xffffffffffffffff}) {
+		t.Errorf("rate = %v; want -1e-24", rate)
+	}
+
+	// The default value is shown using String
+	if def := fs.Lookup("min-stake").DefValue; def != "1.0" {
+		t.Errorf("min-stake default = %q; want %q", def, "1.0")
+	}
+
+	for _, arg := range []string{"-min-stake=0.000000001", "-min-stake=abc", "-min-stake=-1"} {
+		if err := fs.Parse([]string{arg}); err == nil || !strings.Contains(err.Error(), "min-stake") {
+			t.Errorf("Parse(%q) = %v; want an error for min-stake", arg, err)
+		}
+	}
+	if minStake != 1350000*UFix64One {
+		t.Errorf("min-stake = %v after invalid values; want 1350000.0", minStake)
+	}
+}
+
+func TestYAML(t *testing.T) {
+
+	t.Parallel()
+
+	if v, err := Fix128Min.MarshalYAML(); err != nil || v != Fix128Min.String() {
+		t.Errorf("Fix128Min.MarshalYAML() = %v, %v; want %q", v, err, Fix128Min.String())
+	}
+
+	// The YAML libraries pass a function that decodes the node into the given value
+	scalar := func(s string) func(any) error {
+		return func(v any) error {
+			p, ok := v.(*string)
+			if !ok {
+				return fmt.Errorf("unexpected %T", v)
+			}
+			*p = s
+			return nil
+		}
+	}
+
+	var stake UFix64
+	if err := stake.UnmarshalYAML(scalar("1350000.5")); err != nil || stake != 1350000*UFix64One+UFix64One/2 {
+		t.Errorf("UFix64.UnmarshalYAML(1350000.5) = %v, %v", stake, err)
+	}
+
+	var rate Fix128
+	if err := rate.UnmarshalYAML(scalar("1e-24")); err != nil || rate != Fix128Iota {
+		t.Errorf("Fix128.UnmarshalYAML(1e-24) = %v, %v", rate, err)
+	}
+
+	if err := stake.UnmarshalYAML(scalar("0.000000001")); err != (InexactError{}) {
+		t.Errorf("UFix64.UnmarshalYAML(1e-9) = %v; want InexactError", err)
+	}
+
+	failing := func(any) error { return errors.New("not a scalar") }
+	if err := new(Fix64).UnmarshalYAML(failing); err == nil || err.Error() != "not a scalar" {
+		t.Errorf("Fix64.UnmarshalYAML(sequence) = %v; want the decoding error", err)
+	}
+}
+
+func TestMsgpack(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		data, err := Fix128(x).MarshalMsgpack()
+		var f128 Fix128
+		if err != nil || len(data) != 18 || f128.UnmarshalMsgpack(data) != nil || f128 != Fix128(x) {
+			t.Errorf("Fix128(%v) msgpack round trip through %x = %v, %v", x, data, f128, err)
+		}
+
+		data, err = UFix128(x).MarshalMsgpack()
+		var u128 UFix128
+		if err != nil || len(data) != 18 || u128.UnmarshalMsgpack(data) != nil || u128 != UFix128(x) {
+			t.Errorf("UFix128(%v) msgpack round trip through %x = %v, %v", x, data, u128, err)
+		}
+
+		data, err = Fix64(x.Lo).MarshalMsgpack()
+		var f64 Fix64
+		if err != nil || len(data) != 10 || f64.UnmarshalMsgpack(data) != nil || f64 != Fix64(x.Lo) {
+			t.Errorf("Fix64(%v) msgpack round trip through %x = %v, %v", x.Lo, data, f64, err)
+		}
+
+		data, err = UFix64(x.Lo).MarshalMsgpack()
+		var u64 UFix64
+		if err != nil || len(data) != 10 || u64.UnmarshalMsgpack(data) != nil || u64 != UFix64(x.Lo) {
+			t.Errorf("UFix64(%v) msgpack round trip through %x = %v, %v", x.Lo, data, u64, err)
+		}
+	}
+
+	if data, _ := UFix128One.MarshalMsgpack(); fmt.Sprintf("%x", data) != "d812000000000000d3c21bcecceda1000000" {
+		t.Errorf("UFix128One.MarshalMsgpack() = %x", data)
+	}
+
+	// The type code and the size must match
+	data, _ := Fix64One.MarshalMsgpack()
+	for _, bad := range [][]byte{nil, data[:9], append(data, 0), {0xd8, 0x11, 0, 0, 0, 0, 0, 0, 0, 0}} {
+		if err := new(Fix64).UnmarshalMsgpack(bad); err != (InvalidEncodingError{}) {
+			t.Errorf("Fix64.UnmarshalMsgpack(%x) = %v; want InvalidEncodingError", bad, err)
+		}
+	}
+	if err := new(UFix64).UnmarshalMsgpack(data); err != (InvalidEncodingError{}) {
+		t.Errorf("UFix64.UnmarshalMsgpack(Fix64 data) = %v; want InvalidEncodingError", err)
+	}
+}
+
+func TestRLP(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		var buf bytes.Buffer
+		if err := UFix128(x).EncodeRLP(&buf); err != nil {
+			t.Fatalf("EncodeRLP() = %v", err)
+		}
+		if res, err := DecodeRLPUFix128(buf.Bytes()); err != nil || res != UFix128(x) {
+			t.Errorf("DecodeRLPUFix128(%x) = %v, %v; want %v", buf.Bytes(), res, err, UFix128(x))
+		}
+
+		// 18 decimals, as used by most ERC-20 tokens
+		data, err := UFix64(x.Lo).AppendRLP(nil, 18)
+		if res, decodeErr := DecodeRLPUFix64(data); err != nil || decodeErr != nil || res != UFix64(x.Lo) {
+			t.Errorf("DecodeRLPUFix64(%x) = %v, %v, %v; want %v", data, res, err, decodeErr, UFix64(x.Lo))
+		}
+		if res, err := DecodeRLPUFix128(data); err != nil || res.String() != UFix64(x.Lo).String() {
+			t.Errorf("DecodeRLPUFix128(%x) = %v, %v; want %v", data, res, err, UFix64(x.Lo))
+		}
+	}
+
+	for _, tc := range []struct {
+		value    UFix64
+		decimals uint8
+		want     string
+		err      error
+	}{
+		{UFix64Zero, 8, "c28008", nil},
+		{UFix64One, 0, "c20180", nil},
+		{UFix64One + UFix64One/2, 18, "ca8814d1120d7b16000012", nil},
+		{UFix64One + UFix64One/2, 0, "", InexactError{}},
+		{UFix64Iota, 8, "c20108", nil},
+		{UFix64Max, 200, "f85cb858381c3de34e49d55a6952bab1aed4efab225569f4d919c7e6afe96d78106a42608af96c7f0b15d0f977f67efd725a40df3343c2a1486e7bdb561e81e0537ea2ff00000000000000000000000000000000000000000000000081c8", nil},
+	} {
+		data, err := tc.value.AppendRLP(nil, tc.decimals)
+		if err != tc.err || fmt.Sprintf("%x", data) != tc.want {
+			t.Errorf("%v.AppendRLP(%d) = %x, %v; want %s, %v", tc.value, tc.decimals, data, err, tc.want, tc.err)
+		}
+	}
+
+	for _, tc := range []struct {
+		data string
+		want UFix64
+		err  error
+	}{
+		{"c20108", UFix64Iota, nil},
+		{"c20109", UFix64Zero, InexactError{}},
+		{"c4820a0109", UFix64Zero, InexactError{}},          // 2561e-9
+		{"c3820a0000", UFix64Zero, InvalidEncodingError{}},  // Trailing data in the list
+		{"c2010800", UFix64Zero, InvalidEncodingError{}},    // Trailing data after the list
+		{"c3810108", UFix64Zero, InvalidEncodingError{}},    // Non-canonical single byte
+		{"c4820001 08", UFix64Zero, InvalidEncodingError{}}, // Leading zero
+		{"c0", UFix64Zero, InvalidEncodingError{}},
+		{"0108", UFix64Zero, InvalidEncodingError{}},
+		{"", UFix64Zero, InvalidEncodingError{}},
+	} {
+		data, _ := hex.DecodeString(strings.ReplaceAll(tc.data, " ", ""))
+		if res, err := DecodeRLPUFix64(data); err != tc.err || res != tc.want {
+			t.Errorf("DecodeRLPUFix64(%s) = %v, %v; want %v, %v", tc.data, res, err, tc.want, tc.err)
+		}
+	}
+}
+
+func TestProto(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		data, err := Fix128(x).MarshalProto()
+		var f128 Fix128
+		if err != nil || f128.UnmarshalProto(data) != nil || f128 != Fix128(x) {
+			t.Errorf("Fix128(%v) proto round trip through %x = %v, %v", x, data, f128, err)
+		}
+		if hi, lo := f128.Words(); NewFix128(hi, lo) != f128 {
+			t.Errorf("NewFix128(%v.Words()) = %v", f128, NewFix128(hi, lo))
+		}
+
+		data, err = UFix128(x).MarshalProto()
+		var u128 UFix128
+		if err != nil || u128.UnmarshalProto(data) != nil || u128 != UFix128(x) {
+			t.Errorf("UFix128(%v) proto round trip through %x = %v, %v", x, data, u128, err)
+		}
+		if hi, lo := u128.Words(); NewUFix128(hi, lo) != u128 {
+			t.Errorf("NewUFix128(%v.Words()) = %v", u128, NewUFix128(hi, lo))
+		}
+
+		data, err = Fix64(x.Lo).MarshalProto()
+		var f64 Fix64
+		if err != nil || f64.UnmarshalProto(data) != nil || f64 != Fix64(x.Lo) {
+			t.Errorf("Fix64(%v) proto round trip through %x = %v, %v", x.Lo, data, f64, err)
+		}
+
+		data, err = UFix64(x.Lo).MarshalProto()
+		var u64 UFix64
+		if err != nil || u64.UnmarshalProto(data) != nil || u64 != UFix64(x.Lo) {
+			t.Errorf("UFix64(%v) proto round trip through %x = %v, %v", x.Lo, data, u64, err)
+		}
+	}
+
+	encodings := []struct {
+		value interface{ MarshalProto() ([]byte, error) }
+		want  string
+	}{
+		{UFix64Zero, ""},
+		{UFix64One, "0880c2d72f"},
+		{Fix64(^uint64(0)), "0801"},
+		{Fix64(1), "0802"},
+		{UFix128Zero, ""},
+		{NewUFix128(1, 2), "08011002"},
+		{NewFix128(0, 2), "1002"},
+	}
+	for _, tc := range encodings {
+		if data, _ := tc.value.MarshalProto(); hex.EncodeToString(data) != tc.want {
+			t.Errorf("%v.MarshalProto() = %x; want %s", tc.value, data, tc.want)
+		}
+	}
+
+	// Unknown fields are skipped, and repeated fields use the last value
+	var u128 UFix128
+	data, _ := hex.DecodeString("1803" + "2203616263" + "2d01020304" + "290102030405060708" + "0805" + "0801" + "1002")
+	if err := u128.UnmarshalProto(data); err != nil || u128 != NewUFix128(1, 2) {
+		t.Errorf("UFix128.UnmarshalProto(%x) = %v, %v", data, u128, err)
+	}
+
+	for _, bad := range []string{"08", "0880", "2205616263", "0a00", "0d01020304", "00", "0b", "29010203"} {
+		data, _ := hex.DecodeString(bad)
+		if err := new(UFix128).UnmarshalProto(data); err != (InvalidEncodingError{}) {
+			t.Errorf("UFix128.UnmarshalProto(%s) = %v; want InvalidEncodingError", bad, err)
+		}
+	}
+}
+
+func TestGraphQL(t *testing.T) {
+
+	t.Parallel()
+
+	var buf bytes.Buffer
+	Fix128Min.MarshalGQL(&buf)
+	if want := `"-170141183460469.231731687303715884105728"`; buf.String() != want {
+		t.Errorf("Fix128Min.MarshalGQL() = %s; want %s", buf.String(), want)
+	}
+
+	var f128 Fix128
+	if err := json.Unmarshal(buf.Bytes(), new(any)); err != nil {
+		t.Errorf("MarshalGQL() produced invalid JSON %s: %v", buf.String(), err)
+	}
+	if err := f128.UnmarshalGQL("-170141183460469.231731687303715884105728"); err != nil || f128 != Fix128Min {
+		t.Errorf("Fix128.UnmarshalGQL() = %v, %v; want %v", f128, err, Fix128Min)
+	}
+
+	for _, v := range []any{"1.0", "1", json.Number("1.00"), 1, int64(1), uint64(1)} {
+		var u64 UFix64
+		if err := u64.UnmarshalGQL(v); err != nil || u64 != UFix64One {
+			t.Errorf("UFix64.UnmarshalGQL(%#v) = %v, %v; want %v", v, u64, err, UFix64One)
+		}
+	}
+
+	var f64 Fix64
+	if err := f64.UnmarshalGQL(-2); err != nil || f64 != Fix64(neg64(2*1e8)) {
+		t.Errorf("Fix64.UnmarshalGQL(-2) = %v, %v", f64, err)
+	}
+
+	// Floats and other types are rejected, as are inexact values
+	errs := []struct {
+		v   any
+		err error
+	}{
+		{1.0, InvalidEncodingError{}},
+		{nil, InvalidEncodingError{}},
+		{true, InvalidEncodingError{}},
+		{"0.0000000000000000000000001", InexactError{}},
+		{"-1", NegativeOverflowError{}},
+		{"abc", SyntaxError{}},
+	}
+	for _, tc := range errs {
+		u128 := UFix128One
+		if err := u128.UnmarshalGQL(tc.v); !errors.Is(err, tc.err) || u128 != UFix128One {
+			t.Errorf("UFix128.UnmarshalGQL(%#v) = %v, %v; want %v", tc.v, u128, err, tc.err)
+		}
+	}
+}
+
+func TestArrowDecimal128(t *testing.T) {
+
+	t.Parallel()
+
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(ArrowDecimal128Precision), nil)
+
+	for _, x := range edgeValues128 {
+		// Fix128
+		b, err := Fix128(x).ArrowDecimal128()
+		v := bigFromRaw128(x, true)
+		switch {
+		case v.CmpAbs(limit) >= 0 && v.Sign() > 0:
+			if err != (PositiveOverflowError{}) {
+				t.Errorf("Fix128(%v).ArrowDecimal128() = %x, %v; want PositiveOverflowError", x, b, err)
+			}
+		case v.CmpAbs(limit) >= 0:
+			if err != (NegativeOverflowError{}) {
+				t.Errorf("Fix128(%v).ArrowDecimal128() = %x, %v; want NegativeOverflowError", x, b, err)
+			}
+		case err != nil || Fix128FromArrowDecimal128(b) != Fix128(x):
+			t.Errorf("Fix128(%v) Arrow round trip through %x = %v, %v", x, b, Fix128FromArrowDecimal128(b), err)
+		}
+
+		// UFix128
+		b, err = UFix128(x).ArrowDecimal128()
+		v = bigFromRaw128(x, false)
+		if v.Cmp(limit) >= 0 {
+			if err != (PositiveOverflowError{}) {
+				t.Errorf("UFix128(%v).ArrowDecimal128() = %x, %v; want PositiveOverflowError", x, b, err)
+			}
+			continue
+		}
+		if res, err2 := UFix128FromArrowDecimal128(b); err != nil || err2 != nil || res != UFix128(x) {
+			t.Errorf("UFix128(%v) Arrow round trip through %x = %v, %v, %v", x, b, res, err, err2)
+		}
+	}
+
+	// Little-endian, low word first
+	b, _ := NewUFix128(1, 2).ArrowDecimal128()
+	if want := "02000000000000000100000000000000"; hex.EncodeToString(b[:]) != want {
+		t.Errorf("NewUFix128(1, 2).ArrowDecimal128() = %x; want %s", b, want)
+	}
+	b, _ = NewFix128(^uint64(0), ^uint64(0)).ArrowDecimal128()
+	if want := strings.Repeat("ff", 16); hex.EncodeToString(b[:]) != want {
+		t.Errorf("Fix128(-iota).ArrowDecimal128() = %x; want %s", b, want)
+	}
+
+	if res, err := UFix128FromArrowDecimal128(b); err != (NegativeOverflowError{}) {
+		t.Errorf("UFix128FromArrowDecimal128(%x) = %v, %v; want NegativeOverflowError", b, res, err)
+	}
+}
+
+func TestPgNumeric(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		data := Fix128(x).AppendPgNumeric(nil)
+		var f128 Fix128
+		if err := f128.UnmarshalPgNumeric(data); err != nil || f128 != Fix128(x) {
+			t.Errorf("Fix128(%v) numeric round trip through %x = %v, %v", x, data, f128, err)
+		}
+
+		data = UFix128(x).AppendPgNumeric(nil)
+		var u128 UFix128
+		if err := u128.UnmarshalPgNumeric(data); err != nil || u128 != UFix128(x) {
+			t.Errorf("UFix128(%v) numeric round trip through %x = %v, %v", x, data, u128, err)
+		}
+
+		data = Fix64(x.Lo).AppendPgNumeric(nil)
+		var f64 Fix64
+		if err := f64.UnmarshalPgNumeric(data); err != nil || f64 != Fix64(x.Lo) {
+			t.Errorf("Fix64(%v) numeric round trip through %x = %v, %v", x.Lo, data, f64, err)
+		}
+
+		data = UFix64(x.Lo).AppendPgNumeric(nil)
+		var u64 UFix64
+		if err := u64.UnmarshalPgNumeric(data); err != nil || u64 != UFix64(x.Lo) {
+			t.Errorf("UFix64(%v) numeric round trip through %x = %v, %v", x.Lo, data, u64, err)
+		}
+	}
+
+	encodings := []struct {
+		value interface{ AppendPgNumeric([]byte) []byte }
+		want  string
+	}{
+		{UFix64Zero, "0000000000000008"},
+		{UFix64One, "00010000000000080001"},
+		{UFix64(1234567890000), "0003000100000008000109291a85"},
+		{UFix64(1), "0001fffe000000080001"},
+		{Fix64(neg64(1e8)), "00010000400000080001"},
+		{UFix128One, "00010000000000180001"},
+		{UFix128Iota, "0001fffa000000180001"},
+	}
+	for _, tc := range encodings {
+		if data := tc.value.AppendPgNumeric(nil); hex.EncodeToString(data) != tc.want {
+			t.Errorf("%v.AppendPgNumeric() = %x; want %s", tc.value, data, tc.want)
+		}
+	}
+
+	// Non-canonical encodings are accepted, e.g. with leading and trailing zero digits
+	var u64 UFix64
+	data, _ := hex.DecodeString("0004000100000002" + "0000" + "0001" + "1388" + "0000")
+	if err := u64.UnmarshalPgNumeric(data); err != nil || u64 != UFix64(1.5e8) {
+		t.Errorf("UFix64.UnmarshalPgNumeric(non-canonical) = %v, %v; want 1.5", u64, err)
+	}
+
+	errs := []struct {
+		data string
+		err  error
+	}{
+		{"", InvalidEncodingError{}},
+		{"00010000000000080001ff", InvalidEncodingError{}},
+		{"00010000000000082710", InvalidEncodingError{}},
+		{"00000000c0000000", InvalidEncodingError{}},
+		{"0000000012340000", InvalidEncodingError{}},
+		{"00000000d0000000", PositiveOverflowError{}},
+		{"00000000f0000000", NegativeOverflowError{}},
+		{"00010000400000080001", NegativeOverflowError{}},
+		{"0001fffd000000080001", InexactError{}},
+		{"00010005000000000001", PositiveOverflowError{}},
+	}
+	for _, tc := range errs {
+		u64 := UFix64One
+		data, _ := hex.DecodeString(tc.data)
+		if err := u64.UnmarshalPgNumeric(data); err != tc.err || u64 != UFix64One {
+			t.Errorf("UFix64.UnmarshalPgNumeric(%s) = %v, %v; want %v", tc.data, u64, err, tc.err)
+		}
+	}
+}
+
+func TestFormatGrouped(t *testing.T) {
+
+	t.Parallel()
+
+	u64, _ := ParseUFix64("1234567.89012345", RoundTowardZero)
+	f64, _ := ParseFix64("-1234.5", RoundTowardZero)
+
+	tests := []struct {
+		value interface {
+			FormatGrouped(rune, int, RoundingMode) (string, error)
+		}
+		sep      rune
+		decimals int
+		round    RoundingMode
+		want     string
+	}{
+		{u64, ',', 6, RoundNearestHalfEven, "1,234,567.890123"},
+		{u64, ',', 7, RoundNearestHalfAway, "1,234,567.8901235"},
+		{u64, ',', 7, RoundTowardZero, "1,234,567.8901234"},
+		{u64, ',', 0, RoundNearestHalfEven, "1,234,568"},
+		{u64, ',', -1, RoundNearestHalfEven, "1,234,567.89012345"},
+		{u64, ',', 10, RoundNearestHalfEven, "1,234,567.8901234500"},
+		{u64, '\'', 2, RoundNearestHalfEven, "1'234'567.89"},
+		{u64, ' ', 2, RoundNearestHalfEven, "1 234 567.89"},
+		{u64, 0, 2, RoundNearestHalfEven, "1234567.89"},
+		{UFix64(123456e8), ',', 2, RoundNearestHalfEven, "123,456.00"},
+		{UFix64Zero, ',', 2, RoundNearestHalfEven, "0.00"},
+		{UFix64One, ',', -1, RoundNearestHalfEven, "1.0"},
+
+		// Directed rounding takes the sign into account
+		{f64, ',', 0, RoundFloor, "-1,235"},
+		{f64, ',', 0, RoundCeil, "-1,234"},
+		{f64, ',', 0, RoundNearestHalfAway, "-1,235"},
+		{f64, ',', 0, RoundNearestHalfEven, "-1,234"},
+		{Fix64(neg64(1)), ',', 2, RoundNearestHalfEven, "0.00"},
+		{Fix64(neg64(1)), ',', 2, RoundFloor, "-0.01"},
+
+		{UFix128Max, ',', 2, RoundNearestHalfEven, "340,282,366,920,938.46"},
+		{UFix128Max, ',', -1, RoundNearestHalfEven, "340,282,366,920,938.463463374607431768211455"},
+		{Fix128Min, ',', 3, RoundTowardZero, "-170,141,183,460,469.231"},
+		{Fix128Min, ',', 0, RoundFloor, "-170,141,183,460,470"},
+	}
+
+	for _, tc := range tests {
+		if res, err := tc.value.FormatGrouped(tc.sep, tc.decimals, tc.round); err != nil || res != tc.want {
+			t.Errorf("%v.FormatGrouped(%q, %d, %v) = %q, %v; want %q", tc.value, tc.sep, tc.decimals, tc.round, res, err, tc.want)
+		}
+
+		// An invalid rounding mode is reported, even when the value is exact or zero.
+		for _, decimals := range []int{tc.decimals, -1, 30} {
+			if res, err := tc.value.FormatGrouped(tc.sep, decimals, RoundingMode(99)); err != (InvalidRoundingModeError{}) {
+				t.Errorf("%v.FormatGrouped(%q, %d, 99) = %q, %v; want InvalidRoundingModeError", tc.value, tc.sep, decimals, res, err)
+			}
+		}
+	}
+}
+
+func TestFormatCurrency(t *testing.T) {
+
+	t.Parallel()
+
+	u64, _ := ParseUFix64("1234.56789", RoundTowardZero)
+	f64, _ := ParseFix64("-1.505", RoundTowardZero)
+
+	tests := []struct {
+		value interface {
+			FormatCurrency(string, int, RoundingMode) (string, error)
+		}
+		currency string
+		decimals int
+		round    RoundingMode
+		want     string
+	}{
+		{u64, "FLOW", 4, RoundTowardZero, "1,234.5678 FLOW"},
+		{u64, "FLOW", 4, RoundNearestHalfEven, "1,234.5679 FLOW"},
+		{u64, "FLOW", -1, RoundTowardZero, "1,234.56789 FLOW"},
+		{u64, "$", 2, RoundNearestHalfEven, "$1,234.57"},
+		{u64, "€", 2, RoundTowardZero, "€1,234.56"},
+		{u64, "", 2, RoundTowardZero, "1,234.56"},
+		{u64, "A$", 2, RoundTowardZero, "1,234.56 A$"},
+		{UFix64Zero, "FLOW", 4, RoundTowardZero, "0.0000 FLOW"},
+		{f64, "$", 2, RoundNearestHalfAway, "-$1.51"},
+		{f64, "$", 2, RoundCeil, "-$1.50"},
+		{f64, "FLOW", 0, RoundTowardZero, "-1 FLOW"},
+		{Fix64(neg64(1)), "$", 2, RoundTowardZero, "$0.00"},
+		{UFix128Max, "FLOW", 8, RoundTowardZero, "340,282,366,920,938.46346337 FLOW"},
+		{Fix128Min, "$", 0, RoundTowardZero, "-$170,141,183,460,469"},
+	}
+
+	for _, tc := range tests {
+		if res, err := tc.value.FormatCurrency(tc.currency, tc.decimals, tc.round); err != nil || res != tc.want {
+			t.Errorf("%v.FormatCurrency(%q, %d, %v) = %q, %v; want %q", tc.value, tc.currency, tc.decimals, tc.round, res, err, tc.want)
+		}
+		if res, err := tc.value.FormatCurrency(tc.currency, tc.decimals, RoundingMode(99)); err != (InvalidRoundingModeError{}) {
+			t.Errorf("%v.FormatCurrency(%q, %d, 99) = %q, %v; want InvalidRoundingModeError", tc.value, tc.currency, tc.decimals, res, err)
+		}
+	}
+}
+
+func TestFormatScientific(t *testing.T) {
+
+	t.Parallel()
+
+	u64, _ := ParseUFix64("123450000000", RoundTowardZero)
+	u128, _ := ParseUFix128("1234500000000", RoundTowardZero)
+	f64, _ := ParseFix64("-0.0012", RoundTowardZero)
+
+	tests := []struct {
+		value interface {
+			FormatScientific() string
+			FormatEngineering() string
+		}
+		scientific, engineering string
+	}{
+		{UFix64Zero, "0e+0", "0e+0"},
+		{UFix64One, "1e+0", "1e+0"},
+		{UFix64(1), "1e-8", "10e-9"},
+		{UFix64(5), "5e-8", "50e-9"},
+		{UFix64(12e8), "1.2e+1", "12e+0"},
+		{u64, "1.2345e+11", "123.45e+9"},
+		{u128, "1.2345e+12", "1.2345e+12"},
+		{f64, "-1.2e-3", "-1.2e-3"},
+		{Fix64(neg64(12345)), "-1.2345e-4", "-123.45e-6"},
+		{UFix128Iota, "1e-24", "1e-24"},
+		{UFix128Max, "3.40282366920938463463374607431768211455e+14", "340.282366920938463463374607431768211455e+12"},
+		{Fix128Min, "-1.70141183460469231731687303715884105728e+14", "-170.141183460469231731687303715884105728e+12"},
+	}
+
+	for _, tc := range tests {
+		if res := tc.value.FormatScientific(); res != tc.scientific {
+			t.Errorf("%v.FormatScientific() = %q; want %q", tc.value, res, tc.scientific)
+		}
+		if res := tc.value.FormatEngineering(); res != tc.engineering {
+			t.Errorf("%v.FormatEngineering() = %q; want %q", tc.value, res, tc.engineering)
+		}
+	}
+
+	// Both forms are exact, so they can be parsed back
+	for _, x := range edgeValues128 {
+		for _, s := range []string{Fix128(x).FormatScientific(), Fix128(x).FormatEngineering()} {
+			if res, err := textParser.ParseFix128(s); err != nil || res != Fix128(x) {
+				t.Errorf("ParseFix128(%q) = %v, %v; want %v", s, res, err, Fix128(x))
+			}
+		}
+		for _, s := range []string{UFix64(x.Lo).FormatScientific(), UFix64(x.Lo).FormatEngineering()} {
+			if res, err := textParser.ParseUFix64(s); err != nil || res != UFix64(x.Lo) {
+				t.Errorf("ParseUFix64(%q) = %v, %v; want %v", s, res, err, UFix64(x.Lo))
+			}
+		}
+	}
+}
+
+func TestFixedString(t *testing.T) {
+
+	t.Parallel()
+
+	f64, _ := ParseFix64("-12.5", RoundTowardZero)
+
+	tests := []struct {
+		value interface {
+			String() string
+			FixedString(int) string
+		}
+		decimals int
+		want     string
+	}{
+		{UFix64One, 0, "1"},
+		{UFix64One, 1, "1.0"},
+		{UFix64One, Fix64Decimals, "1.00000000"},
+		{UFix64One, 12, "1.000000000000"},
+		{UFix64One, -1, "1"},
+		{UFix64Zero, 0, "0"},
+		{UFix64Zero, 2, "0.00"},
+		{UFix64(1), 0, "0.00000001"},
+		{UFix64(1), 4, "0.00000001"},
+		{f64, 0, "-12.5"},
+		{f64, 3, "-12.500"},
+		{UFix128One, Fix128Decimals, "1.000000000000000000000000"},
+		{UFix128Max, 0, "340282366920938.463463374607431768211455"},
+		{Fix128Min, 30, "-170141183460469.231731687303715884105728000000"},
+	}
+
+	for _, tc := range tests {
+		if res := tc.value.FixedString(tc.decimals); res != tc.want {
+			t.Errorf("%v.FixedString(%d) = %q; want %q", tc.value, tc.decimals, res, tc.want)
+		}
+	}
+
+	// String is the same as FixedString(1), and every form parses back to the same value, both
+	// strictly and leniently.
+	for _, x := range edgeValues128 {
+		values := []interface {
+			String() string
+			FixedString(int) string
+		}{UFix64(x.Lo), Fix64(x.Lo), UFix128(x), Fix128(x)}
+
+		for _, v := range values {
+			if v.String() != v.FixedString(1) {
+				t.Errorf("%v.String() = %q; want %q", v, v.String(), v.FixedString(1))
+			}
+
+			for _, s := range []string{v.String(), v.FixedString(0), v.FixedString(Fix128Decimals), v.FixedString(50)} {
+				var res, lenient any
+				var err, lenientErr error
+				switch v.(type) {
+				case UFix64:
+					res, err = textParser.ParseUFix64(s)
+					lenient, lenientErr = ParseUFix64(s, RoundTowardZero)
+				case Fix64:
+					res, err = textParser.ParseFix64(s)
+					lenient, lenientErr = ParseFix64(s, RoundTowardZero)
+				case UFix128:
+					res, err = textParser.ParseUFix128(s)
+					lenient, lenientErr = ParseUFix128(s, RoundTowardZero)
+				case Fix128:
+					res, err = textParser.ParseFix128(s)
+					lenient, lenientErr = ParseFix128(s, RoundTowardZero)
+				}
+				if err != nil || res != v || lenientErr != nil || lenient != v {
+					t.Errorf("Parse(%q) = %v, %v (lenient %v, %v); want %v", s, res, err, lenient, lenientErr, v)
+				}
+			}
+		}
+	}
+}
+
+// Not run in parallel, since AllocsPerRun panics in parallel tests.
+func TestParseColumn(t *testing.T) {
+
+	inputs := []string{"1.0", "-2.5", "abc", "340282366920938.463463374607431768211455", "1e-30", " 3 "}
+	values, errs := ParseUFix128Column(inputs, RoundTowardZero)
+
+	three, _ := ParseUFix128("3", RoundTowardZero)
+	want := []UFix128{UFix128One, UFix128Zero, UFix128Zero, UFix128Max, UFix128Zero, three}
+	wantErrs := []error{nil, NegativeOverflowError{}, SyntaxError{}, nil, UnderflowError{}, nil}
+
+	if len(values) != len(want) || len(errs) != len(wantErrs) {
+		t.Fatalf("ParseUFix128Column() = %v, %v; want %v, %v", values, errs, want, wantErrs)
+	}
+	for i := range want {
+		if values[i] != want[i] || errs[i] != wantErrs[i] {
+			t.Errorf("ParseUFix128Column()[%d] = %v, %v; want %v, %v", i, values[i], errs[i], want[i], wantErrs[i])
+		}
+	}
+
+	// The errors are nil if there aren't any
+	if values, errs := ParseFix64Column([]string{"1", "-1"}, RoundTowardZero); errs != nil || len(values) != 2 || values[1] != Fix64(neg64(1e8)) {
+		t.Errorf("ParseFix64Column() = %v, %v", values, errs)
+	}
+	if values, errs := textParser.ParseFix128Column(nil); errs != nil || len(values) != 0 {
+		t.Errorf("ParseFix128Column(nil) = %v, %v", values, errs)
+	}
+
+	// Only the result slice is allocated.
+	column := make([]string, 1000)
+	for i := range column {
+		column[i] = strconv.Itoa(i) + ".5"
+	}
+	if allocs := testing.AllocsPerRun(10, func() { ParseUFix128Column(column, RoundTowardZero) }); allocs > 1 {
+		t.Errorf("ParseUFix128Column() allocates %v times; want 1", allocs)
+	}
+
+	// The io-based variant gives the same results, regardless of how the input is split up.
+	text := strings.Join(inputs, "\n")
+	readers := []io.Reader{
+		strings.NewReader(text),
+		strings.NewReader(text + "\n"),
+		strings.NewReader(strings.Join(inputs, "\r\n") + "\r\n"),
+		iotest.OneByteReader(strings.NewReader(text)),
+		iotest.HalfReader(strings.NewReader(text)),
+	}
+	for i, r := range readers {
+		values, errs, err := ParseOptions{}.ReadUFix128Column(r)
+		if err != nil || len(values) != len(want) || len(errs) != len(wantErrs) {
+			t.Errorf("reader %d: ReadUFix128Column() = %v, %v, %v; want %v, %v", i, values, errs, err, want, wantErrs)
+			continue
+		}
+		for j := range want {
+			if values[j] != want[j] || errs[j] != wantErrs[j] {
+				t.Errorf("reader %d: ReadUFix128Column()[%d] = %v, %v; want %v, %v", i, j, values[j], errs[j], want[j], wantErrs[j])
+			}
+		}
+	}
+
+	// Lines longer than the read buffer, and an empty input
+	long := "1." + strings.Repeat("0", 2*readBlockSize)
+	if values, errs, err := textParser.ReadUFix64Column(strings.NewReader("2\n" + long)); err != nil || errs != nil ||
+		len(values) != 2 || values[1] != UFix64One {
+		t.Errorf("ReadUFix64Column(long line) = %v, %v, %v", values, errs, err)
+	}
+	if values, errs, err := textParser.ReadFix64Column(strings.NewReader("")); err != nil || errs != nil || len(values) != 0 {
+		t.Errorf("ReadFix64Column(\"\") = %v, %v, %v", values, errs, err)
+	}
+
+	// Read errors are returned along with the values read so far
+	r := io.MultiReader(strings.NewReader("1\n2\n3"), iotest.ErrReader(io.ErrUnexpectedEOF))
+	if values, _, err := textParser.ReadFix128Column(r); err != io.ErrUnexpectedEOF || len(values) != 2 {
+		t.Errorf("ReadFix128Column(failing reader) = %v, %v; want 2 values, %v", values, err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestMigrateSlices(t *testing.T) {
+
+	t.Parallel()
+
+	var usrc []UFix64
+	var src []Fix64
+	for _, x := range edgeValues64 {
+		usrc = append(usrc, UFix64(x))
+		src = append(src, Fix64(x))
+	}
+
+	// The bulk converters match the per-value ones.
+	uwide := ToUFix128Slice(usrc)
+	wide := ToFix128Slice(src)
+	if len(uwide) != len(usrc) || len(wide) != len(src) {
+		t.Fatalf("ToUFix128Slice() and ToFix128Slice() returned %d and %d values; want %d", len(uwide), len(wide), len(src))
+	}
+	for i := range src {
+		if uwide[i] != usrc[i].ToUFix128() {
+			t.Errorf("ToUFix128Slice()[%d] = %v; want %v", i, uwide[i], usrc[i].ToUFix128())
+		}
+		if wide[i] != src[i].ToFix128() {
+			t.Errorf("ToFix128Slice()[%d] = %v; want %v", i, wide[i], src[i].ToFix128())
+		}
+	}
+
+	// Round trips are exact, without errors.
+	if back, errs := ToUFix64Slice(uwide, RoundTowardZero); errs != nil || !slices.Equal(back, usrc) {
+		t.Errorf("ToUFix64Slice(ToUFix128Slice()) = %v, %v; want %v", back, errs, usrc)
+	}
+	if back, errs := ToFix64Slice(wide, RoundTowardZero); errs != nil || !slices.Equal(back, src) {
+		t.Errorf("ToFix64Slice(ToFix128Slice()) = %v, %v; want %v", back, errs, src)
+	}
+
+	// Each failure is reported against its own element.
+	narrow := []Fix128{Fix128One, Fix128Max, MustParseFix128("-0.000000001"), Fix128Min, MustParseFix128("-1.5")}
+	values, errs := ToFix64Slice(narrow, RoundTowardZero)
+	want := []Fix64{Fix64One, Fix64Zero, Fix64Zero, Fix64Zero, MustParseFix64("-1.5")}
+	wantErrs := []error{nil, PositiveOverflowError{}, UnderflowError{}, NegativeOverflowError{}, nil}
+	if len(values) != len(want) || len(errs) != len(wantErrs) {
+		t.Fatalf("ToFix64Slice() = %v, %v; want %v, %v", values, errs, want, wantErrs)
+	}
+	for i := range want {
+		if values[i] != want[i] || errs[i] != wantErrs[i] {
+			t.Errorf("ToFix64Slice()[%d] = %v, %v; want %v, %v", i, values[i], errs[i], want[i], wantErrs[i])
+		}
+	}
+
+	// Appending converts a stream in chunks into the same buffer.
+	buf := AppendUFix128Slice(nil, usrc[:3])
+	buf = AppendUFix128Slice(buf, usrc[3:])
+	if !slices.Equal(buf, uwide) {
+		t.Errorf("AppendUFix128Slice() = %v; want %v", buf, uwide)
+	}
+}
+
+func TestByteOrder(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		var b [16]byte
+
+		BigEndian.PutFix128(b[:], Fix128(x))
+		if res := BigEndian.Fix128FromBytes(b[:]); res != Fix128(x) {
+			t.Errorf("BigEndian Fix128(%v) round trip through %x = %v", x, b, res)
+		}
+		if data, _ := Fix128(x).MarshalBinary(); !bytes.Equal(data, b[:]) {
+			t.Errorf("BigEndian.PutFix128(%v) = %x; want %x", x, b, data)
+		}
+		if data := BigEndian.AppendFix128([]byte{1}, Fix128(x)); !bytes.Equal(data[1:], b[:]) {
+			t.Errorf("BigEndian.AppendFix128(%v) = %x; want %x", x, data[1:], b)
+		}
+
+		LittleEndian.PutUFix128(b[:], UFix128(x))
+		if res := LittleEndian.UFix128FromBytes(b[:]); res != UFix128(x) {
+			t.Errorf("LittleEndian UFix128(%v) round trip through %x = %v", x, b, res)
+		}
+		if data := LittleEndian.AppendUFix128(nil, UFix128(x)); !bytes.Equal(data, b[:]) {
+			t.Errorf("LittleEndian.AppendUFix128(%v) = %x; want %x", x, data, b)
+		}
+
+		BigEndian.PutUFix64(b[:], UFix64(x.Lo))
+		if res := BigEndian.UFix64FromBytes(b[:]); res != UFix64(x.Lo) {
+			t.Errorf("BigEndian UFix64(%v) round trip through %x = %v", x.Lo, b[:8], res)
+		}
+
+		LittleEndian.PutFix64(b[:], Fix64(x.Lo))
+		if res := LittleEndian.Fix64FromBytes(b[:]); res != Fix64(x.Lo) {
+			t.Errorf("LittleEndian Fix64(%v) round trip through %x = %v", x.Lo, b[:8], res)
+		}
+		if data := LittleEndian.AppendFix64(nil, Fix64(x.Lo)); !bytes.Equal(data, b[:8]) {
+			t.Errorf("LittleEndian.AppendFix64(%v) = %x; want %x", x.Lo, data, b[:8])
+		}
+	}
+
+	v := NewUFix128(0x0102030405060708, 0x090a0b0c0d0e0f10)
+	if data := BigEndian.AppendUFix128(nil, v); hex.EncodeToString(data) != "0102030405060708090a0b0c0d0e0f10" {
+		t.Errorf("BigEndian.AppendUFix128(%v) = %x", v, data)
+	}
+	if data := LittleEndian.AppendUFix128(nil, v); hex.EncodeToString(data) != "100f0e0d0c0b0a090807060504030201" {
+		t.Errorf("LittleEndian.AppendUFix128(%v) = %x", v, data)
+	}
+
+	// Short slices panic without writing anything
+	b := make([]byte, 15)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("LittleEndian.PutUFix128() with a short slice didn't panic")
+			}
+		}()
+		LittleEndian.PutUFix128(b, UFix128Max)
+	}()
+	if !bytes.Equal(b, make([]byte, 15)) {
+		t.Errorf("LittleEndian.PutUFix128() with a short slice wrote %x", b)
+	}
+}
+
+func TestHash(t *testing.T) {
+
+	t.Parallel()
+
+	layouts := []struct {
+		value interface {
+			AppendHash([]byte) []byte
+			WriteTo(io.Writer) (int64, error)
+			HashInto(hash.Hash)
+		}
+		want string
+	}{
+		{UFix64One, "010000000005f5e100"},
+		{Fix64One, "020000000005f5e100"},
+		{Fix64(neg64(1)), "02ffffffffffffffff"},
+		{UFix128One, "03000000000000d3c21bcecceda1000000"},
+		{Fix128Min, "0480000000000000000000000000000000"},
+	}
+
+	for _, tc := range layouts {
+		if res := hex.EncodeToString(tc.value.AppendHash(nil)); res != tc.want {
+			t.Errorf("%v.AppendHash() = %s; want %s", tc.value, res, tc.want)
+		}
+
+		var buf bytes.Buffer
+		if n, err := tc.value.WriteTo(&buf); err != nil || n != int64(len(tc.want)/2) || hex.EncodeToString(buf.Bytes()) != tc.want {
+			t.Errorf("%v.WriteTo() = %x, %d, %v; want %s", tc.value, buf.Bytes(), n, err, tc.want)
+		}
+
+		h1, h2 := sha256.New(), sha256.New()
+		tc.value.HashInto(h1)
+		h2.Write(buf.Bytes())
+		if !bytes.Equal(h1.Sum(nil), h2.Sum(nil)) {
+			t.Errorf("%v.HashInto() = %x; want %x", tc.value, h1.Sum(nil), h2.Sum(nil))
+		}
+	}
+
+	// Equal raw values of different types hash differently
+	if bytes.Equal(UFix64One.AppendHash(nil), Fix64One.AppendHash(nil)) {
+		t.Errorf("UFix64One and Fix64One have the same hashing layout")
+	}
+}
+
+func TestHex(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		s := UFix128(x).ToHex()
+		if res, err := UFix128FromHex(s); err != nil || res != UFix128(x) || len(s) != 34 {
+			t.Errorf("UFix128(%v) hex round trip through %q = %v, %v", x, s, res, err)
+		}
+
+		s = Fix128(x).ToHex()
+		if res, err := Fix128FromHex(strings.ToUpper(s)); err != nil || res != Fix128(x) {
+			t.Errorf("Fix128(%v) hex round trip through %q = %v, %v", x, s, res, err)
+		}
+
+		s = UFix64(x.Lo).ToHex()
+		if res, err := UFix64FromHex(s); err != nil || res != UFix64(x.Lo) || len(s) != 18 {
+			t.Errorf("UFix64(%v) hex round trip through %q = %v, %v", x.Lo, s, res, err)
+		}
+
+		s = Fix64(x.Lo).ToHex()
+		if res, err := Fix64FromHex(s[2:]); err != nil || res != Fix64(x.Lo) {
+			t.Errorf("Fix64(%v) hex round trip through %q = %v, %v", x.Lo, s, res, err)
+		}
+	}
+
+	if s := UFix64One.ToHex(); s != "0x0000000005f5e100" {
+		t.Errorf("UFix64One.ToHex() = %q", s)
+	}
+	if s := Fix64(neg64(1e8)).ToHex(); s != "0xfffffffffa0a1f00" {
+		t.Errorf("Fix64(-1).ToHex() = %q", s)
+	}
+	if s := UFix128One.ToHex(); s != "0x000000000000d3c21bcecceda1000000" {
+		t.Errorf("UFix128One.ToHex() = %q", s)
+	}
+
+	for _, bad := range []string{"", "0x", "0x5f5e100", "0x00000000005f5e100", "0x000000000g5f5e100", "x0000000005f5e100",
+		"0x0000000005f5e10 ", "-0x0000000005f5e100", "0x000000000000d3c21bcecceda1000000"} {
+		if res, err := UFix64FromHex(bad); err != (SyntaxError{}) {
+			t.Errorf("UFix64FromHex(%q) = %v, %v; want SyntaxError", bad, res, err)
+		}
+	}
+	for _, bad := range []string{"0x0000000005f5e100", "0x000000000000d3c21bcecceda100000", "0x000000000000d3c21bcecceda10000000"} {
+		if res, err := UFix128FromHex(bad); err != (SyntaxError{}) {
+			t.Errorf("UFix128FromHex(%q) = %v, %v; want SyntaxError", bad, res, err)
+		}
+	}
+}
+
+func TestFloat64(t *testing.T) {
+
+	t.Parallel()
+
+	// ToFloat64 is correctly rounded, i.e. the same as parsing the exact decimal form.
+	for _, x := range edgeValues128 {
+		values := []interface {
+			String() string
+			ToFloat64() float64
+		}{UFix64(x.Lo), Fix64(x.Lo), UFix128(x), Fix128(x)}
+
+		for _, v := range values {
+			want, _ := strconv.ParseFloat(v.String(), 64)
+			if res := v.ToFloat64(); res != want {
+				t.Errorf("%v.ToFloat64() = %v; want %v", v, res, want)
+			}
+		}
+	}
+
+	tests := []struct {
+		f     float64
+		round RoundingMode
+		want  string
+		err   error
+	}{
+		{0, RoundTowardZero, "0.0", nil},
+		{math.Copysign(0, -1), RoundTowardZero, "0.0", nil},
+		{1.5, RoundTowardZero, "1.5", nil},
+		{0.1, RoundTowardZero, "0.1", nil},
+		{0.1, RoundNearestHalfEven, "0.1", nil},
+		{0.1, RoundAwayFromZero, "0.10000001", nil},
+		{0.3, RoundTowardZero, "0.29999999", nil},
+		{0.3, RoundNearestHalfEven, "0.3", nil},
+		{1e-9, RoundTowardZero, "0.0", UnderflowError{}},
+		{1e-9, RoundCeil, "0.00000001", nil},
+		{math.SmallestNonzeroFloat64, RoundAwayFromZero, "0.00000001", nil},
+		{123456789.125, RoundTowardZero, "123456789.125", nil},
+		{184467440737.09551615, RoundTowardZero, "0.0", PositiveOverflowError{}},
+		{1.9e11, RoundTowardZero, "0.0", PositiveOverflowError{}},
+		{math.MaxFloat64, RoundTowardZero, "0.0", PositiveOverflowError{}},
+		{-1, RoundTowardZero, "0.0", NegativeOverflowError{}},
+		{math.Inf(1), RoundTowardZero, "0.0", PositiveOverflowError{}},
+		{math.Inf(-1), RoundTowardZero, "0.0", NegativeOverflowError{}},
+		{math.NaN(), RoundTowardZero, "0.0", OutOfDomainErrorError{}},
+		{1, RoundingMode(255), "0.0", InvalidRoundingModeError{}},
+	}
+
+	for _, tc := range tests {
+		if res, err := UFix64FromFloat64(tc.f, tc.round); err != tc.err || res.String() != tc.want {
+			t.Errorf("UFix64FromFloat64(%v, %v) = %v, %v; want %s, %v", tc.f, tc.round, res, err, tc.want, tc.err)
+		}
+	}
+
+	// The 128-bit types see more of the float's exact value.
+	if res, err := UFix128FromFloat64(0.1, RoundTowardZero); err != nil || res.String() != "0.100000000000000005551115" {
+		t.Errorf("UFix128FromFloat64(0.1) = %v, %v", res, err)
+	}
+	if res, err := Fix128FromFloat64(-0.1, RoundFloor); err != nil || res.String() != "-0.100000000000000005551116" {
+		t.Errorf("Fix128FromFloat64(-0.1, RoundFloor) = %v, %v", res, err)
+	}
+	if res, err := Fix64FromFloat64(-2.5e-8, RoundNearestHalfEven); err != nil || res != Fix64(neg64(2)) {
+		t.Errorf("Fix64FromFloat64(-2.5e-8) = %v, %v", res, err)
+	}
+	if res, err := Fix64FromFloat64(-1e12, RoundTowardZero); err != (NegativeOverflowError{}) {
+		t.Errorf("Fix64FromFloat64(-1e12) = %v, %v; want NegativeOverflowError", res, err)
+	}
+	if res, err := Fix128FromFloat64(1<<100, RoundTowardZero); err != (PositiveOverflowError{}) {
+		t.Errorf("Fix128FromFloat64(2^100) = %v, %v; want PositiveOverflowError", res, err)
+	}
+}
+
+func TestBigConversions(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		// Raw values
+		if res := Fix128(x).ToBigInt(); res.Cmp(bigFromRaw128(x, true)) != 0 {
+			t.Errorf("Fix128(%v).ToBigInt() = %v; want %v", x, res, bigFromRaw128(x, true))
+		}
+		if res, err := Fix128FromBigInt(bigFromRaw128(x, true)); err != nil || res != Fix128(x) {
+			t.Errorf("Fix128FromBigInt(%v) = %v, %v; want %v", bigFromRaw128(x, true), res, err, Fix128(x))
+		}
+		if res, err := UFix128FromBigInt(UFix128(x).ToBigInt()); err != nil || res != UFix128(x) {
+			t.Errorf("UFix128FromBigInt(%v) = %v, %v; want %v", UFix128(x).ToBigInt(), res, err, UFix128(x))
+		}
+		if res, err := Fix64FromBigInt(Fix64(x.Lo).ToBigInt()); err != nil || res != Fix64(x.Lo) {
+			t.Errorf("Fix64FromBigInt(%v) = %v, %v; want %v", Fix64(x.Lo).ToBigInt(), res, err, Fix64(x.Lo))
+		}
+		if res, err := UFix64FromBigInt(UFix64(x.Lo).ToBigInt()); err != nil || res != UFix64(x.Lo) {
+			t.Errorf("UFix64FromBigInt(%v) = %v, %v; want %v", UFix64(x.Lo).ToBigInt(), res, err, UFix64(x.Lo))
+		}
+
+		// Exact values
+		if res, err := Fix128FromBigRat(Fix128(x).ToBigRat(), RoundTowardZero); err != nil || res != Fix128(x) {
+			t.Errorf("Fix128 big.Rat round trip of %v = %v, %v", x, res, err)
+		}
+		if res, err := UFix64FromBigRat(UFix64(x.Lo).ToBigRat(), RoundTowardZero); err != nil || res != UFix64(x.Lo) {
+			t.Errorf("UFix64 big.Rat round trip of %v = %v, %v", x.Lo, res, err)
+		}
+
+		// The default precision is enough to round trip with rounding to nearest.
+		if res, err := Fix128FromBigFloat(Fix128(x).ToBigFloat(0), RoundNearestHalfEven); err != nil || res != Fix128(x) {
+			t.Errorf("Fix128 big.Float round trip of %v = %v, %v", x, res, err)
+		}
+		if res, err := UFix128FromBigFloat(UFix128(x).ToBigFloat(0), RoundNearestHalfEven); err != nil || res != UFix128(x) {
+			t.Errorf("UFix128 big.Float round trip of %v = %v, %v", x, res, err)
+		}
+	}
+
+	// Rounding of rationals matches the reference for every mode.
+	dens := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3), big.NewInt(-7), new(big.Int).Lsh(big.NewInt(1), 70),
+		new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil), new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 130), big.NewInt(1))}
+
+	for _, x := range edgeValues128 {
+		num := bigFromRaw128(x, true)
+		for _, den := range dens {
+			r := new(big.Rat).SetFrac(num, den)
+			for _, round := range allRoundingModes {
+				want, wantErr := refRange(refQuo(new(big.Int).Mul(num, fix128ScaleBig), den, round), 128, true, num.Sign() != 0)
+				res, err := Fix128FromBigRat(r, round)
+				if err != wantErr || bigFromRaw128(raw128(res), false).Cmp(want) != 0 {
+					t.Errorf("Fix128FromBigRat(%v, %v) = %v, %v; want %v, %v", r, round, res, err, want, wantErr)
+				}
+
+				want, wantErr = refRange(refQuo(new(big.Int).Mul(num, big.NewInt(1e8)), den, round), 64, false, num.Sign() != 0)
+				res64, err := UFix64FromBigRat(r, round)
+				if err != wantErr || new(big.Int).SetUint64(uint64(res64)).Cmp(want) != 0 {
+					t.Errorf("UFix64FromBigRat(%v, %v) = %v, %v; want %v, %v", r, round, res64, err, want, wantErr)
+				}
+			}
+		}
+	}
+
+	// Out of range integers, infinities and invalid rounding modes
+	if res, err := UFix64FromBigInt(big.NewInt(-1)); err != (NegativeOverflowError{}) {
+		t.Errorf("UFix64FromBigInt(-1) = %v, %v; want NegativeOverflowError", res, err)
+	}
+	if res, err := Fix128FromBigInt(new(big.Int).Lsh(big.NewInt(-1), 127)); err != nil || res != Fix128Min {
+		t.Errorf("Fix128FromBigInt(-2^127) = %v, %v; want %v", res, err, Fix128Min)
+	}
+	if res, err := Fix128FromBigInt(new(big.Int).Lsh(big.NewInt(1), 127)); err != (PositiveOverflowError{}) {
+		t.Errorf("Fix128FromBigInt(2^127) = %v, %v; want PositiveOverflowError", res, err)
+	}
+	if res, err := UFix128FromBigInt(new(big.Int).Lsh(big.NewInt(-1), 200)); err != (NegativeOverflowError{}) {
+		t.Errorf("UFix128FromBigInt(-2^200) = %v, %v; want NegativeOverflowError", res, err)
+	}
+	if res, err := Fix64FromBigFloat(new(big.Float).SetInf(true), RoundTowardZero); err != (NegativeOverflowError{}) {
+		t.Errorf("Fix64FromBigFloat(-Inf) = %v, %v; want NegativeOverflowError", res, err)
+	}
+	if res, err := UFix64FromBigRat(big.NewRat(1, 3), RoundingMode(255)); err != (InvalidRoundingModeError{}) {
+		t.Errorf("UFix64FromBigRat(1/3, 255) = %v, %v; want InvalidRoundingModeError", res, err)
+	}
+
+	if res := UFix64One.ToBigFloat(53); res.String() != "1" || res.Prec() != 53 {
+		t.Errorf("UFix64One.ToBigFloat(53) = %v (prec %d)", res, res.Prec())
+	}
+}
+
+func TestMantScale(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		mant, scale := Fix128(x).MantScale()
+		if res, err := Fix128FromMantScale(mant, scale, RoundTowardZero); err != nil || res != Fix128(x) || scale != 24 {
+			t.Errorf("Fix128(%v) mant/scale round trip through %v, %d = %v, %v", x, mant, scale, res, err)
+		}
+
+		mant, scale = UFix64(x.Lo).MantScale()
+		if res, err := UFix64FromMantScale(mant, scale, RoundTowardZero); err != nil || res != UFix64(x.Lo) || scale != 8 {
+			t.Errorf("UFix64(%v) mant/scale round trip through %v, %d = %v, %v", x.Lo, mant, scale, res, err)
+		}
+	}
+
+	tests := []struct {
+		mant  int64
+		scale int
+		round RoundingMode
+		want  string
+		err   error
+	}{
+		{15, 1, RoundTowardZero, "1.5", nil},
+		{15, -3, RoundTowardZero, "15000.0", nil},
+		{123456789, 10, RoundTowardZero, "0.01234567", nil},
+		{123456789, 10, RoundNearestHalfAway, "0.01234568", nil},
+		{-1, 0, RoundTowardZero, "0.0", NegativeOverflowError{}},
+		{1, 9, RoundTowardZero, "0.0", UnderflowError{}},
+		{1, 1 << 40, RoundCeil, "0.00000001", nil},
+		{1, -1 << 40, RoundTowardZero, "0.0", PositiveOverflowError{}},
+		{0, -1 << 40, RoundTowardZero, "0.0", nil},
+		{1, 0, RoundingMode(255), "0.0", InvalidRoundingModeError{}},
+	}
+
+	for _, tc := range tests {
+		if res, err := UFix64FromMantScale(big.NewInt(tc.mant), tc.scale, tc.round); err != tc.err || res.String() != tc.want {
+			t.Errorf("UFix64FromMantScale(%d, %d, %v) = %v, %v; want %s, %v", tc.mant, tc.scale, tc.round, res, err, tc.want, tc.err)
+		}
+	}
+
+	if res, err := Fix128FromMantScale(big.NewInt(-25), 25, RoundNearestHalfEven); err != nil || res != Fix128(raw128{^raw64(0), ^raw64(1)}) {
+		t.Errorf("Fix128FromMantScale(-25, 25) = %v, %v; want -0.000000000000000000000002", res, err)
+	}
+}
+
+func TestWADRAY(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		// The 64-bit types and RAYs are exact in both directions.
+		if res, err := UFix64FromWAD(UFix64(x.Lo).ToWAD(), RoundTowardZero); err != nil || res != UFix64(x.Lo) {
+			t.Errorf("UFix64(%v) WAD round trip = %v, %v", x.Lo, res, err)
+		}
+		if res, err := Fix64FromRAY(Fix64(x.Lo).ToRAY(), RoundTowardZero); err != nil || res != Fix64(x.Lo) {
+			t.Errorf("Fix64(%v) RAY round trip = %v, %v", x.Lo, res, err)
+		}
+		if res, err := Fix128FromRAY(Fix128(x).ToRAY(), RoundTowardZero); err != nil || res != Fix128(x) {
+			t.Errorf("Fix128(%v) RAY round trip = %v, %v", x, res, err)
+		}
+		if res, err := UFix128FromRAY(UFix128(x).ToRAY(), RoundTowardZero); err != nil || res != UFix128(x) {
+			t.Errorf("UFix128(%v) RAY round trip = %v, %v", x, res, err)
+		}
+
+		// Converting a 128-bit value to a WAD rounds it as the reference does.
+		for _, round := range allRoundingModes {
+			num := bigFromRaw128(x, true)
+			want, wantErr := refQuo(num, big.NewInt(1e6), round), error(nil)
+			if want.Sign() == 0 && num.Sign() != 0 {
+				want, wantErr = nil, UnderflowError{}
+			}
+
+			res, err := Fix128(x).ToWAD(round)
+			if err != wantErr || (err == nil && res.Cmp(want) != 0) {
+				t.Errorf("Fix128(%v).ToWAD(%v) = %v, %v; want %v, %v", x, round, res, err, want, wantErr)
+			}
+
+			// Converting back is exact, unless rounding went out of range.
+			if err == nil {
+				want, wantErr := refRange(new(big.Int).Mul(res, big.NewInt(1e6)), 128, true, false)
+				if back, err := Fix128FromWAD(res); err != wantErr || bigFromRaw128(raw128(back), false).Cmp(want) != 0 {
+					t.Errorf("Fix128FromWAD(%v) = %v, %v; want %v, %v", res, back, err, want, wantErr)
+				}
+			}
+		}
+	}
+
+	if res := UFix64One.ToWAD(); res.String() != "1000000000000000000" {
+		t.Errorf("UFix64One.ToWAD() = %v", res)
+	}
+	if res := Fix64(neg64(1e8)).ToRAY(); res.String() != "-1000000000000000000000000000" {
+		t.Errorf("Fix64(-1).ToRAY() = %v", res)
+	}
+
+	half, _ := new(big.Int).SetString("1500000000000000000000000500", 10)
+	if res, err := UFix128FromRAY(half, RoundNearestHalfEven); err != nil || res != MustParseUFix128("1.5") {
+		t.Errorf("UFix128FromRAY(%v, HalfEven) = %v, %v", half, res, err)
+	}
+	if res, err := UFix128FromRAY(half, RoundNearestHalfAway); err != nil || res != MustParseUFix128("1.500000000000000000000001") {
+		t.Errorf("UFix128FromRAY(%v, HalfAway) = %v, %v", half, res, err)
+	}
+	if res, err := UFix64FromWAD(big.NewInt(1), RoundTowardZero); err != (UnderflowError{}) {
+		t.Errorf("UFix64FromWAD(1) = %v, %v; want UnderflowError", res, err)
+	}
+	if res, err := UFix128FromWAD(big.NewInt(-1)); err != (NegativeOverflowError{}) {
+		t.Errorf("UFix128FromWAD(-1) = %v, %v; want NegativeOverflowError", res, err)
+	}
+	if res, err := UFix128One.ToWAD(RoundingMode(255)); err != (InvalidRoundingModeError{}) {
+		t.Errorf("UFix128One.ToWAD(255) = %v, %v; want InvalidRoundingModeError", res, err)
+	}
+}
+
+func TestUint256(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		for _, decimals := range []uint8{24, 30, 62} {
+			w, err := UFix128(x).ToUint256(decimals, RoundTowardZero)
+			if res, err2 := UFix128FromUint256(w, decimals, RoundTowardZero); err != nil || err2 != nil || res != UFix128(x) {
+				t.Errorf("UFix128(%v) uint256 round trip with %d decimals through %x = %v, %v, %v", x, decimals, w, res, err, err2)
+			}
+		}
+
+		for _, decimals := range []uint8{8, 18, 65} {
+			w, err := UFix64(x.Lo).ToUint256(decimals, RoundTowardZero)
+			if res, err2 := UFix64FromUint256(w, decimals, RoundTowardZero); err != nil || err2 != nil || res != UFix64(x.Lo) {
+				t.Errorf("UFix64(%v) uint256 round trip with %d decimals through %x = %v, %v, %v", x.Lo, decimals, w, res, err, err2)
+			}
+		}
+	}
+
+	// 1.5 with 18 decimals, and the word order
+	if w, err := MustParseUFix64("1.5").ToUint256(18, RoundTowardZero); err != nil || w != [4]uint64{1.5e18, 0, 0, 0} {
+		t.Errorf("UFix64(1.5).ToUint256(18) = %x, %v", w, err)
+	}
+	if w, err := UFix128Max.ToUint256(24, RoundTowardZero); err != nil || w != [4]uint64{^uint64(0), ^uint64(0), 0, 0} {
+		t.Errorf("UFix128Max.ToUint256(24) = %x, %v", w, err)
+	}
+	if res, err := UFix128FromUint256([4]uint64{0, 0, 1, 0}, 24, RoundTowardZero); err != (PositiveOverflowError{}) {
+		t.Errorf("UFix128FromUint256(2^128) = %v, %v; want PositiveOverflowError", res, err)
+	}
+
+	// Fewer decimals round, and too many overflow
+	v := MustParseUFix128("1.000000000000000000000001")
+	if w, err := v.ToUint256(6, RoundCeil); err != nil || w != [4]uint64{1000001, 0, 0, 0} {
+		t.Errorf("%v.ToUint256(6, RoundCeil) = %x, %v", v, w, err)
+	}
+	if w, err := UFix128Iota.ToUint256(6, RoundTowardZero); err != (UnderflowError{}) {
+		t.Errorf("UFix128Iota.ToUint256(6) = %x, %v; want UnderflowError", w, err)
+	}
+	if w, err := UFix128Max.ToUint256(63, RoundTowardZero); err != (PositiveOverflowError{}) {
+		t.Errorf("UFix128Max.ToUint256(63) = %x, %v; want PositiveOverflowError", w, err)
+	}
+	if res, err := UFix64FromUint256([4]uint64{15, 0, 0, 0}, 9, RoundNearestHalfEven); err != nil || res != UFix64(2) {
+		t.Errorf("UFix64FromUint256(15e-9) = %v, %v; want 0.00000002", res, err)
+	}
+}
+
+func TestDuration(t *testing.T) {
+
+	t.Parallel()
+
+	for _, d := range []time.Duration{0, 1, -1, 10, -10, 15, time.Second, -90 * time.Minute, math.MaxInt64, math.MinInt64} {
+		want := new(big.Int).Mul(big.NewInt(int64(d)), pow10Big(Fix128Decimals-9))
+		_, unsignedErr := refRange(want, 128, false, false)
+
+		res128 := Fix128FromDuration(d)
+		if res128.ToBigInt().Cmp(want) != 0 {
+			t.Errorf("Fix128FromDuration(%v) = %v", d, res128)
+		}
+		if back, err := res128.ToDuration(RoundTowardZero); err != nil || back != d {
+			t.Errorf("Fix128FromDuration(%v).ToDuration() = %v, %v", d, back, err)
+		}
+		if res, err := UFix128FromDuration(d); err != unsignedErr || (err == nil && res != UFix128(res128)) {
+			t.Errorf("UFix128FromDuration(%v) = %v, %v", d, res, err)
+		}
+
+		for _, round := range allRoundingModes {
+			want := refQuo(big.NewInt(int64(d)), big.NewInt(10), round)
+			wantRaw, wantErr := refRange(want, 64, true, d != 0)
+			if res, err := Fix64FromDuration(d, round); err != wantErr || (err == nil && bigFromRaw64(uint64(res), false).Cmp(wantRaw) != 0) {
+				t.Errorf("Fix64FromDuration(%v, %v) = %v, %v; want %v, %v", d, round, res, err, wantRaw, wantErr)
+			}
+			_, wantErr = refRange(want, 64, false, d != 0)
+			if res, err := UFix64FromDuration(d, round); err != wantErr {
+				t.Errorf("UFix64FromDuration(%v, %v) = %v, %v; want %v", d, round, res, err, wantErr)
+			}
+		}
+	}
+
+	tests := []struct {
+		s     string
+		round RoundingMode
+		d     time.Duration
+		err   error
+	}{
+		{"1.5", RoundTowardZero, 1500 * time.Millisecond, nil},
+		{"-0.0000000015", RoundNearestHalfEven, -2, nil},
+		{"-0.0000000015", RoundCeil, -1, nil},
+		{"0.0000000004", RoundNearestHalfAway, 0, UnderflowError{}},
+		{"9223372036.854775807", RoundTowardZero, math.MaxInt64, nil},
+		{"9223372036.8547758075", RoundNearestHalfAway, 0, PositiveOverflowError{}},
+		{"-9223372036.854775808", RoundTowardZero, math.MinInt64, nil},
+		{"-9223372036.854775809", RoundTowardZero, 0, NegativeOverflowError{}},
+		{"1", RoundingMode(99), 0, InvalidRoundingModeError{}},
+	}
+
+	for _, tt := range tests {
+		if res, err := MustParseFix128(tt.s).ToDuration(tt.round); res != tt.d || err != tt.err {
+			t.Errorf("Fix128(%s).ToDuration(%v) = %v, %v; want %v, %v", tt.s, tt.round, res, err, tt.d, tt.err)
+		}
+	}
+
+	if res, err := MustParseFix64("-9223372036.85477581").ToDuration(); err != (NegativeOverflowError{}) {
+		t.Errorf("Fix64(-9223372036.85477581).ToDuration() = %v, %v; want NegativeOverflowError", res, err)
+	}
+	if res, err := MustParseUFix64("9223372036.8547758").ToDuration(); err != nil || res != 9223372036854775800 {
+		t.Errorf("UFix64(9223372036.8547758).ToDuration() = %v, %v", res, err)
+	}
+	if res, err := Fix64Min.ToDuration(); err != (NegativeOverflowError{}) {
+		t.Errorf("Fix64Min.ToDuration() = %v, %v; want NegativeOverflowError", res, err)
+	}
+}
+
+func TestMulDuration(t *testing.T) {
+
+	t.Parallel()
+
+	durations := []time.Duration{0, 1, -1, 7, 999999999, time.Second, -time.Second, 30 * 24 * time.Hour, math.MaxInt64, math.MinInt64}
+	nsPerSecond := big.NewInt(1e9)
+
+	for _, d := range durations {
+		dBig := big.NewInt(int64(d))
+
+		for _, x := range edgeValues128 {
+			for _, round := range allRoundingModes {
+				num := new(big.Int).Mul(bigFromRaw128(x, true), dBig)
+				want := refQuo(num, nsPerSecond, round)
+				wantRaw, wantErr := refRange(want, 128, true, num.Sign() != 0)
+				if res, err := Fix128(x).MulDuration(d, round); err != wantErr || (err == nil && bigFromRaw128(raw128(res), false).Cmp(wantRaw) != 0) {
+					t.Errorf("Fix128(%v).MulDuration(%v, %v) = %v, %v; want %v, %v", x, d, round, res, err, wantRaw, wantErr)
+				}
+
+				num = new(big.Int).Mul(bigFromRaw128(x, false), dBig)
+				want = refQuo(num, nsPerSecond, round)
+				wantRaw, wantErr = refRange(want, 128, false, num.Sign() != 0)
+				if res, err := UFix128(x).MulDuration(d, round); err != wantErr || (err == nil && bigFromRaw128(raw128(res), false).Cmp(wantRaw) != 0) {
+					t.Errorf("UFix128(%v).MulDuration(%v, %v) = %v, %v; want %v, %v", x, d, round, res, err, wantRaw, wantErr)
+				}
+			}
+		}
+
+		for _, x := range edgeValues64 {
+			for _, round := range allRoundingModes {
+				num := new(big.Int).Mul(bigFromRaw64(uint64(x), true), dBig)
+				want := refQuo(num, nsPerSecond, round)
+				wantRaw, wantErr := refRange(want, 64, true, num.Sign() != 0)
+				if res, err := Fix64(x).MulDuration(d, round); err != wantErr || (err == nil && bigFromRaw64(uint64(res), false).Cmp(wantRaw) != 0) {
+					t.Errorf("Fix64(%v).MulDuration(%v, %v) = %v, %v; want %v, %v", x, d, round, res, err, wantRaw, wantErr)
+				}
+
+				num = new(big.Int).Mul(bigFromRaw64(uint64(x), false), dBig)
+				want = refQuo(num, nsPerSecond, round)
+				wantRaw, wantErr = refRange(want, 64, false, num.Sign() != 0)
+				if res, err := UFix64(x).MulDuration(d, round); err != wantErr || (err == nil && bigFromRaw64(uint64(res), false).Cmp(wantRaw) != 0) {
+					t.Errorf("UFix64(%v).MulDuration(%v, %v) = %v, %v; want %v, %v", x, d, round, res, err, wantRaw, wantErr)
+				}
+			}
+		}
+	}
+
+	// 1 token per day, streamed for 1 hour
+	rate := MustParseUFix64("0.00001157")
+	if res, err := rate.MulDuration(time.Hour, RoundNearestHalfEven); err != nil || res != MustParseUFix64("0.04165200") {
+		t.Errorf("%v.MulDuration(1h) = %v, %v", rate, res, err)
+	}
+}
+
+// scale30 is a custom scale with more decimals than Fix128, for TestFix.
+type scale30 struct{}
+
+func (scale30) Decimals() int { return 30 }
+
+func TestFix(t *testing.T) {
+
+	t.Parallel()
+
+	// Mul and Div round once, with the scale of the type
+	for _, x := range edgeValues128 {
+		for _, y := range edgeValues128 {
+			for _, round := range allRoundingModes {
+				a, b := Fix[Scale6](x), Fix[Scale6](y)
+				num := new(big.Int).Mul(bigFromRaw128(x, true), bigFromRaw128(y, true))
+				want := refQuo(num, big.NewInt(1e6), round)
+				wantRaw, wantErr := refRange(want, 128, true, num.Sign() != 0)
+				if res, err := a.Mul(b, round); err != wantErr || (err == nil && bigFromRaw128(raw128(res), false).Cmp(wantRaw) != 0) {
+					t.Errorf("Fix[Scale6](%v).Mul(%v, %v) = %v, %v; want %v, %v", x, y, round, res, err, wantRaw, wantErr)
+				}
+
+				if isZero128(y) {
+					continue
+				}
+
+				num = new(big.Int).Mul(bigFromRaw128(x, true), big.NewInt(1e6))
+				want = refQuo(num, bigFromRaw128(y, true), round)
+				wantRaw, wantErr = refRange(want, 128, true, num.Sign() != 0)
+				if res, err := a.Div(b, round); err != wantErr || (err == nil && bigFromRaw128(raw128(res), false).Cmp(wantRaw) != 0) {
+					t.Errorf("Fix[Scale6](%v).Div(%v, %v) = %v, %v; want %v, %v", x, y, round, res, err, wantRaw, wantErr)
+				}
+			}
+		}
+	}
+
+	usdc := MustParseFix[Scale6]("1234.5")
+	if usdc != NewFix[Scale6](0, 1234500000) || usdc.String() != "1234.5" {
+		t.Errorf("MustParseFix[Scale6](1234.5) = %#v, %s", usdc, usdc)
+	}
+	if res, err := usdc.Mul(MustParseFix[Scale6]("0.000001"), RoundNearestHalfEven); err != nil || res.String() != "0.001234" {
+		t.Errorf("%v * 0.000001 = %v, %v", usdc, res, err)
+	}
+	if res, err := ParseFix[Scale6]("-0.0000015", RoundNearestHalfEven); err != nil || res.String() != "-0.000002" {
+		t.Errorf("ParseFix[Scale6](-0.0000015) = %v, %v", res, err)
+	}
+	if res, err := ParseFix[Scale2]("1.005", RoundCeil); err != nil || res.String() != "1.01" || !res.Gt(MustParseFix[Scale2]("1")) {
+		t.Errorf("ParseFix[Scale2](1.005, RoundCeil) = %v, %v", res, err)
+	}
+	if res, err := MustParseFix[Scale18]("-2.5").Mod(MustParseFix[Scale18]("1")); err != nil || res.String() != "-0.5" {
+		t.Errorf("Fix[Scale18](-2.5).Mod(1) = %v, %v", res, err)
+	}
+
+	// Metadata
+	var f Fix[Scale9]
+	if f.Decimals() != 9 || f.Scale().Cmp(big.NewInt(1e9)) != 0 || f.One() != NewFix[Scale9](0, 1e9) || !f.One().IsInteger() || f.Iota().IsInteger() {
+		t.Errorf("Fix[Scale9] metadata = %d, %v, %v", f.Decimals(), f.Scale(), f.One())
+	}
+	if (Fix[scale30]{}).One() != Fix[scale30](pow10Table128[30]) {
+		t.Errorf("Fix[scale30].One() = %#v", Fix[scale30]{}.One())
+	}
+
+	// Conversions to and from Fix128
+	if res, err := usdc.ToFix128(RoundTowardZero); err != nil || res != MustParseFix128("1234.5") {
+		t.Errorf("%v.ToFix128() = %v, %v", usdc, res, err)
+	}
+	if res, err := FixFromFix128[Scale6](MustParseFix128("-1.0000005"), RoundNearestHalfAway); err != nil || res != MustParseFix[Scale6]("-1.000001") {
+		t.Errorf("FixFromFix128[Scale6](-1.0000005) = %v, %v", res, err)
+	}
+	if res, err := FixFromFix128[Scale6](Fix128Iota, RoundTowardZero); err != (UnderflowError{}) {
+		t.Errorf("FixFromFix128[Scale6](Fix128Iota) = %v, %v; want UnderflowError", res, err)
+	}
+	if res, err := FixFromFix128[scale30](Fix128Max, RoundTowardZero); err != (PositiveOverflowError{}) {
+		t.Errorf("FixFromFix128[scale30](Fix128Max) = %v, %v; want PositiveOverflowError", res, err)
+	}
+	if res, err := (Fix[scale30]{}).Iota().ToFix128(RoundAwayFromZero); err != nil || res != Fix128Iota {
+		t.Errorf("Fix[scale30].Iota().ToFix128(RoundAwayFromZero) = %v, %v", res, err)
+	}
+
+	// Text encoding
+	var decoded struct{ Amount Fix[Scale6] }
+	if err := json.Unmarshal([]byte(`{"Amount":"-12.000034"}`), &decoded); err != nil || decoded.Amount.String() != "-12.000034" {
+		t.Errorf("json.Unmarshal(Fix[Scale6]) = %v, %v", decoded.Amount, err)
+	}
+	if err := json.Unmarshal([]byte(`{"Amount":"0.0000001"}`), &decoded); !errors.Is(err, InexactError{}) {
+		t.Errorf("json.Unmarshal(Fix[Scale6](0.0000001)) = %v, %v; want InexactError", decoded.Amount, err)
+	}
+}
+
+func TestWithScales(t *testing.T) {
+
+	t.Parallel()
+
+	scales := []uint8{0, 6, 24, 38, 60}
+	bValues := []raw128{{0, 0}, {0, 1}, {0, 3}, {0, 1e6}, {^raw64(0), ^raw64(0)}, {0x7fffffffffffffff, ^raw64(0)}, {0x8000000000000000, 0}, {1, 0}}
+
+	for _, x := range edgeValues128 {
+		for _, y := range bValues {
+			a, b := Fix128(x), Fix128(y)
+			aBig, bBig := bigFromRaw128(x, true), bigFromRaw128(y, true)
+
+			for _, aDecimals := range scales {
+				for _, bDecimals := range scales {
+					for _, outDecimals := range scales {
+						for _, round := range []RoundingMode{RoundTowardZero, RoundFloor, RoundNearestHalfEven} {
+							shift := int64(outDecimals) - int64(aDecimals) - int64(bDecimals)
+							num, den := new(big.Int).Mul(aBig, bBig), big.NewInt(1)
+							if shift > 0 {
+								num.Mul(num, pow10Big(shift))
+							} else {
+								den = pow10Big(-shift)
+							}
+							wantRaw, wantErr := refRange(refQuo(num, den, round), 128, true, num.Sign() != 0)
+							if res, err := MulWithScales(a, aDecimals, b, bDecimals, outDecimals, round); err != wantErr || (err == nil && bigFromRaw128(raw128(res), false).Cmp(wantRaw) != 0) {
+								t.Errorf("MulWithScales(%v, %d, %v, %d, %d, %v) = %v, %v; want %v, %v", x, aDecimals, y, bDecimals, outDecimals, round, res, err, wantRaw, wantErr)
+							}
+
+							if isZero128(y) {
+								if res, err := DivWithScales(a, aDecimals, b, bDecimals, outDecimals, round); err != (DivisionByZeroError{}) {
+									t.Errorf("DivWithScales(%v, %d, 0, %d, %d, %v) = %v, %v; want DivisionByZeroError", x, aDecimals, bDecimals, outDecimals, round, res, err)
+								}
+								continue
+							}
+
+							shift = int64(outDecimals) - int64(aDecimals) + int64(bDecimals)
+							num, den = new(big.Int).Set(aBig), new(big.Int).Set(bBig)
+							if shift > 0 {
+								num.Mul(num, pow10Big(shift))
+							} else {
+								den.Mul(den, pow10Big(-shift))
+							}
+							wantRaw, wantErr = refRange(refQuo(num, den, round), 128, true, num.Sign() != 0)
+							if res, err := DivWithScales(a, aDecimals, b, bDecimals, outDecimals, round); err != wantErr || (err == nil && bigFromRaw128(raw128(res), false).Cmp(wantRaw) != 0) {
+								t.Errorf("DivWithScales(%v, %d, %v, %d, %d, %v) = %v, %v; want %v, %v", x, aDecimals, y, bDecimals, outDecimals, round, res, err, wantRaw, wantErr)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// 1.5 USDC (6 decimals) at a price of 2.25 (18 decimals), in WAD
+	usdc := MustParseFix[Scale6]("1.5").Raw()
+	price := MustParseFix[Scale18]("2.25").Raw()
+	if res, err := MulWithScales(usdc, 6, price, 18, WADDecimals, RoundTowardZero); err != nil || res != MustParseFix[Scale18]("3.375").Raw() {
+		t.Errorf("MulWithScales(1.5, 2.25) = %v, %v", res, err)
+	}
+	if res, err := RescaleRaw(price, 18, 6, RoundTowardZero); err != nil || res != MustParseFix[Scale6]("2.25").Raw() {
+		t.Errorf("RescaleRaw(2.25, 18, 6) = %v, %v", res, err)
+	}
+	if res, err := RescaleRaw(NewFix128(0, 5), 1, 0, RoundNearestHalfEven); err != (UnderflowError{}) {
+		t.Errorf("RescaleRaw(0.5, 1, 0, RoundNearestHalfEven) = %v, %v; want UnderflowError", res, err)
+	}
+}
+
+func TestFix192(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues128 {
+		a, b := Fix128(x), Fix128(raw128{x.Lo, x.Hi})
+
+		// Conversions to and from Fix128 are exact
+		if res, err := a.ToFix192().ToFix128(RoundTowardZero); err != nil || res != a {
+			t.Errorf("Fix128(%v) Fix192 round trip = %v, %v", a, res, err)
+		}
+
+		if u, err := UFix128(x).ToFix192(); (err != nil) != isNeg64(x.Hi) {
+			t.Errorf("UFix128(%v).ToFix192() = %v, %v", x, u, err)
+		} else if res, err := u.ToUFix128(RoundTowardZero); err == nil && !isNeg64(x.Hi) && res != UFix128(x) {
+			t.Errorf("UFix128(%v) Fix192 round trip = %v", x, res)
+		}
+
+		// Add, Sub and comparisons match Fix128, since the low word is zero
+		sum, err := a.ToFix192().Add(b.ToFix192())
+		want, wantErr := a.Add(b)
+		if res, err2 := sum.ToFix128(RoundTowardZero); (err == nil) != (wantErr == nil) || (err == nil && (err2 != nil || res != want)) {
+			t.Errorf("Fix192 %v + %v = %v, %v; want %v, %v", a, b, res, err, want, wantErr)
+		}
+
+		diff, err := a.ToFix192().Sub(b.ToFix192())
+		want, wantErr = a.Sub(b)
+		if res, err2 := diff.ToFix128(RoundTowardZero); (err == nil) != (wantErr == nil) || (err == nil && (err2 != nil || res != want)) {
+			t.Errorf("Fix192 %v - %v = %v, %v; want %v, %v", a, b, res, err, want, wantErr)
+		}
+
+		if a.ToFix192().Lt(b.ToFix192()) != a.Lt(b) {
+			t.Errorf("Fix192 %v < %v = %v", a, b, !a.Lt(b))
+		}
+	}
+
+	if _, err := Fix192(fix192{Hi: 1 << 63}).Neg(); err != (NegativeOverflowError{}) {
+		t.Errorf("Fix192Min.Neg() = %v; want NegativeOverflowError", err)
+	}
+	if _, err := Fix192One.Neg(); err != nil {
+		t.Errorf("Fix192One.Neg() = %v", err)
+	}
+
+	// Rounding only happens on the final conversion: 1/3 * 3 rounds to exactly one, even though
+	// 1/3 isn't representable.
+	three := MustParseFix128("3").ToFix192()
+	third, _ := Fix192One.Div(three)
+	if res, err := must(third.Mul(three)).ToFix128(RoundNearestHalfEven); err != nil || res != Fix128One {
+		t.Errorf("1/3 * 3 = %v, %v; want 1", res, err)
+	}
+	if res, err := third.ToFix128(RoundCeil); err != nil || res != MustParseFix128("0.333333333333333333333334") {
+		t.Errorf("(1/3).ToFix128(RoundCeil) = %v, %v", res, err)
+	}
+	if res, err := must(third.Neg()).ToFix64(RoundFloor); err != nil || res != MustParseFix64("-0.33333334") {
+		t.Errorf("(-1/3).ToFix64(RoundFloor) = %v, %v", res, err)
+	}
+	if res, err := must(third.Neg()).ToUFix128(RoundTowardZero); err != (NegativeOverflowError{}) {
+		t.Errorf("(-1/3).ToUFix128() = %v, %v; want NegativeOverflowError", res, err)
+	}
+
+	// The transcendental functions agree with the Fix128 versions
+	x := MustParseFix128("2.5")
+	if res, err := must(x.ToFix192().Exp()).ToUFix128(RoundNearestHalfAway); err != nil || res != must(x.Exp()) {
+		t.Errorf("Fix192(%v).Exp() = %v, %v; want %v", x, res, err, must(x.Exp()))
+	}
+	if res, err := must(x.ToFix192().Ln()).ToFix128(RoundNearestHalfAway); err != nil || res != must(UFix128(x).Ln()) {
+		t.Errorf("Fix192(%v).Ln() = %v, %v; want %v", x, res, err, must(UFix128(x).Ln()))
+	}
+	if res, err := must(x.ToFix192().Sin()).ToFix128(RoundNearestHalfAway); err != nil || res != must(x.Sin()) {
+		t.Errorf("Fix192(%v).Sin() = %v, %v; want %v", x, res, err, must(x.Sin()))
+	}
+	if res, err := must(x.ToFix192().Cos()).ToFix128(RoundNearestHalfAway); err != nil || res != must(x.Cos()) {
+		t.Errorf("Fix192(%v).Cos() = %v, %v; want %v", x, res, err, must(x.Cos()))
+	}
+	if res, err := must(x.ToFix192().Pow(x.ToFix192())).ToUFix128(RoundNearestHalfAway); err != nil || res != must(UFix128(x).Pow(x)) {
+		t.Errorf("Fix192(%v).Pow(%v) = %v, %v; want %v", x, x, res, err, must(UFix128(x).Pow(x)))
+	}
+
+	// Ln(Exp(x)) == x, with only one rounding at the end
+	if res, err := must(must(x.ToFix192().Exp()).Ln()).ToFix128(RoundNearestHalfAway); err != nil || res != x {
+		t.Errorf("Ln(Exp(%v)) = %v, %v", x, res, err)
+	}
+
+	if _, err := must(x.Neg()).ToFix192().Ln(); err != (OutOfDomainErrorError{}) {
+		t.Errorf("Fix192(-2.5).Ln() = %v; want OutOfDomainErrorError", err)
+	}
+	if _, err := Fix192Zero.Pow(must(Fix192One.Neg())); err != (OutOfDomainErrorError{}) {
+		t.Errorf("0^-1 = %v; want OutOfDomainErrorError", err)
+	}
+	if _, err := Fix128Max.ToFix192().Exp(); err != (PositiveOverflowError{}) {
+		t.Errorf("Exp(Fix128Max) = %v; want PositiveOverflowError", err)
+	}
+
+	// 2x^2 - 3x + 1 at 2.5 is 6
+	coeffs := []Fix192{MustParseFix128("2").ToFix192(), MustParseFix128("-3").ToFix192(), Fix192One}
+	if res, err := must(x.ToFix192().Poly(coeffs)).ToFix128(RoundTowardZero); err != nil || res != MustParseFix128("6") {
+		t.Errorf("Poly(%v) = %v, %v; want 6", x, res, err)
+	}
+}
+
+func TestRawWords(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues64 {
+		if res := UFix64FromRaw(x).Raw(); res != x {
+			t.Errorf("UFix64FromRaw(%x).Raw() = %x", x, res)
+		}
+		if res := Fix64FromRaw(x).Raw(); res != x {
+			t.Errorf("Fix64FromRaw(%x).Raw() = %x", x, res)
+		}
+	}
+
+	for _, x := range edgeValues128 {
+		if hi, lo := UFix128FromRaw(uint64(x.Hi), uint64(x.Lo)).Words(); hi != uint64(x.Hi) || lo != uint64(x.Lo) {
+			t.Errorf("UFix128FromRaw(%x, %x).Words() = %x, %x", x.Hi, x.Lo, hi, lo)
+		}
+		if hi, lo := Fix128FromRaw(uint64(x.Hi), uint64(x.Lo)).Words(); hi != uint64(x.Hi) || lo != uint64(x.Lo) {
+			t.Errorf("Fix128FromRaw(%x, %x).Words() = %x, %x", x.Hi, x.Lo, hi, lo)
+		}
+	}
+
+	// The raw value is the value multiplied by the scale, in two's complement
+	if res := MustParseFix64("-0.00000001").Raw(); res != 0xffffffffffffffff {
+		t.Errorf("Fix64(-0.00000001).Raw() = %x", res)
+	}
+	if hi, lo := Fix128One.Words(); hi != 0xd3c2 || lo != 0x1bcecceda1000000 {
+		t.Errorf("Fix128One.Words() = %x, %x", hi, lo)
+	}
+	if w3, w2, w1, w0 := MustParseFix256("-1").Words(); w3 != ^uint64(0) || w2 != ^uint64(0) || NewFix256(w3, w2, w1, w0) != MustParseFix256("-1") {
+		t.Errorf("Fix256(-1).Words() = %x, %x, %x, %x", w3, w2, w1, w0)
+	}
+}
+
+func TestDecFloat(t *testing.T) {
+
+	t.Parallel()
+
+	// shifted returns num / den × 10^-shift, rounded as specified.
+	shifted := func(num, den *big.Int, shift int64, round RoundingMode) *big.Int {
+		if shift >= 0 {
+			return refQuo(num, new(big.Int).Mul(den, pow10Big(shift)), round)
+		}
+		return refQuo(new(big.Int).Mul(num, pow10Big(-shift)), den, round)
+	}
+
+	// check verifies that res is num / den × 10^exp (for raw mantissas), rounded to the most digits
+	// that fit in a mantissa.
+	check := func(op string, res DecFloat, err error, num, den *big.Int, exp int64, round RoundingMode) {
+		t.Helper()
+
+		if err != nil {
+			t.Errorf("%s = %v, %v", op, res, err)
+			return
+		}
+		if num.Sign() == 0 {
+			if !res.IsZero() {
+				t.Errorf("%s = %v; want 0", op, res)
+			}
+			return
+		}
+
+		shift := res.exp() - exp
+		if want := shifted(num, den, shift, round); want.Cmp(res.Mant.ToBigInt()) != 0 {
+			t.Errorf("%s = %v; want %se%d", op, res, want, res.exp())
+		}
+		if wider, err := refRange(shifted(num, den, shift-1, round), 128, true, false); err == nil && (den.Cmp(big.NewInt(1)) != 0 || shift > 0) {
+			t.Errorf("%s = %v; could keep another digit: %se%d", op, res, wider, res.exp()-1)
+		}
+	}
+
+	one := big.NewInt(1)
+	exps := []int32{0, -30, 17}
+
+	for _, round := range allRoundingModes {
+		for i, x := range edgeValues128 {
+			a := NewDecFloat(Fix128(x), exps[i%len(exps)])
+			for j, y := range edgeValues128 {
+				b := NewDecFloat(Fix128(y), exps[j%len(exps)])
+				ax, bx := a.Mant.ToBigInt(), b.Mant.ToBigInt()
+
+				res, err := a.Mul(b, round)
+				check(fmt.Sprintf("%v.Mul(%v, %v)", a, b, round), res, err, new(big.Int).Mul(ax, bx), one, a.exp()+b.exp(), round)
+
+				if b.IsZero() {
+					if _, err := a.Div(b, round); err != (DivisionByZeroError{}) {
+						t.Errorf("%v.Div(0) = %v; want DivisionByZeroError", a, err)
+					}
+					continue
+				}
+				res, err = a.Div(b, round)
+				check(fmt.Sprintf("%v.Div(%v, %v)", a, b, round), res, err, ax, bx, a.exp()-b.exp(), round)
+			}
+		}
+	}
+
+	// Values far outside the range of Fix128 are still exact in DecFloat.
+	big14 := MustParseFix128("100000000000000").ToDecFloat()
+	sq, _ := big14.Mul(big14, RoundTowardZero)
+	if res, err := sq.ToFix128(RoundTowardZero); err != (PositiveOverflowError{}) {
+		t.Errorf("%v.ToFix128() = %v, %v; want PositiveOverflowError", sq, res, err)
+	}
+	if q, err := sq.Div(NewDecFloat(Fix128One, 20), RoundTowardZero); err != nil {
+		t.Errorf("%v.Div(1e20) = %v, %v", sq, q, err)
+	} else if res, err := q.ToFix128(RoundTowardZero); err != nil || res != MustParseFix128("100000000") {
+		t.Errorf("%v.ToFix128() = %v, %v; want 100000000", q, res, err)
+	}
+
+	// 1/3*3 rounds back to 1, and tiny addends only affect the directed rounding modes.
+	third, _ := Fix128One.ToDecFloat().Div(MustParseFix128("3").ToDecFloat(), RoundNearestHalfEven)
+	prod, _ := third.Mul(MustParseFix128("3").ToDecFloat(), RoundNearestHalfEven)
+	if res, err := prod.ToFix128(RoundNearestHalfEven); err != nil || res != Fix128One {
+		t.Errorf("1/3*3 = %v, %v; want 1", res, err)
+	}
+	tiny := NewDecFloat(Fix128One, -1000)
+	for _, tt := range []struct {
+		round RoundingMode
+		want  string
+	}{
+		{RoundNearestHalfEven, "1"},
+		{RoundTowardZero, "1"},
+		{RoundAwayFromZero, "1.000000000000000000000001"},
+	} {
+		sum, err := Fix128One.ToDecFloat().Add(tiny, tt.round)
+		if err != nil {
+			t.Errorf("1 + 1e-1000 = %v, %v", sum, err)
+			continue
+		}
+		if res, err := sum.ToFix128(tt.round); err != nil || res != MustParseFix128(tt.want) {
+			t.Errorf("(1 + 1e-1000).ToFix128(%v) = %v, %v; want %s", tt.round, res, err, tt.want)
+		}
+	}
+	if diff, err := Fix128One.ToDecFloat().Sub(tiny, RoundTowardZero); err != nil {
+		t.Errorf("1 - 1e-1000 = %v, %v", diff, err)
+	} else if res, err := diff.ToFix128(RoundTowardZero); err != nil || res != MustParseFix128("0.999999999999999999999999") {
+		t.Errorf("(1 - 1e-1000).ToFix128(RoundTowardZero) = %v, %v", res, err)
+	}
+
+	// Additions with nearby exponents are exact when they fit.
+	a, b := NewDecFloat(MustParseFix128("1.5"), 3), NewDecFloat(MustParseFix128("-2.25"), -2)
+	if sum, err := a.Add(b, RoundNearestHalfEven); err != nil {
+		t.Errorf("%v.Add(%v) = %v, %v", a, b, sum, err)
+	} else if res, err := sum.ToFix128(RoundNearestHalfEven); err != nil || res != MustParseFix128("1499.9775") {
+		t.Errorf("%v.Add(%v).ToFix128() = %v, %v; want 1499.9775", a, b, res, err)
+	}
+	if s := a.String(); s != "1.5e3" {
+		t.Errorf("%v.String() = %q; want \"1.5e3\"", a, s)
+	}
+
+	// Conversions back round values below the last decimal, and check the range.
+	for _, x := range edgeValues128 {
+		if res, err := Fix128(x).ToDecFloat().ToFix128(RoundTowardZero); err != nil || res != Fix128(x) {
+			t.Errorf("%v.ToDecFloat().ToFix128() = %v, %v", Fix128(x), res, err)
+		}
+	}
+	if res, err := NewDecFloat(Fix128One, -25).ToFix128(RoundNearestHalfEven); err != (UnderflowError{}) {
+		t.Errorf("1e-25.ToFix128() = %v, %v; want UnderflowError", res, err)
+	}
+	if res, err := NewDecFloat(MustParseFix128("-1"), math.MinInt32).ToFix128(RoundFloor); err != nil || res != Fix128(neg128(raw128{0, 1})) {
+		t.Errorf("-1e-2147483648.ToFix128(RoundFloor) = %v, %v", res, err)
+	}
+	if res, err := NewDecFloat(MustParseFix128("-1"), math.MaxInt32).ToFix128(RoundFloor); err != (NegativeOverflowError{}) {
+		t.Errorf("-1e2147483647.ToFix128() = %v, %v; want NegativeOverflowError", res, err)
+	}
+	if res, err := NewDecFloat(Fix128One, math.MaxInt32).Mul(NewDecFloat(Fix128One, 1), RoundTowardZero); err != nil || res.Mant != MustParseFix128("100000000000000") {
+		t.Errorf("1e2147483647 * 10 = %v, %v; want 1e14e2147483634", res, err)
+	}
+	huge := NewDecFloat(MustParseFix128("-1"), math.MaxInt32)
+	if res, err := huge.Mul(huge.Neg(), RoundTowardZero); err != (NegativeOverflowError{}) {
+		t.Errorf("%v * %v = %v, %v; want NegativeOverflowError", huge, huge.Neg(), res, err)
+	}
+	if res, err := NewDecFloat(Fix128One, math.MinInt32).Div(huge, RoundTowardZero); err != (UnderflowError{}) {
+		t.Errorf("1e-2147483648 / %v = %v, %v; want UnderflowError", huge, res, err)
+	}
+	if neg := NewDecFloat(Fix128Min, 0).Neg(); neg.IsNeg() || neg.Exp != 1 {
+		t.Errorf("Fix128Min.Neg() = %v", neg)
+	}
+}
+
+func TestBigFix(t *testing.T) {
+
+	t.Parallel()
+
+	// Every operation agrees with Fix128 whenever the Fix128 result is in range, and the
+	// BigFix result is out of range (with the same sign) when it isn't.
+	agrees := func(op string, want Fix128, wantErr error, res BigFix, err error) {
+		t.Helper()
+
+		if _, over := wantErr.(PositiveOverflowError); over || wantErr == (NegativeOverflowError{}) {
+			if err != nil {
+				t.Errorf("%s = %v, %v; want a value out of range", op, res, err)
+			} else if got, err := res.ToFix128(); err != wantErr {
+				t.Errorf("%s.ToFix128() = %v, %v; want %v", op, got, err, wantErr)
+			}
+			return
+		}
+		if err != wantErr {
+			t.Errorf("%s = %v, %v; want %v, %v", op, res, err, want, wantErr)
+			return
+		}
+		if err == nil {
+			if got, err := res.ToFix128(); err != nil || got != want {
+				t.Errorf("%s = %v, %v; want %v", op, got, err, want)
+			}
+		}
+	}
+
+	for _, x := range edgeValues128 {
+		a := Fix128(x)
+		if s := a.ToBigFix().String(); s != a.String() {
+			t.Errorf("%v.ToBigFix().String() = %q; want %q", a, s, a.String())
+		}
+
+		// The text and JSON encodings match Fix128's and round trip.
+		text, _ := a.ToBigFix().MarshalText()
+		if want, _ := a.MarshalText(); string(text) != string(want) {
+			t.Errorf("%v.ToBigFix().MarshalText() = %q; want %q", a, text, want)
+		}
+		var back BigFix
+		if err := back.UnmarshalText(text); err != nil || !back.Eq(a.ToBigFix()) {
+			t.Errorf("UnmarshalText(%q) = %v, %v; want %v", text, back, err, a)
+		}
+		for _, format := range []JSONFormat{JSONString, JSONNumber} {
+			data := a.ToBigFix().AppendJSON(nil, format)
+			if want := a.AppendJSON(nil, format); string(data) != string(want) {
+				t.Errorf("%v.ToBigFix().AppendJSON(%v) = %s; want %s", a, format, data, want)
+			}
+			if err := json.Unmarshal(data, &back); err != nil || !back.Eq(a.ToBigFix()) {
+				t.Errorf("json.Unmarshal(%s) = %v, %v; want %v", data, back, err, a)
+			}
+		}
+
+		for _, round := range allRoundingModes {
+			want, wantErr := a.Sqrt(round)
+			res, err := a.ToBigFix().Sqrt(round)
+			agrees(fmt.Sprintf("%v.Sqrt(%v)", a, round), want, wantErr, res, err)
+		}
+
+		for _, y := range edgeValues128 {
+			b := Fix128(y)
+			want, wantErr := a.Add(b)
+			agrees(fmt.Sprintf("%v.Add(%v)", a, b), want, wantErr, a.ToBigFix().Add(b.ToBigFix()), nil)
+
+			want, wantErr = a.Mod(b)
+			res, err := a.ToBigFix().Mod(b.ToBigFix())
+			agrees(fmt.Sprintf("%v.Mod(%v)", a, b), want, wantErr, res, err)
+
+			for _, round := range allRoundingModes {
+				want, wantErr := a.Mul(b, round)
+				res, err := a.ToBigFix().Mul(b.ToBigFix(), round)
+				agrees(fmt.Sprintf("%v.Mul(%v, %v)", a, b, round), want, wantErr, res, err)
+
+				want, wantErr = a.Div(b, round)
+				res, err = a.ToBigFix().Div(b.ToBigFix(), round)
+				agrees(fmt.Sprintf("%v.Div(%v, %v)", a, b, round), want, wantErr, res, err)
+			}
+		}
+	}
+
+	// Parsing matches ParseFix128, and goes beyond its range.
+	inputs := []string{"0", "-0.0", "1.5", "-12.345", ".5", "5.", " 7 ", "1e3", "1.5E-10", "-2.5e-24",
+		"0.0000000000000000000000015", "-0.0000000000000000000000005", "1e-100", "-1e-1048576", "1e-2000000",
+		"170141183460469.231731687303715884105727", "123456789.1234567890123456789012345",
+		"", ".", "-", "e5", "1e", "1.2.3", "1_000", "abc"}
+	for _, s := range inputs {
+		for _, round := range allRoundingModes {
+			want, wantErr := ParseFix128(s, round)
+			res, err := ParseBigFix(s, round)
+			agrees(fmt.Sprintf("ParseBigFix(%q, %v)", s, round), want, wantErr, res, err)
+		}
+	}
+	if res, err := ParseBigFix("-1.5e40", RoundTowardZero); err != nil || res.String() != "-15000000000000000000000000000000000000000.0" {
+		t.Errorf("ParseBigFix(\"-1.5e40\") = %v, %v", res, err)
+	}
+
+	// Exponents beyond the limit are reported, rather than clamped.
+	for _, tc := range []struct {
+		s       string
+		wantErr error
+	}{
+		{"1e2000000", PositiveOverflowError{}},
+		{"-0.5e1048577", NegativeOverflowError{}},
+		{"0e2000000", nil},
+		{"1" + strings.Repeat("0", 1<<20) + "e-1048577", OutOfDomainErrorError{}},
+	} {
+		if res, err := ParseBigFix(tc.s, RoundTowardZero); err != tc.wantErr || (err == nil && !res.IsZero()) {
+			t.Errorf("ParseBigFix(%.20q) = %.20v, %v; want %v", tc.s, res, err, tc.wantErr)
+		}
+	}
+
+	// Intermediate results beyond Fix128 are kept exactly, and convert back when in range.
+	maxBig, ten := Fix128Max.ToBigFix(), MustParseFix128("1e10").ToBigFix()
+	scaled, _ := maxBig.Mul(ten, RoundTowardZero)
+	if res, err := scaled.ToFix128(); err != (PositiveOverflowError{}) {
+		t.Errorf("(Fix128Max * 1e10).ToFix128() = %v, %v; want PositiveOverflowError", res, err)
+	}
+	if back, err := scaled.Div(ten, RoundTowardZero); err != nil || !back.Eq(maxBig) {
+		t.Errorf("Fix128Max * 1e10 / 1e10 = %v, %v; want %v", back, err, maxBig)
+	}
+	if res, err := MustParseFix128("-2.5").ToBigFix().ToUFix128(); err != (NegativeOverflowError{}) {
+		t.Errorf("-2.5.ToUFix128() = %v, %v; want NegativeOverflowError", res, err)
+	}
+	if res, err := MustParseFix128("-2.123456785").ToBigFix().ToFix64(RoundNearestHalfEven); err != nil || res != MustParseFix64("-2.12345678") {
+		t.Errorf("-2.123456785.ToFix64(RoundNearestHalfEven) = %v, %v", res, err)
+	}
+	if res, err := UFix64Max.ToBigFix().ToUFix64(RoundTowardZero); err != nil || res != UFix64Max {
+		t.Errorf("UFix64Max.ToBigFix().ToUFix64() = %v, %v", res, err)
+	}
+
+	// The zero value is zero.
+	var zero BigFix
+	if !zero.IsZero() || zero.String() != "0.0" || !zero.Add(Fix128One.ToBigFix()).Eq(Fix128One.ToBigFix()) {
+		t.Errorf("BigFix{} = %v", zero)
+	}
+	if _, err := zero.Div(zero, RoundTowardZero); err != (DivisionByZeroError{}) {
+		t.Errorf("0 / 0 = %v; want DivisionByZeroError", err)
+	}
+
+	// Sqrt and Mod work beyond the range of Fix128.
+	if root, err := scaled.Sqrt(RoundTowardZero); err != nil {
+		t.Errorf("(Fix128Max * 1e10).Sqrt() = %v", err)
+	} else if sq, _ := root.Mul(root, RoundTowardZero); sq.Gt(scaled) {
+		t.Errorf("(Fix128Max * 1e10).Sqrt() = %v; its square %v exceeds the input", root, sq)
+	}
+	if res, err := scaled.Mod(maxBig); err != nil || !res.IsZero() {
+		t.Errorf("(Fix128Max * 1e10) %% Fix128Max = %v, %v; want 0", res, err)
+	}
+
+	// Strict text decoding rejects what it would have to round or trim.
+	for _, s := range []string{"0.0000000000000000000000015", " 1.5"} {
+		if err := zero.UnmarshalText([]byte(s)); err == nil {
+			t.Errorf("UnmarshalText(%q) = %v; want an error", s, zero)
+		}
+	}
+}
+
+func TestDot(t *testing.T) {
+
+	t.Parallel()
+
+	// A portfolio where rounding each price * quantity drifts by one unit per term.
+	prices := make([]UFix128, 1000)
+	quantities := make([]UFix128, 1000)
+	num := new(big.Int)
+	var drifted UFix128
+	for i := range prices {
+		prices[i] = MustParseUFix128("0.333333333333333333333333")
+		quantities[i] = MustParseUFix128(strconv.Itoa(i) + ".5")
+		num.Add(num, new(big.Int).Mul(prices[i].ToBigInt(), quantities[i].ToBigInt()))
+
+		term, _ := prices[i].Mul(quantities[i], RoundTowardZero)
+		drifted, _ = drifted.Add(term)
+	}
+	for _, round := range allRoundingModes {
+		want := refQuo(num, fix128ScaleBig, round)
+		res, err := DotUFix128(prices, quantities, round)
+		if err != nil || res.ToBigInt().Cmp(want) != 0 {
+			t.Errorf("DotUFix128(%v) = %v, %v; want raw %v", round, res, err, want)
+		}
+		if round == RoundTowardZero && res == drifted {
+			t.Errorf("DotUFix128() = %v; shouldn't match the per-term rounding", res)
+		}
+	}
+
+	// The dot products agree with SumOfProducts, including the signed cancellation.
+	var a, b []Fix128
+	var pairs [][2]Fix128
+	var ua, ub []UFix128
+	var upairs [][2]UFix128
+	for _, x := range edgeValues128 {
+		for _, y := range edgeValues128[:8] {
+			a, b = append(a, Fix128(x)), append(b, Fix128(y))
+			pairs = append(pairs, [2]Fix128{Fix128(x), Fix128(y)})
+			ua, ub = append(ua, UFix128(x)), append(ub, UFix128(y))
+			upairs = append(upairs, [2]UFix128{UFix128(x), UFix128(y)})
+
+			want, wantErr := SumOfProductsFix128(pairs, RoundNearestHalfEven)
+			if res, err := DotFix128(a, b, RoundNearestHalfEven); res != want || err != wantErr {
+				t.Errorf("DotFix128() = %v, %v; want %v, %v", res, err, want, wantErr)
+			}
+			uwant, uwantErr := SumOfProductsUFix128(upairs, RoundNearestHalfEven)
+			if res, err := DotUFix128(ua, ub, RoundNearestHalfEven); res != uwant || err != uwantErr {
+				t.Errorf("DotUFix128() = %v, %v; want %v, %v", res, err, uwant, uwantErr)
+			}
+		}
+	}
+
+	x64 := []Fix64{Fix64Max, Fix64Min, Fix64One}
+	y64 := []Fix64{Fix64Max, Fix64Max, 3}
+	// Fix64Max * (Fix64Max + Fix64Min) is -Fix64Max scaled down, which is -922.33720368 rounded
+	// toward zero.
+	if res, err := DotFix64(x64, y64, RoundTowardZero); err != nil || res != MustParseFix64("-922.33720365") {
+		t.Errorf("DotFix64(%v, %v) = %v, %v; want -922.33720365", x64, y64, res, err)
+	}
+	if res, err := DotUFix64([]UFix64{UFix64One, UFix64Max}, []UFix64{UFix64Max, UFix64One}, RoundTowardZero); err != (PositiveOverflowError{}) {
+		t.Errorf("DotUFix64(overflow) = %v, %v; want PositiveOverflowError", res, err)
+	}
+
+	if _, err := DotUFix128(prices, quantities[1:], RoundTowardZero); err != (OutOfDomainErrorError{}) {
+		t.Errorf("DotUFix128() with different lengths = %v; want OutOfDomainErrorError", err)
+	}
+	if _, err := DotFix64(x64, nil, RoundTowardZero); err != (OutOfDomainErrorError{}) {
+		t.Errorf("DotFix64() with different lengths = %v; want OutOfDomainErrorError", err)
+	}
+
+	// An invalid rounding mode is reported even when the dot product is trivially zero.
+	invalid := RoundingMode(99)
+	errs := make([]error, 4)
+	_, errs[0] = DotUFix64(nil, nil, invalid)
+	_, errs[1] = DotFix64([]Fix64{Fix64Zero}, []Fix64{Fix64One}, invalid)
+	_, errs[2] = DotUFix128(nil, nil, invalid)
+	_, errs[3] = DotFix128(nil, nil, invalid)
+	for i, err := range errs {
+		if err != (InvalidRoundingModeError{}) {
+			t.Errorf("case %d: Dot with an invalid rounding mode = %v; want InvalidRoundingModeError", i, err)
+		}
+	}
+}
+
+func TestMean(t *testing.T) {
+
+	t.Parallel()
+
+	// The mean of the edge values matches the exact reference, in every rounding mode, even
+	// though their sum overflows.
+	var signed []Fix128
+	var unsigned []UFix128
+	sum, usum := new(big.Int), new(big.Int)
+	for _, x := range edgeValues128 {
+		signed = append(signed, Fix128(x))
+		unsigned = append(unsigned, UFix128(x))
+		sum.Add(sum, Fix128(x).ToBigInt())
+		usum.Add(usum, UFix128(x).ToBigInt())
+	}
+	n := big.NewInt(int64(len(edgeValues128)))
+	for _, round := range allRoundingModes {
+		want, wantErr := refRange(refQuo(sum, n, round), 128, true, sum.Sign() != 0)
+		if res, err := Mean(signed, round); err != wantErr || (err == nil && res.ToBigInt().Cmp(want) != 0) {
+			t.Errorf("Mean(signed, %v) = %v, %v; want raw %v, %v", round, res, err, want, wantErr)
+		}
+		uwant, uwantErr := refRange(refQuo(usum, n, round), 128, false, true)
+		if res, err := Mean(unsigned, round); err != uwantErr || (err == nil && res.ToBigInt().Cmp(uwant) != 0) {
+			t.Errorf("Mean(unsigned, %v) = %v, %v; want raw %v, %v", round, res, err, uwant, uwantErr)
+		}
+	}
+
+	values := []Fix64{MustParseFix64("1"), MustParseFix64("2"), MustParseFix64("2")}
+	if res, err := Mean(values, RoundNearestHalfEven); err != nil || res != MustParseFix64("1.66666667") {
+		t.Errorf("Mean(%v) = %v, %v; want 1.66666667", values, res, err)
+	}
+	if res, err := Mean(values, RoundTowardZero); err != nil || res != MustParseFix64("1.66666666") {
+		t.Errorf("Mean(%v, RoundTowardZero) = %v, %v; want 1.66666666", values, res, err)
+	}
+	if res, err := Mean([]UFix64{UFix64Max, UFix64Max}, RoundTowardZero); err != nil || res != UFix64Max {
+		t.Errorf("Mean(UFix64Max, UFix64Max) = %v, %v", res, err)
+	}
+	if res, err := Mean([]Fix64{Fix64Iota, Fix64Zero, Fix64Zero}, RoundTowardZero); err != (UnderflowError{}) {
+		t.Errorf("Mean(iota, 0, 0) = %v, %v; want UnderflowError", res, err)
+	}
+	if res, err := Mean([]Fix64{Fix64One, MustParseFix64("-1")}, RoundTowardZero); err != nil || res != Fix64Zero {
+		t.Errorf("Mean(1, -1) = %v, %v; want 0", res, err)
+	}
+	if _, err := Mean([]UFix128(nil), RoundTowardZero); err != (DivisionByZeroError{}) {
+		t.Errorf("Mean(nil) = %v; want DivisionByZeroError", err)
+	}
+	if _, err := Mean(values, RoundingMode(99)); err != (InvalidRoundingModeError{}) {
+		t.Errorf("Mean(%v, 99) = %v; want InvalidRoundingModeError", values, err)
+	}
+
+	// Weighted means.
+	prices := []UFix128{MustParseUFix128("10"), MustParseUFix128("20"), MustParseUFix128("40")}
+	weights := []UFix128{MustParseUFix128("1"), MustParseUFix128("0.5"), MustParseUFix128("1.5")}
+	if res, err := WeightedMean(prices, weights, RoundNearestHalfEven); err != nil || res != MustParseUFix128("26.666666666666666666666667") {
+		t.Errorf("WeightedMean(%v, %v) = %v, %v", prices, weights, res, err)
+	}
+	if res, err := WeightedMean(prices, []UFix128{UFix128Zero, UFix128Zero, UFix128Max}, RoundTowardZero); err != nil || res != prices[2] {
+		t.Errorf("WeightedMean(max weight on last) = %v, %v; want %v", res, err, prices[2])
+	}
+	signedWeights := []Fix128{MustParseFix128("2"), MustParseFix128("-1")}
+	signedValues := []Fix128{MustParseFix128("3"), MustParseFix128("5")}
+	if res, err := WeightedMean(signedValues, signedWeights, RoundTowardZero); err != nil || res != MustParseFix128("1") {
+		t.Errorf("WeightedMean(%v, %v) = %v, %v; want 1", signedValues, signedWeights, res, err)
+	}
+	if _, err := WeightedMean(signedValues, []Fix128{Fix128One, MustParseFix128("-1")}, RoundTowardZero); err != (DivisionByZeroError{}) {
+		t.Errorf("WeightedMean() with zero total weight = %v; want DivisionByZeroError", err)
+	}
+	if _, err := WeightedMean(prices, weights[1:], RoundTowardZero); err != (OutOfDomainErrorError{}) {
+		t.Errorf("WeightedMean() with different lengths = %v; want OutOfDomainErrorError", err)
+	}
+	if res, err := WeightedMean([]Fix64{Fix64Max}, []Fix64{Fix64Min}, RoundTowardZero); err != nil || res != Fix64Max {
+		t.Errorf("WeightedMean(Fix64Max, Fix64Min) = %v, %v; want Fix64Max", res, err)
+	}
+}
+
+func TestAccumulator(t *testing.T) {
+
+	t.Parallel()
+
+	// Fees on a series of trades are rounded once, as for a dot product.
+	rate := MustParseUFix128("0.000333333333333333333333")
+	var fees Accumulator[UFix128]
+	var amounts, rates []UFix128
+	for i := 0; i < 1000; i++ {
+		amount := MustParseUFix128(strconv.Itoa(i) + ".5")
+		fees.AddProduct(amount, rate)
+		amounts, rates = append(amounts, amount), append(rates, rate)
+	}
+	for _, round := range allRoundingModes {
+		want, wantErr := DotUFix128(amounts, rates, round)
+		if res, err := fees.Value(round); res != want || err != wantErr {
+			t.Errorf("Accumulator.Value(%v) = %v, %v; want %v, %v", round, res, err, want, wantErr)
+		}
+	}
+
+	// The running total may go out of range (and negative for unsigned types) along the way.
+	var u Accumulator[UFix64]
+	u.Add(UFix64One)
+	u.Sub(MustParseUFix64("2"))
+	if res, err := u.Value(RoundTowardZero); err != (NegativeOverflowError{}) {
+		t.Errorf("Accumulator[UFix64](1 - 2) = %v, %v; want NegativeOverflowError", res, err)
+	}
+	u.Add(UFix64Max)
+	if res, err := u.Value(RoundTowardZero); err != nil || res != UFix64Max-UFix64One {
+		t.Errorf("Accumulator[UFix64](1 - 2 + max) = %v, %v", res, err)
+	}
+	u.Add(MustParseUFix64("2"))
+	if res, err := u.Value(RoundTowardZero); err != (PositiveOverflowError{}) {
+		t.Errorf("Accumulator[UFix64](1 - 2 + max + 2) = %v, %v; want PositiveOverflowError", res, err)
+	}
+	u.Reset()
+	if res, err := u.Value(RoundTowardZero); err != nil || res != UFix64Zero {
+		t.Errorf("Accumulator[UFix64] after Reset = %v, %v; want 0", res, err)
+	}
+
+	var s Accumulator[Fix64]
+	s.AddProduct(Fix64Max, Fix64Max)
+	s.SubProduct(Fix64Max, Fix64Max)
+	s.AddProduct(MustParseFix64("-0.5"), MustParseFix64("0.00000001"))
+	if res, err := s.Value(RoundNearestHalfAway); err != nil || res != Fix64(neg64(1)) {
+		t.Errorf("Accumulator[Fix64] = %v, %v; want -0.00000001", res, err)
+	}
+	if res, err := s.Value(RoundNearestHalfEven); err != (UnderflowError{}) {
+		t.Errorf("Accumulator[Fix64] = %v, %v; want UnderflowError", res, err)
+	}
+	s.Add(Fix64Min)
+	if res, err := s.Value(RoundFloor); err != (NegativeOverflowError{}) {
+		t.Errorf("Accumulator[Fix64] = %v, %v; want NegativeOverflowError", res, err)
+	}
+
+	var f Accumulator[Fix128]
+	for _, x := range edgeValues128 {
+		f.Add(Fix128(x))
+		f.SubProduct(Fix128(x), Fix128One)
+	}
+	f.SubProduct(MustParseFix128("1.5"), MustParseFix128("-2"))
+	if res, err := f.Value(RoundTowardZero); err != nil || res != MustParseFix128("3") {
+		t.Errorf("Accumulator[Fix128] = %v, %v; want 3", res, err)
+	}
+	if res, err := f.Value(RoundingMode(99)); err != (InvalidRoundingModeError{}) {
+		t.Errorf("Accumulator[Fix128].Value(99) = %v, %v; want InvalidRoundingModeError", res, err)
+	}
+
+	// The rounding mode is validated even when the total is zero.
+	var empty Accumulator[UFix64]
+	if res, err := empty.Value(RoundingMode(99)); err != (InvalidRoundingModeError{}) {
+		t.Errorf("Accumulator[UFix64]{}.Value(99) = %v, %v; want InvalidRoundingModeError", res, err)
+	}
+	f.Reset()
+	if res, err := f.Value(RoundingMode(99)); err != (InvalidRoundingModeError{}) {
+		t.Errorf("Accumulator[Fix128].Reset().Value(99) = %v, %v; want InvalidRoundingModeError", res, err)
+	}
+}
+
+func TestRunningStats(t *testing.T) {
+
+	t.Parallel()
+
+	var stats RunningStats[Fix128]
+	for _, s := range []string{"2", "4", "4", "4", "5", "5", "7", "9"} {
+		stats.Add(MustParseFix128(s))
+	}
+
+	check := func(name string, res Fix128, err error, want string) {
+		t.Helper()
+		if err != nil || res != MustParseFix128(want) {
+			t.Errorf("RunningStats.%s = %v, %v; want %s", name, res, err, want)
+		}
+	}
+
+	if n := stats.Count(); n != 8 {
+		t.Errorf("RunningStats.Count() = %d; want 8", n)
+	}
+	res, err := stats.Mean(RoundNearestHalfEven)
+	check("Mean()", res, err, "5")
+	res, err = stats.Variance(RoundNearestHalfEven)
+	check("Variance()", res, err, "4")
+	res, err = stats.Stddev(RoundNearestHalfEven)
+	check("Stddev()", res, err, "2")
+	res, err = stats.SampleVariance(RoundNearestHalfEven)
+	check("SampleVariance()", res, err, "4.571428571428571428571429")
+
+	// sqrt(32/7), rounded from a reference with 10 extra digits (which aren't near a tie).
+	for _, round := range allRoundingModes {
+		p := new(big.Int).Mul(big.NewInt(32), pow10Big(2*Fix128Decimals+20))
+		q := new(big.Int).Sqrt(new(big.Int).Quo(p, big.NewInt(7)))
+		want := refQuo(q, pow10Big(10), round)
+		if res, err := stats.SampleStddev(round); err != nil || res.ToBigInt().Cmp(want) != 0 {
+			t.Errorf("RunningStats.SampleStddev(%v) = %v, %v; want raw %v", round, res, err, want)
+		}
+	}
+
+	// The square root is rounded once, at the precision of T, including exact ties.
+	tests := []struct {
+		values []string
+		round  RoundingMode
+		want   string
+		err    error
+	}{
+		{[]string{"0", "0.00000001"}, RoundNearestHalfAway, "0.00000001", nil},
+		{[]string{"0", "0.00000001"}, RoundNearestHalfEven, "0", UnderflowError{}},
+		{[]string{"0", "0.00000001"}, RoundAwayFromZero, "0.00000001", nil},
+		{[]string{"0", "0.00000003"}, RoundNearestHalfEven, "0.00000002", nil},
+		{[]string{"0", "0.00000003"}, RoundTowardZero, "0.00000001", nil},
+		{[]string{"1.5", "1.5", "1.5"}, RoundNearestHalfEven, "0", nil},
+	}
+	for _, tt := range tests {
+		var s RunningStats[UFix64]
+		for _, v := range tt.values {
+			s.Add(MustParseUFix64(v))
+		}
+		if res, err := s.Stddev(tt.round); err != tt.err || (err == nil && res != MustParseUFix64(tt.want)) {
+			t.Errorf("RunningStats(%v).Stddev(%v) = %v, %v; want %s, %v", tt.values, tt.round, res, err, tt.want, tt.err)
+		}
+	}
+
+	// The totals are exact, so values over the whole range of T don't overflow, only results
+	// that are out of range do.
+	var prices RunningStats[UFix64]
+	prices.Add(UFix64Max)
+	prices.Add(UFix64Zero)
+	if res, err := prices.Mean(RoundNearestHalfEven); err != nil || res != 1<<63 {
+		t.Errorf("RunningStats(UFix64Max, 0).Mean() = %v, %v; want %v", res, err, UFix64(1<<63))
+	}
+	if res, err := prices.Stddev(RoundNearestHalfEven); err != nil || res != 1<<63 {
+		t.Errorf("RunningStats(UFix64Max, 0).Stddev() = %v, %v; want %v", res, err, UFix64(1<<63))
+	}
+	if res, err := prices.Variance(RoundNearestHalfEven); err != (PositiveOverflowError{}) {
+		t.Errorf("RunningStats(UFix64Max, 0).Variance() = %v, %v; want PositiveOverflowError", res, err)
+	}
+
+	var big64 RunningStats[Fix64]
+	big64.Add(Fix64Max)
+	if _, err := big64.SampleVariance(RoundTowardZero); err != (DivisionByZeroError{}) {
+		t.Errorf("RunningStats.SampleVariance() with one value = %v; want DivisionByZeroError", err)
+	}
+	big64.Add(Fix64Min)
+	if res, err := big64.Stddev(RoundTowardZero); big64.Count() != 2 || err != nil || res != Fix64Max {
+		t.Errorf("RunningStats(Fix64Max, Fix64Min).Stddev() = %v, %v; want %v", res, err, Fix64Max)
+	}
+	if res, err := big64.Stddev(RoundNearestHalfEven); err != (PositiveOverflowError{}) {
+		t.Errorf("RunningStats(Fix64Max, Fix64Min).Stddev(RoundNearestHalfEven) = %v, %v; want PositiveOverflowError", res, err)
+	}
+	if res, err := big64.Mean(RoundNearestHalfEven); err != (UnderflowError{}) {
+		t.Errorf("RunningStats(Fix64Max, Fix64Min).Mean() = %v, %v; want UnderflowError", res, err)
+	}
+
+	big64.Reset()
+	if _, err := big64.Mean(RoundTowardZero); big64.Count() != 0 || err != (DivisionByZeroError{}) {
+		t.Errorf("RunningStats.Mean() after Reset = %v; want DivisionByZeroError", err)
+	}
+	if _, err := big64.Stddev(RoundTowardZero); err != (DivisionByZeroError{}) {
+		t.Errorf("RunningStats.Stddev() after Reset = %v; want DivisionByZeroError", err)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+
+	values := []UFix64{MustParseUFix64("4"), MustParseUFix64("1"), MustParseUFix64("3"), MustParseUFix64("2")}
+	orig := slices.Clone(values)
+
+	tests := []struct {
+		p    string
+		mode PercentileMode
+		want string
+	}{
+		{"0", PercentileLinear, "1"},
+		{"100", PercentileLinear, "4"},
+		{"50", PercentileLinear, "2.5"},
+		{"10", PercentileLinear, "1.3"},
+		{"10", PercentileLower, "1"},
+		{"10", PercentileHigher, "2"},
+		{"10", PercentileNearest, "1"},
+		{"20", PercentileNearest, "2"},
+		{"50", PercentileNearest, "3"},
+		{"83.33333333", PercentileNearest, "3"},
+		{"10", PercentileMidpoint, "1.5"},
+		{"100", PercentileMidpoint, "4"},
+		{"33.33333333", PercentileLinear, "2"},
+	}
+
+	for _, tt := range tests {
+		res, err := Percentile(values, MustParseUFix64(tt.p), tt.mode, RoundNearestHalfEven)
+		if err != nil || res != MustParseUFix64(tt.want) {
+			t.Errorf("Percentile(%v, %s, %d) = %v, %v; want %s", values, tt.p, tt.mode, res, err, tt.want)
+		}
+	}
+	if !slices.Equal(values, orig) {
+		t.Errorf("Percentile modified its input: %v", values)
+	}
+
+	if res, err := Median(values[:3], RoundTowardZero); err != nil || res != MustParseUFix64("3") {
+		t.Errorf("Median(%v) = %v, %v; want 3", values[:3], res, err)
+	}
+	if _, err := Median([]Fix128{Fix128Min, Fix128Max}, RoundTowardZero); err != (UnderflowError{}) {
+		t.Errorf("Median(Fix128Min, Fix128Max) = %v; want UnderflowError", err)
+	}
+	if res, err := Median([]Fix128{Fix128Min, Fix128Max}, RoundFloor); err != nil || res != MustParseFix128("-0.000000000000000000000001") {
+		t.Errorf("Median(Fix128Min, Fix128Max, RoundFloor) = %v, %v; want -iota", res, err)
+	}
+
+	if _, err := Median([]Fix64{}, RoundTowardZero); err != (OutOfDomainErrorError{}) {
+		t.Errorf("Median([]) = %v; want OutOfDomainErrorError", err)
+	}
+	if _, err := Percentile(values, MustParseUFix64("100.00000001"), PercentileLinear, RoundTowardZero); err != (OutOfDomainErrorError{}) {
+		t.Errorf("Percentile(100.00000001) = %v; want OutOfDomainErrorError", err)
+	}
+	if _, err := Percentile(values, UFix64Zero, PercentileMidpoint+1, RoundTowardZero); err != (OutOfDomainErrorError{}) {
+		t.Errorf("Percentile with an invalid mode = %v; want OutOfDomainErrorError", err)
+	}
+	if _, err := Percentile(values, UFix64Zero, PercentileLinear, RoundingMode(-1)); err != (InvalidRoundingModeError{}) {
+		t.Errorf("Percentile with an invalid rounding mode = %v; want InvalidRoundingModeError", err)
+	}
+
+	// Linear interpolation between every pair of edge values matches the exact rational result.
+	den := big.NewInt(percentileRankScale)
+	for _, p := range []string{"0.00000001", "25.5", "50", "99.99999999"} {
+		pFix := MustParseUFix64(p)
+		r := big.NewInt(int64(pFix))
+		rest := new(big.Int).Sub(den, r)
+
+		for _, x := range edgeValues64 {
+			for _, y := range edgeValues64 {
+				a, b := Fix64(x), Fix64(y)
+				if b.Lt(a) {
+					continue
+				}
+				num := new(big.Int).Mul(big.NewInt(int64(a)), rest)
+				num.Add(num, new(big.Int).Mul(big.NewInt(int64(b)), r))
+
+				for _, round := range allRoundingModes {
+					res, err := Percentile([]Fix64{b, a}, pFix, PercentileLinear, round)
+					want, wantErr := refRange(refQuo(num, den, round), 64, true, num.Sign() != 0)
+					if err != wantErr || (err == nil && uint64(res) != want.Uint64()) {
+						t.Errorf("Percentile(%v, %v, %s, %v) = %v, %v; want %v, %v", a, b, p, round, res, err, want, wantErr)
+					}
+				}
+			}
+		}
+
+		for _, x := range edgeValues128 {
+			for _, y := range edgeValues128 {
+				a, b := Fix128(x), Fix128(y)
+				if b.Lt(a) {
+					continue
+				}
+				num := new(big.Int).Mul(bigFromRaw128(x, true), rest)
+				num.Add(num, new(big.Int).Mul(bigFromRaw128(y, true), r))
+
+				for _, round := range allRoundingModes {
+					res, err := Percentile([]Fix128{b, a}, pFix, PercentileLinear, round)
+					want, wantErr := refRange(refQuo(num, den, round), 128, true, num.Sign() != 0)
+					if err != wantErr || (err == nil && bigFromRaw128(raw128(res), false).Cmp(want) != 0) {
+						t.Errorf("Percentile(%v, %v, %s, %v) = %v, %v; want %v, %v", a, b, p, round, res, err, want, wantErr)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestTWAP(t *testing.T) {
+	t.Parallel()
+
+	ts := func(s string) UFix64 { return MustParseUFix64(s) }
+	v := func(s string) Fix128 { return MustParseFix128(s) }
+
+	twap := NewTWAP(ts("100"))
+
+	if _, err := twap.Average(ts("1"), RoundTowardZero); err != (OutOfDomainErrorError{}) {
+		t.Errorf("TWAP.Average() with no observations = %v; want OutOfDomainErrorError", err)
+	}
+	if _, _, ok := twap.Latest(); ok {
+		t.Errorf("TWAP.Latest() with no observations = true; want false")
+	}
+
+	// 2 from t=10, 5 from t=20, -1 from t=50 (and 3 from t=60, replacing 7).
+	for _, o := range []struct{ value, ts string }{{"2", "10"}, {"5", "20"}, {"-1", "50"}, {"7", "60"}, {"3", "60"}} {
+		if err := twap.Observe(v(o.value), ts(o.ts)); err != nil {
+			t.Fatalf("TWAP.Observe(%s, %s) = %v", o.value, o.ts, err)
+		}
+	}
+	if err := twap.Observe(v("1"), ts("59.99999999")); err != (OutOfDomainErrorError{}) {
+		t.Errorf("TWAP.Observe() before the latest observation = %v; want OutOfDomainErrorError", err)
+	}
+	if value, timestamp, ok := twap.Latest(); !ok || value != v("3") || timestamp != ts("60") {
+		t.Errorf("TWAP.Latest() = %v, %v, %v; want 3, 60, true", value, timestamp, ok)
+	}
+
+	tests := []struct {
+		window string
+		round  RoundingMode
+		want   string
+		err    error
+	}{
+		{"10", RoundTowardZero, "-1", nil},
+		{"40", RoundTowardZero, "3.5", nil},
+		// (2*5 + 5*30 - 10) / 45
+		{"45", RoundTowardZero, "3.333333333333333333333333", nil},
+		{"45", RoundAwayFromZero, "3.333333333333333333333334", nil},
+		{"50", RoundTowardZero, "3.2", nil},
+		{"0.00000001", RoundTowardZero, "-1", nil},
+		{"50.00000001", RoundTowardZero, "", OutOfDomainErrorError{}},
+		{"60.00000001", RoundTowardZero, "", OutOfDomainErrorError{}},
+		{"0", RoundTowardZero, "", DivisionByZeroError{}},
+		{"10", RoundingMode(-1), "", InvalidRoundingModeError{}},
+	}
+
+	for _, tt := range tests {
+		res, err := twap.Average(ts(tt.window), tt.round)
+		if err != tt.err || (err == nil && res != v(tt.want)) {
+			t.Errorf("TWAP.Average(%s, %v) = %v, %v; want %s, %v", tt.window, tt.round, res, err, tt.want, tt.err)
+		}
+	}
+
+	// Observations older than the largest window are discarded, except the one covering its start.
+	if err := twap.Observe(v("4"), ts("150")); err != nil {
+		t.Fatalf("TWAP.Observe(4, 150) = %v", err)
+	}
+	if len(twap.obs) != 3 {
+		t.Errorf("TWAP kept %d observations; want 3", len(twap.obs))
+	}
+	// (-1*10 + 3*90) / 100
+	if res, err := twap.Average(ts("100"), RoundTowardZero); err != nil || res != v("2.6") {
+		t.Errorf("TWAP.Average(100) = %v, %v; want 2.6", res, err)
+	}
+	if _, err := twap.Average(ts("100.00000001"), RoundTowardZero); err != (OutOfDomainErrorError{}) {
+		t.Errorf("TWAP.Average() beyond the retained window = %v; want OutOfDomainErrorError", err)
+	}
+
+	// The integral doesn't overflow, even for extreme values over long periods.
+	long := NewTWAP(UFix64Max)
+	long.Observe(Fix128Max, UFix64Zero)
+	long.Observe(Fix128Min, UFix64Max)
+	if res, err := long.Average(UFix64Max, RoundTowardZero); err != nil || res != Fix128Max {
+		t.Errorf("TWAP.Average() of Fix128Max = %v, %v; want Fix128Max", res, err)
+	}
+}
+
+func TestSeq(t *testing.T) {
+	t.Parallel()
+
+	one64 := Fix64One
+	negOne64, _ := one64.Neg()
+
+	if res, err := SumSeq(slices.Values([]Fix64{Fix64Max, one64, negOne64})); err != nil || res != Fix64Max {
+		t.Errorf("SumSeq(Fix64Max, 1, -1) = %v, %v; want Fix64Max", res, err)
+	}
+	if res, err := SumSeq(slices.Values([]UFix64{})); err != nil || res != UFix64Zero {
+		t.Errorf("SumSeq() = %v, %v; want 0", res, err)
+	}
+	if _, err := SumSeq(slices.Values([]UFix128{UFix128Max, UFix128Iota})); err != (PositiveOverflowError{}) {
+		t.Errorf("SumSeq(UFix128Max, iota) = %v; want PositiveOverflowError", err)
+	}
+	if _, err := SumSeq(slices.Values([]UFix128{UFix128One, UFix128Zero})); err != nil {
+		t.Errorf("SumSeq(1, 0) = %v; want nil", err)
+	}
+	if _, err := SumSeq(slices.Values([]Fix128{Fix128Min, MustParseFix128("-0.000000000000000000000001")})); err != (NegativeOverflowError{}) {
+		t.Errorf("SumSeq(Fix128Min, -iota) = %v; want NegativeOverflowError", err)
+	}
+
+	// The values 1..1000, generated without a slice.
+	count := func(n uint64) iter.Seq[UFix128] {
+		return func(yield func(UFix128) bool) {
+			for i := uint64(1); i <= n; i++ {
+				v, _ := UFix128FromUint64(i)
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+
+	if res, err := SumSeq(count(1000)); err != nil || res != MustParseUFix128("500500") {
+		t.Errorf("SumSeq(1..1000) = %v, %v; want 500500", res, err)
+	}
+	if res, err := MeanSeq(count(1000), RoundTowardZero); err != nil || res != MustParseUFix128("500.5") {
+		t.Errorf("MeanSeq(1..1000) = %v, %v; want 500.5", res, err)
+	}
+	if res, err := MeanSeq(count(3), RoundNearestHalfEven); err != nil || res != MustParseUFix128("2") {
+		t.Errorf("MeanSeq(1..3) = %v, %v; want 2", res, err)
+	}
+	if _, err := MeanSeq(count(0), RoundTowardZero); err != (DivisionByZeroError{}) {
+		t.Errorf("MeanSeq() = %v; want DivisionByZeroError", err)
+	}
+	if _, err := MeanSeq(count(1), RoundingMode(-1)); err != (InvalidRoundingModeError{}) {
+		t.Errorf("MeanSeq() with an invalid rounding mode = %v; want InvalidRoundingModeError", err)
+	}
+
+	// MeanSeq agrees with Mean, including the rounding.
+	values := []Fix64{MustParseFix64("-1"), MustParseFix64("0.00000001"), MustParseFix64("0.00000001")}
+	for _, round := range allRoundingModes {
+		want, wantErr := Mean(values, round)
+		if res, err := MeanSeq(slices.Values(values), round); res != want || err != wantErr {
+			t.Errorf("MeanSeq(%v, %v) = %v, %v; want %v, %v", values, round, res, err, want, wantErr)
+		}
+	}
+
+	lo, hi, err := MinMaxSeq(count(1000))
+	if err != nil || lo != UFix128One || hi != MustParseUFix128("1000") {
+		t.Errorf("MinMaxSeq(1..1000) = %v, %v, %v; want 1, 1000", lo, hi, err)
+	}
+	lo64, hi64, err := MinMaxSeq(slices.Values([]Fix64{one64, Fix64Max, Fix64Min, negOne64}))
+	if err != nil || lo64 != Fix64Min || hi64 != Fix64Max {
+		t.Errorf("MinMaxSeq() = %v, %v, %v; want Fix64Min, Fix64Max", lo64, hi64, err)
+	}
+	if _, _, err := MinMaxSeq(count(0)); err != (OutOfDomainErrorError{}) {
+		t.Errorf("MinMaxSeq() = %v; want OutOfDomainErrorError", err)
+	}
+}
+
+func TestElementwise(t *testing.T) {
+	t.Parallel()
+
+	u64 := func(ss ...string) []UFix64 {
+		res := make([]UFix64, len(ss))
+		for i, s := range ss {
+			res[i] = MustParseUFix64(s)
+		}
+		return res
+	}
+
+	a, b := u64("1", "2.5", "3"), u64("0.5", "0.5", "4")
+
+	dst := make([]UFix64, 3)
+	if index, err := AddSlices(dst, a, b); index != -1 || err != nil || !slices.Equal(dst, u64("1.5", "3", "7")) {
+		t.Errorf("AddSlices() = %v, %d, %v", dst, index, err)
+	}
+	if index, err := SubSlices(dst, b, a); index != 0 || err != (NegativeOverflowError{}) || !slices.Equal(dst, u64("1.5", "3", "7")) {
+		t.Errorf("SubSlices() = %v, %d, %v; want index 0, NegativeOverflowError, dst unchanged", dst, index, err)
+	}
+	if index, err := SubSlices(dst[:2], a[:2], b[:2]); index != -1 || err != nil || !slices.Equal(dst, u64("0.5", "2", "7")) {
+		t.Errorf("SubSlices() = %v, %d, %v", dst, index, err)
+	}
+	if index, err := MulSlices(dst, a, b, RoundTowardZero); index != -1 || err != nil || !slices.Equal(dst, u64("0.5", "1.25", "12")) {
+		t.Errorf("MulSlices() = %v, %d, %v", dst, index, err)
+	}
+
+	// Results are written in place, up to the first failure.
+	c := u64("1", "0.00000001", "5")
+	if index, err := MulSlices(c, c, u64("3", "0.5", "2"), RoundTowardZero); index != 1 || err != (UnderflowError{}) || !slices.Equal(c, u64("3", "0.00000001", "5")) {
+		t.Errorf("MulSlices() in place = %v, %d, %v; want index 1, UnderflowError", c, index, err)
+	}
+	if index, err := DivSlices(dst, a, u64("2", "0", "1"), RoundTowardZero); index != 1 || err != (DivisionByZeroError{}) || !slices.Equal(dst[:1], u64("0.5")) {
+		t.Errorf("DivSlices() = %v, %d, %v; want index 1, DivisionByZeroError", dst, index, err)
+	}
+
+	// Mismatched lengths are rejected up front.
+	dst = u64("9", "9", "9")
+	if index, err := AddSlices(dst, a, b[:2]); index != -1 || err != (OutOfDomainErrorError{}) || !slices.Equal(dst, u64("9", "9", "9")) {
+		t.Errorf("AddSlices() with mismatched lengths = %v, %d, %v; want OutOfDomainErrorError", dst, index, err)
+	}
+	if index, err := ScaleSlice(dst[:2], a, UFix64One, RoundTowardZero); index != -1 || err != (OutOfDomainErrorError{}) {
+		t.Errorf("ScaleSlice() with a short dst = %d, %v; want OutOfDomainErrorError", index, err)
+	}
+	if index, err := MulSlices(dst, a, b, RoundingMode(-1)); index != -1 || err != (InvalidRoundingModeError{}) {
+		t.Errorf("MulSlices() with an invalid rounding mode = %d, %v; want InvalidRoundingModeError", index, err)
+	}
+
+	// The signed and 128-bit types, with rounding.
+	x := []Fix128{MustParseFix128("1"), MustParseFix128("-2"), Fix128Max}
+	out := make([]Fix128, len(x))
+	third := MustParseFix128("0.333333333333333333333333")
+	if index, err := ScaleSlice(out, x[:2], third, RoundAwayFromZero); index != -1 || err != nil ||
+		!slices.Equal(out[:2], []Fix128{third, MustParseFix128("-0.666666666666666666666666")}) {
+		t.Errorf("ScaleSlice() = %v, %d, %v", out, index, err)
+	}
+	three := MustParseFix128("3")
+	if index, err := ScaleSliceFrac(out, x, Fix128One, three, RoundFloor); index != -1 || err != nil ||
+		!slices.Equal(out[:2], []Fix128{third, MustParseFix128("-0.666666666666666666666667")}) {
+		t.Errorf("ScaleSliceFrac() = %v, %d, %v", out, index, err)
+	}
+	if index, err := ScaleSliceFrac(out, x, three, Fix128One, RoundFloor); index != 2 || err != (PositiveOverflowError{}) {
+		t.Errorf("ScaleSliceFrac() = %d, %v; want index 2, PositiveOverflowError", index, err)
+	}
+}
+
+func TestMapParallel(t *testing.T) {
+	t.Parallel()
+
+	// Enough values for several chunks, including a partial one.
+	values := make([]UFix128, 3*parallelChunkSize+5)
+	for i := range values {
+		values[i], _ = UFix128FromUint64(uint64(i))
+	}
+	values[5] = UFix128Max
+	values[2*parallelChunkSize+1] = UFix128Max
+
+	price := MustParseUFix128("7")
+	scale := MustParseUFix128("1.5")
+	f := func(a UFix128) (UFix128, error) { return a.FMD(price, scale, RoundHalfEven) }
+
+	want := make([]UFix128, len(values))
+	wantErrs := make([]error, len(values))
+	for i, v := range values {
+		want[i], wantErrs[i] = f(v)
+	}
+
+	for _, workers := range []int{1, 3, 0, 100} {
+		res, errs := MapParallel(values, f, workers)
+		if !slices.Equal(res, want) || !slices.Equal(errs, wantErrs) {
+			t.Errorf("MapParallel(%d workers) doesn't match the serial results", workers)
+		}
+		if errs[5] != (PositiveOverflowError{}) || errs[2*parallelChunkSize+1] != (PositiveOverflowError{}) {
+			t.Errorf("MapParallel(%d workers) errors = %v, %v; want PositiveOverflowError", workers, errs[5], errs[2*parallelChunkSize+1])
+		}
+	}
+
+	// The errors are nil if every value succeeded.
+	res, errs := MapParallel(values[6:2*parallelChunkSize], f, 4)
+	if errs != nil || !slices.Equal(res, want[6:2*parallelChunkSize]) {
+		t.Errorf("MapParallel() without failures = %v errors", len(errs))
+	}
+
+	// The results can have a different type.
+	strs, errs := MapParallel(values[:3], func(a UFix128) (string, error) { return a.String(), nil }, 2)
+	if errs != nil || !slices.Equal(strs, []string{"0.0", "1.0", "2.0"}) {
+		t.Errorf("MapParallel() to strings = %v, %v", strs, errs)
+	}
+
+	if res, errs := MapParallel([]UFix128{}, f, 0); len(res) != 0 || errs != nil {
+		t.Errorf("MapParallel() of no values = %v, %v", res, errs)
+	}
+}
@@ -16,6 +16,8 @@
 
 package fixedPoint
 
+import "github.com/onflow/fixed-point/fixbits"
+
 // A 192-bit fixed-point type used for transcendental calculations. It's uses a scale factor of
 // 10**24 * 2**64. This means that the top 128 bites are a valid UFix128 value or Fix128 value, with
 // the bottom 64 bits being an extension of the fractional part for additional precision. Using the
@@ -26,6 +28,11 @@ type fix192 struct {
 	Hi, Mid, Lo raw64
 }
 
+// The fix192 code (umul() in particular) assumes that Fix128Scale is 10**24, these declarations
+// fail to compile if that constant changes.
+var _ [Fix128Scale - 1e24]struct{}
+var _ [1e24 - Fix128Scale]struct{}
+
 // Returns the absolute value of the fix192 value, and a sign integer (1 or -1) matching the original sign
 func (a fix192) abs() (fix192, int64) {
 	if isNeg64(a.Hi) {
@@ -122,7 +129,15 @@ func (a fix192) toUFix64(round RoundingMode) (UFix64, error) {
 		return UFix64Zero, PositiveOverflowError{}
 	}
 
-	scaledX, _ := div192by64(a.Hi, a.Mid, a.Lo, scaleFactor64To128)
+	scaledX, rem := div192by64(a.Hi, a.Mid, a.Lo, scaleFactor64To128)
+
+	// If the division wasn't exact, set the lowest bit of the result as a "sticky" bit, so that the
+	// rounding below can tell that the value is slightly larger than the truncated result. Without
+	// this, a value just above a tie would be treated as a tie, and a value just above a
+	// representable value wouldn't be rounded up by the directed rounding modes.
+	if !isZero128(rem) {
+		scaledX.Lo |= 1
+	}
 
 	// We've now scaled the fix192 value down to fit within the UFix64 range, but we still have
 	// the extra 64-bits of precision. We can truncate the last 64 bits the same way we do when
@@ -132,16 +147,26 @@ func (a fix192) toUFix64(round RoundingMode) (UFix64, error) {
 	var roundingAddend raw64
 
 	switch round {
-	case RoundTowardZero:
+	case RoundTowardZero, RoundFloor:
 		roundingAddend = raw64(0)
 	case RoundNearestHalfAway:
 		roundingAddend = raw64(0x8000000000000000)
 	case RoundNearestHalfEven:
-		roundingAddend = raw64(0x7fffffffffffffff + (a.Mid & 1))
-	case RoundAwayFromZero:
+		// Ties go to the even result, so the parity that matters is that of the scaled value, not
+		// of the input.
+		roundingAddend = raw64(0x7fffffffffffffff + (scaledX.Hi & 1))
+	case RoundNearestHalfTowardZero:
+		roundingAddend = raw64(0x7fffffffffffffff)
+	case RoundNearestHalfOdd:
+		roundingAddend = raw64(0x8000000000000000 - (scaledX.Hi & 1))
+	case RoundAwayFromZero, RoundCeil:
 		roundingAddend = raw64(0xffffffffffffffff)
+	case RoundStochastic:
+		// Adding a uniformly random value carries into the result with a probability equal
+		// to the fractional part being truncated.
+		roundingAddend = raw64(stochasticUint64())
 	default:
-		panic("invalid rounding mode")
+		return UFix64Zero, InvalidRoundingModeError{}
 	}
 
 	roundedX, carry := add128(scaledX, raw128{0, roundingAddend}, 0)
@@ -166,7 +191,7 @@ func (a fix192) toUFix64(round RoundingMode) (UFix64, error) {
 func (a fix192) toFix64(round RoundingMode) (Fix64, error) {
 	unsignedX, sign := a.abs()
 
-	res, err := unsignedX.toUFix64(round)
+	res, err := unsignedX.toUFix64(round.forSign(sign))
 
 	if err != nil {
 		return Fix64Zero, err
@@ -184,16 +209,24 @@ func (a fix192) toUFix128(round RoundingMode) (UFix128, error) {
 	var roundingAddend raw64
 
 	switch round {
-	case RoundTowardZero:
+	case RoundTowardZero, RoundFloor:
 		roundingAddend = raw64(0)
 	case RoundNearestHalfAway:
 		roundingAddend = raw64(0x8000000000000000)
 	case RoundNearestHalfEven:
 		roundingAddend = raw64(0x7fffffffffffffff + (a.Mid & 1))
-	case RoundAwayFromZero:
+	case RoundNearestHalfTowardZero:
+		roundingAddend = raw64(0x7fffffffffffffff)
+	case RoundNearestHalfOdd:
+		roundingAddend = raw64(0x8000000000000000 - (a.Mid & 1))
+	case RoundAwayFromZero, RoundCeil:
 		roundingAddend = raw64(0xffffffffffffffff)
+	case RoundStochastic:
+		// Adding a uniformly random value carries into the result with a probability equal
+		// to the fractional part being truncated.
+		roundingAddend = raw64(stochasticUint64())
 	default:
-		panic("invalid rounding mode")
+		return UFix128Zero, InvalidRoundingModeError{}
 	}
 
 	roundedX, carry := add192(a, fix192{0, 0, roundingAddend}, 0)
@@ -218,7 +251,7 @@ func (a fix192) toUFix128(round RoundingMode) (UFix128, error) {
 func (a fix192) toFix128(round RoundingMode) (Fix128, error) {
 	unsignedX, sign := a.abs()
 
-	unsignedRes, err := unsignedX.toUFix128(round)
+	unsignedRes, err := unsignedX.toUFix128(round.forSign(sign))
 
 	if err != nil {
 		return Fix128Zero, err
@@ -295,12 +328,6 @@ func (a fix192) umul(b fix192) (fix192, error) {
 	//    extends beyond the bottom 192 bits is less than 5**24 before the division.
 	// 2. After shifting down and checking for overflow, we divide the result by 5**24 and return.
 
-	// This check is here to detect any changes the Fix128Scale constant. It should compile to
-	// a no-op if the constant is matches our expectation, and will panic if it doesn't.
-	if Fix128Scale != 1e24 {
-		panic("fix192 assumes Fix128Scale equals 10e24")
-	}
-
 	rawProductLo = rawProductLo.ushiftRight(24)
 	rawProductLo.Hi |= shiftLeft64(rawProductHi.Lo, 40)
 	rawProductHi = ushiftRight128(rawProductHi, 24)
@@ -341,6 +368,91 @@ func (a fix192) smul(b fix192) (fix192, error) {
 	return resUnsigned.applySign(rSign)
 }
 
+// Divides two fix192 values, treating both as unsigned values. The result is rounded to the
+// nearest fix192 value (ties away from zero). Does not flag underflow and will simply return zero if
+// the quotient is too small to represent.
+func (a fix192) udiv(b fix192) (fix192, error) {
+	if b.isZero() {
+		return fix192Zero, DivisionByZeroError{}
+	}
+
+	// The quotient is a*S/b, where S (the scale factor of fix192) is 10**24 * 2**64. We compute the
+	// numerator as (a * 5**24) << 88, since 5**24 fits into 64 bits and the remaining factor of
+	// 2**88 is just a shift. Rather than materialising the full 344-bit numerator, we feed its bits
+	// into a simple shift-and-subtract long division one at a time, from the most significant bit
+	// down. This is slow compared to the other fix192 operations, but division is rare enough (and
+	// the code simple enough) that it's a reasonable trade-off.
+	xhi, hi, mid, lo := mul192by64(a, fiveToThe24)
+	words := [4]raw64{lo, mid, hi, xhi}
+
+	const shift = 88
+	numBits := uint64(256)
+
+	// Skip leading zero bits of the numerator, they can't contribute to the quotient.
+	for numBits > 0 && isZero64(words[numBits/64-1]) {
+		numBits -= 64
+	}
+	if numBits == 0 {
+		return fix192Zero, nil
+	}
+	numBits -= leadingZeroBits64(words[numBits/64-1])
+
+	var quo, rem fix192
+
+	for i := numBits + shift; i > 0; i-- {
+		var bit raw64
+		if i > shift {
+			j := i - shift - 1
+			bit = ushiftRight64(words[j/64], j%64) & 1
+		}
+
+		// Shift the next numerator bit into the remainder, keeping track of the bit that was
+		// shifted out of the top, since the remainder can temporarily need 193 bits.
+		remTop := ushiftRight64(rem.Hi, 63)
+		rem = rem.shiftLeft(1)
+		rem.Lo |= bit
+
+		var quoBit raw64
+		if !isZero64(remTop) || !rem.ult(b) {
+			rem = rem.sub(b)
+			quoBit = 1
+		}
+
+		if !isZero64(ushiftRight64(quo.Hi, 63)) {
+			return fix192Zero, PositiveOverflowError{}
+		}
+		quo = quo.shiftLeft(1)
+		quo.Lo |= quoBit
+	}
+
+	// Round to nearest, ties away from zero, i.e. round up if 2*rem >= b.
+	if !isZero64(ushiftRight64(rem.Hi, 63)) || !rem.shiftLeft(1).ult(b) {
+		var carry uint64
+		quo, carry = add192(quo, fix192Zero, 1)
+
+		if carry != 0 {
+			return fix192Zero, PositiveOverflowError{}
+		}
+	}
+
+	return quo, nil
+}
+
+// Performs division of two fix192 values, treating both as signed values.
+func (a fix192) sdiv(b fix192) (fix192, error) {
+	aUnsigned, aSign := a.abs()
+	bUnsigned, bSign := b.abs()
+	rSign := aSign * bSign
+
+	resUnsigned, err := aUnsigned.udiv(bUnsigned)
+
+	if err != nil {
+		return fix192Zero, applySign(err, rSign)
+	}
+
+	return resUnsigned.applySign(rSign)
+}
+
 // Perform integer multiplication of a fix192 value by a uint64 value, treating a as an unsigned
 // value. Does NOT handle overflow, so only use internally where overflow can't happen.
 func (a fix192) uintMul(b uint64) fix192 {
@@ -828,7 +940,7 @@ func (a fix192) clampAngle() (fix192, int64) {
 				// If we do the adjustment correctly, we should be flipping a negative value into a
 				// positive, which would result in the carry flag being set. If that flag isn't set,
 				// then something has gone horribly wrong!
-				panic("clampAngle: residual adjustment failed")
+				debugPanic("clampAngle: residual adjustment failed")
 			}
 		}
 
@@ -859,16 +971,6 @@ func add192(a, b fix192, carryIn uint64) (res fix192, carryOut uint64) {
 }
 
 func mul192by64(a fix192, b raw64) (xhi, hi, mid, lo raw64) {
-	var carry uint64
-
-	loHi, loLo := mul64(a.Lo, b)
-	midHi, midLo := mul64(a.Mid, b)
-	hiHi, hiLo := mul64(a.Hi, b)
-
-	lo = loLo
-	mid, carry = add64(loHi, midLo, 0)
-	hi, carry = add64(midHi, hiLo, carry)
-	xhi, _ = add64(hiHi, raw64Zero, carry)
-
-	return
+	p3, p2, p1, p0 := fixbits.Mul192By64(uint64(a.Hi), uint64(a.Mid), uint64(a.Lo), uint64(b))
+	return raw64(p3), raw64(p2), raw64(p1), raw64(p0)
 }
@@ -0,0 +1,66 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import "testing"
+
+// TestFix192ToUFix64HalfEven checks that half-even ties in fix192.toUFix64 go to the even UFix64
+// result. The tie-break used to look at the parity of the fix192 input, which is always even for
+// an exact tie, so odd results were never rounded up: 0.000000015 rounded to 0.00000001.
+func TestFix192ToUFix64HalfEven(t *testing.T) {
+
+	t.Parallel()
+
+	for _, tc := range []struct {
+		a    fix192
+		want UFix64
+	}{
+		{fix192{Mid: 15000000000000000}, 2}, // 1.5 iota
+		{fix192{Mid: 25000000000000000}, 2}, // 2.5 iota
+		{fix192{Mid: 35000000000000000}, 4}, // 3.5 iota
+	} {
+		if res, err := tc.a.toUFix64(RoundNearestHalfEven); err != nil || res != tc.want {
+			t.Errorf("%v.toUFix64(RoundNearestHalfEven) = %v, %v; want %v", tc.a, res, err, tc.want)
+		}
+	}
+}
+
+// TestFix192ToUFix64Sticky checks that fix192.toUFix64 doesn't lose the remainder of the division
+// that scales the value down. It used to be dropped, so a value just above a tie was rounded as a
+// tie (2.5 iota plus the smallest fix192 increment gave 2 with RoundNearestHalfEven), and a value
+// just above a representable value wasn't rounded up by RoundCeil or RoundAwayFromZero.
+func TestFix192ToUFix64Sticky(t *testing.T) {
+
+	t.Parallel()
+
+	for _, tc := range []struct {
+		a     fix192
+		round RoundingMode
+		want  UFix64
+	}{
+		{fix192{Mid: 25000000000000000, Lo: 1}, RoundNearestHalfEven, 3},
+		{fix192{Mid: 15000000000000000, Lo: 1}, RoundNearestHalfTowardZero, 2},
+		{fix192{Mid: 20000000000000000, Lo: 1}, RoundCeil, 3},
+		{fix192{Mid: 20000000000000000, Lo: 1}, RoundAwayFromZero, 3},
+		{fix192{Mid: 20000000000000000, Lo: 1}, RoundTowardZero, 2},
+		{fix192{Mid: 25000000000000000}, RoundNearestHalfEven, 2},
+	} {
+		if res, err := tc.a.toUFix64(tc.round); err != nil || res != tc.want {
+			t.Errorf("%v.toUFix64(%v) = %v, %v; want %v", tc.a, tc.round, res, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,677 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+// This file contains UFix256 and Fix256, 256-bit fixed-point types with the same scale as
+// UFix128 and Fix128 (24 decimals), for calculations whose intermediate values overflow the
+// 128-bit types, e.g. the x*y=k invariant of an AMM with 128-bit reserves. They're implemented
+// with the raw256 functions in raw256.go.
+//
+// They support a subset of the 128-bit API: comparisons, Add/Sub/Mul/Div/Mod/FMD/FMA and their
+// Checked, Wrap and Must variants, Sqrt, conversions to and from the 128-bit types, ToBigInt, and
+// the text, JSON, binary, gob, YAML, SQL and CBOR encodings. The following are NOT implemented for
+// them; convert to the 128-bit types (or use BigFix) where they're needed:
+//   - the transcendental functions (Ln, Exp, Pow, Sin, Cos, etc.),
+//   - fmt.Formatter (Format) and the other formatting helpers (FormatGrouped, FormatCurrency,
+//     FormatScientific, etc.), so only the fmt verbs that use String (e.g. %v and %s) print the
+//     decimal value,
+//   - SortKey, and the MessagePack, PostgreSQL numeric, protobuf, RLP, Arrow and GraphQL codecs,
+//   - the canonical hashing helpers (HashInto and AppendHash).
+
+// Fix256Decimals is the number of decimal places for Fix256 and UFix256, the same as for the
+// 128-bit types, so converting between them is exact.
+const Fix256Decimals = Fix128Decimals
+
+var UFix256Zero = UFix256{}
+var Fix256Zero = Fix256{}
+var UFix256One = UFix256{Lo: raw128(UFix128One)}
+var Fix256One = Fix256{Lo: raw128(Fix128One)}
+var UFix256Max = UFix256{Hi: raw128(UFix128Max), Lo: raw128(UFix128Max)}
+var Fix256Max = Fix256{Hi: raw128(Fix128Max), Lo: raw128(UFix128Max)}
+var Fix256Min = Fix256{Hi: raw128(Fix128Min), Lo: raw128Zero}
+var UFix256Iota = UFix256{Lo: raw128(UFix128Iota)}
+var Fix256Iota = Fix256{Lo: raw128(Fix128Iota)}
+
+// == Comparison Operators ==
+
+// Eq returns true if `a` and `b` are equal.
+func (a UFix256) Eq(b UFix256) bool { return isEqual256(raw256(a), raw256(b)) }
+func (a Fix256) Eq(b Fix256) bool   { return isEqual256(raw256(a), raw256(b)) }
+
+// Lt returns true if `a` is less than `b`
+func (a UFix256) Lt(b UFix256) bool { return ult256(raw256(a), raw256(b)) }
+func (a Fix256) Lt(b Fix256) bool   { return slt256(raw256(a), raw256(b)) }
+
+// Gt returns true if `a` is greater than `b`.
+func (a UFix256) Gt(b UFix256) bool { return b.Lt(a) }
+func (a Fix256) Gt(b Fix256) bool   { return b.Lt(a) }
+
+// Lte returns true if `a` is less than or equal to `b`.
+func (a UFix256) Lte(b UFix256) bool { return !a.Gt(b) }
+func (a Fix256) Lte(b Fix256) bool   { return !a.Gt(b) }
+
+// Gte returns true if `a` is greater than or equal to `b`.
+func (a UFix256) Gte(b UFix256) bool { return !a.Lt(b) }
+func (a Fix256) Gte(b Fix256) bool   { return !a.Lt(b) }
+
+// IsNeg returns true if `a` is negative.
+func (a Fix256) IsNeg() bool { return isNeg256(raw256(a)) }
+
+// IsInteger returns true if `a` is a whole number, i.e. it has no fractional part.
+func (a UFix256) IsInteger() bool { return isZero256(mod256(raw256(a), raw256(UFix256One))) }
+func (a Fix256) IsInteger() bool {
+	// The raw value of a negative number modulo the scale isn't meaningful, so check the magnitude.
+	aUnsigned, _ := a.Abs()
+
+	return aUnsigned.IsInteger()
+}
+
+// Iota returns the smallest positive value representable by the type of `a`, see UFix128.Iota.
+func (UFix256) Iota() UFix256 { return UFix256Iota }
+func (Fix256) Iota() Fix256   { return Fix256Iota }
+
+// IsZero returns true if `a` is zero.
+func (a UFix256) IsZero() bool { return isZero256(raw256(a)) }
+func (a Fix256) IsZero() bool  { return isZero256(raw256(a)) }
+
+// == Arithmetic Operators ==
+
+// Add returns the sum of `a` and `b`, or an error on overflow.
+func (a UFix256) Add(b UFix256) (UFix256, error) {
+	sum, carry := add256(raw256(a), raw256(b), 0)
+
+	if carry != 0 {
+		return UFix256Zero, PositiveOverflowError{}
+	}
+
+	return UFix256(sum), nil
+}
+
+// Add returns the sum of `a` and `b`, or an error on overflow or negative overflow.
+func (a Fix256) Add(b Fix256) (Fix256, error) {
+	sum, _ := add256(raw256(a), raw256(b), 0)
+
+	res := Fix256(sum)
+
+	// Check for overflow by checking the sign bits of the operands and the result.
+	if !a.IsNeg() && !b.IsNeg() && res.IsNeg() {
+		return Fix256Zero, PositiveOverflowError{}
+	} else if a.IsNeg() && b.IsNeg() && !res.IsNeg() {
+		return Fix256Zero, NegativeOverflowError{}
+	}
+
+	return res, nil
+}
+
+// Sub returns the difference of `a` and `b`, or an error on negative overflow.
+func (a UFix256) Sub(b UFix256) (UFix256, error) {
+	diff, borrow := sub256(raw256(a), raw256(b), 0)
+
+	if borrow != 0 {
+		return UFix256Zero, NegativeOverflowError{}
+	}
+
+	return UFix256(diff), nil
+}
+
+// Sub returns the difference of `a` and `b`, or an error on overflow or negative overflow.
+func (a Fix256) Sub(b Fix256) (Fix256, error) {
+	diff, _ := sub256(raw256(a), raw256(b), 0)
+
+	res := Fix256(diff)
+
+	// See Fix128.Sub
+	if !a.IsNeg() && b.IsNeg() && res.IsNeg() {
+		return Fix256Zero, PositiveOverflowError{}
+	} else if a.IsNeg() && !b.IsNeg() && !res.IsNeg() {
+		return Fix256Zero, NegativeOverflowError{}
+	}
+
+	return res, nil
+}
+
+// Neg returns `-a`, or a NegativeOverflowError if `a` is positive (for UFix256), or if `a` is
+// Fix256Min (as for Fix128.Neg, even though the true result is too large rather than too small).
+func (a UFix256) Neg() (UFix256, error) {
+	if a.IsZero() {
+		return UFix256Zero, nil
+	}
+
+	return UFix256Zero, NegativeOverflowError{}
+}
+
+func (a Fix256) Neg() (Fix256, error) {
+	if a == Fix256Min {
+		// Special case: negating the minimum value will overflow.
+		return Fix256Zero, NegativeOverflowError{}
+	}
+
+	return Fix256(neg256(raw256(a))), nil
+}
+
+// Abs returns the absolute value of `a` as an unsigned value, with a sign value as an int64, see
+// Fix128.Abs.
+func (a Fix256) Abs() (UFix256, int64) {
+	if a.IsNeg() {
+		return UFix256(neg256(raw256(a))), -1
+	}
+
+	return UFix256(a), 1
+}
+
+// ApplySign converts a UFix256 to a Fix256, applying the sign specified by the input.
+func (a UFix256) ApplySign(sign int64) (Fix256, error) {
+	if sign == 1 {
+		if a.Gt(UFix256(Fix256Max)) {
+			return Fix256Zero, PositiveOverflowError{}
+		}
+		return Fix256(a), nil
+	} else {
+		// See UFix128.ApplySign
+		if isEqual256(raw256(a), raw256(Fix256Min)) {
+			return Fix256Min, nil
+		}
+		if a.Gt(UFix256(Fix256Max)) {
+			return Fix256Zero, NegativeOverflowError{}
+		}
+
+		return Fix256(neg256(raw256(a))), nil
+	}
+}
+
+// Mul returns the product of `a` and `b`, or an error on overflow or underflow.
+func (a UFix256) Mul(b UFix256, round RoundingMode) (UFix256, error) {
+	// Same rationale for using FMD as for UFix128.Mul
+	return a.FMD(b, UFix256One, round)
+}
+
+// Mul returns the product of `a` and `b`, or an error on overflow or underflow.
+func (a Fix256) Mul(b Fix256, round RoundingMode) (Fix256, error) {
+	return a.FMD(b, Fix256One, round)
+}
+
+// Div returns the quotient of `a` and `b`, or an error on division by zero, overflow, or underflow.
+func (a UFix256) Div(b UFix256, round RoundingMode) (UFix256, error) {
+	return a.FMD(UFix256One, b, round)
+}
+
+// Div returns the quotient of `a` and `b`, or an error on division by zero, overflow, or underflow.
+func (a Fix256) Div(b Fix256, round RoundingMode) (Fix256, error) {
+	return a.FMD(Fix256One, b, round)
+}
+
+// FMD returns `a*b/c` without intermediate rounding, or an error on division by zero, overflow, or
+// underflow. The product is computed with 512 bits, so it can't overflow before the division.
+func (a UFix256) FMD(b, c UFix256, round RoundingMode) (UFix256, error) {
+	if !round.isValid() {
+		return UFix256Zero, InvalidRoundingModeError{}
+	}
+
+	// Must come before the check for a or b == 0 so we flag 0.0/0.0 as an error.
+	if c.IsZero() {
+		return UFix256Zero, DivisionByZeroError{}
+	}
+
+	if a.IsZero() || b.IsZero() {
+		return UFix256Zero, nil
+	}
+
+	hi, lo := mul256(raw256(a), raw256(b))
+
+	return udivRound256(hi, lo, raw256(c), round)
+}
+
+// FMD returns `a*b/c` without intermediate rounding, see UFix256.FMD.
+func (a Fix256) FMD(b, c Fix256, round RoundingMode) (Fix256, error) {
+	if !round.isValid() {
+		return Fix256Zero, InvalidRoundingModeError{}
+	}
+
+	if c.IsZero() {
+		return Fix256Zero, DivisionByZeroError{}
+	}
+
+	if a.IsZero() || b.IsZero() {
+		return Fix256Zero, nil
+	}
+
+	// Determine the sign of the result based on the signs of a, b, and c.
+	sign := int64(1)
+
+	aUnsigned, signMul := a.Abs()
+	sign *= signMul
+	bUnsigned, signMul := b.Abs()
+	sign *= signMul
+	cUnsigned, signMul := c.Abs()
+	sign *= signMul
+
+	// Compute the result using unsigned arithmetic.
+	res, err := aUnsigned.FMD(bUnsigned, cUnsigned, round.forSign(sign))
+
+	if err != nil {
+		return Fix256Zero, applySign(err, sign)
+	}
+
+	return res.ApplySign(sign)
+}
+
+// Mod returns the remainder of `a` divided by `b`, or an error on division by zero.
+func (a UFix256) Mod(b UFix256) (UFix256, error) {
+	if b.IsZero() {
+		return UFix256Zero, DivisionByZeroError{}
+	}
+
+	return UFix256(mod256(raw256(a), raw256(b))), nil
+}
+
+// Mod returns the remainder of `a` divided by `b`, the result matches the sign of `a` (as per Go's %
+// operator).
+func (a Fix256) Mod(b Fix256) (Fix256, error) {
+	if b.IsZero() {
+		return Fix256Zero, DivisionByZeroError{}
+	}
+
+	aUnsigned, aSign := a.Abs()
+	bUnsigned, _ := b.Abs()
+
+	rem, err := aUnsigned.Mod(bUnsigned)
+
+	if err != nil {
+		return Fix256Zero, err
+	}
+
+	return rem.ApplySign(aSign)
+}
+
+// FMA returns `a*b + c` without intermediate rounding, or an error on overflow or underflow.
+func (a UFix256) FMA(b, c UFix256, round RoundingMode) (UFix256, error) {
+	if !round.isValid() {
+		return UFix256Zero, InvalidRoundingModeError{}
+	}
+
+	if a.IsZero() || b.IsZero() {
+		return c, nil
+	}
+
+	hi, lo := mul256(raw256(a), raw256(b))
+
+	// Scale up `c` so that it can be added directly to the double-width product.
+	cHi, cLo := mul256(raw256(c), raw256(UFix256One))
+
+	var carry uint64
+	lo, carry = add256(lo, cLo, 0)
+	hi, carry = add256(hi, cHi, carry)
+
+	if carry != 0 {
+		return UFix256Zero, PositiveOverflowError{}
+	}
+
+	return udivRound256(hi, lo, raw256(UFix256One), round)
+}
+
+// FMA returns `a*b + c` without intermediate rounding, or an error on overflow, negative overflow,
+// or underflow, see Fix128.FMA.
+func (a Fix256) FMA(b, c Fix256, round RoundingMode) (Fix256, error) {
+	if !round.isValid() {
+		return Fix256Zero, InvalidRoundingModeError{}
+	}
+
+	if a.IsZero() || b.IsZero() {
+		return c, nil
+	}
+
+	aUnsigned, aSign := a.Abs()
+	bUnsigned, bSign := b.Abs()
+	cUnsigned, cSign := c.Abs()
+
+	sign := aSign * bSign
+
+	hi, lo := mul256(raw256(aUnsigned), raw256(bUnsigned))
+	cHi, cLo := mul256(raw256(cUnsigned), raw256(UFix256One))
+
+	if sign == cSign {
+		var carry uint64
+		lo, carry = add256(lo, cLo, 0)
+		hi, carry = add256(hi, cHi, carry)
+
+		if carry != 0 {
+			return Fix256Zero, applySign(PositiveOverflowError{}, sign)
+		}
+	} else {
+		if ult256(hi, cHi) || (isEqual256(hi, cHi) && ult256(lo, cLo)) {
+			hi, lo, cHi, cLo = cHi, cLo, hi, lo
+			sign = cSign
+		}
+
+		var borrow uint64
+		lo, borrow = sub256(lo, cLo, 0)
+		hi, _ = sub256(hi, cHi, borrow)
+
+		// The terms cancelled out exactly.
+		if isZero256(hi) && isZero256(lo) {
+			return Fix256Zero, nil
+		}
+	}
+
+	res, err := udivRound256(hi, lo, raw256(UFix256One), round.forSign(sign))
+
+	if err != nil {
+		return Fix256Zero, applySign(err, sign)
+	}
+
+	return res.ApplySign(sign)
+}
+
+// Sqrt returns the square root of `a`, rounded as specified. The result is computed exactly, so
+// it's correctly rounded in every mode (there are no ties, since the square root of a value that
+// isn't a perfect square is irrational).
+func (a UFix256) Sqrt(round RoundingMode) (UFix256, error) {
+	if !round.isValid() {
+		return UFix256Zero, InvalidRoundingModeError{}
+	}
+
+	if a.IsZero() {
+		return UFix256Zero, nil
+	}
+
+	// The raw result is the square root of the raw value times the scale, which has up to 336 bits.
+	xHi, xLo := mul256(raw256(a), raw256(UFix256One))
+	s, rem, remTop := usqrt512(xHi, xLo)
+
+	// The result is positive, so the directed modes are equivalent to the symmetric ones. For the
+	// nearest modes, the true root is above s + 1/2 iff x > s^2 + s + 1/4, i.e. iff rem > s.
+	up := false
+	switch round.forSign(1) {
+	case RoundTowardZero:
+	case RoundAwayFromZero:
+		up = remTop != 0 || !isZero256(rem)
+	default:
+		// RoundStochastic is treated as rounding to nearest, the same as in UFix128.Sqrt.
+		up = remTop != 0 || ult256(s, rem)
+	}
+
+	// The root of a value below 2^256 times the scale is far below 2^256, so this can't overflow.
+	if up {
+		s, _ = add256(s, raw256Zero, 1)
+	}
+
+	return UFix256(s), nil
+}
+
+// Sqrt returns the square root of `a`, or a domain error if `a` is negative.
+func (a Fix256) Sqrt(round RoundingMode) (Fix256, error) {
+	if a.IsNeg() {
+		return Fix256Zero, OutOfDomainErrorError{}
+	}
+
+	res, err := UFix256(a).Sqrt(round)
+
+	if err != nil {
+		return Fix256Zero, err
+	}
+
+	return Fix256(res), nil
+}
+
+// == Checked Operators ==
+//
+// The checked variants return a boolean instead of an error, see the Checked Operators of Fix128.
+
+// AddChecked returns the sum of `a` and `b`, and false on overflow.
+func (a UFix256) AddChecked(b UFix256) (UFix256, bool) {
+	sum, carry := add256(raw256(a), raw256(b), 0)
+
+	return UFix256(sum), carry == 0
+}
+
+// AddChecked returns the sum of `a` and `b`, and false on overflow or negative overflow.
+func (a Fix256) AddChecked(b Fix256) (Fix256, bool) {
+	sum, _ := add256(raw256(a), raw256(b), 0)
+
+	res := Fix256(sum)
+
+	// Overflow happened if both operands have the same sign, and the result has a different sign.
+	return res, a.IsNeg() != b.IsNeg() || a.IsNeg() == res.IsNeg()
+}
+
+// SubChecked returns the difference of `a` and `b`, and false on negative overflow.
+func (a UFix256) SubChecked(b UFix256) (UFix256, bool) {
+	diff, borrow := sub256(raw256(a), raw256(b), 0)
+
+	return UFix256(diff), borrow == 0
+}
+
+// SubChecked returns the difference of `a` and `b`, and false on overflow or negative overflow.
+func (a Fix256) SubChecked(b Fix256) (Fix256, bool) {
+	diff, _ := sub256(raw256(a), raw256(b), 0)
+
+	res := Fix256(diff)
+
+	// Overflow happened if the operands have different signs, and the result has a different
+	// sign from `a`.
+	return res, a.IsNeg() == b.IsNeg() || a.IsNeg() == res.IsNeg()
+}
+
+// MulChecked returns the product of `a` and `b`, and false on overflow or underflow.
+func (a UFix256) MulChecked(b UFix256, round RoundingMode) (UFix256, bool) {
+	res, err := a.Mul(b, round)
+
+	return res, err == nil
+}
+
+// MulChecked returns the product of `a` and `b`, and false on overflow or underflow.
+func (a Fix256) MulChecked(b Fix256, round RoundingMode) (Fix256, bool) {
+	res, err := a.Mul(b, round)
+
+	return res, err == nil
+}
+
+// DivChecked returns the quotient of `a` and `b`, and false on division by zero, overflow, or
+// underflow.
+func (a UFix256) DivChecked(b UFix256, round RoundingMode) (UFix256, bool) {
+	res, err := a.Div(b, round)
+
+	return res, err == nil
+}
+
+// DivChecked returns the quotient of `a` and `b`, and false on division by zero, overflow, or
+// underflow.
+func (a Fix256) DivChecked(b Fix256, round RoundingMode) (Fix256, bool) {
+	res, err := a.Div(b, round)
+
+	return res, err == nil
+}
+
+// == Wrapping Operators ==
+//
+// The wrapping variants never fail, and return the raw result modulo 2^256, see the Wrapping
+// Operators of Fix128.
+
+// AddWrap returns the sum of `a` and `b`, wrapping around on overflow.
+func (a UFix256) AddWrap(b UFix256) UFix256 {
+	sum, _ := add256(raw256(a), raw256(b), 0)
+
+	return UFix256(sum)
+}
+
+// AddWrap returns the sum of `a` and `b`, wrapping around on overflow or negative overflow.
+func (a Fix256) AddWrap(b Fix256) Fix256 {
+	sum, _ := add256(raw256(a), raw256(b), 0)
+
+	return Fix256(sum)
+}
+
+// SubWrap returns the difference of `a` and `b`, wrapping around on negative overflow.
+func (a UFix256) SubWrap(b UFix256) UFix256 {
+	diff, _ := sub256(raw256(a), raw256(b), 0)
+
+	return UFix256(diff)
+}
+
+// SubWrap returns the difference of `a` and `b`, wrapping around on overflow or negative overflow.
+func (a Fix256) SubWrap(b Fix256) Fix256 {
+	diff, _ := sub256(raw256(a), raw256(b), 0)
+
+	return Fix256(diff)
+}
+
+// MulWrap returns the product of `a` and `b`, rounded as specified, keeping only the low bits
+// of the raw result if the product overflows. Underflow results in zero. An invalid rounding mode
+// is treated as RoundTowardZero, and panics in debug builds.
+func (a UFix256) MulWrap(b UFix256, round RoundingMode) UFix256 {
+	if !round.isValid() {
+		debugPanic("MulWrap: invalid rounding mode")
+	}
+
+	hi, lo := mul256(raw256(a), raw256(b))
+
+	// Removing the multiples of the scale factor from the high part removes exactly the multiples
+	// of 2^256 from the quotient, see UFix128.MulWrap.
+	hi = mod256(hi, raw256(UFix256One))
+
+	quo, rem := div256(hi, lo, raw256(UFix256One))
+
+	if ushouldRound256(quo, rem, raw256(UFix256One), round) {
+		quo, _ = add256(quo, raw256Zero, 1)
+	}
+
+	return UFix256(quo)
+}
+
+// MulWrap returns the product of `a` and `b`, rounded as specified, keeping only the low bits
+// of the two's-complement representation if the product overflows. Underflow results in zero.
+// An invalid rounding mode is treated as RoundTowardZero, and panics in debug builds.
+func (a Fix256) MulWrap(b Fix256, round RoundingMode) Fix256 {
+	aUnsigned, aSign := a.Abs()
+	bUnsigned, bSign := b.Abs()
+	sign := aSign * bSign
+
+	res := raw256(aUnsigned.MulWrap(bUnsigned, round.forSign(sign)))
+
+	if sign < 0 {
+		res = neg256(res)
+	}
+
+	return Fix256(res)
+}
+
+// == Must Operators ==
+//
+// The Must variants panic instead of returning an error, see the Must Operators of Fix128.
+
+// MustAdd returns the sum of `a` and `b`, and panics on overflow.
+func (a UFix256) MustAdd(b UFix256) UFix256 { return must(a.Add(b)) }
+func (a Fix256) MustAdd(b Fix256) Fix256    { return must(a.Add(b)) }
+
+// MustSub returns the difference of `a` and `b`, and panics on overflow or negative overflow.
+func (a UFix256) MustSub(b UFix256) UFix256 { return must(a.Sub(b)) }
+func (a Fix256) MustSub(b Fix256) Fix256    { return must(a.Sub(b)) }
+
+// MustMul returns the product of `a` and `b`, and panics on overflow or underflow.
+func (a UFix256) MustMul(b UFix256, round RoundingMode) UFix256 { return must(a.Mul(b, round)) }
+func (a Fix256) MustMul(b Fix256, round RoundingMode) Fix256    { return must(a.Mul(b, round)) }
+
+// MustDiv returns the quotient of `a` and `b`, and panics on division by zero, overflow, or underflow.
+func (a UFix256) MustDiv(b UFix256, round RoundingMode) UFix256 { return must(a.Div(b, round)) }
+func (a Fix256) MustDiv(b Fix256, round RoundingMode) Fix256    { return must(a.Div(b, round)) }
+
+// MustFMD returns `a*b/c`, and panics on division by zero, overflow, or underflow.
+func (a UFix256) MustFMD(b, c UFix256, round RoundingMode) UFix256 { return must(a.FMD(b, c, round)) }
+func (a Fix256) MustFMD(b, c Fix256, round RoundingMode) Fix256    { return must(a.FMD(b, c, round)) }
+
+// udivRound256 divides the double-width, NON-ZERO value (hi, lo) by `y`, rounding the result as
+// specified, see udivRound128.
+func udivRound256(hi, lo, y raw256, round RoundingMode) (UFix256, error) {
+	if !round.isValid() {
+		return UFix256Zero, InvalidRoundingModeError{}
+	}
+
+	// If the hi part is >= the divisor the result can't fit in 256 bits.
+	if !ult256(hi, y) {
+		return UFix256Zero, PositiveOverflowError{}
+	}
+
+	quo, rem := div256(hi, lo, y)
+
+	if ushouldRound256(quo, rem, y, round) {
+		var carry uint64
+		quo, carry = add256(quo, raw256Zero, 1)
+
+		// Make sure we don't "round up" to a value outside of the range of UFix256!
+		if carry != 0 {
+			return UFix256Zero, PositiveOverflowError{}
+		}
+	}
+
+	// The numerator is non-zero, so a quotient of 0 means the result is too small to
+	// represent, i.e. underflow. Note that we check this AFTER rounding.
+	if isZero256(quo) {
+		return UFix256Zero, UnderflowError{}
+	}
+
+	return UFix256(quo), nil
+}
+
+// == Conversions ==
+
+// ToUFix256 converts `a` to a UFix256. This is always exact.
+func (a UFix128) ToUFix256() UFix256 { return UFix256{Lo: raw128(a)} }
+
+// ToFix256 converts `a` to a Fix256. This is always exact.
+func (a Fix128) ToFix256() Fix256 {
+	if a.IsNeg() {
+		// Sign-extend the high word.
+		return Fix256{Hi: raw128{^raw64Zero, ^raw64Zero}, Lo: raw128(a)}
+	}
+
+	return Fix256{Lo: raw128(a)}
+}
+
+// ToUFix128 converts `a` to a UFix128, or returns an error on overflow if `a` is larger than
+// UFix128Max.
+func (a UFix256) ToUFix128() (UFix128, error) {
+	if !isZero128(a.Hi) {
+		return UFix128Zero, PositiveOverflowError{}
+	}
+
+	return UFix128(a.Lo), nil
+}
+
+// ToFix128 converts `a` to a Fix128, or returns an overflow error if `a` is outside of the range
+// of Fix128.
+func (a Fix256) ToFix128() (Fix128, error) {
+	aUnsigned, sign := a.Abs()
+	if !isZero128(aUnsigned.Hi) {
+		return Fix128Zero, applySign(PositiveOverflowError{}, sign)
+	}
+
+	return UFix128(aUnsigned.Lo).ApplySign(sign)
+}
+
+// ToFix256 converts a UFix256 to a Fix256, or returns an error on overflow if `a` is larger than
+// Fix256Max.
+func (a UFix256) ToFix256() (Fix256, error) { return a.ApplySign(1) }
+
+// ToUFix256 converts a Fix256 to a UFix256, or returns an error on negative overflow if `a` is
+// negative.
+func (a Fix256) ToUFix256() (UFix256, error) {
+	if a.IsNeg() {
+		return UFix256Zero, NegativeOverflowError{}
+	}
+
+	return UFix256(a), nil
+}
@@ -0,0 +1,784 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+// edgeValues256 combines edge cases for each half of a 256-bit value, including the scale factor
+// (10^24), the signed limits, and values that only fit in the low half.
+var edgeValues256 = func() []raw256 {
+	halves := []raw128{
+		{0x0000000000000000, 0x0000000000000000},
+		{0x0000000000000000, 0x0000000000000001},
+		{0x000000000000d3c2, 0x1bcecceda1000000}, // 10^24
+		{0x0000000000000001, 0x0000000000000000},
+		{0x7fffffffffffffff, 0xffffffffffffffff},
+		{0x8000000000000000, 0x0000000000000000},
+		{0xffffffffffffffff, 0xffffffffffffffff},
+	}
+
+	var values []raw256
+	for _, hi := range halves {
+		for _, lo := range halves {
+			values = append(values, raw256{hi, lo})
+		}
+	}
+
+	return values
+}()
+
+// bigFromRaw256Signed converts a raw 256-bit value to a big.Int, interpreting it as signed or
+// unsigned.
+func bigFromRaw256Signed(x raw256, signed bool) *big.Int {
+	res := bigFromRaw256(x)
+	if signed && isNeg256(x) {
+		res.Sub(res, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+
+	return res
+}
+
+func TestArithmetic256(t *testing.T) {
+
+	t.Parallel()
+
+	check := func(op string, x, y raw256, signed bool, res raw256, err error, want *big.Int, nonZero bool) {
+		t.Helper()
+
+		wantRaw, wantErr := refRange(want, 256, signed, nonZero)
+		if err != wantErr || (err == nil && bigFromRaw256(res).Cmp(wantRaw) != 0) {
+			t.Errorf("%s(%x, %x) signed=%v = %x, %v; want %x, %v", op, x, y, signed, res, err, wantRaw, wantErr)
+		}
+	}
+
+	for _, x := range edgeValues256 {
+		for _, y := range edgeValues256 {
+			for _, signed := range []bool{false, true} {
+				a, b := bigFromRaw256Signed(x, signed), bigFromRaw256Signed(y, signed)
+				sum := new(big.Int).Add(a, b)
+				diff := new(big.Int).Sub(a, b)
+
+				if signed {
+					res, err := Fix256(x).Add(Fix256(y))
+					check("Add", x, y, true, raw256(res), err, sum, false)
+					res, err = Fix256(x).Sub(Fix256(y))
+					check("Sub", x, y, true, raw256(res), err, diff, false)
+				} else {
+					res, err := UFix256(x).Add(UFix256(y))
+					check("Add", x, y, false, raw256(res), err, sum, false)
+					res, err = UFix256(x).Sub(UFix256(y))
+					check("Sub", x, y, false, raw256(res), err, diff, false)
+				}
+
+				if b.Sign() != 0 {
+					rem := new(big.Int).Rem(a, b)
+					if signed {
+						res, err := Fix256(x).Mod(Fix256(y))
+						check("Mod", x, y, true, raw256(res), err, rem, false)
+					} else {
+						res, err := UFix256(x).Mod(UFix256(y))
+						check("Mod", x, y, false, raw256(res), err, rem, false)
+					}
+				}
+
+				for _, round := range allRoundingModes {
+					num := new(big.Int).Mul(a, b)
+					prod := refQuo(num, fix128ScaleBig, round)
+
+					var quo *big.Int
+					if b.Sign() != 0 {
+						quo = refQuo(new(big.Int).Mul(a, fix128ScaleBig), b, round)
+					}
+
+					if signed {
+						res, err := Fix256(x).Mul(Fix256(y), round)
+						check("Mul", x, y, true, raw256(res), err, prod, num.Sign() != 0)
+						if quo != nil {
+							res, err = Fix256(x).Div(Fix256(y), round)
+							check("Div", x, y, true, raw256(res), err, quo, a.Sign() != 0)
+						}
+					} else {
+						res, err := UFix256(x).Mul(UFix256(y), round)
+						check("Mul", x, y, false, raw256(res), err, prod, num.Sign() != 0)
+						if quo != nil {
+							res, err = UFix256(x).Div(UFix256(y), round)
+							check("Div", x, y, false, raw256(res), err, quo, a.Sign() != 0)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if res, err := UFix256One.Div(UFix256Zero, RoundTowardZero); err != (DivisionByZeroError{}) {
+		t.Errorf("UFix256One.Div(0) = %v, %v; want DivisionByZeroError", res, err)
+	}
+	if res, err := Fix256One.Mul(Fix256One, RoundingMode(99)); err != (InvalidRoundingModeError{}) {
+		t.Errorf("Fix256One.Mul(1, 99) = %v, %v; want InvalidRoundingModeError", res, err)
+	}
+	if res, err := Fix256Min.Neg(); err != (NegativeOverflowError{}) {
+		t.Errorf("Fix256Min.Neg() = %v, %v; want NegativeOverflowError", res, err)
+	}
+}
+
+func TestUsqrt512(t *testing.T) {
+
+	t.Parallel()
+
+	check := func(hi, lo raw256) {
+		t.Helper()
+
+		x := new(big.Int).Lsh(bigFromRaw256(hi), 256)
+		x.Add(x, bigFromRaw256(lo))
+		want := new(big.Int).Sqrt(x)
+		wantRem := new(big.Int).Sub(x, new(big.Int).Mul(want, want))
+
+		s, rem, remTop := usqrt512(hi, lo)
+		gotRem := new(big.Int).Add(bigFromRaw256(rem), new(big.Int).Lsh(new(big.Int).SetUint64(remTop), 256))
+		if bigFromRaw256(s).Cmp(want) != 0 || gotRem.Cmp(wantRem) != 0 {
+			t.Errorf("usqrt512(%x, %x) = %x, %x; want %x, %x", hi, lo, s, gotRem, want, wantRem)
+		}
+	}
+
+	for _, x := range edgeValues256 {
+		for _, y := range edgeValues256 {
+			// The value must be below 2^511.
+			check(ushiftRight256(x, 1), y)
+		}
+
+		// Perfect squares and their neighbours, up to the largest root allowed.
+		root := ushiftRight256(x, 1)
+		hi, lo := mul256(root, root)
+		check(hi, lo)
+		if !isZero256(root) {
+			lo, borrow := sub256(lo, raw256{Lo: raw128{Lo: 1}}, 0)
+			hi, _ := sub256(hi, raw256Zero, borrow)
+			check(hi, lo)
+		}
+	}
+}
+
+func TestOperators256(t *testing.T) {
+
+	t.Parallel()
+
+	modulus := new(big.Int).Lsh(big.NewInt(1), 256)
+
+	check := func(op string, x, y raw256, signed bool, res raw256, err error, want *big.Int, nonZero bool) {
+		t.Helper()
+
+		wantRaw, wantErr := refRange(want, 256, signed, nonZero)
+		if err != wantErr || (err == nil && bigFromRaw256(res).Cmp(wantRaw) != 0) {
+			t.Errorf("%s(%x, %x) signed=%v = %x, %v; want %x, %v", op, x, y, signed, res, err, wantRaw, wantErr)
+		}
+	}
+
+	checkOk := func(op string, x, y raw256, signed bool, res raw256, ok bool, want *big.Int) {
+		t.Helper()
+
+		wantRaw, wantErr := refRange(want, 256, signed, false)
+		if ok != (wantErr == nil) || (ok && bigFromRaw256(res).Cmp(wantRaw) != 0) {
+			t.Errorf("%s(%x, %x) signed=%v = %x, %v; want %x, %v", op, x, y, signed, res, ok, wantRaw, wantErr == nil)
+		}
+	}
+
+	checkWrap := func(op string, x, y raw256, res raw256, want *big.Int) {
+		t.Helper()
+
+		if wantRaw := new(big.Int).Mod(want, modulus); bigFromRaw256(res).Cmp(wantRaw) != 0 {
+			t.Errorf("%s(%x, %x) = %x; want %x", op, x, y, res, wantRaw)
+		}
+	}
+
+	c := raw256(UFix256One)
+	cBig := bigFromRaw256(c)
+
+	for _, x := range edgeValues256 {
+		for _, y := range edgeValues256 {
+			for _, signed := range []bool{false, true} {
+				a, b := bigFromRaw256Signed(x, signed), bigFromRaw256Signed(y, signed)
+				sum := new(big.Int).Add(a, b)
+				diff := new(big.Int).Sub(a, b)
+
+				if signed {
+					res, ok := Fix256(x).AddChecked(Fix256(y))
+					checkOk("AddChecked", x, y, true, raw256(res), ok, sum)
+					res, ok = Fix256(x).SubChecked(Fix256(y))
+					checkOk("SubChecked", x, y, true, raw256(res), ok, diff)
+					checkWrap("AddWrap", x, y, raw256(Fix256(x).AddWrap(Fix256(y))), sum)
+					checkWrap("SubWrap", x, y, raw256(Fix256(x).SubWrap(Fix256(y))), diff)
+				} else {
+					res, ok := UFix256(x).AddChecked(UFix256(y))
+					checkOk("AddChecked", x, y, false, raw256(res), ok, sum)
+					res, ok = UFix256(x).SubChecked(UFix256(y))
+					checkOk("SubChecked", x, y, false, raw256(res), ok, diff)
+					checkWrap("AddWrap", x, y, raw256(UFix256(x).AddWrap(UFix256(y))), sum)
+					checkWrap("SubWrap", x, y, raw256(UFix256(x).SubWrap(UFix256(y))), diff)
+				}
+
+				for _, round := range allRoundingModes {
+					// a*b + 1
+					num := new(big.Int).Mul(a, b)
+					num.Add(num, new(big.Int).Mul(cBig, fix128ScaleBig))
+					fma := refQuo(num, fix128ScaleBig, round)
+
+					prod := refQuo(new(big.Int).Mul(a, b), fix128ScaleBig, round)
+
+					if signed {
+						res, err := Fix256(x).FMA(Fix256(y), Fix256(c), round)
+						check("FMA", x, y, true, raw256(res), err, fma, num.Sign() != 0)
+						checkWrap("MulWrap", x, y, raw256(Fix256(x).MulWrap(Fix256(y), round)), prod)
+					} else {
+						res, err := UFix256(x).FMA(UFix256(y), UFix256(c), round)
+						check("FMA", x, y, false, raw256(res), err, fma, num.Sign() != 0)
+						checkWrap("MulWrap", x, y, raw256(UFix256(x).MulWrap(UFix256(y), round)), prod)
+					}
+				}
+			}
+		}
+
+		// Sqrt, checked against floor(sqrt(4n)) = floor(2*sqrt(n)), which gives the nearest
+		// integer to sqrt(n) as (floor(2*sqrt(n)) + 1) / 2.
+		n := new(big.Int).Mul(bigFromRaw256(x), fix128ScaleBig)
+		floor := new(big.Int).Sqrt(n)
+		twice := new(big.Int).Sqrt(new(big.Int).Lsh(n, 2))
+		nearest := new(big.Int).Rsh(twice.Add(twice, big.NewInt(1)), 1)
+		ceil := new(big.Int).Set(floor)
+		if new(big.Int).Mul(floor, floor).Cmp(n) != 0 {
+			ceil.Add(ceil, big.NewInt(1))
+		}
+
+		for _, round := range allRoundingModes {
+			want := nearest
+			switch round {
+			case RoundTowardZero, RoundFloor:
+				want = floor
+			case RoundAwayFromZero, RoundCeil:
+				want = ceil
+			}
+
+			res, err := UFix256(x).Sqrt(round)
+			check("Sqrt", x, x, false, raw256(res), err, want, false)
+
+			sres, err := Fix256(x).Sqrt(round)
+			if isNeg256(x) {
+				if err != (OutOfDomainErrorError{}) {
+					t.Errorf("Fix256(%x).Sqrt() = %v, %v; want OutOfDomainErrorError", x, sres, err)
+				}
+			} else {
+				check("Sqrt", x, x, true, raw256(sres), err, want, false)
+			}
+		}
+	}
+
+	// The Checked, Must and Sqrt variants of operators that round.
+	two := UFix256One.MustAdd(UFix256One)
+	if res, ok := UFix256Max.MulChecked(two, RoundTowardZero); ok {
+		t.Errorf("UFix256Max.MulChecked(2) = %v, true; want false", res)
+	}
+	if res, ok := Fix256One.DivChecked(Fix256Zero, RoundTowardZero); ok {
+		t.Errorf("Fix256One.DivChecked(0) = %v, true; want false", res)
+	}
+	if res, ok := UFix256One.DivChecked(two, RoundTowardZero); !ok || res != MustParseUFix256("0.5") {
+		t.Errorf("UFix256One.DivChecked(2) = %v, %v; want 0.5, true", res, ok)
+	}
+	if res := MustParseFix256("-1.5").MustMul(Fix256One.MustSub(MustParseFix256("3")), RoundTowardZero); res != MustParseFix256("3") {
+		t.Errorf("-1.5.MustMul(-2) = %v; want 3", res)
+	}
+	if res := UFix256One.MustFMD(two, MustParseUFix256("4"), RoundTowardZero).MustDiv(two, RoundTowardZero); res != MustParseUFix256("0.25") {
+		t.Errorf("1.MustFMD(2, 4).MustDiv(2) = %v; want 0.25", res)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != (PositiveOverflowError{}) {
+				t.Errorf("UFix256Max.MustAdd(1) panicked with %v; want PositiveOverflowError", r)
+			}
+		}()
+		UFix256Max.MustAdd(UFix256One)
+	}()
+	if res, err := UFix256Zero.FMA(UFix256One, UFix256One, RoundingMode(99)); err != (InvalidRoundingModeError{}) {
+		t.Errorf("UFix256Zero.FMA(1, 1, 99) = %v, %v; want InvalidRoundingModeError", res, err)
+	}
+	if res, err := UFix256One.Sqrt(RoundingMode(99)); err != (InvalidRoundingModeError{}) {
+		t.Errorf("UFix256One.Sqrt(99) = %v, %v; want InvalidRoundingModeError", res, err)
+	}
+}
+
+func TestFix256(t *testing.T) {
+
+	t.Parallel()
+
+	// The AMM invariant of two UFix128Max reserves overflows UFix128, but not UFix256.
+	x := UFix128Max.ToUFix256()
+	k, err := x.Mul(x, RoundTowardZero)
+	if err != nil {
+		t.Fatalf("UFix128Max^2 = %v", err)
+	}
+	want := new(big.Int).Quo(new(big.Int).Mul(UFix128Max.ToBigInt(), UFix128Max.ToBigInt()), fix128ScaleBig)
+	if k.ToBigInt().Cmp(want) != 0 {
+		t.Errorf("UFix128Max^2 = %v; want %v", k, want)
+	}
+	if y, err := k.Div(x, RoundCeil); err != nil || y != x {
+		t.Errorf("k / UFix128Max = %v, %v; want %v", y, err, x)
+	}
+
+	// Strings and parsing
+	tests := []struct {
+		s string
+		v Fix256
+	}{
+		{"0.0", Fix256Zero},
+		{"1.0", Fix256One},
+		{"0.000000000000000000000001", Fix256Iota},
+		{"57896044618658097711785492504343953926634992332820282.019728792003956564819967", Fix256Max},
+		{"-57896044618658097711785492504343953926634992332820282.019728792003956564819968", Fix256Min},
+		{"-1.5", MustParseFix128("-1.5").ToFix256()},
+	}
+
+	for _, tt := range tests {
+		if s := tt.v.String(); s != tt.s {
+			t.Errorf("%#v.String() = %q; want %q", tt.v, s, tt.s)
+		}
+		if v, err := ParseFix256(tt.s, RoundTowardZero); err != nil || v != tt.v {
+			t.Errorf("ParseFix256(%q) = %v, %v", tt.s, v, err)
+		}
+	}
+
+	if s := UFix256Max.String(); s != "115792089237316195423570985008687907853269984665640564.039457584007913129639935" {
+		t.Errorf("UFix256Max.String() = %q", s)
+	}
+	if v, err := ParseUFix256("115792089237316195423570985008687907853269984665640564.039457584007913129639936", RoundTowardZero); err != (PositiveOverflowError{}) {
+		t.Errorf("ParseUFix256(UFix256Max + iota) = %v, %v; want PositiveOverflowError", v, err)
+	}
+	if v, err := ParseUFix256("115792089237316195423570985008687907853269984665640564.0394575840079131296399355", RoundNearestHalfAway); err != (PositiveOverflowError{}) {
+		t.Errorf("ParseUFix256(UFix256Max + iota/2, RoundNearestHalfAway) = %v, %v; want PositiveOverflowError", v, err)
+	}
+	if v, err := ParseUFix256("-1", RoundTowardZero); err != (NegativeOverflowError{}) {
+		t.Errorf("ParseUFix256(-1) = %v, %v; want NegativeOverflowError", v, err)
+	}
+	if v, err := ParseFix256("1e53", RoundTowardZero); err != (PositiveOverflowError{}) {
+		t.Errorf("ParseFix256(1e53) = %v, %v; want PositiveOverflowError", v, err)
+	}
+	if v, err := ParseFix256("-1e52", RoundTowardZero); err != nil || v.ToBigInt().Cmp(new(big.Int).Neg(pow10Big(76))) != 0 {
+		t.Errorf("ParseFix256(-1e52) = %v, %v", v, err)
+	}
+	if v, err := ParseUFix256("0.0000000000000000000000015", RoundNearestHalfEven); err != nil || v != NewUFix256(0, 0, 0, 2) {
+		t.Errorf("ParseUFix256(1.5 iota) = %#v, %v", v, err)
+	}
+
+	// The 128-bit parser still detects overflow at 128 bits.
+	if v, err := ParseUFix128("340282366920938.463463374607431768211456", RoundTowardZero); err != (PositiveOverflowError{}) {
+		t.Errorf("ParseUFix128(UFix128Max + iota) = %v, %v; want PositiveOverflowError", v, err)
+	}
+
+	// Conversions
+	for _, x := range edgeValues128 {
+		if res, err := UFix128(x).ToUFix256().ToUFix128(); err != nil || res != UFix128(x) {
+			t.Errorf("UFix128(%v) round trip through UFix256 = %v, %v", x, res, err)
+		}
+		if res, err := Fix128(x).ToFix256().ToFix128(); err != nil || res != Fix128(x) {
+			t.Errorf("Fix128(%v) round trip through Fix256 = %v, %v", x, res, err)
+		}
+		if Fix128(x).ToFix256().String() != Fix128(x).String() {
+			t.Errorf("Fix128(%v).ToFix256().String() = %s", x, Fix128(x).ToFix256())
+		}
+	}
+	if res, err := Fix256Min.ToFix128(); err != (NegativeOverflowError{}) {
+		t.Errorf("Fix256Min.ToFix128() = %v, %v; want NegativeOverflowError", res, err)
+	}
+	if res, err := UFix256Max.ToUFix128(); err != (PositiveOverflowError{}) {
+		t.Errorf("UFix256Max.ToUFix128() = %v, %v; want PositiveOverflowError", res, err)
+	}
+	if res, err := UFix256Max.ToFix256(); err != (PositiveOverflowError{}) {
+		t.Errorf("UFix256Max.ToFix256() = %v, %v; want PositiveOverflowError", res, err)
+	}
+	if res, err := Fix256Min.ToUFix256(); err != (NegativeOverflowError{}) {
+		t.Errorf("Fix256Min.ToUFix256() = %v, %v; want NegativeOverflowError", res, err)
+	}
+
+	// Text encoding
+	var decoded struct{ K UFix256 }
+	if data, err := json.Marshal(struct{ K UFix256 }{k}); err != nil {
+		t.Errorf("json.Marshal(%v) = %v", k, err)
+	} else if err := json.Unmarshal(data, &decoded); err != nil || decoded.K != k {
+		t.Errorf("json round trip of %s = %v, %v", data, decoded.K, err)
+	}
+
+	if !UFix256One.IsInteger() || UFix256Iota.IsInteger() || !MustParseFix256("-3").IsInteger() {
+		t.Errorf("IsInteger is wrong")
+	}
+}
+
+func TestEncoding256(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues256 {
+		data, err := UFix256(x).MarshalBinary()
+		want := bigFromRaw256Signed(x, false).FillBytes(make([]byte, 32))
+		if err != nil || string(data) != string(want) {
+			t.Errorf("UFix256(%v).MarshalBinary() = %x, %v; want %x", x, data, err, want)
+		}
+
+		var u256 UFix256
+		if err := u256.UnmarshalBinary(data); err != nil || u256 != UFix256(x) {
+			t.Errorf("UFix256.UnmarshalBinary(%x) = %v, %v; want %v", data, u256, err, UFix256(x))
+		}
+
+		var f256 Fix256
+		data, _ = Fix256(x).GobEncode()
+		if err := f256.GobDecode(data); err != nil || f256 != Fix256(x) {
+			t.Errorf("Fix256.GobDecode(%x) = %v, %v; want %v", data, f256, err, Fix256(x))
+		}
+
+		data, err = Fix256(x).MarshalJSON()
+		f256 = Fix256Zero
+		if err != nil || f256.UnmarshalJSON(data) != nil || f256 != Fix256(x) {
+			t.Errorf("Fix256(%v) JSON round trip through %s = %v, %v", x, data, f256, err)
+		}
+
+		data, err = UFix256(x).MarshalCBOR()
+		u256 = UFix256Zero
+		if err != nil || u256.UnmarshalCBOR(data) != nil || u256 != UFix256(x) {
+			t.Errorf("UFix256(%v) CBOR round trip through %x = %v, %v", x, data, u256, err)
+		}
+
+		data, err = Fix256(x).MarshalCBOR()
+		f256 = Fix256Zero
+		if err != nil || f256.UnmarshalCBOR(data) != nil || f256 != Fix256(x) {
+			t.Errorf("Fix256(%v) CBOR round trip through %x = %v, %v", x, data, f256, err)
+		}
+
+		v, err := Fix256(x).Value()
+		f256 = Fix256Zero
+		if err != nil || f256.Scan(v) != nil || f256 != Fix256(x) {
+			t.Errorf("Fix256(%v) SQL round trip through %v = %v, %v", x, v, f256, err)
+		}
+
+		v, err = UFix256(x).Value()
+		u256 = UFix256Zero
+		if err != nil || u256.Scan([]byte(v.(string))) != nil || u256 != UFix256(x) {
+			t.Errorf("UFix256(%v) SQL round trip through %v = %v, %v", x, v, u256, err)
+		}
+	}
+
+	// The length must be exact, and the value is unchanged on error
+	for _, n := range []int{0, 16, 31, 33} {
+		f256 := Fix256One
+		if err := f256.UnmarshalBinary(make([]byte, n)); err != (InvalidEncodingError{}) || f256 != Fix256One {
+			t.Errorf("Fix256.UnmarshalBinary(%d bytes) = %v, %v; want InvalidEncodingError", n, f256, err)
+		}
+	}
+
+	// Bignums of up to 32 bytes are decoded, and the narrower types reject values that don't fit
+	for _, tc := range []struct {
+		value interface{ MarshalCBOR() ([]byte, error) }
+		want  string
+	}{
+		{UFix256Iota, "c4823701"},
+		{UFix256One, "c48237c24ad3c21bcecceda1000000"},
+		{UFix256Max, "c48237c25820ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"},
+		{Fix256Min, "c48237c358207fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"},
+	} {
+		if data, err := tc.value.MarshalCBOR(); err != nil || fmt.Sprintf("%x", data) != tc.want {
+			t.Errorf("%v.MarshalCBOR() = %x, %v; want %s", tc.value, data, err, tc.want)
+		}
+	}
+
+	data, _ := UFix256Max.MarshalCBOR()
+	u128 := UFix128One
+	if err := u128.UnmarshalCBOR(data); err != (PositiveOverflowError{}) || u128 != UFix128One {
+		t.Errorf("UFix128.UnmarshalCBOR(UFix256Max) = %v, %v; want PositiveOverflowError", u128, err)
+	}
+	data, _ = hex.DecodeString("c48237c2582101" + strings.Repeat("00", 32))
+	if err := new(UFix256).UnmarshalCBOR(data); err != (InvalidEncodingError{}) {
+		t.Errorf("UFix256.UnmarshalCBOR(2^256) = %v; want InvalidEncodingError", err)
+	}
+
+	// Null types
+	var n NullFix256
+	if err := n.Scan("-1.5"); err != nil || !n.Valid || n.Fix256 != MustParseFix256("-1.5") {
+		t.Errorf("NullFix256.Scan(-1.5) = %+v, %v; want -1.5", n, err)
+	}
+	if err := n.Scan(nil); err != nil || n.Valid {
+		t.Errorf("NullFix256.Scan(nil) = %+v, %v; want invalid", n, err)
+	}
+	if v, err := (NullUFix256{UFix256One, true}).Value(); err != nil || v != "1.0" {
+		t.Errorf("NullUFix256.Value() = %v, %v; want 1.0", v, err)
+	}
+}
+
+func TestIntegerTypes(t *testing.T) {
+
+	t.Parallel()
+
+	check := func(op string, x, y any, bits uint, signed bool, res *big.Int, err error, want *big.Int) {
+		t.Helper()
+
+		wantRaw, wantErr := refRange(want, bits, signed, false)
+		if err != wantErr || (err == nil && new(big.Int).Mod(res, new(big.Int).Lsh(big.NewInt(1), bits)).Cmp(wantRaw) != 0) {
+			t.Errorf("%s(%v, %v) bits=%d signed=%v = %v, %v; want %v, %v", op, x, y, bits, signed, res, err, want, wantErr)
+		}
+	}
+
+	for _, x := range edgeValues256 {
+		for _, y := range edgeValues256 {
+			for _, signed := range []bool{false, true} {
+				a, b := bigFromRaw256Signed(x, signed), bigFromRaw256Signed(y, signed)
+				sum := new(big.Int).Add(a, b)
+				diff := new(big.Int).Sub(a, b)
+				prod := new(big.Int).Mul(a, b)
+
+				if signed {
+					res, err := Int256(x).Add(Int256(y))
+					check("Add", x, y, 256, true, res.ToBigInt(), err, sum)
+					res, err = Int256(x).Sub(Int256(y))
+					check("Sub", x, y, 256, true, res.ToBigInt(), err, diff)
+					res, err = Int256(x).Mul(Int256(y))
+					check("Mul", x, y, 256, true, res.ToBigInt(), err, prod)
+					if cmp := Int256(x).Cmp(Int256(y)); cmp != a.Cmp(b) {
+						t.Errorf("Int256 Cmp(%v, %v) = %d", a, b, cmp)
+					}
+				} else {
+					res, err := UInt256(x).Add(UInt256(y))
+					check("Add", x, y, 256, false, res.ToBigInt(), err, sum)
+					res, err = UInt256(x).Sub(UInt256(y))
+					check("Sub", x, y, 256, false, res.ToBigInt(), err, diff)
+					res, err = UInt256(x).Mul(UInt256(y))
+					check("Mul", x, y, 256, false, res.ToBigInt(), err, prod)
+					if cmp := UInt256(x).Cmp(UInt256(y)); cmp != a.Cmp(b) {
+						t.Errorf("UInt256 Cmp(%v, %v) = %d", a, b, cmp)
+					}
+				}
+
+				if b.Sign() == 0 {
+					continue
+				}
+
+				for _, round := range allRoundingModes {
+					if round == RoundStochastic {
+						continue
+					}
+
+					quo := refQuo(a, b, round)
+					if signed {
+						res, err := Int256(x).Div(Int256(y), round)
+						check("Div", x, y, 256, true, res.ToBigInt(), err, quo)
+					} else {
+						res, err := UInt256(x).Div(UInt256(y), round)
+						check("Div", x, y, 256, false, res.ToBigInt(), err, quo)
+					}
+				}
+			}
+		}
+	}
+
+	for _, x := range edgeValues128 {
+		for _, y := range edgeValues128 {
+			for _, signed := range []bool{false, true} {
+				a, b := bigFromRaw128(x, signed), bigFromRaw128(y, signed)
+				sum := new(big.Int).Add(a, b)
+				prod := new(big.Int).Mul(a, b)
+
+				if signed {
+					res, err := Int128(x).Add(Int128(y))
+					check("Add", x, y, 128, true, res.ToBigInt(), err, sum)
+					res, err = Int128(x).Mul(Int128(y))
+					check("Mul", x, y, 128, true, res.ToBigInt(), err, prod)
+				} else {
+					res, err := UInt128(x).Add(UInt128(y))
+					check("Add", x, y, 128, false, res.ToBigInt(), err, sum)
+					res, err = UInt128(x).Mul(UInt128(y))
+					check("Mul", x, y, 128, false, res.ToBigInt(), err, prod)
+				}
+
+				if b.Sign() == 0 {
+					continue
+				}
+
+				for _, round := range allRoundingModes {
+					if round == RoundStochastic {
+						continue
+					}
+
+					quo := refQuo(a, b, round)
+					if signed {
+						res, err := Int128(x).Div(Int128(y), round)
+						check("Div", x, y, 128, true, res.ToBigInt(), err, quo)
+					} else {
+						res, err := UInt128(x).Div(UInt128(y), round)
+						check("Div", x, y, 128, false, res.ToBigInt(), err, quo)
+					}
+				}
+			}
+		}
+	}
+
+	if res, err := NewUInt128(0, 1).Div(UInt128{}, RoundTowardZero); err != (DivisionByZeroError{}) {
+		t.Errorf("UInt128(1).Div(0) = %v, %v; want DivisionByZeroError", res, err)
+	}
+	if res, err := NewUInt256(0, 0, 0, 1).Div(NewUInt256(0, 0, 0, 1), RoundingMode(99)); err != (InvalidRoundingModeError{}) {
+		t.Errorf("UInt256(1).Div(1, 99) = %v, %v; want InvalidRoundingModeError", res, err)
+	}
+	if s := Int128FromInt64(-42).String(); s != "-42" {
+		t.Errorf("Int128FromInt64(-42) = %s", s)
+	}
+	if s := Int256FromInt64(-42).String(); s != "-42" {
+		t.Errorf("Int256FromInt64(-42) = %s", s)
+	}
+
+	// Conversions to and from the fixed-point types, as a mantissa with the given decimals
+	shares := NewUInt128(0, 1500000)
+	if res, err := shares.ToUFix128(6, RoundTowardZero); err != nil || res != MustParseUFix128("1.5") {
+		t.Errorf("UInt128(1500000).ToUFix128(6) = %v, %v; want 1.5", res, err)
+	}
+	if res, err := MustParseUFix128("1.5").ToUInt128(6, RoundTowardZero); err != nil || res != shares {
+		t.Errorf("UFix128(1.5).ToUInt128(6) = %v, %v; want %v", res, err, shares)
+	}
+	if res, err := MustParseFix64("-1.23456789").ToInt256(4, RoundFloor); err != nil || res != Int256FromInt64(-12346) {
+		t.Errorf("Fix64(-1.23456789).ToInt256(4, RoundFloor) = %v, %v; want -12346", res, err)
+	}
+	if res, err := Int256FromInt64(-12346).ToFix64(4, RoundTowardZero); err != nil || res != MustParseFix64("-1.2346") {
+		t.Errorf("Int256(-12346).ToFix64(4) = %v, %v; want -1.2346", res, err)
+	}
+	if res, err := UFix128Max.ToUInt128(25, RoundTowardZero); err != (PositiveOverflowError{}) {
+		t.Errorf("UFix128Max.ToUInt128(25) = %v, %v; want PositiveOverflowError", res, err)
+	}
+	if res, err := UFix128Max.ToUInt256(25, RoundTowardZero); err != nil || res.ToBigInt().Cmp(new(big.Int).Mul(UFix128Max.ToBigInt(), big.NewInt(10))) != 0 {
+		t.Errorf("UFix128Max.ToUInt256(25) = %v, %v", res, err)
+	}
+	if res, err := Fix128Min.ToInt128(24, RoundTowardZero); err != nil || res != Int128(Fix128Min) {
+		t.Errorf("Fix128Min.ToInt128(24) = %v, %v", res, err)
+	}
+
+	// The conversions agree with the math/big ones, for every number of decimals that makes a
+	// difference.
+	agrees := func(op string, res any, err error, want any, wantErr error) {
+		t.Helper()
+
+		if err != wantErr || (err == nil && res != want) {
+			t.Errorf("%s = %v, %v; want %v, %v", op, res, err, want, wantErr)
+		}
+	}
+	allDecimals := []uint8{0, 1, 6, 8, 9, 18, 23, 24, 25, 38, 39, 52, 53, 62, 63, 76, 77, 78, 79, 100, 154, 155, 156, 255}
+	for _, x := range edgeValues256 {
+		for _, decimals := range allDecimals {
+			for _, round := range allRoundingModes {
+				u, i := UInt256(x), Int256(x)
+				op := fmt.Sprintf("(%v).To*(%d, %v)", u, decimals, round)
+
+				res64, err := u.ToUFix64(decimals, round)
+				want64, wantErr64 := UFix64FromMantScale(u.ToBigInt(), int(decimals), round)
+				agrees("UInt256"+op, res64, err, want64, wantErr64)
+				res128, err := u.ToUFix128(decimals, round)
+				want128, wantErr128 := UFix128FromMantScale(u.ToBigInt(), int(decimals), round)
+				agrees("UInt256"+op, res128, err, want128, wantErr128)
+				sres64, err := i.ToFix64(decimals, round)
+				swant64, wantErr := Fix64FromMantScale(i.ToBigInt(), int(decimals), round)
+				agrees("Int256"+op, sres64, err, swant64, wantErr)
+				sres128, err := i.ToFix128(decimals, round)
+				swant128, wantErr := Fix128FromMantScale(i.ToBigInt(), int(decimals), round)
+				agrees("Int256"+op, sres128, err, swant128, wantErr)
+
+				if isZero128(x.Hi) {
+					res64, err = UInt128(x.Lo).ToUFix64(decimals, round)
+					agrees("UInt128"+op, res64, err, want64, wantErr64)
+					res128, err = UInt128(x.Lo).ToUFix128(decimals, round)
+					agrees("UInt128"+op, res128, err, want128, wantErr128)
+
+					i := Int128(x.Lo)
+					sres64, err := i.ToFix64(decimals, round)
+					swant64, wantErr := Fix64FromMantScale(i.ToBigInt(), int(decimals), round)
+					agrees("Int128"+op, sres64, err, swant64, wantErr)
+					sres128, err := i.ToFix128(decimals, round)
+					swant128, wantErr := Fix128FromMantScale(i.ToBigInt(), int(decimals), round)
+					agrees("Int128"+op, sres128, err, swant128, wantErr)
+				}
+			}
+		}
+	}
+	for _, x := range edgeValues128 {
+		for _, decimals := range allDecimals {
+			for _, round := range allRoundingModes {
+				op := fmt.Sprintf("(%v).To*Int*(%d, %v)", UFix128(x), decimals, round)
+
+				n, wantErr := toMantissa(false, uint64(x.Hi), uint64(x.Lo), Fix128Decimals, int(decimals), round)
+				var want128 UInt128
+				var want256 UInt256
+				wantErr128, wantErr256 := wantErr, wantErr
+				if wantErr == nil {
+					want128, wantErr128 = UInt128FromBigInt(n)
+					want256, wantErr256 = UInt256FromBigInt(n)
+				}
+				res128, err := UFix128(x).ToUInt128(decimals, round)
+				agrees("UFix128"+op, res128, err, want128, wantErr128)
+				res256, err := UFix128(x).ToUInt256(decimals, round)
+				agrees("UFix128"+op, res256, err, want256, wantErr256)
+
+				aUnsigned, sign := Fix128(x).Abs()
+				n, wantErr = toMantissa(sign < 0, uint64(aUnsigned.Hi), uint64(aUnsigned.Lo), Fix128Decimals, int(decimals), round)
+				var swant128 Int128
+				var swant256 Int256
+				wantErr128, wantErr256 = wantErr, wantErr
+				if wantErr == nil {
+					swant128, wantErr128 = Int128FromBigInt(n)
+					swant256, wantErr256 = Int256FromBigInt(n)
+				}
+				sres128, err := Fix128(x).ToInt128(decimals, round)
+				agrees("Fix128"+op, sres128, err, swant128, wantErr128)
+				sres256, err := Fix128(x).ToInt256(decimals, round)
+				agrees("Fix128"+op, sres256, err, swant256, wantErr256)
+			}
+		}
+
+		if s, want := UInt128(x).String(), UInt128(x).ToBigInt().String(); s != want {
+			t.Errorf("UInt128(%v).String() = %s; want %s", x, s, want)
+		}
+		if s, want := Int128(x).String(), Int128(x).ToBigInt().String(); s != want {
+			t.Errorf("Int128(%v).String() = %s; want %s", x, s, want)
+		}
+	}
+	for _, x := range edgeValues256 {
+		if s, want := UInt256(x).String(), UInt256(x).ToBigInt().String(); s != want {
+			t.Errorf("UInt256(%v).String() = %s; want %s", x, s, want)
+		}
+		if s, want := Int256(x).String(), Int256(x).ToBigInt().String(); s != want {
+			t.Errorf("Int256(%v).String() = %s; want %s", x, s, want)
+		}
+	}
+}
+
+// Not run in parallel, since AllocsPerRun panics in parallel tests
+func TestIntegerConversionAllocs(t *testing.T) {
+	shares := NewUInt256(0, 0, 1, 0)
+	allocs := testing.AllocsPerRun(100, func() {
+		res, _ := shares.ToUFix128(30, RoundNearestHalfEven)
+		_, _ = res.ToUInt256(30, RoundTowardZero)
+		_, _ = Int128FromInt64(-12346).ToFix64(4, RoundFloor)
+		_, _ = MustParseFix128("-1.5").ToInt128(6, RoundTowardZero)
+	})
+	if allocs != 0 {
+		t.Errorf("integer conversions allocated %v times per run; want 0", allocs)
+	}
+}
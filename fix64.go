@@ -45,10 +45,48 @@ func (a Fix64) Lte(b Fix64) bool   { return !a.Gt(b) }
 func (a UFix64) Gte(b UFix64) bool { return !a.Lt(b) }
 func (a Fix64) Gte(b Fix64) bool   { return !a.Lt(b) }
 
+// ApproxEq returns true if `a` and `b` differ by at most `tol`. Unlike subtracting and comparing
+// the result, this can't overflow, even when `a` and `b` are far apart or have opposite signs.
+func (a UFix64) ApproxEq(b, tol UFix64) bool      { return tol.Gte(a.distance(b)) }
+func (a Fix64) ApproxEq(b Fix64, tol UFix64) bool { return tol.Gte(a.distance(b)) }
+
+// distance returns the absolute difference between `a` and `b`, which is always representable as
+// an unsigned value (even for signed inputs).
+func (a UFix64) distance(b UFix64) UFix64 {
+	if a.Lt(b) {
+		a, b = b, a
+	}
+
+	diff, _ := sub64(raw64(a), raw64(b), 0)
+
+	return UFix64(diff)
+}
+
+func (a Fix64) distance(b Fix64) UFix64 {
+	if a.Lt(b) {
+		a, b = b, a
+	}
+
+	// The true difference is non-negative and less than 2**64, so an unsigned subtraction of the
+	// raw values gives the right answer, even if it wraps around.
+	diff, _ := sub64(raw64(a), raw64(b), 0)
+
+	return UFix64(diff)
+}
+
 // IsNeg returns true if `a` is negative.
 func (a Fix64) IsNeg() bool { return isNeg64(raw64(a)) }
 
-// Neg returns the additive inverse of `a` (i.e. -a), or a negative overflow error
+// Neg returns the additive inverse of `a` (i.e. -a), or a negative overflow error. For unsigned
+// values, zero is the only value with a representable inverse, so any other value is an error.
+func (a UFix64) Neg() (UFix64, error) {
+	if a.IsZero() {
+		return UFix64Zero, nil
+	}
+
+	return UFix64Zero, NegativeOverflowError{}
+}
+
 func (a Fix64) Neg() (Fix64, error) {
 	if a == Fix64Min {
 		// Special case: negating the minimum value will overflow.
@@ -58,6 +96,25 @@ func (a Fix64) Neg() (Fix64, error) {
 	return Fix64(neg64(raw64(a))), nil
 }
 
+// IsInteger returns true if `a` is a whole number, i.e. it has no fractional part.
+func (a UFix64) IsInteger() bool { return isZero64(mod64(raw64(a), raw64(UFix64One))) }
+func (a Fix64) IsInteger() bool {
+	// The raw value of a negative number modulo the scale isn't meaningful, so check the magnitude.
+	aUnsigned, _ := a.Abs()
+
+	return aUnsigned.IsInteger()
+}
+
+// HasFraction returns true if `a` has a non-zero fractional part.
+func (a UFix64) HasFraction() bool { return !a.IsInteger() }
+func (a Fix64) HasFraction() bool  { return !a.IsInteger() }
+
+// Iota returns the smallest positive value representable by the type of `a` (i.e. UFix64Iota or
+// Fix64Iota), regardless of the value of `a`. Useful in generic code, where the constants can't be
+// named directly.
+func (UFix64) Iota() UFix64 { return UFix64Iota }
+func (Fix64) Iota() Fix64   { return Fix64Iota }
+
 // IsZero returns true if `a` is zero.
 func (a UFix64) IsZero() bool { return isZero64(raw64(a)) }
 func (a Fix64) IsZero() bool  { return isZero64(raw64(a)) }
@@ -121,6 +178,75 @@ func (a Fix64) Sub(b Fix64) (Fix64, error) {
 	return res, nil
 }
 
+// AddSigned returns the sum of the unsigned value `a` and the signed value `delta`, or an error on
+// overflow, or negative overflow if the result would be negative. Useful for applying a signed
+// change to an unsigned quantity (e.g. a balance) without juggling Abs and ApplySign.
+func (a UFix64) AddSigned(delta Fix64) (UFix64, error) {
+	mag, sign := delta.Abs()
+
+	if sign < 0 {
+		return a.Sub(mag)
+	}
+
+	return a.Add(mag)
+}
+
+// SubSigned returns the difference of the unsigned value `a` and the signed value `delta`, or an
+// error on overflow, or negative overflow if the result would be negative.
+func (a UFix64) SubSigned(delta Fix64) (UFix64, error) {
+	mag, sign := delta.Abs()
+
+	if sign < 0 {
+		return a.Add(mag)
+	}
+
+	return a.Sub(mag)
+}
+
+// NextUp returns the smallest representable value greater than `a`, or an error on overflow if
+// `a` is the largest representable value.
+func (a UFix64) NextUp() (UFix64, error) {
+	res, carry := add64(raw64(a), raw64Zero, 1)
+
+	if carry != 0 {
+		return UFix64Zero, PositiveOverflowError{}
+	}
+
+	return UFix64(res), nil
+}
+
+func (a Fix64) NextUp() (Fix64, error) {
+	if a == Fix64Max {
+		return Fix64Zero, PositiveOverflowError{}
+	}
+
+	res, _ := add64(raw64(a), raw64Zero, 1)
+
+	return Fix64(res), nil
+}
+
+// NextDown returns the largest representable value less than `a`, or an error on negative
+// overflow if `a` is the smallest representable value.
+func (a UFix64) NextDown() (UFix64, error) {
+	res, borrow := sub64(raw64(a), raw64Zero, 1)
+
+	if borrow != 0 {
+		return UFix64Zero, NegativeOverflowError{}
+	}
+
+	return UFix64(res), nil
+}
+
+func (a Fix64) NextDown() (Fix64, error) {
+	if a == Fix64Min {
+		return Fix64Zero, NegativeOverflowError{}
+	}
+
+	res, _ := sub64(raw64(a), raw64Zero, 1)
+
+	return Fix64(res), nil
+}
+
 // Abs returns the absolute value of `a` as an unsigned value, with a sign value as an int64.
 // Note that this method works properly for Fix64Min, which can NOT be represented as a positive Fix64.
 func (a Fix64) Abs() (UFix64, int64) {
@@ -133,27 +259,58 @@ func (a Fix64) Abs() (UFix64, int64) {
 	return UFix64(a), 1
 }
 
+// CmpAbs compares the magnitudes of `a` and `b`, returning -1 if |a| < |b|, 0 if |a| == |b|, and
+// +1 if |a| > |b|. Note that this works properly for Fix64Min, which has a larger magnitude
+// than any other Fix64 value.
+func (a Fix64) CmpAbs(b Fix64) int {
+	aUnsigned, _ := a.Abs()
+	bUnsigned, _ := b.Abs()
+
+	return compare(aUnsigned, bUnsigned)
+}
+
 // ApplySign converts a UFix64 to a Fix64, applying the sign specified by the input.
 func (a UFix64) ApplySign(sign int64) (Fix64, error) {
+	res, code := a.applySignCode(sign)
+
+	return res, code.err()
+}
+
+// applySignCode implements ApplySign, reporting any failure as an ErrorCode rather than an error.
+func (a UFix64) applySignCode(sign int64) (Fix64, ErrorCode) {
 	if sign == 1 {
 		if a.Gt(UFix64(Fix64Max)) {
-			return Fix64Zero, PositiveOverflowError{}
+			return Fix64Zero, ErrorCodeOverflow
 		}
-		return Fix64(a), nil
+		return Fix64(a), ErrorCodeNone
 	} else {
 		// Special case: if the result's sign should be negative and the converted
 		// value is the minimum representable value, we can just return the minimum
 		// value. We need to do this because the comparison against FixMax will fail
 		// below, even thought would be a valid result.
 		if isEqual64(raw64(a), raw64(Fix64Min)) {
-			return Fix64Min, nil
+			return Fix64Min, ErrorCodeNone
 		}
 		if a.Gt(UFix64(Fix64Max)) {
-			return Fix64Zero, NegativeOverflowError{}
+			return Fix64Zero, ErrorCodeNegativeOverflow
 		}
 
-		return Fix64(neg64(raw64(a))), nil
+		return Fix64(neg64(raw64(a))), ErrorCodeNone
+	}
+}
+
+// ToFix64 converts a UFix64 to a Fix64, or returns an error on overflow if `a` is larger than
+// Fix64Max. Use this instead of a plain type conversion, which silently wraps large values.
+func (a UFix64) ToFix64() (Fix64, error) { return a.ApplySign(1) }
+
+// ToUFix64 converts a Fix64 to a UFix64, or returns an error on negative overflow if `a` is
+// negative. Use this instead of a plain type conversion, which silently wraps negative values.
+func (a Fix64) ToUFix64() (UFix64, error) {
+	if a.IsNeg() {
+		return UFix64Zero, NegativeOverflowError{}
 	}
+
+	return UFix64(a), nil
 }
 
 // Mul returns the product of `a` and `b`, or an error on overflow or underflow.
@@ -187,55 +344,171 @@ func (a Fix64) Div(b Fix64, round RoundingMode) (Fix64, error) {
 	return a.FMD(Fix64One, b, round)
 }
 
-// FMD returns a*b/c without intermediate rounding, or an error on division by zero, overflow, or underflow.
-func (a UFix64) FMD(b, c UFix64, round RoundingMode) (UFix64, error) {
-	// Must come before the check for a or b == 0 so we flag 0.0/0.0 as an error.
-	if c.IsZero() {
-		return UFix64Zero, DivisionByZeroError{}
-	}
+// MulDefault and DivDefault are compatibility wrappers for callers written against the older
+// API, where Mul and Div didn't take a rounding mode and always truncated the result. New code
+// should call Mul and Div with an explicit rounding mode.
 
-	if a.IsZero() || b.IsZero() {
-		return UFix64Zero, nil
+// MulDefault returns the product of `a` and `b` rounded toward zero, or an error on overflow or
+// underflow.
+func (a UFix64) MulDefault(b UFix64) (UFix64, error) { return a.Mul(b, RoundTowardZero) }
+func (a Fix64) MulDefault(b Fix64) (Fix64, error)    { return a.Mul(b, RoundTowardZero) }
+
+// DivDefault returns the quotient of `a` and `b` rounded toward zero, or an error on division by
+// zero, overflow, or underflow.
+func (a UFix64) DivDefault(b UFix64) (UFix64, error) { return a.Div(b, RoundTowardZero) }
+func (a Fix64) DivDefault(b Fix64) (Fix64, error)    { return a.Div(b, RoundTowardZero) }
+
+// MulExact returns the product of `a` and `b`, or an error on overflow, underflow, or if the
+// product can't be represented exactly.
+func (a UFix64) MulExact(b UFix64) (UFix64, error) {
+	res, exact, err := a.fmd(b, UFix64One, RoundTowardZero)
+
+	if err == nil && !exact {
+		return UFix64Zero, InexactError{}
 	}
 
-	hi, lo := mul64(raw64(a), raw64(b))
+	return res, err
+}
 
-	// If the hi part is >= the divisor the result can't fit in 64 bits.
-	if UFix64(hi).Gte(c) {
-		return UFix64Zero, PositiveOverflowError{}
+// MulExact returns the product of `a` and `b`, or an error on overflow, underflow, or if the
+// product can't be represented exactly.
+func (a Fix64) MulExact(b Fix64) (Fix64, error) {
+	res, exact, err := a.fmd(b, Fix64One, RoundTowardZero)
+
+	if err == nil && !exact {
+		return Fix64Zero, InexactError{}
 	}
 
-	quo, rem := div64(hi, lo, raw64(c))
+	return res, err
+}
 
-	if ushouldRound64(quo, rem, raw64(c), round) {
-		var carry uint64
-		quo, carry = add64(quo, raw64Zero, 1)
+// DivExact returns the quotient of `a` and `b`, or an error on division by zero, overflow,
+// underflow, or if the quotient can't be represented exactly.
+func (a UFix64) DivExact(b UFix64) (UFix64, error) {
+	res, exact, err := a.fmd(UFix64One, b, RoundTowardZero)
 
-		// Make sure we don't "round up" to a value outside of the range of UFix64!
-		if carry != 0 {
-			return UFix64Zero, PositiveOverflowError{}
-		}
+	if err == nil && !exact {
+		return UFix64Zero, InexactError{}
 	}
 
-	// We can't get here if `a == 0` or `b == 0` because we checked that first. So,
-	// a quotient of 0 means the result is too small to represent, i.e. underflow.
-	// Note that we check this AFTER rounding.
-	if isZero64(quo) {
-		return UFix64Zero, UnderflowError{}
+	return res, err
+}
+
+// DivExact returns the quotient of `a` and `b`, or an error on division by zero, overflow,
+// underflow, or if the quotient can't be represented exactly.
+func (a Fix64) DivExact(b Fix64) (Fix64, error) {
+	res, exact, err := a.fmd(Fix64One, b, RoundTowardZero)
+
+	if err == nil && !exact {
+		return Fix64Zero, InexactError{}
 	}
 
-	return UFix64(quo), nil
+	return res, err
+}
+
+// MulX returns the product of `a` and `b` like Mul, along with a flag that is true if the product
+// was exact (i.e. no rounding was needed), or an error on overflow or underflow.
+func (a UFix64) MulX(b UFix64, round RoundingMode) (UFix64, bool, error) {
+	return a.fmd(b, UFix64One, round)
+}
+
+// MulX returns the product of `a` and `b` like Mul, along with a flag that is true if the product
+// was exact (i.e. no rounding was needed), or an error on overflow or underflow.
+func (a Fix64) MulX(b Fix64, round RoundingMode) (Fix64, bool, error) {
+	return a.fmd(b, Fix64One, round)
+}
+
+// DivX returns the quotient of `a` and `b` like Div, along with a flag that is true if the quotient
+// was exact (i.e. no rounding was needed), or an error on division by zero, overflow, or underflow.
+func (a UFix64) DivX(b UFix64, round RoundingMode) (UFix64, bool, error) {
+	return a.fmd(UFix64One, b, round)
+}
+
+// DivX returns the quotient of `a` and `b` like Div, along with a flag that is true if the quotient
+// was exact (i.e. no rounding was needed), or an error on division by zero, overflow, or underflow.
+func (a Fix64) DivX(b Fix64, round RoundingMode) (Fix64, bool, error) {
+	return a.fmd(Fix64One, b, round)
+}
+
+// FMDX returns `a*b/c` like FMD, along with a flag that is true if the result was exact (i.e. no
+// rounding was needed), or an error on division by zero, overflow, or underflow.
+func (a UFix64) FMDX(b, c UFix64, round RoundingMode) (UFix64, bool, error) {
+	return a.fmd(b, c, round)
+}
+
+// FMDX returns `a*b/c` like FMD, along with a flag that is true if the result was exact (i.e. no
+// rounding was needed), or an error on division by zero, overflow, or underflow.
+func (a Fix64) FMDX(b, c Fix64, round RoundingMode) (Fix64, bool, error) {
+	return a.fmd(b, c, round)
+}
+
+// FMD returns a*b/c without intermediate rounding, or an error on division by zero, overflow, or underflow.
+func (a UFix64) FMD(b, c UFix64, round RoundingMode) (UFix64, error) {
+	res, _, err := a.fmd(b, c, round)
+
+	return res, err
 }
 
 // FMD returns `a*b/c` without intermediate rounding, or an error on division by zero, overflow, or underflow.
 func (a Fix64) FMD(b, c Fix64, round RoundingMode) (Fix64, error) {
+	res, _, err := a.fmd(b, c, round)
+
+	return res, err
+}
+
+// fmd implements FMD, additionally returning true if the result is exact (i.e. no rounding was
+// needed).
+func (a UFix64) fmd(b, c UFix64, round RoundingMode) (UFix64, bool, error) {
+	res, exact, code := a.fmdCode(b, c, round)
+
+	return res, exact, code.err()
+}
+
+// fmdCode implements fmd, reporting any failure as an ErrorCode rather than an error, for the
+// checked operators.
+func (a UFix64) fmdCode(b, c UFix64, round RoundingMode) (UFix64, bool, ErrorCode) {
+	if !round.isValid() {
+		return UFix64Zero, false, ErrorCodeInvalidRoundingMode
+	}
+
+	// Must come before the check for a or b == 0 so we flag 0.0/0.0 as an error.
+	if c.IsZero() {
+		return UFix64Zero, false, ErrorCodeDivisionByZero
+	}
+
+	if a.IsZero() || b.IsZero() {
+		return UFix64Zero, true, ErrorCodeNone
+	}
+
+	hi, lo := mul64(raw64(a), raw64(b))
+
+	// We can't get here if `a == 0` or `b == 0` because we checked that first, so the product
+	// is non-zero as udivRoundCode64 requires.
+	return udivRoundCode64(hi, lo, raw64(c), round)
+}
+
+// fmd implements FMD, additionally returning true if the result is exact (i.e. no rounding was
+// needed).
+func (a Fix64) fmd(b, c Fix64, round RoundingMode) (Fix64, bool, error) {
+	res, exact, code := a.fmdCode(b, c, round)
+
+	return res, exact, code.err()
+}
+
+// fmdCode implements fmd, reporting any failure as an ErrorCode rather than an error, for the
+// checked operators.
+func (a Fix64) fmdCode(b, c Fix64, round RoundingMode) (Fix64, bool, ErrorCode) {
+	if !round.isValid() {
+		return Fix64Zero, false, ErrorCodeInvalidRoundingMode
+	}
+
 	// Must come before the check for `a` or `b` == 0 so we flag 0.0/0.0 as an error.
 	if c.IsZero() {
-		return Fix64Zero, DivisionByZeroError{}
+		return Fix64Zero, false, ErrorCodeDivisionByZero
 	}
 
 	if a.IsZero() || b.IsZero() {
-		return Fix64Zero, nil
+		return Fix64Zero, true, ErrorCodeNone
 	}
 
 	// Determine the sign of the result based on the signs of a, b, and c.
@@ -249,7 +522,95 @@ func (a Fix64) FMD(b, c Fix64, round RoundingMode) (Fix64, error) {
 	sign *= signMul
 
 	// Compute the result using unsigned arithmetic.
-	res, err := aUnsigned.FMD(bUnsigned, cUnsigned, round)
+	res, exact, code := aUnsigned.fmdCode(bUnsigned, cUnsigned, round.forSign(sign))
+
+	if code != ErrorCodeNone {
+		return Fix64Zero, false, applySignCode(code, sign)
+	}
+
+	signedRes, code := res.applySignCode(sign)
+
+	return signedRes, exact, code
+}
+
+// FMA returns `a*b + c` without intermediate rounding, or an error on overflow or underflow.
+func (a UFix64) FMA(b, c UFix64, round RoundingMode) (UFix64, error) {
+	if !round.isValid() {
+		return UFix64Zero, InvalidRoundingModeError{}
+	}
+
+	if a.IsZero() || b.IsZero() {
+		return c, nil
+	}
+
+	hi, lo := mul64(raw64(a), raw64(b))
+
+	// Scale up `c` so that it can be added directly to the double-width product.
+	cHi, cLo := mul64(raw64(c), raw64(UFix64One))
+
+	var carry uint64
+	lo, carry = add64(lo, cLo, 0)
+	hi, carry = add64(hi, cHi, carry)
+
+	if carry != 0 {
+		return UFix64Zero, PositiveOverflowError{}
+	}
+
+	res, _, err := udivRound64(hi, lo, raw64(UFix64One), round)
+
+	return res, err
+}
+
+// FMA returns `a*b + c` without intermediate rounding, or an error on overflow, negative overflow,
+// or underflow.
+func (a Fix64) FMA(b, c Fix64, round RoundingMode) (Fix64, error) {
+	if !round.isValid() {
+		return Fix64Zero, InvalidRoundingModeError{}
+	}
+
+	if a.IsZero() || b.IsZero() {
+		return c, nil
+	}
+
+	// We do all of the work on the magnitudes of the product and `c`, keeping track of the sign
+	// separately. This ensures that the rounding is symmetric, the same as in FMD.
+	aUnsigned, aSign := a.Abs()
+	bUnsigned, bSign := b.Abs()
+	cUnsigned, cSign := c.Abs()
+
+	sign := aSign * bSign
+
+	hi, lo := mul64(raw64(aUnsigned), raw64(bUnsigned))
+	cHi, cLo := mul64(raw64(cUnsigned), raw64(UFix64One))
+
+	if sign == cSign {
+		// Same signs, add the magnitudes.
+		var carry uint64
+		lo, carry = add64(lo, cLo, 0)
+		hi, carry = add64(hi, cHi, carry)
+
+		if carry != 0 {
+			return Fix64Zero, applySign(PositiveOverflowError{}, sign)
+		}
+	} else {
+		// Different signs, subtract the smaller magnitude from the larger one, and use the sign
+		// of the larger one.
+		if ult64(hi, cHi) || (isEqual64(hi, cHi) && ult64(lo, cLo)) {
+			hi, lo, cHi, cLo = cHi, cLo, hi, lo
+			sign = cSign
+		}
+
+		var borrow uint64
+		lo, borrow = sub64(lo, cLo, 0)
+		hi, _ = sub64(hi, cHi, borrow)
+
+		// The terms cancelled out exactly.
+		if isZero64(hi) && isZero64(lo) {
+			return Fix64Zero, nil
+		}
+	}
+
+	res, _, err := udivRound64(hi, lo, raw64(UFix64One), round.forSign(sign))
 
 	if err != nil {
 		return Fix64Zero, applySign(err, sign)
@@ -258,7 +619,159 @@ func (a Fix64) FMD(b, c Fix64, round RoundingMode) (Fix64, error) {
 	return res.ApplySign(sign)
 }
 
-// Mod returns the remainder of `a` divided by `b`, or an error on division by zero.
+// Dot2 returns `a*b + c*d` without intermediate rounding, or an error on overflow or underflow.
+func (a UFix64) Dot2(b, c, d UFix64, round RoundingMode) (UFix64, error) {
+	return SumOfProductsUFix64([][2]UFix64{{a, b}, {c, d}}, round)
+}
+
+// Dot2 returns `a*b + c*d` without intermediate rounding, or an error on overflow, negative
+// overflow, or underflow.
+func (a Fix64) Dot2(b, c, d Fix64, round RoundingMode) (Fix64, error) {
+	return SumOfProductsFix64([][2]Fix64{{a, b}, {c, d}}, round)
+}
+
+// SumOfProductsUFix64 returns the sum of the products of each pair of values, with a single
+// rounding at the end, or an error on overflow or underflow.
+func SumOfProductsUFix64(pairs [][2]UFix64, round RoundingMode) (UFix64, error) {
+	var acc accum64
+
+	for _, pair := range pairs {
+		acc.addProduct(pair[0], pair[1], 1)
+	}
+
+	res, _, err := acc.value(round)
+
+	return res, err
+}
+
+// SumOfProductsFix64 returns the sum of the products of each pair of values, with a single
+// rounding at the end, or an error on overflow, negative overflow, or underflow. Intermediate
+// sums are allowed to exceed the range of Fix64, provided the final result is in range.
+func SumOfProductsFix64(pairs [][2]Fix64, round RoundingMode) (Fix64, error) {
+	var acc accum64
+
+	for _, pair := range pairs {
+		aUnsigned, aSign := pair[0].Abs()
+		bUnsigned, bSign := pair[1].Abs()
+
+		acc.addProduct(aUnsigned, bUnsigned, aSign*bSign)
+	}
+
+	res, sign, err := acc.value(round)
+
+	if err != nil {
+		return Fix64Zero, applySign(err, sign)
+	}
+
+	return res.ApplySign(sign)
+}
+
+// udivRound64 divides the double-width, NON-ZERO value (hi, lo) by `y`, rounding the result as
+// specified, or returns an error if the result overflows or underflows. The boolean result is true
+// if the division was exact (i.e. no rounding was needed). This is the core of FMD, and the other
+// operations that need to scale down a wide intermediate value with a single rounding.
+func udivRound64(hi, lo, y raw64, round RoundingMode) (UFix64, bool, error) {
+	res, exact, code := udivRoundCode64(hi, lo, y, round)
+
+	return res, exact, code.err()
+}
+
+// udivRoundCode64 implements udivRound64, reporting any failure as an ErrorCode rather than an
+// error.
+func udivRoundCode64(hi, lo, y raw64, round RoundingMode) (UFix64, bool, ErrorCode) {
+	if !round.isValid() {
+		return UFix64Zero, false, ErrorCodeInvalidRoundingMode
+	}
+
+	// If the hi part is >= the divisor the result can't fit in 64 bits.
+	if !ult64(hi, y) {
+		return UFix64Zero, false, ErrorCodeOverflow
+	}
+
+	quo, rem := div64(hi, lo, y)
+	exact := isZero64(rem)
+
+	if ushouldRound64(quo, rem, y, round) {
+		var carry uint64
+		quo, carry = add64(quo, raw64Zero, 1)
+
+		// Make sure we don't "round up" to a value outside of the range of UFix64!
+		if carry != 0 {
+			return UFix64Zero, false, ErrorCodeOverflow
+		}
+	}
+
+	// The numerator is non-zero, so a quotient of 0 means the result is too small to
+	// represent, i.e. underflow. Note that we check this AFTER rounding.
+	if isZero64(quo) {
+		return UFix64Zero, false, ErrorCodeUnderflow
+	}
+
+	return UFix64(quo), exact, ErrorCodeNone
+}
+
+// accum64 is a signed accumulator for products of fixed-point values. Each product is added
+// at full, double-width precision (i.e. before scaling down and rounding), and the running total
+// is extended by a signed overflow word so that it can't overflow for any realistic number of
+// terms. The total is only scaled down and rounded when value() is called.
+type accum64 struct {
+	hi, lo raw64 // The low two words of the total, in two's-complement form
+	ext    int64 // The extension word (i.e. the sign and any carries out of the top word)
+}
+
+// addProduct adds (or subtracts, if sign is negative) the full product of `a` and `b`.
+func (acc *accum64) addProduct(a, b UFix64, sign int64) {
+	hi, lo := mul64(raw64(a), raw64(b))
+
+	var carry uint64
+
+	if sign < 0 {
+		acc.lo, carry = sub64(acc.lo, lo, 0)
+		acc.hi, carry = sub64(acc.hi, hi, carry)
+		acc.ext -= int64(carry)
+	} else {
+		acc.lo, carry = add64(acc.lo, lo, 0)
+		acc.hi, carry = add64(acc.hi, hi, carry)
+		acc.ext += int64(carry)
+	}
+}
+
+// value returns the magnitude of the total scaled down by the scale factor and rounded as
+// specified, along with the sign of the total, or an error if the magnitude overflows or
+// underflows. (The error is always unsigned, callers should use applySign() as needed.)
+func (acc *accum64) value(round RoundingMode) (UFix64, int64, error) {
+	// Must come before the check for a zero total, so the error doesn't depend on the values.
+	if !round.isValid() {
+		return UFix64Zero, 1, InvalidRoundingModeError{}
+	}
+
+	hi, lo, ext, sign := acc.hi, acc.lo, acc.ext, int64(1)
+
+	if ext < 0 {
+		// Negate all three words to get the magnitude of the total.
+		var borrow uint64
+		lo, borrow = sub64(raw64Zero, lo, 0)
+		hi, borrow = sub64(raw64Zero, hi, borrow)
+		ext = -ext - int64(borrow)
+		sign = -1
+	}
+
+	if ext != 0 {
+		return UFix64Zero, sign, PositiveOverflowError{}
+	}
+
+	if isZero64(hi) && isZero64(lo) {
+		return UFix64Zero, sign, nil
+	}
+
+	res, _, err := udivRound64(hi, lo, raw64(UFix64One), round.forSign(sign))
+
+	return res, sign, err
+}
+
+// Mod returns the remainder of `a` divided by `b`, or an error on division by zero. Note that
+// the remainder is always exactly representable, so unlike the other operations, Mod never rounds
+// and doesn't take a rounding mode.
 func (a UFix64) Mod(b UFix64) (UFix64, error) {
 	if b.IsZero() {
 		return UFix64Zero, DivisionByZeroError{}
@@ -288,14 +801,432 @@ func (a Fix64) Mod(b Fix64) (Fix64, error) {
 	return rem.ApplySign(aSign)
 }
 
+// MulPow10 returns `a` multiplied by 10^n, or an error on overflow. The decimal point is shifted
+// with an integer multiplication, so the result is always exact.
+func (a UFix64) MulPow10(n uint) (UFix64, error) {
+	if a.IsZero() {
+		return UFix64Zero, nil
+	}
+
+	p, ok := pow10Raw64(uint64(n))
+
+	if !ok {
+		return UFix64Zero, PositiveOverflowError{}
+	}
+
+	hi, lo := mul64(raw64(a), p)
+
+	if !isZero64(hi) {
+		return UFix64Zero, PositiveOverflowError{}
+	}
+
+	return UFix64(lo), nil
+}
+
+// MulPow10 returns `a` multiplied by 10^n, or an error on overflow. The result is always exact.
+func (a Fix64) MulPow10(n uint) (Fix64, error) {
+	aUnsigned, sign := a.Abs()
+
+	res, err := aUnsigned.MulPow10(n)
+
+	if err != nil {
+		return Fix64Zero, applySign(err, sign)
+	}
+
+	return res.ApplySign(sign)
+}
+
+// DivPow10 returns `a` divided by 10^n, rounded using the given rounding mode, or an error on
+// underflow.
+func (a UFix64) DivPow10(n uint, round RoundingMode) (UFix64, error) {
+	if !round.isValid() {
+		return UFix64Zero, InvalidRoundingModeError{}
+	}
+
+	if a.IsZero() {
+		return UFix64Zero, nil
+	}
+
+	p, ok := pow10Raw64(uint64(n))
+
+	if ok {
+		res, _, err := udivRound64(raw64Zero, raw64(a), p, round)
+		return res, err
+	}
+
+	// 10^n doesn't fit in the raw type, so the quotient is zero and the remainder (a) is less
+	// than half of the divisor. Only the modes that round away from zero can round up.
+	roundUp := false
+
+	switch round {
+	case RoundAwayFromZero, RoundCeil:
+		roundUp = true
+	case RoundStochastic:
+		// The probability of rounding up is a/10^n, which is split into a/10^k (for the largest
+		// power k that fits) times a 1-in-10 chance for each of the remaining powers.
+		k := uint64(len(pow10Table64) - 1)
+		ten, _ := pow10Raw64(1)
+		roundUp = ushouldRound64(raw64Zero, raw64(a), pow10Table64[k], round)
+
+		for ; roundUp && k < uint64(n); k++ {
+			roundUp = ushouldRound64(raw64Zero, raw64(UFix64Iota), ten, round)
+		}
+	}
+
+	if roundUp {
+		return UFix64Iota, nil
+	}
+
+	return UFix64Zero, UnderflowError{}
+}
+
+// DivPow10 returns `a` divided by 10^n, rounded using the given rounding mode, or an error on
+// underflow.
+func (a Fix64) DivPow10(n uint, round RoundingMode) (Fix64, error) {
+	aUnsigned, sign := a.Abs()
+
+	res, err := aUnsigned.DivPow10(n, round.forSign(sign))
+
+	if err != nil {
+		return Fix64Zero, applySign(err, sign)
+	}
+
+	return res.ApplySign(sign)
+}
+
+// Double returns `a` multiplied by two, or an error on overflow. It's implemented as a shift,
+// so it's much cheaper than Mul and the result is always exact.
+func (a UFix64) Double() (UFix64, error) {
+	if isNeg64(raw64(a)) {
+		// The top bit is set, so it would be shifted out.
+		return UFix64Zero, PositiveOverflowError{}
+	}
+
+	return UFix64(shiftLeft64(raw64(a), 1)), nil
+}
+
+// Double returns `a` multiplied by two, or an error on overflow. The result is always exact.
+func (a Fix64) Double() (Fix64, error) {
+	res := shiftLeft64(raw64(a), 1)
+
+	// The shift overflows if it changes the sign bit.
+	if isNeg64(res) != a.IsNeg() {
+		if a.IsNeg() {
+			return Fix64Zero, NegativeOverflowError{}
+		}
+		return Fix64Zero, PositiveOverflowError{}
+	}
+
+	return Fix64(res), nil
+}
+
+// Halve returns `a` divided by two, rounded using the given rounding mode, or an error on
+// underflow. Like Double, it's implemented as a shift instead of a full division.
+func (a UFix64) Halve(round RoundingMode) (UFix64, error) {
+	if !round.isValid() {
+		return UFix64Zero, InvalidRoundingModeError{}
+	}
+
+	if a.IsZero() {
+		return UFix64Zero, nil
+	}
+
+	quo := ushiftRight64(raw64(a), 1)
+	two := shiftLeft64(raw64(UFix64Iota), 1)
+	rem, _ := sub64(raw64(a), shiftLeft64(quo, 1), 0)
+
+	if ushouldRound64(quo, rem, two, round) {
+		// Can't overflow, quo is at most half the maximum value.
+		quo, _ = add64(quo, raw64Zero, 1)
+	}
+
+	if isZero64(quo) {
+		return UFix64Zero, UnderflowError{}
+	}
+
+	return UFix64(quo), nil
+}
+
+// Halve returns `a` divided by two, rounded using the given rounding mode, or an error on
+// underflow.
+func (a Fix64) Halve(round RoundingMode) (Fix64, error) {
+	aUnsigned, sign := a.Abs()
+
+	res, err := aUnsigned.Halve(round.forSign(sign))
+
+	if err != nil {
+		return Fix64Zero, err
+	}
+
+	return res.ApplySign(sign)
+}
+
+// == Checked Operators ==
+//
+// The checked variants below return a boolean instead of an error, which is cheaper to test in
+// hot loops. A false result means the operation failed for any reason (overflow, underflow,
+// division by zero, etc.), and the returned value should be ignored. Callers that need to know
+// WHY an operation failed should use the error-returning methods instead.
+
+// AddChecked returns the sum of `a` and `b`, and false on overflow.
+func (a UFix64) AddChecked(b UFix64) (UFix64, bool) {
+	sum, carry := add64(raw64(a), raw64(b), 0)
+
+	return UFix64(sum), carry == 0
+}
+
+// AddChecked returns the sum of `a` and `b`, and false on overflow or negative overflow.
+func (a Fix64) AddChecked(b Fix64) (Fix64, bool) {
+	sum, _ := add64(raw64(a), raw64(b), 0)
+
+	res := Fix64(sum)
+
+	// Overflow happened if both operands have the same sign, and the result has a different sign.
+	return res, a.IsNeg() != b.IsNeg() || a.IsNeg() == res.IsNeg()
+}
+
+// SubChecked returns the difference of `a` and `b`, and false on negative overflow.
+func (a UFix64) SubChecked(b UFix64) (UFix64, bool) {
+	diff, borrow := sub64(raw64(a), raw64(b), 0)
+
+	return UFix64(diff), borrow == 0
+}
+
+// SubChecked returns the difference of `a` and `b`, and false on overflow or negative overflow.
+func (a Fix64) SubChecked(b Fix64) (Fix64, bool) {
+	diff, _ := sub64(raw64(a), raw64(b), 0)
+
+	res := Fix64(diff)
+
+	// Overflow happened if the operands have different signs, and the result has a different
+	// sign from `a`.
+	return res, a.IsNeg() == b.IsNeg() || a.IsNeg() == res.IsNeg()
+}
+
+// MulChecked returns the product of `a` and `b`, and false on overflow or underflow.
+func (a UFix64) MulChecked(b UFix64, round RoundingMode) (UFix64, bool) {
+	res, _, code := a.fmdCode(b, UFix64One, round)
+
+	return res, code == ErrorCodeNone
+}
+
+// MulChecked returns the product of `a` and `b`, and false on overflow or underflow.
+func (a Fix64) MulChecked(b Fix64, round RoundingMode) (Fix64, bool) {
+	res, _, code := a.fmdCode(b, Fix64One, round)
+
+	return res, code == ErrorCodeNone
+}
+
+// DivChecked returns the quotient of `a` and `b`, and false on division by zero, overflow, or
+// underflow.
+func (a UFix64) DivChecked(b UFix64, round RoundingMode) (UFix64, bool) {
+	res, _, code := a.fmdCode(UFix64One, b, round)
+
+	return res, code == ErrorCodeNone
+}
+
+// DivChecked returns the quotient of `a` and `b`, and false on division by zero, overflow, or
+// underflow.
+func (a Fix64) DivChecked(b Fix64, round RoundingMode) (Fix64, bool) {
+	res, _, code := a.fmdCode(Fix64One, b, round)
+
+	return res, code == ErrorCodeNone
+}
+
+// == Wrapping Operators ==
+//
+// The wrapping variants below never fail, instead they return the raw result modulo 2^N, where N
+// is the bit width of the type (i.e. the result "wraps around" using two's-complement semantics,
+// exactly like Go's built-in integer operators). These are useful for checksums and hash-derived values, but
+// are almost certainly NOT what you want for financial calculations!
+
+// AddWrap returns the sum of `a` and `b`, wrapping around on overflow.
+func (a UFix64) AddWrap(b UFix64) UFix64 {
+	sum, _ := add64(raw64(a), raw64(b), 0)
+
+	return UFix64(sum)
+}
+
+// AddWrap returns the sum of `a` and `b`, wrapping around on overflow or negative overflow.
+func (a Fix64) AddWrap(b Fix64) Fix64 {
+	sum, _ := add64(raw64(a), raw64(b), 0)
+
+	return Fix64(sum)
+}
+
+// SubWrap returns the difference of `a` and `b`, wrapping around on negative overflow.
+func (a UFix64) SubWrap(b UFix64) UFix64 {
+	diff, _ := sub64(raw64(a), raw64(b), 0)
+
+	return UFix64(diff)
+}
+
+// SubWrap returns the difference of `a` and `b`, wrapping around on overflow or negative overflow.
+func (a Fix64) SubWrap(b Fix64) Fix64 {
+	diff, _ := sub64(raw64(a), raw64(b), 0)
+
+	return Fix64(diff)
+}
+
+// MulWrap returns the product of `a` and `b`, rounded as specified, keeping only the low bits
+// of the raw result if the product overflows. Underflow results in zero. An invalid rounding mode
+// is treated as RoundTowardZero, and panics in debug builds.
+func (a UFix64) MulWrap(b UFix64, round RoundingMode) UFix64 {
+	if !round.isValid() {
+		debugPanic("MulWrap: invalid rounding mode")
+	}
+
+	hi, lo := mul64(raw64(a), raw64(b))
+
+	// The full quotient might not fit in the result, but we only need the low bits of it. Removing
+	// all multiples of the scale factor from the high part of the product removes exactly the
+	// multiples of 2^N from the quotient, and ensures that the division below can't overflow.
+	hi = mod64(hi, raw64(UFix64One))
+
+	quo, rem := div64(hi, lo, raw64(UFix64One))
+
+	if ushouldRound64(quo, rem, raw64(UFix64One), round) {
+		quo, _ = add64(quo, raw64Zero, 1)
+	}
+
+	return UFix64(quo)
+}
+
+// MulWrap returns the product of `a` and `b`, rounded as specified, keeping only the low bits
+// of the two's-complement representation if the product overflows. Underflow results in zero.
+// An invalid rounding mode is treated as RoundTowardZero, and panics in debug builds.
+func (a Fix64) MulWrap(b Fix64, round RoundingMode) Fix64 {
+	aUnsigned, aSign := a.Abs()
+	bUnsigned, bSign := b.Abs()
+	sign := aSign * bSign
+
+	res := raw64(aUnsigned.MulWrap(bUnsigned, round.forSign(sign)))
+
+	if sign < 0 {
+		res = neg64(res)
+	}
+
+	return Fix64(res)
+}
+
+// == Must Operators ==
+//
+// The Must variants below panic instead of returning an error. They are intended for
+// initializing package-level variables and for test fixtures, where the inputs are known ahead
+// of time and error handling would just be noise. Don't use them on untrusted inputs!
+
+// MustAdd returns the sum of `a` and `b`, and panics on overflow.
+func (a UFix64) MustAdd(b UFix64) UFix64 { return must(a.Add(b)) }
+func (a Fix64) MustAdd(b Fix64) Fix64    { return must(a.Add(b)) }
+
+// MustSub returns the difference of `a` and `b`, and panics on overflow or negative overflow.
+func (a UFix64) MustSub(b UFix64) UFix64 { return must(a.Sub(b)) }
+func (a Fix64) MustSub(b Fix64) Fix64    { return must(a.Sub(b)) }
+
+// MustMul returns the product of `a` and `b`, and panics on overflow or underflow.
+func (a UFix64) MustMul(b UFix64, round RoundingMode) UFix64 { return must(a.Mul(b, round)) }
+func (a Fix64) MustMul(b Fix64, round RoundingMode) Fix64    { return must(a.Mul(b, round)) }
+
+// MustDiv returns the quotient of `a` and `b`, and panics on division by zero, overflow, or underflow.
+func (a UFix64) MustDiv(b UFix64, round RoundingMode) UFix64 { return must(a.Div(b, round)) }
+func (a Fix64) MustDiv(b Fix64, round RoundingMode) Fix64    { return must(a.Div(b, round)) }
+
+// MustFMD returns `a*b/c`, and panics on division by zero, overflow, or underflow.
+func (a UFix64) MustFMD(b, c UFix64, round RoundingMode) UFix64 { return must(a.FMD(b, c, round)) }
+func (a Fix64) MustFMD(b, c Fix64, round RoundingMode) Fix64    { return must(a.FMD(b, c, round)) }
+
+// == Context Operators ==
+//
+// The Context variants below use the rounding mode and trap settings of the Context, see
+// Context for details.
+
+// AddUFix64 returns the sum of `a` and `b` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) AddUFix64(a, b UFix64) (UFix64, error) {
+	res, err := a.Add(b)
+	res, err = contextResult(ctx, res, true, err, UFix64Max, UFix64Zero)
+	return res, wrapOpError(ctx, err, "Add", a, b)
+}
+
+// AddFix64 returns the sum of `a` and `b` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) AddFix64(a, b Fix64) (Fix64, error) {
+	res, err := a.Add(b)
+	res, err = contextResult(ctx, res, true, err, Fix64Max, Fix64Min)
+	return res, wrapOpError(ctx, err, "Add", a, b)
+}
+
+// SubUFix64 returns the difference of `a` and `b` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) SubUFix64(a, b UFix64) (UFix64, error) {
+	res, err := a.Sub(b)
+	res, err = contextResult(ctx, res, true, err, UFix64Max, UFix64Zero)
+	return res, wrapOpError(ctx, err, "Sub", a, b)
+}
+
+// SubFix64 returns the difference of `a` and `b` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) SubFix64(a, b Fix64) (Fix64, error) {
+	res, err := a.Sub(b)
+	res, err = contextResult(ctx, res, true, err, Fix64Max, Fix64Min)
+	return res, wrapOpError(ctx, err, "Sub", a, b)
+}
+
+// MulUFix64 returns the product of `a` and `b` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) MulUFix64(a, b UFix64) (UFix64, error) {
+	res, exact, err := a.MulX(b, ctx.Rounding)
+	res, err = contextResult(ctx, res, exact, err, UFix64Max, UFix64Zero)
+	return res, wrapOpError(ctx, err, "Mul", a, b)
+}
+
+// MulFix64 returns the product of `a` and `b` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) MulFix64(a, b Fix64) (Fix64, error) {
+	res, exact, err := a.MulX(b, ctx.Rounding)
+	res, err = contextResult(ctx, res, exact, err, Fix64Max, Fix64Min)
+	return res, wrapOpError(ctx, err, "Mul", a, b)
+}
+
+// DivUFix64 returns the quotient of `a` and `b` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) DivUFix64(a, b UFix64) (UFix64, error) {
+	res, exact, err := a.DivX(b, ctx.Rounding)
+	res, err = contextResult(ctx, res, exact, err, UFix64Max, UFix64Zero)
+	return res, wrapOpError(ctx, err, "Div", a, b)
+}
+
+// DivFix64 returns the quotient of `a` and `b` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) DivFix64(a, b Fix64) (Fix64, error) {
+	res, exact, err := a.DivX(b, ctx.Rounding)
+	res, err = contextResult(ctx, res, exact, err, Fix64Max, Fix64Min)
+	return res, wrapOpError(ctx, err, "Div", a, b)
+}
+
+// FMDUFix64 returns `a*b/c` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) FMDUFix64(a, b, c UFix64) (UFix64, error) {
+	res, exact, err := a.FMDX(b, c, ctx.Rounding)
+	res, err = contextResult(ctx, res, exact, err, UFix64Max, UFix64Zero)
+	return res, wrapOpError(ctx, err, "FMD", a, b, c)
+}
+
+// FMDFix64 returns `a*b/c` according to the rounding and trap settings of `ctx`.
+func (ctx *Context) FMDFix64(a, b, c Fix64) (Fix64, error) {
+	res, exact, err := a.FMDX(b, c, ctx.Rounding)
+	res, err = contextResult(ctx, res, exact, err, Fix64Max, Fix64Min)
+	return res, wrapOpError(ctx, err, "FMD", a, b, c)
+}
+
 // Sqrt returns the square root of `a` using Newton-Rhaphson. Note that this
 // method returns an error result for consistency with other methods,
-// but can't actually ever fail...
+// but can't actually ever fail... RoundStochastic is treated as rounding
+// to nearest.
 func (a UFix64) Sqrt(round RoundingMode) (UFix64, error) {
+	if !round.isValid() {
+		return UFix64Zero, InvalidRoundingModeError{}
+	}
+
 	if a.IsZero() {
 		return UFix64Zero, nil
 	}
 
+	// The result is always positive, so we can treat the directed rounding modes as their
+	// symmetric equivalents.
+	round = round.forSign(1)
+
 	// Count the number of leading zero bits in `a`, this is a cheap way of estimating
 	// the order of magnitude of the input.
 	n := leadingZeroBits64(raw64(a))
@@ -412,7 +1343,31 @@ func (a UFix64) Sqrt(round RoundingMode) (UFix64, error) {
 	return UFix64(est), nil
 }
 
-func (a UFix64) Ln() (Fix64, error) {
+// Sqrt returns the square root of `a`, or a domain error if `a` is negative.
+func (a Fix64) Sqrt(round RoundingMode) (Fix64, error) {
+	if a.IsNeg() {
+		return Fix64Zero, OutOfDomainErrorError{}
+	}
+
+	res, err := UFix64(a).Sqrt(round)
+
+	if err != nil {
+		return Fix64Zero, err
+	}
+
+	// The square root is never larger than max(a, 1), so this can't overflow.
+	return res.ApplySign(1)
+}
+
+// Ln returns the natural logarithm of `a`, rounded to nearest (ties away from zero), or an error
+// if `a` is zero.
+func (a UFix64) Ln() (Fix64, error) { return a.LnRound(RoundNearestHalfAway) }
+
+// LnRound is the same as Ln, but rounds the result using the given rounding mode. Note that the
+// result is rounded from an approximation with much higher precision than Fix64, rather than
+// from the exact result, so in rare cases where the exact result is extremely close to a
+// representable value, the directed rounding modes might pick the wrong side.
+func (a UFix64) LnRound(round RoundingMode) (Fix64, error) {
 	// TODO: x192.ln() provides a ton of precision that we don't need, it
 	// would be ideal if we could pass an error limit to it so it could
 	// stop early when we don't need the full precision.
@@ -422,7 +1377,7 @@ func (a UFix64) Ln() (Fix64, error) {
 		return Fix64Zero, err
 	}
 
-	res, err := res192.toFix64(RoundNearestHalfAway)
+	res, err := res192.toFix64(round)
 
 	// TODO: Should this catch underflow?
 	if _, ok := err.(UnderflowError); ok {
@@ -433,9 +1388,49 @@ func (a UFix64) Ln() (Fix64, error) {
 	return res, err
 }
 
+// LogBase returns the logarithm of `a` in the given base, rounded using the given rounding mode.
+// Returns a domain error if `a` is zero, or if `base` is zero or one (where the logarithm is
+// undefined). The same caveat as LnRound applies to the directed rounding modes.
+func (a UFix64) LogBase(base UFix64, round RoundingMode) (Fix64, error) {
+	if base.IsZero() || base == UFix64One {
+		return Fix64Zero, OutOfDomainErrorError{}
+	}
+
+	lnA, err := a.toFix192().ln()
+
+	if err != nil {
+		return Fix64Zero, err
+	}
+
+	lnBase, err := base.toFix192().ln()
+
+	if err != nil {
+		return Fix64Zero, err
+	}
+
+	res192, err := lnA.sdiv(lnBase)
+
+	if err != nil {
+		return Fix64Zero, err
+	}
+
+	res, err := res192.toFix64(round)
+
+	// Same as Ln, treat underflows as zero.
+	if _, ok := err.(UnderflowError); ok {
+		return Fix64Zero, nil
+	}
+
+	return res, err
+}
+
 // Exp(a) returns `e^a`, or an error on overflow or underflow. Note that although the
 // input is a Fix64, the output is a UFix64, since `e^a` is always positive.
-func (a Fix64) Exp() (UFix64, error) {
+func (a Fix64) Exp() (UFix64, error) { return a.ExpRound(RoundNearestHalfAway) }
+
+// ExpRound is the same as Exp, but rounds the result using the given rounding mode. The same
+// caveat as LnRound applies to the directed rounding modes.
+func (a Fix64) ExpRound(round RoundingMode) (UFix64, error) {
 	// If `a` is 0, return 1.
 	if a.IsZero() {
 		return UFix64One, nil
@@ -455,10 +1450,16 @@ func (a Fix64) Exp() (UFix64, error) {
 		return UFix64Zero, err
 	}
 
-	return res192.toUFix64(RoundNearestHalfAway)
+	return res192.toUFix64(round)
 }
 
-func (a UFix64) Pow(b Fix64) (UFix64, error) {
+// Pow returns `a^b`, rounded to nearest (ties away from zero), or an error on overflow or
+// underflow, or a domain error if `a` is zero and `b` is negative.
+func (a UFix64) Pow(b Fix64) (UFix64, error) { return a.PowRound(b, RoundNearestHalfAway) }
+
+// PowRound is the same as Pow, but rounds the result using the given rounding mode. The same
+// caveat as LnRound applies to the directed rounding modes.
+func (a UFix64) PowRound(b Fix64, round RoundingMode) (UFix64, error) {
 	// We accept 0^0 as 1.
 	if b.IsZero() {
 		return UFix64One, nil
@@ -467,7 +1468,7 @@ func (a UFix64) Pow(b Fix64) (UFix64, error) {
 	if a.IsZero() {
 		if b.IsNeg() {
 			// 0^negative is undefined, so we return an error.
-			return UFix64Zero, DivisionByZeroError{} // 0^negative is undefined
+			return UFix64Zero, OutOfDomainErrorError{}
 		} else {
 			// 0^positive is 0.
 			return UFix64Zero, nil
@@ -493,15 +1494,15 @@ func (a UFix64) Pow(b Fix64) (UFix64, error) {
 		return UFix64Zero, err
 	}
 
-	return res192.toUFix64(RoundNearestHalfAway)
+	return res192.toUFix64(round)
 }
 
-func trigResult64(res192 fix192, err error) (Fix64, error) {
+func trigResult64(res192 fix192, err error, round RoundingMode) (Fix64, error) {
 	if err != nil {
 		return Fix64Zero, err
 	}
 
-	res, err := res192.toFix64(RoundNearestHalfAway)
+	res, err := res192.toFix64(round)
 
 	switch err.(type) {
 	case nil:
@@ -515,16 +1516,26 @@ func trigResult64(res192 fix192, err error) (Fix64, error) {
 	}
 }
 
-func (a Fix64) Sin() (Fix64, error) {
+// Sin returns the sine of `a` (in radians), rounded to nearest (ties away from zero).
+func (a Fix64) Sin() (Fix64, error) { return a.SinRound(RoundNearestHalfAway) }
+
+// SinRound is the same as Sin, but rounds the result using the given rounding mode. The same
+// caveat as LnRound applies to the directed rounding modes.
+func (a Fix64) SinRound(round RoundingMode) (Fix64, error) {
 	x192 := a.toFix192()
 	res192, err := x192.sin()
 
-	return trigResult64(res192, err)
+	return trigResult64(res192, err, round)
 }
 
-func (a Fix64) Cos() (Fix64, error) {
+// Cos returns the cosine of `a` (in radians), rounded to nearest (ties away from zero).
+func (a Fix64) Cos() (Fix64, error) { return a.CosRound(RoundNearestHalfAway) }
+
+// CosRound is the same as Cos, but rounds the result using the given rounding mode. The same
+// caveat as LnRound applies to the directed rounding modes.
+func (a Fix64) CosRound(round RoundingMode) (Fix64, error) {
 	x192 := a.toFix192()
 	res192, err := x192.cos()
 
-	return trigResult64(res192, err)
+	return trigResult64(res192, err, round)
 }
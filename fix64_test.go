@@ -19,7 +19,12 @@ package fixedPoint
 import (
 	"bufio"
 	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand/v2"
 	"os/exec"
+	"slices"
 	"strconv"
 	"strings"
 	"testing"
@@ -928,3 +933,2014 @@ func TestCosFix64(t *testing.T) {
 	}
 	t.Log(testState.operation+testState.outType, testState.successCount, "passed,", testState.failureCount, "failed")
 }
+
+// A small set of "interesting" raw values used by tests that compare two implementations of the
+// same operation against each other, rather than against data generated by the Python scripts.
+var edgeValues64 = []uint64{
+	0x0000000000000000,
+	0x0000000000000001,
+	0x0000000000000002,
+	0x0000000005f5e0ff,
+	0x0000000005f5e100, // 1.0
+	0x0000000005f5e101,
+	0x00000000bebc2000, // 32.0
+	0x0000002540be4000, // 1600.0
+	0x00000000ffffffff,
+	0x0000000100000000,
+	0x7ffffffffffffffe,
+	0x7fffffffffffffff,
+	0x8000000000000000,
+	0x8000000000000001,
+	0xfffffffffa0a1f00, // -1.0 as Fix64
+	0xfffffffffffffffe,
+	0xffffffffffffffff,
+}
+
+func TestCheckedUFix64(t *testing.T) {
+
+	t.Parallel()
+
+	check := func(op string, x, y uint64, res UFix64, ok bool, expected UFix64, err error) {
+		if ok != (err == nil) || (ok && res != expected) {
+			t.Errorf("%sCheckedUFix64 (0x%016x, 0x%016x) = 0x%016x, %v; want 0x%016x, %v",
+				op, x, y, uint64(res), ok, uint64(expected), err)
+		}
+	}
+
+	for _, x := range edgeValues64 {
+		for _, y := range edgeValues64 {
+			a := UFix64(x)
+			b := UFix64(y)
+
+			res, ok := a.AddChecked(b)
+			expected, err := a.Add(b)
+			check("Add", x, y, res, ok, expected, err)
+
+			res, ok = a.SubChecked(b)
+			expected, err = a.Sub(b)
+			check("Sub", x, y, res, ok, expected, err)
+
+			res, ok = a.MulChecked(b, RoundHalfEven)
+			expected, err = a.Mul(b, RoundHalfEven)
+			check("Mul", x, y, res, ok, expected, err)
+
+			res, ok = a.DivChecked(b, RoundUp)
+			expected, err = a.Div(b, RoundUp)
+			check("Div", x, y, res, ok, expected, err)
+		}
+	}
+}
+
+func TestCheckedFix64(t *testing.T) {
+
+	t.Parallel()
+
+	check := func(op string, x, y uint64, res Fix64, ok bool, expected Fix64, err error) {
+		if ok != (err == nil) || (ok && res != expected) {
+			t.Errorf("%sCheckedFix64 (0x%016x, 0x%016x) = 0x%016x, %v; want 0x%016x, %v",
+				op, x, y, uint64(res), ok, uint64(expected), err)
+		}
+	}
+
+	for _, x := range edgeValues64 {
+		for _, y := range edgeValues64 {
+			a := Fix64(x)
+			b := Fix64(y)
+
+			res, ok := a.AddChecked(b)
+			expected, err := a.Add(b)
+			check("Add", x, y, res, ok, expected, err)
+
+			res, ok = a.SubChecked(b)
+			expected, err = a.Sub(b)
+			check("Sub", x, y, res, ok, expected, err)
+
+			res, ok = a.MulChecked(b, RoundHalfEven)
+			expected, err = a.Mul(b, RoundHalfEven)
+			check("Mul", x, y, res, ok, expected, err)
+
+			res, ok = a.DivChecked(b, RoundUp)
+			expected, err = a.Div(b, RoundUp)
+			check("Div", x, y, res, ok, expected, err)
+		}
+	}
+}
+
+func TestMust64(t *testing.T) {
+
+	t.Parallel()
+
+	if res := UFix64One.MustAdd(UFix64One); res != 2*UFix64One {
+		t.Errorf("MustAdd(1, 1) = 0x%016x, want 0x%016x", uint64(res), uint64(2*UFix64One))
+	}
+
+	negTwo, _ := Fix64(2 * Fix64One).Neg()
+	negOneAndAHalf, _ := Fix64(150000000).Neg()
+
+	if res := Fix64One.MustFMD(3*Fix64One, negTwo, RoundTowardZero); res != negOneAndAHalf {
+		t.Errorf("MustFMD(1, 3, -2) = 0x%016x, want 0x%016x", uint64(res), uint64(negOneAndAHalf))
+	}
+
+	defer func() {
+		if r := recover(); r != (PositiveOverflowError{}) {
+			t.Errorf("MustAdd(max, 1) panicked with %v, want %v", r, PositiveOverflowError{})
+		}
+	}()
+
+	UFix64Max.MustAdd(UFix64One)
+	t.Error("MustAdd(max, 1) didn't panic")
+}
+
+// All of the supported rounding modes, for tests that iterate over them.
+var allRoundingModes = []RoundingMode{
+	RoundTowardZero,
+	RoundAwayFromZero,
+	RoundNearestHalfAway,
+	RoundNearestHalfEven,
+	RoundFloor,
+	RoundCeil,
+	RoundNearestHalfTowardZero,
+	RoundNearestHalfOdd,
+}
+
+// refQuo returns `num/den` computed with big.Int and rounded as specified. Other than RoundFloor
+// and RoundCeil, the rounding is applied to the magnitude of the result, matching the semantics of
+// the fixed-point types.
+func refQuo(num, den *big.Int, round RoundingMode) *big.Int {
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	if rem.Sign() == 0 {
+		return quo
+	}
+
+	// Compare twice the magnitude of the remainder with the magnitude of the denominator.
+	half := new(big.Int).Abs(rem)
+	half.Lsh(half, 1)
+	cmp := half.Cmp(new(big.Int).Abs(den))
+
+	var roundUp bool
+
+	switch round {
+	case RoundTowardZero:
+		roundUp = false
+	case RoundAwayFromZero:
+		roundUp = true
+	case RoundNearestHalfAway:
+		roundUp = cmp >= 0
+	case RoundNearestHalfEven:
+		roundUp = cmp > 0 || (cmp == 0 && quo.Bit(0) == 1)
+	case RoundNearestHalfTowardZero:
+		roundUp = cmp > 0
+	case RoundNearestHalfOdd:
+		roundUp = cmp > 0 || (cmp == 0 && quo.Bit(0) == 0)
+	case RoundFloor:
+		roundUp = num.Sign()*den.Sign() < 0
+	case RoundCeil:
+		roundUp = num.Sign()*den.Sign() > 0
+	}
+
+	if roundUp {
+		if num.Sign()*den.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+
+	return quo
+}
+
+// refRange checks that an exact, rounded result fits into a fixed-point type with the given bit
+// width and signedness, returning the expected raw value (modulo 2^bits) and error. The nonZero
+// flag indicates if the unrounded result was non-zero, and is used to detect underflow.
+func refRange(q *big.Int, bits uint, signed bool, nonZero bool) (*big.Int, error) {
+	max := new(big.Int).Lsh(big.NewInt(1), bits)
+	min := big.NewInt(0)
+
+	if signed {
+		max.Rsh(max, 1)
+		min.Neg(max)
+	}
+
+	if q.Cmp(max) >= 0 {
+		return big.NewInt(0), PositiveOverflowError{}
+	} else if q.Cmp(min) < 0 {
+		return big.NewInt(0), NegativeOverflowError{}
+	} else if q.Sign() == 0 && nonZero {
+		return big.NewInt(0), UnderflowError{}
+	}
+
+	modulus := new(big.Int).Lsh(big.NewInt(1), bits)
+
+	return new(big.Int).Mod(q, modulus), nil
+}
+
+// bigFromRaw64 converts a raw 64-bit value to a big.Int, interpreting it as signed or unsigned.
+func bigFromRaw64(x uint64, signed bool) *big.Int {
+	if signed {
+		return big.NewInt(int64(x))
+	}
+
+	return new(big.Int).SetUint64(x)
+}
+
+func TestWrapFix64(t *testing.T) {
+
+	t.Parallel()
+
+	modulus := new(big.Int).Lsh(big.NewInt(1), 64)
+	scale := big.NewInt(Fix64Scale)
+
+	// Computes the expected result of a wrapping operation using big.Int, interpreting the inputs
+	// as signed or unsigned values.
+	expected := func(x, y uint64, signed bool, op func(z, a, b *big.Int) *big.Int) uint64 {
+		a := new(big.Int).SetUint64(x)
+		b := new(big.Int).SetUint64(y)
+
+		if signed {
+			a.SetInt64(int64(x))
+			b.SetInt64(int64(y))
+		}
+
+		res := op(new(big.Int), a, b)
+		res.Mod(res, modulus)
+
+		return res.Uint64()
+	}
+
+	mulTruncate := func(z, a, b *big.Int) *big.Int { return z.Quo(z.Mul(a, b), scale) }
+
+	for _, x := range edgeValues64 {
+		for _, y := range edgeValues64 {
+			if res, want := uint64(UFix64(x).AddWrap(UFix64(y))), expected(x, y, false, (*big.Int).Add); res != want {
+				t.Errorf("AddWrapUFix64 (0x%016x, 0x%016x) = 0x%016x, want 0x%016x", x, y, res, want)
+			}
+			if res, want := uint64(Fix64(x).SubWrap(Fix64(y))), expected(x, y, true, (*big.Int).Sub); res != want {
+				t.Errorf("SubWrapFix64 (0x%016x, 0x%016x) = 0x%016x, want 0x%016x", x, y, res, want)
+			}
+			if res, want := uint64(UFix64(x).MulWrap(UFix64(y), RoundTowardZero)), expected(x, y, false, mulTruncate); res != want {
+				t.Errorf("MulWrapUFix64 (0x%016x, 0x%016x) = 0x%016x, want 0x%016x", x, y, res, want)
+			}
+			if res, want := uint64(Fix64(x).MulWrap(Fix64(y), RoundTowardZero)), expected(x, y, true, mulTruncate); res != want {
+				t.Errorf("MulWrapFix64 (0x%016x, 0x%016x) = 0x%016x, want 0x%016x", x, y, res, want)
+			}
+
+			// When the product is in range, MulWrap must agree with Mul.
+			if res, err := UFix64(x).Mul(UFix64(y), RoundHalfEven); err == nil {
+				if wrapped := UFix64(x).MulWrap(UFix64(y), RoundHalfEven); wrapped != res {
+					t.Errorf("MulWrapUFix64 (0x%016x, 0x%016x) = 0x%016x, want 0x%016x", x, y, uint64(wrapped), uint64(res))
+				}
+			}
+		}
+	}
+}
+
+func TestFMAFix64(t *testing.T) {
+
+	t.Parallel()
+
+	scale := big.NewInt(Fix64Scale)
+
+	for _, round := range allRoundingModes {
+		for _, x := range edgeValues64 {
+			for _, y := range edgeValues64 {
+				for _, z := range edgeValues64 {
+					for _, signed := range []bool{false, true} {
+						num := new(big.Int).Mul(bigFromRaw64(x, signed), bigFromRaw64(y, signed))
+						num.Add(num, new(big.Int).Mul(bigFromRaw64(z, signed), scale))
+
+						want, wantErr := refRange(refQuo(num, scale, round), 64, signed, num.Sign() != 0)
+
+						var res uint64
+						var err error
+
+						if signed {
+							var r Fix64
+							r, err = Fix64(x).FMA(Fix64(y), Fix64(z), round)
+							res = uint64(r)
+						} else {
+							var r UFix64
+							r, err = UFix64(x).FMA(UFix64(y), UFix64(z), round)
+							res = uint64(r)
+						}
+
+						if res != want.Uint64() || err != wantErr {
+							t.Errorf("FMA (signed: %v, round: %v) (0x%016x, 0x%016x, 0x%016x) = 0x%016x, %v; want 0x%016x, %v",
+								signed, round, x, y, z, res, err, want.Uint64(), wantErr)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDot2Fix64(t *testing.T) {
+
+	t.Parallel()
+
+	scale := big.NewInt(Fix64Scale)
+
+	for _, round := range []RoundingMode{RoundTowardZero, RoundHalfEven} {
+		for _, w := range edgeValues64 {
+			for _, x := range edgeValues64 {
+				for _, y := range edgeValues64 {
+					for _, z := range edgeValues64 {
+						for _, signed := range []bool{false, true} {
+							num := new(big.Int).Mul(bigFromRaw64(w, signed), bigFromRaw64(x, signed))
+							num.Add(num, new(big.Int).Mul(bigFromRaw64(y, signed), bigFromRaw64(z, signed)))
+
+							want, wantErr := refRange(refQuo(num, scale, round), 64, signed, num.Sign() != 0)
+
+							var res uint64
+							var err error
+
+							if signed {
+								var r Fix64
+								r, err = Fix64(w).Dot2(Fix64(x), Fix64(y), Fix64(z), round)
+								res = uint64(r)
+							} else {
+								var r UFix64
+								r, err = UFix64(w).Dot2(UFix64(x), UFix64(y), UFix64(z), round)
+								res = uint64(r)
+							}
+
+							if res != want.Uint64() || err != wantErr {
+								t.Fatalf("Dot2 (signed: %v, round: %v) (0x%016x, 0x%016x, 0x%016x, 0x%016x) = 0x%016x, %v; want 0x%016x, %v",
+									signed, round, w, x, y, z, res, err, want.Uint64(), wantErr)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestSumOfProductsFix64(t *testing.T) {
+
+	t.Parallel()
+
+	// The intermediate sums overflow Fix64, but the final result doesn't.
+	pairs := [][2]Fix64{
+		{Fix64Max, Fix64Max},
+		{Fix64Max, Fix64Max},
+		{Fix64Min, Fix64Max},
+		{Fix64Max, Fix64Min},
+		{Fix64One, 3},
+	}
+
+	res, err := SumOfProductsFix64(pairs, RoundHalfUp)
+
+	// Fix64Max^2 * 2 + Fix64Min * Fix64Max * 2 == -Fix64Max * 2
+	num := new(big.Int).Mul(big.NewInt(-2), big.NewInt(int64(Fix64Max)))
+	num.Add(num, big.NewInt(3*Fix64Scale))
+	want, wantErr := refRange(refQuo(num, big.NewInt(Fix64Scale), RoundHalfUp), 64, true, true)
+
+	if uint64(res) != want.Uint64() || err != wantErr {
+		t.Errorf("SumOfProductsFix64 = 0x%016x, %v; want 0x%016x, %v", uint64(res), err, want.Uint64(), wantErr)
+	}
+
+	if res, err := SumOfProductsUFix64(nil, RoundHalfUp); res != UFix64Zero || err != nil {
+		t.Errorf("SumOfProductsUFix64(nil) = 0x%016x, %v; want 0, nil", uint64(res), err)
+	}
+}
+
+func TestExactFix64(t *testing.T) {
+
+	t.Parallel()
+
+	scale := big.NewInt(Fix64Scale)
+
+	for _, x := range edgeValues64 {
+		for _, y := range edgeValues64 {
+			for _, signed := range []bool{false, true} {
+				for _, op := range []string{"MulExact", "DivExact"} {
+					num := new(big.Int).Mul(bigFromRaw64(x, signed), bigFromRaw64(y, signed))
+					den := scale
+
+					if op == "DivExact" {
+						num = new(big.Int).Mul(bigFromRaw64(x, signed), scale)
+						den = bigFromRaw64(y, signed)
+					}
+
+					var res uint64
+					var err error
+
+					switch {
+					case signed && op == "MulExact":
+						r, e := Fix64(x).MulExact(Fix64(y))
+						res, err = uint64(r), e
+					case signed && op == "DivExact":
+						r, e := Fix64(x).DivExact(Fix64(y))
+						res, err = uint64(r), e
+					case op == "MulExact":
+						r, e := UFix64(x).MulExact(UFix64(y))
+						res, err = uint64(r), e
+					default:
+						r, e := UFix64(x).DivExact(UFix64(y))
+						res, err = uint64(r), e
+					}
+
+					var want *big.Int
+					var wantErr error
+
+					if den.Sign() == 0 {
+						want, wantErr = big.NewInt(0), DivisionByZeroError{}
+					} else {
+						want, wantErr = refRange(refQuo(num, den, RoundTowardZero), 64, signed, num.Sign() != 0)
+
+						if wantErr == nil && new(big.Int).Rem(num, den).Sign() != 0 {
+							want, wantErr = big.NewInt(0), InexactError{}
+						}
+					}
+
+					if res != want.Uint64() || err != wantErr {
+						t.Errorf("%s (signed: %v) (0x%016x, 0x%016x) = 0x%016x, %v; want 0x%016x, %v",
+							op, signed, x, y, res, err, want.Uint64(), wantErr)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFMDXFix64(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues64 {
+		for _, y := range edgeValues64 {
+			for _, z := range edgeValues64 {
+				num := new(big.Int).Mul(bigFromRaw64(x, true), bigFromRaw64(y, true))
+				den := bigFromRaw64(z, true)
+
+				res, exact, err := Fix64(x).FMDX(Fix64(y), Fix64(z), RoundHalfUp)
+				want, wantErr := Fix64(x).FMD(Fix64(y), Fix64(z), RoundHalfUp)
+
+				if res != want || err != wantErr {
+					t.Errorf("FMDXFix64 (0x%016x, 0x%016x, 0x%016x) = 0x%016x, %v; want 0x%016x, %v",
+						x, y, z, uint64(res), err, uint64(want), wantErr)
+				}
+
+				if err == nil && exact != (new(big.Int).Rem(num, den).Sign() == 0) {
+					t.Errorf("FMDXFix64 (0x%016x, 0x%016x, 0x%016x) exact = %v", x, y, z, exact)
+				}
+			}
+		}
+	}
+}
+
+// Not run in parallel, since it replaces the package-level stochastic source
+func TestStochasticRounding(t *testing.T) {
+	SetStochasticSource(rand.NewPCG(1, 2))
+	defer SetStochasticSource(nil)
+
+	const trials = 10000
+
+	// 7e-8 / 4 = 1.75e-8, should round up to 2e-8 roughly 75% of the time
+	ups := 0
+	for range trials {
+		res, err := UFix64(7).Div(UFix64(4*UFix64One), RoundStochastic)
+		if err != nil || (res != 1 && res != 2) {
+			t.Fatalf("Div(7e-8, 4) = %d, %v", res, err)
+		}
+		if res == 2 {
+			ups++
+		}
+	}
+	if ups < trials*70/100 || ups > trials*80/100 {
+		t.Errorf("Div(7e-8, 4) rounded up %d/%d times; want about 75%%", ups, trials)
+	}
+
+	// Signed values round the magnitude, so -1.75e-8 should be -2e-8 roughly 75% of the time
+	ups = 0
+	for range trials {
+		res, err := Fix64(neg64(7)).Div(Fix64(4*Fix64One), RoundStochastic)
+		if err != nil || (res != Fix64(neg64(1)) && res != Fix64(neg64(2))) {
+			t.Fatalf("Div(-7e-8, 4) = %d, %v", int64(res), err)
+		}
+		if res == Fix64(neg64(2)) {
+			ups++
+		}
+	}
+	if ups < trials*70/100 || ups > trials*80/100 {
+		t.Errorf("Div(-7e-8, 4) rounded away from zero %d/%d times; want about 75%%", ups, trials)
+	}
+
+	// 1e-24 / 8 = 1.25e-25, should round up to 1e-24 roughly 12.5% of the time
+	two, _ := UFix128One.Add(UFix128One)
+	four, _ := two.Add(two)
+	eight, _ := four.Add(four)
+	ups = 0
+	for range trials {
+		res, err := UFix128{0, 1}.Div(eight, RoundStochastic)
+		if err != nil && err != (UnderflowError{}) {
+			t.Fatalf("Div(1e-24, 8) = %v, %v", res, err)
+		}
+		if err == nil {
+			if res != (UFix128{0, 1}) {
+				t.Fatalf("Div(1e-24, 8) = %v", res)
+			}
+			ups++
+		}
+	}
+	if ups < trials*10/100 || ups > trials*15/100 {
+		t.Errorf("Div(1e-24, 8) rounded up %d/%d times; want about 12.5%%", ups, trials)
+	}
+
+	// The standard deviation of 0, 1e-8 and 2e-8 is sqrt(2/3)e-8, about 0.8165e-8, so it should
+	// round up roughly 81.6% of the time, using the exact remainder of the square root.
+	var stats RunningStats[UFix64]
+	for _, x := range []UFix64{0, 1, 2} {
+		stats.Add(x)
+	}
+	ups = 0
+	for range trials {
+		res, err := stats.Stddev(RoundStochastic)
+		if err != nil && err != (UnderflowError{}) {
+			t.Fatalf("Stddev(0, 1e-8, 2e-8) = %d, %v", res, err)
+		}
+		if err == nil {
+			if res != 1 {
+				t.Fatalf("Stddev(0, 1e-8, 2e-8) = %d", res)
+			}
+			ups++
+		}
+	}
+	if ups < trials*79/100 || ups > trials*84/100 {
+		t.Errorf("Stddev(0, 1e-8, 2e-8) rounded up %d/%d times; want about 81.6%%", ups, trials)
+	}
+
+	// Exact results are never perturbed
+	for range 100 {
+		res, err := UFix64(8).Div(UFix64(4*UFix64One), RoundStochastic)
+		if res != 2 || err != nil {
+			t.Fatalf("Div(8e-8, 4) = %d, %v; want 2", res, err)
+		}
+	}
+
+	// Narrowing conversions round to one of the two neighbouring values
+	x, _ := Fix128{0, 1}.Neg()
+	for range 100 {
+		res, err := x.ToFix64(RoundStochastic)
+		if err != nil && err != (UnderflowError{}) {
+			t.Fatalf("ToFix64(-1e-24) = %d, %v", int64(res), err)
+		}
+		if err == nil && res != Fix64(neg64(1)) {
+			t.Fatalf("ToFix64(-1e-24) = %d", int64(res))
+		}
+	}
+
+	// The same seed produces the same sequence of results
+	results := func() []UFix64 {
+		SetStochasticSource(rand.NewPCG(3, 4))
+		out := make([]UFix64, 32)
+		for i := range out {
+			out[i], _ = UFix64(7).Div(UFix64(4*UFix64One), RoundStochastic)
+		}
+		return out
+	}
+	first, second := results(), results()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("stochastic rounding is not reproducible with a fixed seed")
+		}
+	}
+}
+
+func TestContext64(t *testing.T) {
+
+	t.Parallel()
+
+	// With the default traps, the Context operators match the plain operators
+	ctx := NewContext(RoundHalfEven)
+	for _, x := range edgeValues64 {
+		for _, y := range edgeValues64 {
+			res, err := ctx.MulUFix64(UFix64(x), UFix64(y))
+			want, wantErr := UFix64(x).Mul(UFix64(y), RoundHalfEven)
+			if res != want || err != wantErr {
+				t.Errorf("MulUFix64(0x%016x, 0x%016x) = 0x%016x, %v; want 0x%016x, %v", x, y, res, err, want, wantErr)
+			}
+
+			sres, err := ctx.DivFix64(Fix64(x), Fix64(y))
+			swant, wantErr := Fix64(x).Div(Fix64(y), RoundHalfEven)
+			if sres != swant || err != wantErr {
+				t.Errorf("DivFix64(0x%016x, 0x%016x) = 0x%016x, %v; want 0x%016x, %v", x, y, sres, err, swant, wantErr)
+			}
+		}
+	}
+
+	// Untrapped overflow and underflow saturate, and are recorded in the flags
+	ctx = &Context{Rounding: RoundTowardZero}
+	if res, err := ctx.AddUFix64(UFix64Max, 1); res != UFix64Max || err != nil || ctx.Flags != Overflow|Inexact {
+		t.Errorf("AddUFix64(max, 1) = 0x%016x, %v, flags %b; want max, nil, %b", res, err, ctx.Flags, Overflow|Inexact)
+	}
+
+	ctx.Flags = 0
+	if res, err := ctx.SubFix64(Fix64Min, 1); res != Fix64Min || err != nil || ctx.Flags != Overflow|Inexact {
+		t.Errorf("SubFix64(min, 1) = 0x%016x, %v, flags %b; want min, nil, %b", res, err, ctx.Flags, Overflow|Inexact)
+	}
+
+	ctx.Flags = 0
+	if res, err := ctx.MulUFix64(1, 1); res != 0 || err != nil || ctx.Flags != Underflow|Inexact {
+		t.Errorf("MulUFix64(1e-8, 1e-8) = 0x%016x, %v, flags %b; want 0, nil, %b", res, err, ctx.Flags, Underflow|Inexact)
+	}
+
+	// Flags accumulate until cleared
+	if _, err := ctx.DivUFix64(UFix64One, 3*UFix64One); err != nil || ctx.Flags != Underflow|Inexact {
+		t.Errorf("DivUFix64(1, 3) = %v, flags %b; want nil, %b", err, ctx.Flags, Underflow|Inexact)
+	}
+
+	// Trapping Inexact reports any rounding
+	ctx = &Context{Rounding: RoundHalfUp, Traps: Inexact}
+	if res, err := ctx.DivUFix64(UFix64One, 3*UFix64One); res != 0 || err != (InexactError{}) || ctx.Flags != Inexact {
+		t.Errorf("DivUFix64(1, 3) = 0x%016x, %v, flags %b; want 0, InexactError, %b", res, err, ctx.Flags, Inexact)
+	}
+	if res, err := ctx.FMDFix64(3*Fix64One, Fix64One, 3*Fix64One); res != Fix64One || err != nil {
+		t.Errorf("FMDFix64(3, 1, 3) = 0x%016x, %v; want 1, nil", res, err)
+	}
+
+	// Division by zero is always an error
+	ctx = &Context{Rounding: RoundHalfUp}
+	if _, err := ctx.DivUFix64(UFix64One, 0); err != (DivisionByZeroError{}) || ctx.Flags != 0 {
+		t.Errorf("DivUFix64(1, 0) = %v, flags %b; want DivisionByZeroError, 0", err, ctx.Flags)
+	}
+}
+
+func TestCalcFix64(t *testing.T) {
+
+	t.Parallel()
+
+	// The largest intermediate product (in raw units of 1e-16) that doesn't overflow fix192
+	limit := new(big.Int).Lsh(big.NewInt(1), 128)
+	limit.Quo(limit, big.NewInt(Fix64Scale))
+
+	for _, x := range edgeValues64 {
+		for _, y := range edgeValues64 {
+			for _, z := range edgeValues64 {
+				for _, signed := range []bool{false, true} {
+					// Check x*y/z, which should match a single rounding of the exact result
+					for _, round := range allRoundingModes {
+						var res uint64
+						var err error
+
+						if signed {
+							r, e := NewCalc(Fix64(x)).Mul(Fix64(y)).Div(Fix64(z)).Result(round)
+							res, err = uint64(r), e
+						} else {
+							r, e := NewCalc(UFix64(x)).Mul(UFix64(y)).Div(UFix64(z)).Result(round)
+							res, err = uint64(r), e
+						}
+
+						num := new(big.Int).Mul(bigFromRaw64(x, signed), bigFromRaw64(y, signed))
+						den := bigFromRaw64(z, signed)
+
+						var want *big.Int
+						var wantErr error
+
+						switch {
+						case new(big.Int).Abs(num).Cmp(limit) >= 0 && num.Sign() > 0:
+							want, wantErr = big.NewInt(0), PositiveOverflowError{}
+						case new(big.Int).Abs(num).Cmp(limit) >= 0:
+							want, wantErr = big.NewInt(0), NegativeOverflowError{}
+						case den.Sign() == 0:
+							want, wantErr = big.NewInt(0), DivisionByZeroError{}
+						default:
+							want, wantErr = refRange(refQuo(num, den, round), 64, signed, num.Sign() != 0)
+						}
+
+						if res != want.Uint64() || err != wantErr {
+							t.Errorf("Calc (signed: %v) (0x%016x * 0x%016x / 0x%016x, %v) = 0x%016x, %v; want 0x%016x, %v",
+								signed, x, y, z, round, res, err, want.Uint64(), wantErr)
+						}
+					}
+
+					// Check x-y+z, where the intermediate result can be negative even for unsigned values
+					var res uint64
+					var err error
+
+					if signed {
+						r, e := NewCalc(Fix64(x)).Sub(Fix64(y)).Add(Fix64(z)).Result(RoundHalfEven)
+						res, err = uint64(r), e
+					} else {
+						r, e := NewCalc(UFix64(x)).Sub(UFix64(y)).Add(UFix64(z)).Result(RoundHalfEven)
+						res, err = uint64(r), e
+					}
+
+					sum := new(big.Int).Sub(bigFromRaw64(x, signed), bigFromRaw64(y, signed))
+					sum.Add(sum, bigFromRaw64(z, signed))
+					want, wantErr := refRange(sum, 64, signed, false)
+
+					if res != want.Uint64() || err != wantErr {
+						t.Errorf("Calc (signed: %v) (0x%016x - 0x%016x + 0x%016x) = 0x%016x, %v; want 0x%016x, %v",
+							signed, x, y, z, res, err, want.Uint64(), wantErr)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDefaultRounding64(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues64 {
+		for _, y := range edgeValues64 {
+			res, err := Fix64(x).MulDefault(Fix64(y))
+			want, wantErr := Fix64(x).Mul(Fix64(y), RoundTowardZero)
+			if res != want || err != wantErr {
+				t.Errorf("MulDefault(0x%016x, 0x%016x) = 0x%016x, %v; want 0x%016x, %v", x, y, res, err, want, wantErr)
+			}
+
+			ures, err := UFix64(x).DivDefault(UFix64(y))
+			uwant, wantErr := UFix64(x).Div(UFix64(y), RoundTowardZero)
+			if ures != uwant || err != wantErr {
+				t.Errorf("DivDefault(0x%016x, 0x%016x) = 0x%016x, %v; want 0x%016x, %v", x, y, ures, err, uwant, wantErr)
+			}
+		}
+	}
+}
+
+func TestTranscendentalRounding64(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues64 {
+		// The default variants round to nearest
+		ln, lnErr := UFix64(x).Ln()
+		if res, err := UFix64(x).LnRound(RoundHalfUp); res != ln || err != lnErr {
+			t.Errorf("LnRound(0x%016x) = 0x%016x, %v; want 0x%016x, %v", x, res, err, ln, lnErr)
+		}
+
+		exp, expErr := Fix64(x).Exp()
+		if res, err := Fix64(x).ExpRound(RoundHalfUp); res != exp || err != expErr {
+			t.Errorf("ExpRound(0x%016x) = 0x%016x, %v; want 0x%016x, %v", x, res, err, exp, expErr)
+		}
+
+		// Floor and ceil bracket the nearest result, and are at most one ulp apart
+		floor, floorErr := Fix64(x).SinRound(RoundFloor)
+		ceil, ceilErr := Fix64(x).SinRound(RoundCeil)
+		sin, _ := Fix64(x).Sin()
+		if floorErr != nil || ceilErr != nil || floor.Gt(sin) || ceil.Lt(sin) || uint64(ceil-floor) > 1 {
+			t.Errorf("SinRound(0x%016x) floor = 0x%016x, %v, ceil = 0x%016x, %v, nearest = 0x%016x",
+				x, floor, floorErr, ceil, ceilErr, sin)
+		}
+	}
+}
+
+func TestAddSignedUFix64(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues64 {
+		for _, y := range edgeValues64 {
+			for _, op := range []string{"AddSigned", "SubSigned"} {
+				var res UFix64
+				var err error
+
+				want := new(big.Int).SetUint64(x)
+				if op == "AddSigned" {
+					res, err = UFix64(x).AddSigned(Fix64(y))
+					want.Add(want, bigFromRaw64(y, true))
+				} else {
+					res, err = UFix64(x).SubSigned(Fix64(y))
+					want.Sub(want, bigFromRaw64(y, true))
+				}
+
+				want, wantErr := refRange(want, 64, false, false)
+
+				if uint64(res) != want.Uint64() || err != wantErr {
+					t.Errorf("%s (0x%016x, 0x%016x) = 0x%016x, %v; want 0x%016x, %v",
+						op, x, y, res, err, want.Uint64(), wantErr)
+				}
+			}
+		}
+	}
+}
+
+func TestSignConversions64(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues64 {
+		want, wantErr := refRange(bigFromRaw64(x, false), 64, true, false)
+		if res, err := UFix64(x).ToFix64(); uint64(res) != want.Uint64() || err != wantErr {
+			t.Errorf("ToFix64(0x%016x) = 0x%016x, %v; want 0x%016x, %v", x, res, err, want.Uint64(), wantErr)
+		}
+
+		want, wantErr = refRange(bigFromRaw64(x, true), 64, false, false)
+		if res, err := Fix64(x).ToUFix64(); uint64(res) != want.Uint64() || err != wantErr {
+			t.Errorf("ToUFix64(0x%016x) = 0x%016x, %v; want 0x%016x, %v", x, res, err, want.Uint64(), wantErr)
+		}
+	}
+}
+
+func TestCmpAbsFix64(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues64 {
+		for _, y := range edgeValues64 {
+			want := bigFromRaw64(x, true).CmpAbs(bigFromRaw64(y, true))
+			if res := Fix64(x).CmpAbs(Fix64(y)); res != want {
+				t.Errorf("CmpAbs(0x%016x, 0x%016x) = %d; want %d", x, y, res, want)
+			}
+		}
+	}
+}
+
+func TestApproxEqFix64(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues64 {
+		for _, y := range edgeValues64 {
+			for _, tol := range edgeValues64 {
+				for _, signed := range []bool{false, true} {
+					diff := new(big.Int).Sub(bigFromRaw64(x, signed), bigFromRaw64(y, signed))
+					want := diff.Abs(diff).Cmp(new(big.Int).SetUint64(tol)) <= 0
+
+					var res bool
+					if signed {
+						res = Fix64(x).ApproxEq(Fix64(y), UFix64(tol))
+					} else {
+						res = UFix64(x).ApproxEq(UFix64(y), UFix64(tol))
+					}
+
+					if res != want {
+						t.Errorf("ApproxEq (signed: %v) (0x%016x, 0x%016x, 0x%016x) = %v; want %v",
+							signed, x, y, tol, res, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestNextUpDownFix64(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues64 {
+		for _, signed := range []bool{false, true} {
+			for _, delta := range []int64{1, -1} {
+				var res uint64
+				var err error
+
+				switch {
+				case signed && delta > 0:
+					r, e := Fix64(x).NextUp()
+					res, err = uint64(r), e
+				case signed:
+					r, e := Fix64(x).NextDown()
+					res, err = uint64(r), e
+				case delta > 0:
+					r, e := UFix64(x).NextUp()
+					res, err = uint64(r), e
+				default:
+					r, e := UFix64(x).NextDown()
+					res, err = uint64(r), e
+				}
+
+				want, wantErr := refRange(new(big.Int).Add(bigFromRaw64(x, signed), big.NewInt(delta)), 64, signed, false)
+
+				if res != want.Uint64() || err != wantErr {
+					t.Errorf("Next (signed: %v, delta: %d) (0x%016x) = 0x%016x, %v; want 0x%016x, %v",
+						signed, delta, x, res, err, want.Uint64(), wantErr)
+				}
+			}
+		}
+	}
+}
+
+func TestIsIntegerFix64(t *testing.T) {
+
+	t.Parallel()
+
+	scale := big.NewInt(Fix64Scale)
+
+	for _, x := range edgeValues64 {
+		for _, signed := range []bool{false, true} {
+			want := new(big.Int).Rem(bigFromRaw64(x, signed), scale).Sign() == 0
+
+			var isInt, hasFrac bool
+			if signed {
+				isInt, hasFrac = Fix64(x).IsInteger(), Fix64(x).HasFraction()
+			} else {
+				isInt, hasFrac = UFix64(x).IsInteger(), UFix64(x).HasFraction()
+			}
+
+			if isInt != want || hasFrac == want {
+				t.Errorf("IsInteger (signed: %v) (0x%016x) = %v, HasFraction = %v; want %v",
+					signed, x, isInt, hasFrac, want)
+			}
+		}
+	}
+}
+
+func TestNegUFix64(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues64 {
+		want, wantErr := refRange(new(big.Int).Neg(bigFromRaw64(x, false)), 64, false, false)
+
+		if res, err := UFix64(x).Neg(); uint64(res) != want.Uint64() || err != wantErr {
+			t.Errorf("Neg(0x%016x) = 0x%016x, %v; want 0x%016x, %v", x, res, err, want.Uint64(), wantErr)
+		}
+	}
+}
+
+// negAll exercises the FixedPoint interface from generic code.
+func negAll[T FixedPoint[T]](values ...T) (res []T, err error) {
+	for _, v := range values {
+		n, err := v.Neg()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, n)
+	}
+
+	return res, nil
+}
+
+func TestFixedPointInterface(t *testing.T) {
+
+	t.Parallel()
+
+	if res, err := negAll(Fix64One, Fix64Zero); err != nil || res[0] != Fix64(neg64(raw64(Fix64One))) || res[1] != Fix64Zero {
+		t.Errorf("negAll(Fix64) = %v, %v", res, err)
+	}
+	if _, err := negAll(UFix128Zero, UFix128One); err != (NegativeOverflowError{}) {
+		t.Errorf("negAll(UFix128) = %v; want NegativeOverflowError", err)
+	}
+}
+
+func TestErrorsIs(t *testing.T) {
+
+	t.Parallel()
+
+	tests := []struct {
+		err      error
+		sentinel error
+	}{
+		{PositiveOverflowError{}, ErrOverflow},
+		{NegativeOverflowError{}, ErrOverflow},
+		{UnderflowError{}, ErrUnderflow},
+		{DivisionByZeroError{}, ErrDivisionByZero},
+		{OutOfDomainErrorError{}, ErrDomain},
+		{InexactError{}, ErrInexact},
+		{InvalidRoundingModeError{}, ErrInvalidRoundingMode},
+		{SyntaxError{}, ErrSyntax},
+		{InvalidEncodingError{}, ErrInvalidEncoding},
+	}
+
+	sentinels := []error{ErrOverflow, ErrUnderflow, ErrDivisionByZero, ErrDomain, ErrInexact, ErrInvalidRoundingMode, ErrSyntax, ErrInvalidEncoding}
+
+	for _, tc := range tests {
+		for _, sentinel := range sentinels {
+			if got := errors.Is(tc.err, sentinel); got != (sentinel == tc.sentinel) {
+				t.Errorf("errors.Is(%T, %v) = %v", tc.err, sentinel, got)
+			}
+		}
+	}
+
+	// Errors from operations match, including when wrapped with context
+	ctx := NewContext(RoundHalfUp)
+	ctx.WrapErrors = true
+
+	_, err := ctx.MulFix64(Fix64Max, Fix64Min)
+	if !errors.Is(err, ErrOverflow) || !errors.As(err, new(NegativeOverflowError)) {
+		t.Errorf("MulFix64(max, min) = %v; want a negative overflow", err)
+	}
+
+	var opErr *OpError
+	if !errors.As(err, &opErr) || opErr.Op != "Mul" || len(opErr.Operands) != 2 || opErr.Operands[0] != Fix64Max {
+		t.Errorf("MulFix64(max, min) = %#v; want an OpError for Mul(max, min)", err)
+	} else if want := "Mul(92233720368.54775807, -92233720368.54775808): negative overflow"; err.Error() != want {
+		t.Errorf("MulFix64(max, min).Error() = %q; want %q", err.Error(), want)
+	}
+
+	if _, err := ctx.MulFix64(Fix64One, Fix64One); err != nil {
+		t.Errorf("MulFix64(1, 1) = %v; want nil", err)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+
+	t.Parallel()
+
+	tests := []struct {
+		err  error
+		code ErrorCode
+	}{
+		{nil, ErrorCodeNone},
+		{PositiveOverflowError{}, 1},
+		{NegativeOverflowError{}, 2},
+		{UnderflowError{}, 3},
+		{DivisionByZeroError{}, 4},
+		{OutOfDomainErrorError{}, 5},
+		{InexactError{}, 6},
+		{InvalidRoundingModeError{}, 7},
+		{SyntaxError{}, 8},
+		{InvalidEncodingError{}, 9},
+		{&OpError{Op: "Mul", Err: UnderflowError{}}, ErrorCodeUnderflow},
+		{errors.New("other"), ErrorCodeUnknown},
+		{ErrOverflow, ErrorCodeUnknown},
+	}
+
+	for _, tc := range tests {
+		if code := ErrorCodeOf(tc.err); code != tc.code {
+			t.Errorf("ErrorCodeOf(%v) = %d; want %d", tc.err, code, tc.code)
+		}
+	}
+
+	for code := ErrorCodeNone; code <= ErrorCodeInvalidEncoding; code++ {
+		if got := ErrorCodeOf(code.err()); got != code {
+			t.Errorf("ErrorCodeOf(ErrorCode(%d).err()) = %d", code, got)
+		}
+	}
+}
+
+func TestDomainErrorsFix64(t *testing.T) {
+
+	t.Parallel()
+
+	negOne := Fix64(neg64(raw64(Fix64One)))
+
+	if _, err := negOne.Sqrt(RoundHalfUp); err != (OutOfDomainErrorError{}) {
+		t.Errorf("Sqrt(-1) = %v; want OutOfDomainErrorError", err)
+	}
+	if res, err := Fix64(4 * Fix64One).Sqrt(RoundHalfUp); res != 2*Fix64One || err != nil {
+		t.Errorf("Sqrt(4) = %v, %v; want 2", res, err)
+	}
+	if _, err := UFix64Zero.Ln(); err != (OutOfDomainErrorError{}) {
+		t.Errorf("Ln(0) = %v; want OutOfDomainErrorError", err)
+	}
+	if _, err := UFix64Zero.Pow(negOne); err != (OutOfDomainErrorError{}) {
+		t.Errorf("Pow(0, -1) = %v; want OutOfDomainErrorError", err)
+	}
+
+	logTests := []struct {
+		a, base UFix64
+		want    Fix64
+		err     error
+	}{
+		{8 * UFix64One, 2 * UFix64One, 3 * Fix64One, nil},
+		{100 * UFix64One, 10 * UFix64One, 2 * Fix64One, nil},
+		{UFix64One / 4, 2 * UFix64One, Fix64(neg64(2 * raw64(Fix64One))), nil},
+		{UFix64One, 7 * UFix64One, 0, nil},
+		{0, 2 * UFix64One, 0, OutOfDomainErrorError{}},
+		{2 * UFix64One, 0, 0, OutOfDomainErrorError{}},
+		{2 * UFix64One, UFix64One, 0, OutOfDomainErrorError{}},
+	}
+
+	for _, tc := range logTests {
+		if res, err := tc.a.LogBase(tc.base, RoundHalfUp); res != tc.want || err != tc.err {
+			t.Errorf("LogBase(%d, %d) = %d, %v; want %d, %v", tc.a, tc.base, int64(res), err, int64(tc.want), tc.err)
+		}
+	}
+}
+
+func TestPow10Fix64(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues64 {
+		for _, n := range []uint{0, 1, 8, 18, 19, 20, 21, 40} {
+			p := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+
+			for _, signed := range []bool{false, true} {
+				a := bigFromRaw64(x, signed)
+
+				want, wantErr := refRange(new(big.Int).Mul(a, p), 64, signed, false)
+				var res uint64
+				var err error
+				if signed {
+					r, e := Fix64(x).MulPow10(n)
+					res, err = uint64(r), e
+				} else {
+					r, e := UFix64(x).MulPow10(n)
+					res, err = uint64(r), e
+				}
+				if res != want.Uint64() || err != wantErr {
+					t.Errorf("MulPow10(0x%016x, %d) signed=%v = 0x%016x, %v; want 0x%016x, %v", x, n, signed, res, err, want, wantErr)
+				}
+
+				for _, round := range allRoundingModes {
+					want, wantErr := refRange(refQuo(a, p, round), 64, signed, a.Sign() != 0)
+					if signed {
+						r, e := Fix64(x).DivPow10(n, round)
+						res, err = uint64(r), e
+					} else {
+						r, e := UFix64(x).DivPow10(n, round)
+						res, err = uint64(r), e
+					}
+					if res != want.Uint64() || err != wantErr {
+						t.Errorf("DivPow10(0x%016x, %d, %v) signed=%v = 0x%016x, %v; want 0x%016x, %v", x, n, round, signed, res, err, want, wantErr)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDoubleHalveFix64(t *testing.T) {
+
+	t.Parallel()
+
+	two := big.NewInt(2)
+
+	for _, x := range edgeValues64 {
+		for _, signed := range []bool{false, true} {
+			a := bigFromRaw64(x, signed)
+
+			want, wantErr := refRange(new(big.Int).Mul(a, two), 64, signed, false)
+			var res uint64
+			var err error
+			if signed {
+				r, e := Fix64(x).Double()
+				res, err = uint64(r), e
+			} else {
+				r, e := UFix64(x).Double()
+				res, err = uint64(r), e
+			}
+			if res != want.Uint64() || err != wantErr {
+				t.Errorf("Double(0x%016x) signed=%v = 0x%016x, %v; want 0x%016x, %v", x, signed, res, err, want, wantErr)
+			}
+
+			for _, round := range allRoundingModes {
+				want, wantErr := refRange(refQuo(a, two, round), 64, signed, a.Sign() != 0)
+				if signed {
+					r, e := Fix64(x).Halve(round)
+					res, err = uint64(r), e
+				} else {
+					r, e := UFix64(x).Halve(round)
+					res, err = uint64(r), e
+				}
+				if res != want.Uint64() || err != wantErr {
+					t.Errorf("Halve(0x%016x, %v) signed=%v = 0x%016x, %v; want 0x%016x, %v", x, round, signed, res, err, want, wantErr)
+				}
+			}
+		}
+	}
+}
+
+// refString formats the exact value of x / 10^decimals with as few fractional digits as possible,
+// but at least one.
+func refString(x *big.Int, decimals int) string {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	s := new(big.Rat).SetFrac(x, scale).FloatString(decimals)
+
+	for strings.HasSuffix(s, "0") && !strings.HasSuffix(s, ".0") {
+		s = s[:len(s)-1]
+	}
+
+	return s
+}
+
+func TestStringFix64(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues64 {
+		if want, res := refString(bigFromRaw64(x, false), 8), UFix64(x).String(); res != want {
+			t.Errorf("UFix64(0x%016x).String() = %q; want %q", x, res, want)
+		}
+		if want, res := refString(bigFromRaw64(x, true), 8), Fix64(x).String(); res != want {
+			t.Errorf("Fix64(0x%016x).String() = %q; want %q", x, res, want)
+		}
+	}
+
+	for _, tc := range []struct {
+		value fmt.Stringer
+		want  string
+	}{
+		{UFix64Zero, "0.0"},
+		{UFix64One, "1.0"},
+		{UFix64Iota, "0.00000001"},
+		{UFix64Max, "184467440737.09551615"},
+		{Fix64Min, "-92233720368.54775808"},
+		{Fix64(neg64(raw64(Fix64One) / 2)), "-0.5"},
+	} {
+		if res := tc.value.String(); res != tc.want {
+			t.Errorf("%T.String() = %q; want %q", tc.value, res, tc.want)
+		}
+	}
+}
+
+// refParse returns the expected result of parsing the exact decimal string for x / 10^decimals
+// with the given extra digits appended, using refQuo and refRange.
+func refParse(x *big.Int, decimals int, extra string, round RoundingMode, bits uint, signed bool) (string, *big.Int, error) {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	s := new(big.Rat).SetFrac(x, scale).FloatString(decimals) + extra
+
+	den := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(len(extra))), nil)
+	num := new(big.Int).Mul(x, den)
+	if extra != "" {
+		e, _ := new(big.Int).SetString(extra, 10)
+		if x.Sign() < 0 {
+			e.Neg(e)
+		}
+		num.Add(num, e)
+	}
+
+	want, wantErr := refRange(refQuo(num, den, round), bits, signed, num.Sign() != 0)
+	return s, want, wantErr
+}
+
+var parseExtraDigits = []string{"", "0", "5", "49", "50", "51", "500000000000000000000000000000001", "000000000000000000000000000000001"}
+
+func TestParseFix64(t *testing.T) {
+
+	t.Parallel()
+
+	for _, x := range edgeValues64 {
+		for _, extra := range parseExtraDigits {
+			for _, round := range allRoundingModes {
+				s, want, wantErr := refParse(bigFromRaw64(x, false), 8, extra, round, 64, false)
+				if res, err := ParseUFix64(s, round); uint64(res) != want.Uint64() || err != wantErr {
+					t.Errorf("ParseUFix64(%q, %v) = 0x%016x, %v; want 0x%016x, %v", s, round, res, err, want, wantErr)
+				}
+
+				s, want, wantErr = refParse(bigFromRaw64(x, true), 8, extra, round, 64, true)
+				if res, err := ParseFix64(s, round); uint64(res) != want.Uint64() || err != wantErr {
+					t.Errorf("ParseFix64(%q, %v) = 0x%016x, %v; want 0x%016x, %v", s, round, res, err, want, wantErr)
+				}
+			}
+		}
+
+		// Parsing the output of String() gives back the same value
+		if res, err := ParseUFix64(UFix64(x).String(), RoundTowardZero); res != UFix64(x) || err != nil {
+			t.Errorf("ParseUFix64(%q) = 0x%016x, %v; want 0x%016x", UFix64(x).String(), res, err, x)
+		}
+		if res, err := ParseFix64(Fix64(x).String(), RoundTowardZero); res != Fix64(x) || err != nil {
+			t.Errorf("ParseFix64(%q) = 0x%016x, %v; want 0x%016x", Fix64(x).String(), res, err, x)
+		}
+	}
+
+	for _, tc := range []struct {
+		s    string
+		want UFix64
+		err  error
+	}{
+		{"1", UFix64One, nil},
+		{"+1.", UFix64One, nil},
+		{".5", UFix64One / 2, nil},
+		{"-0.0", UFix64Zero, nil},
+		{"0.000000001", UFix64Zero, UnderflowError{}},
+		{"-0.000000001", UFix64Zero, UnderflowError{}},
+		{"-0.00000001", UFix64Zero, NegativeOverflowError{}},
+		{"184467440737.09551616", UFix64Zero, PositiveOverflowError{}},
+		{"99999999999999999999999999999999999999999", UFix64Zero, PositiveOverflowError{}},
+		{"", UFix64Zero, SyntaxError{}},
+		{"-", UFix64Zero, SyntaxError{}},
+		{".", UFix64Zero, SyntaxError{}},
+		{"1.2.3", UFix64Zero, SyntaxError{}},
+		{" 1\t\n", UFix64One, nil},
+		{"1 1", UFix64Zero, SyntaxError{}},
+		{"1x", UFix64Zero, SyntaxError{}},
+		{"--1", UFix64Zero, SyntaxError{}},
+	} {
+		if res, err := ParseUFix64(tc.s, RoundTowardZero); res != tc.want || err != tc.err {
+			t.Errorf("ParseUFix64(%q) = %v, %v; want %v, %v", tc.s, res, err, tc.want, tc.err)
+		}
+	}
+
+	if _, err := ParseFix64("1", RoundingMode(-1)); err != (InvalidRoundingModeError{}) {
+		t.Errorf("ParseFix64 with an invalid rounding mode = %v; want InvalidRoundingModeError", err)
+	}
+}
+
+func TestParseScientific(t *testing.T) {
+
+	t.Parallel()
+
+	mantissas := []string{"0", "1", "1.5", "-1.5", "123456789", "0.000123", ".5", "-9.99999999999999999999", "5."}
+	exps := []int{-60, -40, -20, -9, -8, -1, 0, 1, 5, 10, 11, 12, 14, 40}
+
+	for _, m := range mantissas {
+		for _, exp := range exps {
+			for _, e := range []string{"e", "E"} {
+				s := m + e + strconv.Itoa(exp)
+
+				mant, _ := new(big.Rat).SetString(m)
+				num := new(big.Rat).Mul(mant, new(big.Rat).SetFrac(
+					new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(max(exp+8, 0))), nil),
+					new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(max(-exp-8, 0))), nil)))
+
+				for _, round := range allRoundingModes {
+					want, wantErr := refRange(refQuo(num.Num(), num.Denom(), round), 64, true, num.Sign() != 0)
+					if res, err := ParseFix64(s, round); uint64(res) != want.Uint64() || err != wantErr {
+						t.Errorf("ParseFix64(%q, %v) = 0x%016x, %v; want 0x%016x, %v", s, round, res, err, want, wantErr)
+					}
+
+					want128, wantErr := refRange(refQuo(new(big.Int).Mul(num.Num(), new(big.Int).Exp(big.NewInt(10), big.NewInt(16), nil)), num.Denom(), round), 128, true, num.Sign() != 0)
+					if res, err := ParseFix128(s, round); raw128(res) != raw128FromBig(want128) || err != wantErr {
+						t.Errorf("ParseFix128(%q, %v) = %v, %v; want %v, %v", s, round, res, err, want128, wantErr)
+					}
+				}
+			}
+		}
+	}
+
+	for _, s := range []string{"1e", "1e+", "e5", "1e5.0", "1e5e5", "1.5e-1x", "1ee5"} {
+		if _, err := ParseFix64(s, RoundTowardZero); err != (SyntaxError{}) {
+			t.Errorf("ParseFix64(%q) = %v; want SyntaxError", s, err)
+		}
+	}
+
+	// Huge exponents are clamped, but behave as expected
+	for _, tc := range []struct {
+		s    string
+		want Fix64
+		err  error
+	}{
+		{"0e999999999999999999999", Fix64Zero, nil},
+		{"1e999999999999999999999", Fix64Zero, PositiveOverflowError{}},
+		{"-1e999999999999999999999", Fix64Zero, NegativeOverflowError{}},
+		{"1e-999999999999999999999", Fix64Zero, UnderflowError{}},
+	} {
+		if res, err := ParseFix64(tc.s, RoundNearestHalfEven); res != tc.want || err != tc.err {
+			t.Errorf("ParseFix64(%q) = %v, %v; want %v, %v", tc.s, res, err, tc.want, tc.err)
+		}
+	}
+	if res, err := ParseFix64("1e-999999999999999999999", RoundCeil); res != Fix64Iota || err != nil {
+		t.Errorf("ParseFix64(1e-huge, RoundCeil) = %v, %v; want %v", res, err, Fix64Iota)
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+
+	t.Parallel()
+
+	strict := ParseOptions{Strict: true}
+
+	for _, tc := range []struct {
+		s    string
+		want Fix64
+		err  error
+	}{
+		{"1.5", Fix64One + Fix64One/2, nil},
+		{"-0.00000001", Fix64(neg64(1)), nil},
+		{"1.00000000000000000000", Fix64One, nil},
+		{"1e-8", Fix64Iota, nil},
+		{"1.000000001", Fix64Zero, InexactError{}},
+		{"0.000000005", Fix64Zero, InexactError{}},
+		{"1e-9", Fix64Zero, InexactError{}},
+		{"1.0000000000000000000000000000001", Fix64Zero, InexactError{}},
+		{" 1", Fix64Zero, SyntaxError{}},
+		{"1\n", Fix64Zero, SyntaxError{}},
+		{"1abc", Fix64Zero, SyntaxError{}},
+		{"100000000000", Fix64Zero, PositiveOverflowError{}},
+	} {
+		if res, err := strict.ParseFix64(tc.s); res != tc.want || err != tc.err {
+			t.Errorf("strict ParseFix64(%q) = %v, %v; want %v, %v", tc.s, res, err, tc.want, tc.err)
+		}
+	}
+
+	// Lenient parsing rounds the same inputs
+	lenient := ParseOptions{Rounding: RoundNearestHalfAway}
+
+	if res, err := lenient.ParseFix64(" 0.000000005 "); res != Fix64Iota || err != nil {
+		t.Errorf("lenient ParseFix64(0.000000005) = %v, %v; want %v", res, err, Fix64Iota)
+	}
+	if res, err := strict.ParseUFix128("0.0000000000000000000000005"); res != UFix128Zero || err != (InexactError{}) {
+		t.Errorf("strict ParseUFix128(5e-25) = %v, %v; want InexactError", res, err)
+	}
+	if res, err := strict.ParseFix128("-0.000000000000000000000001"); res != Fix128(raw128{0xffffffffffffffff, 0xffffffffffffffff}) || err != nil {
+		t.Errorf("strict ParseFix128(-1e-24) = %v, %v; want -1e-24", res, err)
+	}
+}
+
+func TestParseSeparators(t *testing.T) {
+
+	t.Parallel()
+
+	oneMillion := Fix64(1000000 * Fix64One)
+
+	for _, tc := range []struct {
+		opts ParseOptions
+		s    string
+		want Fix64
+		err  error
+	}{
+		{ParseOptions{Separators: true}, "1,000,000", oneMillion, nil},
+		{ParseOptions{Separators: true}, "1_000_000.0", oneMillion, nil},
+		{ParseOptions{Separators: true}, "-1,000,000.000_000_01", -oneMillion - 1, nil},
+		{ParseOptions{Separators: true}, "1,0,0,0,000", oneMillion, nil},
+		{ParseOptions{Separators: true}, "1_000e3", oneMillion, nil},
+		{ParseOptions{Separators: true}, ",100", Fix64Zero, SyntaxError{}},
+		{ParseOptions{Separators: true}, "100,", Fix64Zero, SyntaxError{}},
+		{ParseOptions{Separators: true}, "1,,000", Fix64Zero, SyntaxError{}},
+		{ParseOptions{Separators: true}, "1,.5", Fix64Zero, SyntaxError{}},
+		{ParseOptions{Separators: true}, "-,1", Fix64Zero, SyntaxError{}},
+		{ParseOptions{Separators: true}, "1e1_0", Fix64Zero, SyntaxError{}},
+		{ParseOptions{}, "1,000", Fix64Zero, SyntaxError{}},
+		{ParseOptions{}, "1_000", Fix64Zero, SyntaxError{}},
+		{ParseOptions{DecimalComma: true}, "0,25", Fix64One / 4, nil},
+		{ParseOptions{DecimalComma: true}, "0.25", Fix64Zero, SyntaxError{}},
+		{ParseOptions{DecimalComma: true, Separators: true}, "1.000.000,0", oneMillion, nil},
+		{ParseOptions{DecimalComma: true, Separators: true}, "1_000_000", oneMillion, nil},
+		{ParseOptions{DecimalComma: true, Separators: true}, "1.000,000,1", Fix64Zero, SyntaxError{}},
+		{ParseOptions{DecimalComma: true, Separators: true, Strict: true}, "0,000000001", Fix64Zero, InexactError{}},
+	} {
+		if res, err := tc.opts.ParseFix64(tc.s); res != tc.want || err != tc.err {
+			t.Errorf("%+v.ParseFix64(%q) = %v, %v; want %v, %v", tc.opts, tc.s, res, err, tc.want, tc.err)
+		}
+	}
+
+	opts := ParseOptions{DecimalComma: true, Separators: true}
+	if res, err := opts.ParseUFix128("1.234,5"); res != UFix128(raw128FromBig(new(big.Int).Div(new(big.Int).Mul(fix128ScaleBig, big.NewInt(12345)), big.NewInt(10)))) || err != nil {
+		t.Errorf("ParseUFix128(1.234,5) = %v, %v; want 1234.5", res, err)
+	}
+}
+
+func TestFormatFix64(t *testing.T) {
+
+	t.Parallel()
+
+	v := Fix64(neg64(1234500000)) // -12.345
+
+	for _, tc := range []struct {
+		format string
+		value  any
+		want   string
+	}{
+		{"%v", v, "-12.345"},
+		{"%s", UFix64One, "1.0"},
+		{"%f", v, "-12.345"},
+		{"%.2f", v, "-12.34"},
+		{"%.2f", UFix64(1235500000), "12.36"},
+		{"%.0f", v, "-12"},
+		{"%.10f", v, "-12.3450000000"},
+		{"%.1f", Fix64(neg64(1)), "0.0"},
+		{"%e", v, "-1.2345e+01"},
+		{"%.2e", v, "-1.23e+01"},
+		{"%.0e", UFix64(999999999), "1e+01"},
+		{"%E", UFix64Iota, "1E-08"},
+		{"%e", UFix64Zero, "0e+00"},
+		{"%.3e", UFix64Zero, "0.000e+00"},
+		{"%x", v, "ffffffffb66b0660"},
+		{"%#X", UFix64One, "0X0000000005F5E100"},
+		{"%+v", UFix64One, "+1.0"},
+		{"% .1f", UFix64One, " 1.0"},
+		{"%8.1f", v, "   -12.3"},
+		{"%-8.1f|", v, "-12.3   |"},
+		{"%08.1f", v, "-00012.3"},
+		{"%d", v, "%!d(fixedPoint.Fix64=-12.345)"},
+	} {
+		if res := fmt.Sprintf(tc.format, tc.value); res != tc.want {
+			t.Errorf("Sprintf(%q, %v) = %q; want %q", tc.format, tc.value, res, tc.want)
+		}
+	}
+
+	// %.Nf rounds the exact value to nearest, with ties to even
+	scale := big.NewInt(Fix64Scale)
+	for _, x := range edgeValues64 {
+		for _, prec := range []int{0, 3, 7} {
+			den := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(8-prec)), nil)
+			q := refQuo(bigFromRaw64(x, true), den, RoundNearestHalfEven)
+			want := new(big.Rat).SetFrac(q, new(big.Int).Quo(scale, den)).FloatString(prec)
+			if q.Sign() == 0 {
+				want = strings.TrimPrefix(want, "-")
+			}
+
+			if res := fmt.Sprintf("%.*f", prec, Fix64(x)); res != want {
+				t.Errorf("Sprintf(%%.%df, 0x%016x) = %q; want %q", prec, x, res, want)
+			}
+		}
+	}
+}
+
+func TestMustParse(t *testing.T) {
+
+	t.Parallel()
+
+	if res := MustParseUFix64("1.25"); res != UFix64(1.25e8) {
+		t.Errorf("MustParseUFix64(\"1.25\") = %v", res)
+	}
+	if res := MustParseFix64("-0.00000001"); res != Fix64(neg64(1)) {
+		t.Errorf("MustParseFix64(\"-0.00000001\") = %v", res)
+	}
+	if res := MustParseUFix128("340282366920938.463463374607431768211455"); res != UFix128Max {
+		t.Errorf("MustParseUFix128(max) = %v", res)
+	}
+	if res := MustParseFix128("1e-24"); res != Fix128Iota {
+		t.Errorf("MustParseFix128(\"1e-24\") = %v", res)
+	}
+
+	panics := []struct {
+		parse func(string)
+		input string
+		err   error
+	}{
+		{func(s string) { MustParseUFix64(s) }, "abc", SyntaxError{}},
+		{func(s string) { MustParseUFix64(s) }, " 1", SyntaxError{}},
+		{func(s string) { MustParseUFix64(s) }, "0.000000001", InexactError{}},
+		{func(s string) { MustParseUFix64(s) }, "-1", NegativeOverflowError{}},
+		{func(s string) { MustParseFix64(s) }, "1e20", PositiveOverflowError{}},
+		{func(s string) { MustParseUFix128(s) }, "", SyntaxError{}},
+		{func(s string) { MustParseFix128(s) }, "1e-25", InexactError{}},
+	}
+
+	for _, tc := range panics {
+		func() {
+			defer func() {
+				err, ok := recover().(error)
+				if !ok || !errors.Is(err, tc.err) || !strings.Contains(err.Error(), strconv.Quote(tc.input)) {
+					t.Errorf("MustParse(%q) panicked with %v; want %v", tc.input, err, tc.err)
+				}
+			}()
+			tc.parse(tc.input)
+		}()
+	}
+}
+
+func TestCadence(t *testing.T) {
+
+	t.Parallel()
+
+	strs := []struct {
+		value interface{ CadenceString() string }
+		want  string
+	}{
+		{UFix64Zero, "0.00000000"},
+		{UFix64One, "1.00000000"},
+		{UFix64(1), "0.00000001"},
+		{UFix64Max, "184467440737.09551615"},
+		{Fix64(neg64(125e7)), "-12.50000000"},
+		{Fix64Min, "-92233720368.54775808"},
+	}
+	for _, tc := range strs {
+		if res := tc.value.CadenceString(); res != tc.want {
+			t.Errorf("%v.CadenceString() = %q; want %q", tc.value, res, tc.want)
+		}
+	}
+
+	// Every value round trips through the Cadence form, including via the 128-bit types.
+	for _, x := range edgeValues64 {
+		if res, err := ParseCadenceUFix64(UFix64(x).CadenceString()); err != nil || res != UFix64(x) {
+			t.Errorf("ParseCadenceUFix64(%q) = %v, %v; want %v", UFix64(x).CadenceString(), res, err, UFix64(x))
+		}
+		if res, err := ParseCadenceFix64(Fix64(x).CadenceString()); err != nil || res != Fix64(x) {
+			t.Errorf("ParseCadenceFix64(%q) = %v, %v; want %v", Fix64(x).CadenceString(), res, err, Fix64(x))
+		}
+
+		u128, err := UFix128FromCadenceUFix64(UFix64(x).CadenceString())
+		if s, err2 := u128.CadenceString(RoundTowardZero); err != nil || err2 != nil || s != UFix64(x).CadenceString() {
+			t.Errorf("UFix128 Cadence round trip of %v = %q, %v, %v", UFix64(x), s, err, err2)
+		}
+		f128, err := Fix128FromCadenceFix64(Fix64(x).CadenceString())
+		if s, err2 := f128.CadenceString(RoundTowardZero); err != nil || err2 != nil || s != Fix64(x).CadenceString() {
+			t.Errorf("Fix128 Cadence round trip of %v = %q, %v, %v", Fix64(x), s, err, err2)
+		}
+	}
+
+	if s, err := MustParseUFix128("0.123456785").CadenceString(RoundNearestHalfEven); err != nil || s != "0.12345678" {
+		t.Errorf("UFix128.CadenceString() = %q, %v; want %q", s, err, "0.12345678")
+	}
+	if s, err := UFix128Max.CadenceString(RoundTowardZero); err != (PositiveOverflowError{}) {
+		t.Errorf("UFix128Max.CadenceString() = %q, %v; want PositiveOverflowError", s, err)
+	}
+
+	parses := []struct {
+		input string
+		want  UFix64
+		err   error
+	}{
+		{"1.0", UFix64One, nil},
+		{"01.5", UFix64(1.5e8), nil},
+		{"1_000.000_1", UFix64(1000.0001e8), nil},
+		{"0.00000001", UFix64(1), nil},
+		{"1", UFix64Zero, SyntaxError{}},
+		{"1.", UFix64Zero, SyntaxError{}},
+		{".5", UFix64Zero, SyntaxError{}},
+		{"1.000000001", UFix64Zero, SyntaxError{}},
+		{"1e3", UFix64Zero, SyntaxError{}},
+		{"1.0e3", UFix64Zero, SyntaxError{}},
+		{"+1.0", UFix64Zero, SyntaxError{}},
+		{"-1.0", UFix64Zero, SyntaxError{}},
+		{" 1.0", UFix64Zero, SyntaxError{}},
+		{"_1.0", UFix64Zero, SyntaxError{}},
+		{"1_.0", UFix64Zero, SyntaxError{}},
+		{"1,000.0", UFix64Zero, SyntaxError{}},
+		{"184467440737.09551616", UFix64Zero, PositiveOverflowError{}},
+	}
+	for _, tc := range parses {
+		if res, err := ParseCadenceUFix64(tc.input); err != tc.err || res != tc.want {
+			t.Errorf("ParseCadenceUFix64(%q) = %v, %v; want %v, %v", tc.input, res, err, tc.want, tc.err)
+		}
+	}
+
+	if res, err := ParseCadenceFix64("-1_0.5"); err != nil || res != Fix64(neg64(10.5e8)) {
+		t.Errorf("ParseCadenceFix64(\"-1_0.5\") = %v, %v", res, err)
+	}
+	if res, err := ParseCadenceFix64("--1.0"); err != (SyntaxError{}) {
+		t.Errorf("ParseCadenceFix64(\"--1.0\") = %v, %v; want SyntaxError", res, err)
+	}
+}
+
+func TestIntConversions(t *testing.T) {
+
+	t.Parallel()
+
+	for _, n := range []int64{0, 1, -1, 42, -42, 184467440737, -92233720368, 92233720368, -92233720369, math.MaxInt64, math.MinInt64} {
+		want := new(big.Int).Mul(big.NewInt(n), big.NewInt(Fix64Scale))
+
+		_, signedErr := refRange(want, 64, true, false)
+		_, unsignedErr := refRange(want, 64, false, false)
+
+		if res, err := Fix64FromInt64(n); err != signedErr || (err == nil && res.ToBigInt().Cmp(want) != 0) {
+			t.Errorf("Fix64FromInt64(%d) = %v, %v", n, res, err)
+		} else if err == nil {
+			if back, err := res.ToInt64(RoundTowardZero); err != nil || back != n {
+				t.Errorf("Fix64FromInt64(%d).ToInt64() = %d, %v", n, back, err)
+			}
+		}
+		if res, err := UFix64FromInt64(n); err != unsignedErr || (err == nil && res.ToBigInt().Cmp(want) != 0) {
+			t.Errorf("UFix64FromInt64(%d) = %v, %v", n, res, err)
+		}
+		want = new(big.Int).Mul(big.NewInt(n), fix128ScaleBig)
+		_, signedErr = refRange(want, 128, true, false)
+		_, unsignedErr = refRange(want, 128, false, false)
+
+		if res, err := Fix128FromInt64(n); err != signedErr || (err == nil && res.ToBigInt().Cmp(want) != 0) {
+			t.Errorf("Fix128FromInt64(%d) = %v, %v", n, res, err)
+		} else if err == nil {
+			if back, err := res.ToInt64(RoundTowardZero); err != nil || back != n {
+				t.Errorf("Fix128FromInt64(%d).ToInt64() = %d, %v", n, back, err)
+			}
+		}
+		if res, err := UFix128FromInt64(n); err != unsignedErr || (err == nil && res.ToBigInt().Cmp(want) != 0) {
+			t.Errorf("UFix128FromInt64(%d) = %v, %v", n, res, err)
+		}
+	}
+
+	if res, err := UFix64FromUint64(184467440737); err != nil || res != UFix64(18446744073700000000) {
+		t.Errorf("UFix64FromUint64(184467440737) = %v, %v", res, err)
+	}
+	if res, err := UFix64FromUint64(184467440738); err != (PositiveOverflowError{}) {
+		t.Errorf("UFix64FromUint64(184467440738) = %v, %v; want PositiveOverflowError", res, err)
+	}
+	if res, err := Fix64FromUint64(math.MaxUint64); err != (PositiveOverflowError{}) {
+		t.Errorf("Fix64FromUint64(MaxUint64) = %v, %v; want PositiveOverflowError", res, err)
+	}
+	if res, err := UFix128FromUint64(340282366920938); err != nil {
+		t.Errorf("UFix128FromUint64(340282366920938) = %v, %v", res, err)
+	} else if back, err := res.ToUint64(RoundTowardZero); err != nil || back != 340282366920938 {
+		t.Errorf("UFix128FromUint64(340282366920938).ToUint64() = %d, %v", back, err)
+	}
+	if res, err := UFix128FromUint64(340282366920939); err != (PositiveOverflowError{}) {
+		t.Errorf("UFix128FromUint64(340282366920939) = %v, %v; want PositiveOverflowError", res, err)
+	}
+
+	tests := []struct {
+		s     string
+		round RoundingMode
+		i     int64
+		iErr  error
+		u     uint64
+		uErr  error
+	}{
+		{"2.5", RoundNearestHalfEven, 2, nil, 2, nil},
+		{"2.5", RoundNearestHalfAway, 3, nil, 3, nil},
+		{"-2.5", RoundNearestHalfAway, -3, nil, 0, NegativeOverflowError{}},
+		{"-2.5", RoundFloor, -3, nil, 0, NegativeOverflowError{}},
+		{"-2.5", RoundCeil, -2, nil, 0, NegativeOverflowError{}},
+		{"-0.4", RoundNearestHalfEven, 0, nil, 0, nil},
+		{"-0.4", RoundFloor, -1, nil, 0, NegativeOverflowError{}},
+		{"0.00000001", RoundCeil, 1, nil, 1, nil},
+		{"0.00000001", RoundTowardZero, 0, nil, 0, nil},
+		{"1.5", RoundingMode(99), 0, InvalidRoundingModeError{}, 0, InvalidRoundingModeError{}},
+	}
+
+	for _, tt := range tests {
+		a64 := MustParseFix64(tt.s)
+		a128 := MustParseFix128(tt.s)
+		if res, err := a64.ToInt64(tt.round); res != tt.i || err != tt.iErr {
+			t.Errorf("Fix64(%s).ToInt64(%v) = %d, %v; want %d, %v", tt.s, tt.round, res, err, tt.i, tt.iErr)
+		}
+		if res, err := a128.ToInt64(tt.round); res != tt.i || err != tt.iErr {
+			t.Errorf("Fix128(%s).ToInt64(%v) = %d, %v; want %d, %v", tt.s, tt.round, res, err, tt.i, tt.iErr)
+		}
+		if res, err := a64.ToUint64(tt.round); res != tt.u || err != tt.uErr {
+			t.Errorf("Fix64(%s).ToUint64(%v) = %d, %v; want %d, %v", tt.s, tt.round, res, err, tt.u, tt.uErr)
+		}
+		if res, err := a128.ToUint64(tt.round); res != tt.u || err != tt.uErr {
+			t.Errorf("Fix128(%s).ToUint64(%v) = %d, %v; want %d, %v", tt.s, tt.round, res, err, tt.u, tt.uErr)
+		}
+	}
+
+	if res, err := Fix128Min.ToInt64(RoundFloor); err != nil || res != -170141183460470 {
+		t.Errorf("Fix128Min.ToInt64(RoundFloor) = %d, %v", res, err)
+	}
+	if res, err := UFix128Max.ToUint64(RoundCeil); err != nil || res != 340282366920939 {
+		t.Errorf("UFix128Max.ToUint64(RoundCeil) = %d, %v", res, err)
+	}
+}
+
+func TestFromParts(t *testing.T) {
+
+	t.Parallel()
+
+	tests := []struct {
+		intPart    int64
+		fracPart   uint64
+		fracDigits uint8
+		want       string
+		err64      error
+		err128     error
+	}{
+		{12, 5, 2, "12.05", nil, nil},
+		{-12, 5, 2, "-12.05", nil, nil},
+		{0, 0, 0, "0", nil, nil},
+		{1, 12345678, 8, "1.12345678", nil, nil},
+		{1, 123456780, 9, "1.12345678", nil, nil},
+		{1, 123456789, 9, "1.123456789", InexactError{}, nil},
+		{0, 1, 24, "0.000000000000000000000001", InexactError{}, nil},
+		{0, 0, 200, "0", nil, nil},
+		{0, 1, 200, "0", InexactError{}, InexactError{}},
+		{1, 100, 2, "", SyntaxError{}, SyntaxError{}},
+		{1, math.MaxUint64, 19, "", SyntaxError{}, SyntaxError{}},
+		{1, math.MaxUint64, 20, "1.18446744073709551615", InexactError{}, nil},
+		{92233720368, 54775807, 8, "92233720368.54775807", nil, nil},
+		{92233720368, 54775808, 8, "92233720368.54775808", PositiveOverflowError{}, nil},
+		{-92233720368, 54775808, 8, "-92233720368.54775808", nil, nil},
+		{-92233720368, 54775809, 8, "-92233720368.54775809", NegativeOverflowError{}, nil},
+		{math.MaxInt64, 0, 0, "", PositiveOverflowError{}, PositiveOverflowError{}},
+		{math.MinInt64, 0, 0, "", NegativeOverflowError{}, NegativeOverflowError{}},
+	}
+
+	for _, tt := range tests {
+		if res, err := NewFix64FromParts(tt.intPart, tt.fracPart, tt.fracDigits); err != tt.err64 || (err == nil && res != MustParseFix64(tt.want)) {
+			t.Errorf("NewFix64FromParts(%d, %d, %d) = %v, %v; want %s, %v", tt.intPart, tt.fracPart, tt.fracDigits, res, err, tt.want, tt.err64)
+		}
+		if res, err := NewFix128FromParts(tt.intPart, tt.fracPart, tt.fracDigits); err != tt.err128 || (err == nil && res != MustParseFix128(tt.want)) {
+			t.Errorf("NewFix128FromParts(%d, %d, %d) = %v, %v; want %s, %v", tt.intPart, tt.fracPart, tt.fracDigits, res, err, tt.want, tt.err128)
+		}
+	}
+
+	if res, err := NewUFix64FromParts(184467440737, 9551615, 8); err != nil || res != UFix64Max {
+		t.Errorf("NewUFix64FromParts(184467440737, 9551615, 8) = %v, %v", res, err)
+	}
+	if res, err := NewUFix64FromParts(184467440737, 9551616, 8); err != (PositiveOverflowError{}) {
+		t.Errorf("NewUFix64FromParts(184467440737, 9551616, 8) = %v, %v; want PositiveOverflowError", res, err)
+	}
+	if res, err := NewUFix128FromParts(12, 5, 2); err != nil || res != MustParseUFix128("12.05") {
+		t.Errorf("NewUFix128FromParts(12, 5, 2) = %v, %v", res, err)
+	}
+	if res, err := NewUFix128FromParts(math.MaxUint64, 0, 0); err != (PositiveOverflowError{}) {
+		t.Errorf("NewUFix128FromParts(MaxUint64, 0, 0) = %v, %v; want PositiveOverflowError", res, err)
+	}
+}
+
+func TestFromMantExp(t *testing.T) {
+
+	t.Parallel()
+
+	tests := []struct {
+		mant   int64
+		exp    int32
+		want   string
+		err64  error
+		err128 error
+	}{
+		{0, 0, "0", nil, nil},
+		{0, math.MaxInt32, "0", nil, nil},
+		{0, math.MinInt32, "0", nil, nil},
+		{1205, -2, "12.05", nil, nil},
+		{-1205, -2, "-12.05", nil, nil},
+		{12, 3, "12000", nil, nil},
+		{1, -8, "0.00000001", nil, nil},
+		{10, -9, "0.00000001", nil, nil},
+		{1, -9, "0.000000001", InexactError{}, nil},
+		{1, -24, "0.000000000000000000000001", InexactError{}, nil},
+		{1, -25, "", InexactError{}, InexactError{}},
+		{math.MaxInt64, -50, "", InexactError{}, InexactError{}},
+		{1, math.MinInt32, "", InexactError{}, InexactError{}},
+		{9223372036854775807, -8, "92233720368.54775807", nil, nil},
+		{math.MinInt64, -8, "-92233720368.54775808", nil, nil},
+		{1, 11, "100000000000", PositiveOverflowError{}, nil},
+		{-1, 11, "-100000000000", NegativeOverflowError{}, nil},
+		{1, 14, "100000000000000", PositiveOverflowError{}, nil},
+		{2, 14, "", PositiveOverflowError{}, PositiveOverflowError{}},
+		{-2, 14, "", NegativeOverflowError{}, NegativeOverflowError{}},
+		{1, math.MaxInt32, "", PositiveOverflowError{}, PositiveOverflowError{}},
+	}
+
+	for _, tt := range tests {
+		if res, err := Fix64FromMantExp(tt.mant, tt.exp); err != tt.err64 || (err == nil && res != MustParseFix64(tt.want)) {
+			t.Errorf("Fix64FromMantExp(%d, %d) = %v, %v; want %s, %v", tt.mant, tt.exp, res, err, tt.want, tt.err64)
+		}
+		if res, err := Fix128FromMantExp(tt.mant, tt.exp); err != tt.err128 || (err == nil && res != MustParseFix128(tt.want)) {
+			t.Errorf("Fix128FromMantExp(%d, %d) = %v, %v; want %s, %v", tt.mant, tt.exp, res, err, tt.want, tt.err128)
+		}
+	}
+
+	if res, err := UFix64FromMantExp(math.MaxUint64, -8); err != nil || res != UFix64Max {
+		t.Errorf("UFix64FromMantExp(MaxUint64, -8) = %v, %v", res, err)
+	}
+	if res, err := UFix64FromMantExp(2, 11); err != (PositiveOverflowError{}) {
+		t.Errorf("UFix64FromMantExp(2, 11) = %v, %v; want PositiveOverflowError", res, err)
+	}
+	if res, err := UFix128FromMantExp(34, 13); err != nil || res != MustParseUFix128("340000000000000") {
+		t.Errorf("UFix128FromMantExp(34, 13) = %v, %v", res, err)
+	}
+	if res, err := UFix128FromMantExp(35, 13); err != (PositiveOverflowError{}) {
+		t.Errorf("UFix128FromMantExp(35, 13) = %v, %v; want PositiveOverflowError", res, err)
+	}
+}
+
+func TestSum(t *testing.T) {
+
+	t.Parallel()
+
+	one64, one128 := Fix64One, Fix128One
+	negOne64, _ := one64.Neg()
+	negOne128, _ := one128.Neg()
+
+	tests64 := []struct {
+		values []Fix64
+		want   Fix64
+		index  int
+		err    error
+	}{
+		{nil, Fix64Zero, -1, nil},
+		{[]Fix64{one64, one64, negOne64}, one64, -1, nil},
+		{[]Fix64{Fix64Max, one64, negOne64}, Fix64Max, -1, nil},
+		{[]Fix64{Fix64Min, negOne64, Fix64Max, one64}, Fix64(neg64(1)), -1, nil},
+		{[]Fix64{Fix64Max, Fix64Max, Fix64Min, Fix64Min}, Fix64(neg64(2)), -1, nil},
+		{[]Fix64{one64, Fix64Max, negOne64, one64, one64}, Fix64Zero, 3, PositiveOverflowError{}},
+		{[]Fix64{Fix64Min, negOne64}, Fix64Zero, 1, NegativeOverflowError{}},
+	}
+	for _, tt := range tests64 {
+		if res, index, err := SumFix64(tt.values); res != tt.want || index != tt.index || err != tt.err {
+			t.Errorf("SumFix64(%v) = %v, %d, %v; want %v, %d, %v", tt.values, res, index, err, tt.want, tt.index, tt.err)
+		}
+
+		// The 128-bit sums agree, with the values converted.
+		values := ToFix128Slice(tt.values)
+		if res, index, err := SumFix128(values); err != nil || index != -1 {
+			t.Errorf("SumFix128(%v) = %v, %d, %v", values, res, index, err)
+		} else if tt.err == nil && res != tt.want.ToFix128() {
+			t.Errorf("SumFix128(%v) = %v; want %v", values, res, tt.want.ToFix128())
+		}
+	}
+
+	tests128 := []struct {
+		values []Fix128
+		want   Fix128
+		index  int
+		err    error
+	}{
+		{[]Fix128{Fix128Max, one128, negOne128}, Fix128Max, -1, nil},
+		{[]Fix128{Fix128Min, negOne128, Fix128Max, one128}, Fix128(neg128(raw128{0, 1})), -1, nil},
+		{[]Fix128{Fix128Max, Fix128Max, Fix128Min, Fix128Min}, Fix128(neg128(raw128{0, 2})), -1, nil},
+		{[]Fix128{one128, Fix128Max, negOne128, one128, one128}, Fix128Zero, 3, PositiveOverflowError{}},
+		{[]Fix128{Fix128Min, Fix128Min, Fix128Max}, Fix128Zero, 1, NegativeOverflowError{}},
+	}
+	for _, tt := range tests128 {
+		if res, index, err := SumFix128(tt.values); res != tt.want || index != tt.index || err != tt.err {
+			t.Errorf("SumFix128(%v) = %v, %d, %v; want %v, %d, %v", tt.values, res, index, err, tt.want, tt.index, tt.err)
+		}
+	}
+
+	if res, index, err := SumUFix64([]UFix64{UFix64One, UFix64Max - UFix64One, UFix64One, UFix64Zero}); err != (PositiveOverflowError{}) || index != 2 {
+		t.Errorf("SumUFix64() = %v, %d, %v; want index 2, PositiveOverflowError", res, index, err)
+	}
+	if res, index, err := SumUFix64([]UFix64{UFix64One, UFix64Max - UFix64One}); err != nil || index != -1 || res != UFix64Max {
+		t.Errorf("SumUFix64() = %v, %d, %v; want UFix64Max", res, index, err)
+	}
+	if res, index, err := SumUFix128([]UFix128{UFix128Max, UFix128Zero, UFix128One}); err != (PositiveOverflowError{}) || index != 2 {
+		t.Errorf("SumUFix128() = %v, %d, %v; want index 2, PositiveOverflowError", res, index, err)
+	}
+	if res, index, err := SumUFix128(ToUFix128Slice([]UFix64{UFix64Max, UFix64Max})); err != nil || index != -1 || res != MustParseUFix128("368934881474.1910323") {
+		t.Errorf("SumUFix128() = %v, %d, %v", res, index, err)
+	}
+}
+
+func TestCumSum(t *testing.T) {
+
+	t.Parallel()
+
+	one64, one128 := UFix64One, UFix128One
+	two64, _ := one64.Add(one64)
+	two128, _ := one128.Add(one128)
+
+	tests64 := []struct {
+		values []UFix64
+		want   []UFix64
+		index  int
+		err    error
+	}{
+		{nil, []UFix64{}, -1, nil},
+		{[]UFix64{one64, one64, UFix64Zero}, []UFix64{one64, two64, two64}, -1, nil},
+		{[]UFix64{UFix64Max - 1, one64, UFix64Iota}, []UFix64{UFix64Max - 1}, 1, PositiveOverflowError{}},
+		{[]UFix64{UFix64Max - 1, UFix64Iota, UFix64Iota}, []UFix64{UFix64Max - 1, UFix64Max}, 2, PositiveOverflowError{}},
+	}
+
+	for _, tt := range tests64 {
+		res, index, err := CumSumUFix64(tt.values)
+		if !slices.Equal(res, tt.want) || index != tt.index || err != tt.err {
+			t.Errorf("CumSumUFix64(%v) = %v, %d, %v; want %v, %d, %v", tt.values, res, index, err, tt.want, tt.index, tt.err)
+		}
+	}
+
+	max128 := UFix128Max
+	tests128 := []struct {
+		values []UFix128
+		want   []UFix128
+		index  int
+		err    error
+	}{
+		{nil, []UFix128{}, -1, nil},
+		{[]UFix128{one128, one128, UFix128Zero}, []UFix128{one128, two128, two128}, -1, nil},
+		{[]UFix128{max128, UFix128Zero, UFix128Iota}, []UFix128{max128, max128}, 2, PositiveOverflowError{}},
+	}
+
+	for _, tt := range tests128 {
+		res, index, err := CumSumUFix128(tt.values)
+		if !slices.Equal(res, tt.want) || index != tt.index || err != tt.err {
+			t.Errorf("CumSumUFix128(%v) = %v, %d, %v; want %v, %d, %v", tt.values, res, index, err, tt.want, tt.index, tt.err)
+		}
+	}
+}
+
+func TestProd(t *testing.T) {
+
+	t.Parallel()
+
+	// The plain products round each step, as chained multiplications do.
+	values := []UFix128{MustParseUFix128("1.5"), MustParseUFix128("0.333333333333333333333333"), MustParseUFix128("3")}
+	for _, round := range allRoundingModes {
+		want := UFix128One
+		for _, v := range values {
+			want, _ = want.Mul(v, round)
+		}
+		if res, index, err := ProdUFix128(values, round); err != nil || index != -1 || res != want {
+			t.Errorf("ProdUFix128(%v, %v) = %v, %d, %v; want %v", values, round, res, index, err, want)
+		}
+	}
+
+	// A year of daily compounding, where the fused product is rounded once.
+	daily := make([]UFix128, 365)
+	for i := range daily {
+		daily[i] = MustParseUFix128("1.0001")
+	}
+	num := new(big.Int).Exp(big.NewInt(10001), big.NewInt(365), nil)
+	den := new(big.Int).Exp(big.NewInt(10), big.NewInt(4*365), nil)
+	for _, round := range []RoundingMode{RoundTowardZero, RoundNearestHalfEven, RoundAwayFromZero} {
+		want := refQuo(new(big.Int).Mul(num, fix128ScaleBig), den, round)
+		if res, index, err := ProdFusedUFix128(daily, round); err != nil || index != -1 || res.ToBigInt().Cmp(want) != 0 {
+			t.Errorf("ProdFusedUFix128(1.0001 x 365, %v) = %v, %d, %v; want raw %v", round, res, index, err, want)
+		}
+	}
+
+	// Exact products are exact either way.
+	exact := []UFix128{MustParseUFix128("1.1"), MustParseUFix128("2.5"), MustParseUFix128("0.000004")}
+	if res, index, err := ProdFusedUFix128(exact, RoundTowardZero); err != nil || index != -1 || res != MustParseUFix128("0.000011") {
+		t.Errorf("ProdFusedUFix128(%v) = %v, %d, %v; want 0.000011", exact, res, index, err)
+	}
+
+	// Errors are reported at the value that caused them.
+	huge, tiny := MustParseUFix128("10000000"), MustParseUFix128("0.0000000001")
+	tests := []struct {
+		values []UFix128
+		index  int
+		err    error
+	}{
+		{[]UFix128{huge, UFix128One, huge, huge}, 3, PositiveOverflowError{}},
+		{[]UFix128{tiny, tiny, tiny, huge}, 2, UnderflowError{}},
+		{[]UFix128{tiny, UFix128Zero, huge, huge}, -1, nil},
+	}
+	for _, tt := range tests {
+		if res, index, err := ProdUFix128(tt.values, RoundTowardZero); index != tt.index || err != tt.err {
+			t.Errorf("ProdUFix128(%v) = %v, %d, %v; want %d, %v", tt.values, res, index, err, tt.index, tt.err)
+		}
+	}
+
+	// The fused product only underflows once the extended precision runs out, so a tiny
+	// intermediate product can recover.
+	if res, index, err := ProdFusedUFix128([]UFix128{tiny, tiny, tiny, huge, huge}, RoundNearestHalfEven); err != nil || index != -1 || res != MustParseUFix128("1e-16") {
+		t.Errorf("ProdFusedUFix128(recovering) = %v, %d, %v; want 1e-16", res, index, err)
+	}
+	if res, index, err := ProdFusedUFix128([]UFix128{tiny, tiny, tiny, tiny}, RoundTowardZero); index != 3 || err != (UnderflowError{}) {
+		t.Errorf("ProdFusedUFix128(tiny^4) = %v, %d, %v; want 3, UnderflowError", res, index, err)
+	}
+	if res, index, err := ProdFusedUFix128([]UFix128{huge, huge, huge}, RoundTowardZero); index != 2 || err != (PositiveOverflowError{}) {
+		t.Errorf("ProdFusedUFix128(huge^3) = %v, %d, %v; want 2, PositiveOverflowError", res, index, err)
+	}
+	if res, index, err := ProdFusedUFix128(nil, RoundTowardZero); err != nil || index != -1 || res != UFix128One {
+		t.Errorf("ProdFusedUFix128(nil) = %v, %d, %v; want 1", res, index, err)
+	}
+
+	if res, index, err := ProdUFix64([]UFix64{UFix64One, MustParseUFix64("0.5"), MustParseUFix64("0.00000001")}, RoundNearestHalfAway); index != -1 || err != nil || res != UFix64Iota {
+		t.Errorf("ProdUFix64() = %v, %d, %v", res, index, err)
+	}
+	if _, _, err := ProdUFix64(nil, RoundingMode(99)); err != (InvalidRoundingModeError{}) {
+		t.Errorf("ProdUFix64(nil, 99) = %v; want InvalidRoundingModeError", err)
+	}
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixbits
+
+import "math/bits"
+
+// Div128 returns the quotient and remainder of the 256-bit value (hi, lo) divided by y, where hi
+// and lo are 128-bit values given as two words each: quo = (hi, lo) / y, rem = (hi, lo) % y.
+// Div128 panics for y == 0 (division by zero) or y <= hi (quotient overflow).
+func Div128(hiHi, hiLo, loHi, loLo, yHi, yLo uint64) (quoHi, quoLo, remHi, remLo uint64) {
+	if hiHi > yHi || (hiHi == yHi && hiLo >= yLo) {
+		if yHi == 0 && yLo == 0 {
+			panic("fixbits: division by zero")
+		}
+		panic("fixbits: quotient overflow")
+	}
+
+	var remResidual uint64
+	remShift := uint(bits.TrailingZeros64(yLo))
+
+	if remShift != 0 {
+		// If the denominator has trailing zeros, we can shift both the numerator and denominator
+		// by the same amount to potentially reduce the size of the numbers involved (in particular,
+		// this could turn the denominator into a 64-bit value or the numerator into a 192-bit value,
+		// either of which is much cheaper to compute).
+		//
+		// This will result in the same quotient, but the remainder will be scaled down by the same
+		// shifted factor, AND be missing the bottom bits that were shifted out. We save those bits
+		// now to re-add to the remainder later.
+		remResidual = loLo & (1<<remShift - 1)
+
+		// Divide the denominator (nothing lost here, the bits that are falling off are all zeros)
+		yHi, yLo = shiftRight128(yHi, yLo, remShift)
+
+		// Shift the numerator down by the same amount, we saved the bottom bits of the lo part
+		// above (remResidual), but we need to bring the low part of the high part down into
+		// the high part of the low part!
+		loHi, loLo = shiftRight128(loHi, loLo, remShift)
+		loHi |= hiLo << (64 - remShift)
+		hiHi, hiLo = shiftRight128(hiHi, hiLo, remShift)
+	}
+
+	if yHi == 0 {
+		// If the denominator fits in 64 bits, the numerator must fit in 192 bits (hiHi == 0),
+		// since the quotient fits in 128 bits.
+		quoHi, quoLo, remLo = Div192By64(hiLo, loHi, loLo, yLo)
+	} else if hiHi == 0 {
+		// If the high part of the numerator is zero, we can use a single pass of our 192x128
+		// division algorithm
+		quoHi, quoLo, remHi, remLo = div192By128(hiLo, loHi, loLo, yHi, yLo)
+	} else {
+		// We use the "divide and conquer" approach to compute the quotient of a 256-bit numerator
+		// by a 128-bit denominator. It involves two calls to a 192 over 128 division algorithm,
+		// ("3 by 2" division)
+		_, qHi, rHi, rLo := div192By128(hiHi, hiLo, loHi, yHi, yLo)
+
+		// The high quotient is under 2^64, so it's shifted up by a whole word
+		var qLoHi, carry uint64
+		qLoHi, quoLo, remHi, remLo = div192By128(rHi, rLo, loLo, yHi, yLo)
+		quoHi, carry = bits.Add64(qHi, qLoHi, 0)
+		if carry != 0 {
+			debugPanic("fixbits.Div128: quotient overflow")
+		}
+	}
+
+	if remShift != 0 {
+		// We shifted before dividing, so we need to unshift the remainder and add back in the
+		// residual bits from the numerator
+		remHi, remLo = shiftLeft128(remHi, remLo, remShift)
+		remLo |= remResidual
+	}
+
+	return quoHi, quoLo, remHi, remLo
+}
+
+// Div192By64 returns the quotient and remainder of the 192-bit value (hi, mid, lo) divided by y.
+// Div192By64 panics for y == 0 (division by zero) or y <= hi (quotient overflow).
+func Div192By64(hi, mid, lo, y uint64) (quoHi, quoLo, rem uint64) {
+	quoHi, rem = bits.Div64(hi, mid, y)
+	quoLo, rem = bits.Div64(rem, lo, y)
+
+	return quoHi, quoLo, rem
+}
+
+// div192By128 divides the 192-bit value (hi, mid, lo) by y, which must be at least 2^64, and have
+// y > hi.
+func div192By128(hi, mid, lo, yHi, yLo uint64) (quoHi, quoLo, remHi, remLo uint64) {
+	var carry, borrow uint64
+
+	shift := uint(bits.LeadingZeros64(yHi))
+
+	// We take the 64 leading, non-zero bits of the denominator and shift it
+	// into a uint64. We shift the top bits of the numerator the same amount
+	// (filling in with bits from the middle value) and divide them to get
+	// an estimate of the high 64-bits of the quotient. This estimate will either
+	// be correct, or slightly too high. (If it is too high, we will see a negative
+	// remainder and can adjust.)
+	estY := (yHi << shift) | (yLo >> (64 - shift))
+	estHi := hi >> (64 - shift)
+	estLo := (hi << shift) | (mid >> (64 - shift))
+
+	quoHi, _ = bits.Div64(estHi, estLo, estY)
+
+	// We multiply our estimate by the denominator and subtract it from the
+	// original numerator to get an intermediate remainder. The estimate can be too high by up to
+	// two, in which case the remainder is negative (i.e. its top word is non-zero), and we add back
+	// copies of the denominator until it isn't.
+	prodTop, prodHi, prodLo := Mul128By64(yHi, yLo, quoHi)
+
+	// Subtract out the product from the top two parts (hi and mid) of the numerator
+	// to get an interim result.
+	interimMid, borrow := bits.Sub64(mid, prodLo, 0)
+	interimHi, borrow := bits.Sub64(hi, prodHi, borrow)
+	interimTop, _ := bits.Sub64(0, prodTop, borrow)
+
+	for interimTop != 0 {
+		quoHi--
+
+		interimMid, carry = bits.Add64(interimMid, yLo, 0)
+		interimHi, carry = bits.Add64(interimHi, yHi, carry)
+		interimTop += carry
+	}
+
+	// The interim remainder (interimHi | interimMid | lo) is a 192-bit value but we know
+	// that it's less than y << 64. The next step is to divide interim remaind by the
+	// denominator to get the low word of the quotient and the final remainder.
+	// It might look like we're right back where we started; we have a 192-bit numerator
+	// (interimHi, interimMid, lo) and a 128-bit denominator (y), but we can use the fact that
+	// we know that interim < y << 64 to predict that the result of this final division will fit
+	// into 64 bits. We can shift the interim remainder down by (64 - shift), which is guaranteed
+	// to fit 128 bits, and use the shifted y we used for the first estimate to get our final result
+	finalHi := (interimHi << shift) | (interimMid >> (64 - shift))
+	finalLo := (interimMid << shift) | (lo >> (64 - shift))
+
+	// There is an edge case here where the COMPLETE interim remainder is less than the denominator,
+	// but the truncated interim remainder (finalHi | finalLo) is equal to the truncated denominator
+	// (estY << 64), so the estimate doesn't fit in 64 bits. The quotient does, so we start from the
+	// largest 64-bit value instead, which is still never too low.
+	if finalHi >= estY {
+		if finalHi > estY {
+			debugPanic("div192By128: finalHi should never be greater than estY, only equal")
+		}
+		quoLo = 0xffffffffffffffff
+	} else {
+		quoLo, _ = bits.Div64(finalHi, finalLo, estY)
+	}
+
+	// Now we just need to compute the final remainder, which is negative (as above) if the
+	// estimate was too high.
+	pHi, pMid, pLo := Mul128By64(yHi, yLo, quoLo)
+
+	remLo, borrow = bits.Sub64(lo, pLo, 0)
+	remHi, borrow = bits.Sub64(interimMid, pMid, borrow)
+	remTop, _ := bits.Sub64(interimHi, pHi, borrow)
+
+	for remTop != 0 {
+		quoLo--
+
+		remLo, carry = bits.Add64(remLo, yLo, 0)
+		remHi, carry = bits.Add64(remHi, yHi, carry)
+		remTop += carry
+	}
+
+	return
+}
+
+func shiftLeft128(hi, lo uint64, shift uint) (uint64, uint64) {
+	if shift >= 64 {
+		return lo << (shift - 64), 0
+	}
+
+	return (hi << shift) | (lo >> (64 - shift)), lo << shift
+}
+
+func shiftRight128(hi, lo uint64, shift uint) (uint64, uint64) {
+	if shift >= 64 {
+		return 0, hi >> (shift - 64)
+	}
+
+	return hi >> shift, (lo >> shift) | (hi << (64 - shift))
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package fixbits provides the wide unsigned integer arithmetic that the fixedPoint package is
+// built on, for other code that needs the same 128 and 192-bit operations. It follows the
+// conventions of math/bits: values are passed as 64-bit words, most significant first, and carries
+// and borrows are 0 or 1.
+//
+// Only the division functions can panic, for a zero divisor or a quotient that doesn't fit, the
+// same as bits.Div64. Callers that need to avoid panics must check these preconditions first.
+package fixbits
+
+import "math/bits"
+
+// Add128 returns the sum with carry of x, y and carry: sum = x + y + carry. The carry input must be
+// 0 or 1, otherwise the behavior is undefined. The carryOut output is guaranteed to be 0 or 1.
+func Add128(xHi, xLo, yHi, yLo, carry uint64) (sumHi, sumLo, carryOut uint64) {
+	sumLo, carry = bits.Add64(xLo, yLo, carry)
+	sumHi, carryOut = bits.Add64(xHi, yHi, carry)
+	return
+}
+
+// Sub128 returns the difference of x, y and borrow: diff = x - y - borrow. The borrow input must be
+// 0 or 1, otherwise the behavior is undefined. The borrowOut output is guaranteed to be 0 or 1.
+func Sub128(xHi, xLo, yHi, yLo, borrow uint64) (diffHi, diffLo, borrowOut uint64) {
+	diffLo, borrow = bits.Sub64(xLo, yLo, borrow)
+	diffHi, borrowOut = bits.Sub64(xHi, yHi, borrow)
+	return
+}
+
+// Mul128 returns the 256-bit product of x and y, as the four words (p3, p2, p1, p0).
+func Mul128(xHi, xLo, yHi, yLo uint64) (p3, p2, p1, p0 uint64) {
+	// If either operand fits into 64 bits, we can use a simpler multiplication.
+	// This also handles the case where one of the operands is zero.
+	if xHi == 0 {
+		p2, p1, p0 = Mul128By64(yHi, yLo, xLo)
+		return
+	} else if yHi == 0 {
+		p2, p1, p0 = Mul128By64(xHi, xLo, yLo)
+		return
+	}
+
+	// Observe that:
+	//   x = xH•B + xL and y = yH•B + yL (where B = 2^64)
+	//   x * y = (xH * yH) * B^2 + ((xH * yL) + (xL * yH)) * B + (xL * yL)
+	//
+	// Note that we DO NOT use Karatsuba multiplication here, because we have
+	// access to efficient 64-bit multiplication, and the "Karatusba product"
+	// operates on sums that could overflow 64 bits and require edge-case handling.
+
+	// u is xH * yH
+	// v is (xH * yL) + (xL * yH)
+	// w is xL * yL
+	uHi, uLo := bits.Mul64(xHi, yHi)
+	v1Hi, v1Lo := bits.Mul64(xHi, yLo)
+	v2Hi, v2Lo := bits.Mul64(xLo, yHi)
+	vHi, vLo, vCarry := Add128(v1Hi, v1Lo, v2Hi, v2Lo, 0)
+	wHi, wLo := bits.Mul64(xLo, yLo)
+
+	// The lowest word of the result is the low part of w
+	p0 = wLo
+
+	// p1 is the low part of v plus the high part of w
+	var midCarry, hiCarry uint64
+	p1, midCarry = bits.Add64(vLo, wHi, 0)
+
+	// p2 is the sum of the low part of u with the high part of v plus any carry from the
+	// previous sum.
+	p2, hiCarry = bits.Add64(uLo, vHi, midCarry)
+
+	// p3 is the high part of u plus any carry from the previous sum (and any carry from
+	// computing v).
+	p3, _ = bits.Add64(uHi, vCarry, hiCarry)
+
+	return
+}
+
+// Mul128By64 returns the 192-bit product of x and y, as the three words (hi, mid, lo).
+func Mul128By64(xHi, xLo, y uint64) (hi, mid, lo uint64) {
+	// NOTE: Earlier versions of this function would try to "fast return"
+	// when x or y were zero, but it actually resulted in slower code.
+	// The go compiler can turn bits.Mul64 into a single instruction
+	// so the branches are more expensive than the computation!
+
+	// Perform multiplication using bits.Mul64. You can think about this as
+	// long multiplication where our "base" is 2^64.
+	//      xH  xL
+	// x         y
+	// -----------
+	//       w   s
+	// + q   z
+	// -----------
+	//  hi mid  lo
+	// where w:s is y•xL and q:z is y•xH. Note that lo == s.
+	var w, z uint64
+	var carry uint64
+	w, lo = bits.Mul64(xLo, y)
+	hi, z = bits.Mul64(xHi, y)
+
+	mid, carry = bits.Add64(w, z, 0)
+
+	// Can't overflow, since that would imply a 128 x 64 multiplication
+	// overflowed 192 bits, which is not possible.
+	hi += carry
+
+	return hi, mid, lo
+}
+
+// Mul192By64 returns the 256-bit product of the 192-bit value x and y, as the four words
+// (p3, p2, p1, p0).
+func Mul192By64(xHi, xMid, xLo, y uint64) (p3, p2, p1, p0 uint64) {
+	var carry uint64
+
+	loHi, loLo := bits.Mul64(xLo, y)
+	midHi, midLo := bits.Mul64(xMid, y)
+	hiHi, hiLo := bits.Mul64(xHi, y)
+
+	p0 = loLo
+	p1, carry = bits.Add64(loHi, midLo, 0)
+	p2, carry = bits.Add64(midHi, hiLo, carry)
+	p3 = hiHi + carry
+
+	return
+}
+
+// debugPanic panics with the given message, but only in debug builds (i.e. when built with the
+// fixedpoint_debug build tag), the same as the helper of the same name in the fixedPoint package.
+func debugPanic(msg string) {
+	if debugBuild {
+		panic(msg)
+	}
+}
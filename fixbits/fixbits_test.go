@@ -0,0 +1,155 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixbits
+
+import (
+	"math/big"
+	"testing"
+)
+
+var edgeWords = []uint64{
+	0,
+	1,
+	2,
+	0x000000000000d3c2,
+	0x1bcecceda1000000,
+	0x7fffffffffffffff,
+	0x8000000000000000,
+	0xfffffffffffffffe,
+	0xffffffffffffffff,
+}
+
+// bigFromWords returns the unsigned value of the given words (most significant first).
+func bigFromWords(words ...uint64) *big.Int {
+	res := new(big.Int)
+	for _, w := range words {
+		res.Lsh(res, 64).Or(res, new(big.Int).SetUint64(w))
+	}
+
+	return res
+}
+
+func TestAddSubMul(t *testing.T) {
+
+	t.Parallel()
+
+	mod128 := new(big.Int).Lsh(big.NewInt(1), 128)
+
+	for _, xHi := range edgeWords {
+		for _, xLo := range edgeWords {
+			for _, yHi := range edgeWords {
+				for _, yLo := range edgeWords {
+					x, y := bigFromWords(xHi, xLo), bigFromWords(yHi, yLo)
+
+					sumHi, sumLo, carry := Add128(xHi, xLo, yHi, yLo, 1)
+					want := new(big.Int).Add(x, y)
+					want.Add(want, big.NewInt(1))
+					if bigFromWords(carry, sumHi, sumLo).Cmp(want) != 0 {
+						t.Errorf("Add128(%x, %x, 1) = %x, %x, %d; want %x", x, y, sumHi, sumLo, carry, want)
+					}
+
+					diffHi, diffLo, borrow := Sub128(xHi, xLo, yHi, yLo, 0)
+					want = new(big.Int).Sub(x, y)
+					if borrow != uint64(-min(want.Sign(), 0)) || bigFromWords(diffHi, diffLo).Cmp(want.Mod(want, mod128)) != 0 {
+						t.Errorf("Sub128(%x, %x, 0) = %x, %x, %d; want %x", x, y, diffHi, diffLo, borrow, want)
+					}
+
+					p3, p2, p1, p0 := Mul128(xHi, xLo, yHi, yLo)
+					if want := new(big.Int).Mul(x, y); bigFromWords(p3, p2, p1, p0).Cmp(want) != 0 {
+						t.Errorf("Mul128(%x, %x) = %x %x %x %x; want %x", x, y, p3, p2, p1, p0, want)
+					}
+
+					p3, p2, p1, p0 = Mul192By64(xHi, xLo, yHi, yLo)
+					want = new(big.Int).Mul(bigFromWords(xHi, xLo, yHi), new(big.Int).SetUint64(yLo))
+					if bigFromWords(p3, p2, p1, p0).Cmp(want) != 0 {
+						t.Errorf("Mul192By64(%x, %x) = %x %x %x %x; want %x", bigFromWords(xHi, xLo, yHi), yLo, p3, p2, p1, p0, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDiv(t *testing.T) {
+
+	t.Parallel()
+
+	for _, hiHi := range edgeWords {
+		for _, hiLo := range edgeWords {
+			for _, lo := range edgeWords {
+				for _, yHi := range edgeWords {
+					for _, yLo := range edgeWords {
+						y := bigFromWords(yHi, yLo)
+						hi := bigFromWords(hiHi, hiLo)
+						if hi.Cmp(y) >= 0 {
+							continue
+						}
+
+						// Use the same word for both halves of the low part, to keep the number
+						// of cases manageable.
+						num := bigFromWords(hiHi, hiLo, lo, ^lo)
+						wantQuo, wantRem := new(big.Int).QuoRem(num, y, new(big.Int))
+
+						qHi, qLo, rHi, rLo := Div128(hiHi, hiLo, lo, ^lo, yHi, yLo)
+						if bigFromWords(qHi, qLo).Cmp(wantQuo) != 0 || bigFromWords(rHi, rLo).Cmp(wantRem) != 0 {
+							t.Errorf("Div128(%x, %x) = %x, %x; want %x, %x", num, y, bigFromWords(qHi, qLo), bigFromWords(rHi, rLo), wantQuo, wantRem)
+						}
+
+						if yHi == 0 && hiHi == 0 {
+							num := bigFromWords(hiLo, lo, ^lo)
+							wantQuo, wantRem := new(big.Int).QuoRem(num, y, new(big.Int))
+
+							qHi, qLo, r := Div192By64(hiLo, lo, ^lo, yLo)
+							if bigFromWords(qHi, qLo).Cmp(wantQuo) != 0 || r != wantRem.Uint64() {
+								t.Errorf("Div192By64(%x, %x) = %x, %x; want %x, %x", num, y, bigFromWords(qHi, qLo), r, wantQuo, wantRem)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for _, tc := range []struct {
+		name                 string
+		hiHi, hiLo, yHi, yLo uint64
+	}{
+		{"zero", 0, 0, 0, 0},
+		{"overflow", 0, 1, 0, 1},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Div128 with %s divisor didn't panic", tc.name)
+				}
+			}()
+			Div128(tc.hiHi, tc.hiLo, 0, 0, tc.yHi, tc.yLo)
+		}()
+	}
+}
+
+// TestDiv192By128Regression checks a division where both quotient estimates in div192By128 are
+// too high, see the test of the same name in the fixedPoint package.
+func TestDiv192By128Regression(t *testing.T) {
+
+	t.Parallel()
+
+	qHi, qLo, rHi, rLo := Div128(0, 0x8000000000000000, 0, 0, 0x8000000000000000, 0xfffffffffffffffe)
+	if qHi != 0 || qLo != 0xfffffffffffffffe || rHi != 3 || rLo != 0xfffffffffffffffc {
+		t.Errorf("Div128(2^191, 2^127 + 2^64 - 2) = %x, %x; want 2^64 - 2, 2^66 - 4", bigFromWords(qHi, qLo), bigFromWords(rHi, rLo))
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"math"
+	"strconv"
+)
+
+// This file contains the conversions between the fixed-point types and float64. Converting from
+// a float64 is exact before rounding: the float's exact binary value (which may have hundreds of
+// decimal digits) is rounded once, to the precision of the type, using the given rounding mode.
+// Converting to a float64 is lossy, since a float64 only has 53 bits of precision, but it's
+// always correctly rounded.
+
+// UFix64FromFloat64 converts f to a UFix64, rounding as specified. It returns an
+// OutOfDomainErrorError for NaN, a PositiveOverflowError for +Inf and values too large for the
+// type, and a NegativeOverflowError for -Inf and values that are negative after rounding. Non-zero
+// values that round to zero return an UnderflowError.
+func UFix64FromFloat64(f float64, round RoundingMode) (UFix64, error) {
+	return fromFloat64(f, round, ParseOptions.ParseUFix64)
+}
+
+// Fix64FromFloat64 converts f to a Fix64, rounding as specified, see UFix64FromFloat64.
+func Fix64FromFloat64(f float64, round RoundingMode) (Fix64, error) {
+	return fromFloat64(f, round, ParseOptions.ParseFix64)
+}
+
+// UFix128FromFloat64 converts f to a UFix128, rounding as specified, see UFix64FromFloat64.
+func UFix128FromFloat64(f float64, round RoundingMode) (UFix128, error) {
+	return fromFloat64(f, round, ParseOptions.ParseUFix128)
+}
+
+// Fix128FromFloat64 converts f to a Fix128, rounding as specified, see UFix64FromFloat64.
+func Fix128FromFloat64(f float64, round RoundingMode) (Fix128, error) {
+	return fromFloat64(f, round, ParseOptions.ParseFix128)
+}
+
+// ToFloat64 returns the float64 closest to `a` (with ties rounded to even). This is lossy:
+// values with more than about 15 significant digits can't be represented exactly, so don't use
+// the result for anything but display, statistics and other approximate calculations.
+func (a UFix64) ToFloat64() float64 {
+	// Both the raw value and the scale are exact in a float64, so a single division is correctly
+	// rounded.
+	if a < 1<<53 {
+		return float64(a) / Fix64Scale
+	}
+
+	return parseFloat(a.Append(nil))
+}
+
+// ToFloat64 returns the float64 closest to `a`, see UFix64.ToFloat64.
+func (a Fix64) ToFloat64() float64 {
+	if aUnsigned, sign := a.Abs(); aUnsigned < 1<<53 {
+		return float64(sign) * float64(aUnsigned) / Fix64Scale
+	}
+
+	return parseFloat(a.Append(nil))
+}
+
+// ToFloat64 returns the float64 closest to `a`, see UFix64.ToFloat64.
+func (a UFix128) ToFloat64() float64 { return parseFloat(a.Append(nil)) }
+
+// ToFloat64 returns the float64 closest to `a`, see UFix64.ToFloat64.
+func (a Fix128) ToFloat64() float64 { return parseFloat(a.Append(nil)) }
+
+// maxFloatDigits is the largest number of significant digits in the exact decimal expansion of a
+// float64 (for the smallest subnormal numbers), so formatting with this many digits is exact.
+const maxFloatDigits = 767
+
+func fromFloat64[T any](f float64, round RoundingMode, parse func(ParseOptions, string) (T, error)) (T, error) {
+	var zero T
+
+	switch {
+	case math.IsNaN(f):
+		return zero, OutOfDomainErrorError{}
+	case math.IsInf(f, 1):
+		return zero, PositiveOverflowError{}
+	case math.IsInf(f, -1):
+		return zero, NegativeOverflowError{}
+	}
+
+	// Format the exact value in decimal, and let the parser do the rounding. (The parser's
+	// rounding checks its arguments, so an invalid rounding mode is reported as usual.)
+	var buf [maxFloatDigits + 8]byte
+	return parse(ParseOptions{Rounding: round}, string(strconv.AppendFloat(buf[:0], f, 'e', maxFloatDigits-1, 64)))
+}
+
+// parseFloat parses the decimal form of a value, which is always valid.
+func parseFloat(s []byte) float64 {
+	f, _ := strconv.ParseFloat(string(s), 64)
+	return f
+}
@@ -0,0 +1,812 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// This file contains the conversions from fixed-point values to decimal strings. All of the
+// formatting is exact; the raw value is converted to decimal digits with integer arithmetic,
+// and never goes through a floating-point type.
+
+// String returns the exact decimal representation of `a`, with as few fractional digits as
+// possible, but always at least one (e.g. "1.0", "0.00000001", "12.5").
+//
+// This is the canonical text form of the type: every value has exactly one, with no sign for
+// zero, no leading zeros and no exponent, and parsing it (with any of the parse functions, in
+// strict or lenient mode) always gives back the same value. The canonical form is used by
+// MarshalText and the other text-based encodings, so it's suitable for hashing and comparing
+// string representations. Use FixedString for a fixed number of decimals.
+func (a UFix64) String() string {
+	var buf [maxDecimalLen]byte
+	return string(a.Append(buf[:0]))
+}
+
+// String returns the exact decimal representation of `a`, with a leading '-' for negative values.
+func (a Fix64) String() string {
+	var buf [maxDecimalLen]byte
+	return string(a.Append(buf[:0]))
+}
+
+// String returns the exact decimal representation of `a`, with as few fractional digits as
+// possible, but always at least one.
+func (a UFix128) String() string {
+	var buf [maxDecimalLen]byte
+	return string(a.Append(buf[:0]))
+}
+
+// String returns the exact decimal representation of `a`, with a leading '-' for negative values.
+// Note that this works for Fix128Min, whose magnitude isn't representable as a Fix128.
+func (a Fix128) String() string {
+	var buf [maxDecimalLen]byte
+	return string(a.Append(buf[:0]))
+}
+
+// String returns the exact decimal representation of `a`, see UFix128.String.
+func (a UFix256) String() string {
+	var buf [maxDecimalLen256]byte
+	return string(a.Append(buf[:0]))
+}
+
+// String returns the exact decimal representation of `a`, see Fix128.String.
+func (a Fix256) String() string {
+	var buf [maxDecimalLen256]byte
+	return string(a.Append(buf[:0]))
+}
+
+// FixedString returns the exact decimal representation of `a` with at least the given number of
+// fractional digits: trailing zeros are added or removed as needed, but digits are never dropped,
+// so (like String) the result always parses back to `a`. FixedString(Fix64Decimals) is the full
+// precision form, e.g. "1.00000000", and FixedString(0) has no fractional part for integers, e.g.
+// "1". A negative number of decimals is treated as zero.
+func (a UFix64) FixedString(decimals int) string {
+	return string(appendFixed(nil, false, 0, uint64(a), Fix64Decimals, decimals))
+}
+
+// FixedString returns the exact decimal representation of `a` with at least the given number of
+// fractional digits, see UFix64.FixedString.
+func (a Fix64) FixedString(decimals int) string {
+	aUnsigned, sign := a.Abs()
+	return string(appendFixed(nil, sign < 0, 0, uint64(aUnsigned), Fix64Decimals, decimals))
+}
+
+// FixedString returns the exact decimal representation of `a` with at least the given number of
+// fractional digits, see UFix64.FixedString. FixedString(Fix128Decimals) is the full precision
+// form.
+func (a UFix128) FixedString(decimals int) string {
+	return string(appendFixed(nil, false, uint64(a.Hi), uint64(a.Lo), Fix128Decimals, decimals))
+}
+
+// FixedString returns the exact decimal representation of `a` with at least the given number of
+// fractional digits, see UFix128.FixedString.
+func (a Fix128) FixedString(decimals int) string {
+	aUnsigned, sign := a.Abs()
+	return string(appendFixed(nil, sign < 0, uint64(aUnsigned.Hi), uint64(aUnsigned.Lo), Fix128Decimals, decimals))
+}
+
+// Append appends the same text as String to dst, and returns the extended buffer. It doesn't
+// allocate if dst has enough spare capacity, which makes it suitable for formatting large numbers
+// of values, e.g. in loggers and serializers.
+func (a UFix64) Append(dst []byte) []byte {
+	return appendDecimal(dst, false, 0, uint64(a), Fix64Decimals, true)
+}
+
+// Append appends the same text as String to dst, and returns the extended buffer.
+func (a Fix64) Append(dst []byte) []byte {
+	aUnsigned, sign := a.Abs()
+	return appendDecimal(dst, sign < 0, 0, uint64(aUnsigned), Fix64Decimals, true)
+}
+
+// Append appends the same text as String to dst, and returns the extended buffer.
+func (a UFix128) Append(dst []byte) []byte {
+	return appendDecimal(dst, false, uint64(a.Hi), uint64(a.Lo), Fix128Decimals, true)
+}
+
+// Append appends the same text as String to dst, and returns the extended buffer.
+func (a Fix128) Append(dst []byte) []byte {
+	aUnsigned, sign := a.Abs()
+	return appendDecimal(dst, sign < 0, uint64(aUnsigned.Hi), uint64(aUnsigned.Lo), Fix128Decimals, true)
+}
+
+// Append appends the same text as String to dst, and returns the extended buffer.
+func (a UFix256) Append(dst []byte) []byte {
+	return appendDecimal256(dst, false, raw256(a), Fix256Decimals)
+}
+
+// Append appends the same text as String to dst, and returns the extended buffer.
+func (a Fix256) Append(dst []byte) []byte {
+	aUnsigned, sign := a.Abs()
+	return appendDecimal256(dst, sign < 0, raw256(aUnsigned), Fix256Decimals)
+}
+
+// GoString returns a Go expression for `a` with its decimal value in a comment, e.g.
+// "fixedPoint.UFix64(0x0000000005f5e100) /* 1.0 */", which is used for the %#v verb.
+func (a UFix64) GoString() string {
+	return string(appendGoString(nil, "UFix64", fmtValue{lo: uint64(a), rawLo: uint64(a), decimals: Fix64Decimals}))
+}
+
+// GoString returns a Go expression for `a` with its decimal value in a comment, see
+// UFix64.GoString.
+func (a Fix64) GoString() string {
+	aUnsigned, sign := a.Abs()
+	return string(appendGoString(nil, "Fix64", fmtValue{neg: sign < 0, lo: uint64(aUnsigned), rawLo: uint64(a),
+		decimals: Fix64Decimals}))
+}
+
+// GoString returns a Go expression for `a` with its decimal value in a comment, e.g.
+// "fixedPoint.UFix128{Hi: 0x000000000000d3c2, Lo: 0x1bcecceda1000000} /* 1.0 */".
+func (a UFix128) GoString() string {
+	return string(appendGoString(nil, "UFix128", fmtValue{hi: uint64(a.Hi), lo: uint64(a.Lo), rawHi: uint64(a.Hi),
+		rawLo: uint64(a.Lo), decimals: Fix128Decimals, wide: true}))
+}
+
+// GoString returns a Go expression for `a` with its decimal value in a comment, see
+// UFix128.GoString.
+func (a Fix128) GoString() string {
+	aUnsigned, sign := a.Abs()
+	return string(appendGoString(nil, "Fix128", fmtValue{neg: sign < 0, hi: uint64(aUnsigned.Hi), lo: uint64(aUnsigned.Lo),
+		rawHi: uint64(a.Hi), rawLo: uint64(a.Lo), decimals: Fix128Decimals, wide: true}))
+}
+
+// Format implements fmt.Formatter, so values can be used directly with the fmt package. The
+// supported verbs are:
+//
+//	%v, %s  the same as String, e.g. "12.5"
+//	%#v     the same as GoString
+//	%f, %F  the exact value without an exponent; with a precision (e.g. %.2f) the value is
+//	        rounded to that many decimals, with ties rounded to even
+//	%e, %E  scientific notation, e.g. "1.25e+01"; with a precision, the mantissa is rounded to
+//	        that many decimals in the same way
+//	%x, %X  the raw value in hexadecimal, as a fixed-width two's complement word; the '#' flag
+//	        adds a 0x prefix
+//
+// The '+', ' ', '-', and '0' flags and the width work as they do for floats.
+func (a UFix64) Format(f fmt.State, verb rune) {
+	formatValue(f, verb, "UFix64", fmtValue{lo: uint64(a), rawLo: uint64(a), decimals: Fix64Decimals})
+}
+
+// Format implements fmt.Formatter, see UFix64.Format for details.
+func (a Fix64) Format(f fmt.State, verb rune) {
+	aUnsigned, sign := a.Abs()
+	formatValue(f, verb, "Fix64", fmtValue{neg: sign < 0, lo: uint64(aUnsigned), rawLo: uint64(a), decimals: Fix64Decimals})
+}
+
+// Format implements fmt.Formatter, see UFix64.Format for details.
+func (a UFix128) Format(f fmt.State, verb rune) {
+	formatValue(f, verb, "UFix128", fmtValue{hi: uint64(a.Hi), lo: uint64(a.Lo), rawHi: uint64(a.Hi), rawLo: uint64(a.Lo),
+		decimals: Fix128Decimals, wide: true})
+}
+
+// Format implements fmt.Formatter, see UFix64.Format for details.
+func (a Fix128) Format(f fmt.State, verb rune) {
+	aUnsigned, sign := a.Abs()
+	formatValue(f, verb, "Fix128", fmtValue{neg: sign < 0, hi: uint64(aUnsigned.Hi), lo: uint64(aUnsigned.Lo),
+		rawHi: uint64(a.Hi), rawLo: uint64(a.Lo), decimals: Fix128Decimals, wide: true})
+}
+
+// FormatGrouped returns `a` with its integer digits grouped in threes by sep, rounded to the
+// given number of decimals using the given rounding mode, e.g. "1,234,567.890123" for
+// FormatGrouped(',', 6, RoundNearestHalfEven). The fraction always has exactly that many digits,
+// unless decimals is negative, in which case the value is exact with the same digits as String. A
+// sep of zero disables grouping. The decimal point is always '.'. Returns an
+// InvalidRoundingModeError if the rounding mode isn't valid.
+func (a UFix64) FormatGrouped(sep rune, decimals int, round RoundingMode) (string, error) {
+	return formatGrouped(fmtValue{lo: uint64(a), decimals: Fix64Decimals}, sep, decimals, round)
+}
+
+// FormatGrouped returns `a` with its integer digits grouped, see UFix64.FormatGrouped. Directed
+// rounding modes (RoundFloor and RoundCeil) take the sign into account, and a value that rounds
+// to zero has no sign.
+func (a Fix64) FormatGrouped(sep rune, decimals int, round RoundingMode) (string, error) {
+	aUnsigned, sign := a.Abs()
+	return formatGrouped(fmtValue{neg: sign < 0, lo: uint64(aUnsigned), decimals: Fix64Decimals}, sep, decimals,
+		round)
+}
+
+// FormatGrouped returns `a` with its integer digits grouped, see UFix64.FormatGrouped.
+func (a UFix128) FormatGrouped(sep rune, decimals int, round RoundingMode) (string, error) {
+	return formatGrouped(fmtValue{hi: uint64(a.Hi), lo: uint64(a.Lo), decimals: Fix128Decimals}, sep, decimals,
+		round)
+}
+
+// FormatGrouped returns `a` with its integer digits grouped, see Fix64.FormatGrouped.
+func (a Fix128) FormatGrouped(sep rune, decimals int, round RoundingMode) (string, error) {
+	aUnsigned, sign := a.Abs()
+	return formatGrouped(fmtValue{neg: sign < 0, hi: uint64(aUnsigned.Hi), lo: uint64(aUnsigned.Lo),
+		decimals: Fix128Decimals}, sep, decimals, round)
+}
+
+// FormatCurrency returns `a` as a currency amount, grouped with commas and rounded to the given
+// number of decimals as for FormatGrouped. A currency code (e.g. "FLOW") follows the amount after a
+// space, as in "1,234.5678 FLOW", while a single symbol character (e.g. "$") comes before it, as
+// in "$1,234.57". For wallets, RoundTowardZero never shows more than the actual balance. Returns
+// an InvalidRoundingModeError if the rounding mode isn't valid.
+func (a UFix64) FormatCurrency(currency string, decimals int, round RoundingMode) (string, error) {
+	return formatCurrency(fmtValue{lo: uint64(a), decimals: Fix64Decimals}, currency, decimals, round)
+}
+
+// FormatCurrency returns `a` as a currency amount, see UFix64.FormatCurrency. Negative amounts
+// have a leading '-', before any symbol (e.g. "-$1.50").
+func (a Fix64) FormatCurrency(currency string, decimals int, round RoundingMode) (string, error) {
+	aUnsigned, sign := a.Abs()
+	return formatCurrency(fmtValue{neg: sign < 0, lo: uint64(aUnsigned), decimals: Fix64Decimals}, currency,
+		decimals, round)
+}
+
+// FormatCurrency returns `a` as a currency amount, see UFix64.FormatCurrency.
+func (a UFix128) FormatCurrency(currency string, decimals int, round RoundingMode) (string, error) {
+	return formatCurrency(fmtValue{hi: uint64(a.Hi), lo: uint64(a.Lo), decimals: Fix128Decimals}, currency,
+		decimals, round)
+}
+
+// FormatCurrency returns `a` as a currency amount, see Fix64.FormatCurrency.
+func (a Fix128) FormatCurrency(currency string, decimals int, round RoundingMode) (string, error) {
+	aUnsigned, sign := a.Abs()
+	return formatCurrency(fmtValue{neg: sign < 0, hi: uint64(aUnsigned.Hi), lo: uint64(aUnsigned.Lo),
+		decimals: Fix128Decimals}, currency, decimals, round)
+}
+
+// FormatScientific returns the exact value of `a` in scientific notation, with one integer digit
+// and as few fractional digits as possible, e.g. "1.2345e+12" or "5e-8". Unlike the %e verb, the
+// exponent isn't padded to two digits. The result can be parsed with ParseOptions.
+func (a UFix64) FormatScientific() string {
+	return string(appendExponential(nil, fmtValue{lo: uint64(a), decimals: Fix64Decimals}, 1))
+}
+
+// FormatEngineering returns the exact value of `a` in engineering notation, i.e. like
+// FormatScientific but with an exponent that is a multiple of three, and one to three integer
+// digits, e.g. "123.45e+9" or "50e-9".
+func (a UFix64) FormatEngineering() string {
+	return string(appendExponential(nil, fmtValue{lo: uint64(a), decimals: Fix64Decimals}, 3))
+}
+
+// FormatScientific returns the exact value of `a` in scientific notation, see
+// UFix64.FormatScientific.
+func (a Fix64) FormatScientific() string {
+	aUnsigned, sign := a.Abs()
+	return string(appendExponential(nil, fmtValue{neg: sign < 0, lo: uint64(aUnsigned), decimals: Fix64Decimals}, 1))
+}
+
+// FormatEngineering returns the exact value of `a` in engineering notation, see
+// UFix64.FormatEngineering.
+func (a Fix64) FormatEngineering() string {
+	aUnsigned, sign := a.Abs()
+	return string(appendExponential(nil, fmtValue{neg: sign < 0, lo: uint64(aUnsigned), decimals: Fix64Decimals}, 3))
+}
+
+// FormatScientific returns the exact value of `a` in scientific notation, see
+// UFix64.FormatScientific.
+func (a UFix128) FormatScientific() string {
+	return string(appendExponential(nil, fmtValue{hi: uint64(a.Hi), lo: uint64(a.Lo), decimals: Fix128Decimals}, 1))
+}
+
+// FormatEngineering returns the exact value of `a` in engineering notation, see
+// UFix64.FormatEngineering.
+func (a UFix128) FormatEngineering() string {
+	return string(appendExponential(nil, fmtValue{hi: uint64(a.Hi), lo: uint64(a.Lo), decimals: Fix128Decimals}, 3))
+}
+
+// FormatScientific returns the exact value of `a` in scientific notation, see
+// UFix64.FormatScientific.
+func (a Fix128) FormatScientific() string {
+	aUnsigned, sign := a.Abs()
+	return string(appendExponential(nil, fmtValue{neg: sign < 0, hi: uint64(aUnsigned.Hi), lo: uint64(aUnsigned.Lo),
+		decimals: Fix128Decimals}, 1))
+}
+
+// FormatEngineering returns the exact value of `a` in engineering notation, see
+// UFix64.FormatEngineering.
+func (a Fix128) FormatEngineering() string {
+	aUnsigned, sign := a.Abs()
+	return string(appendExponential(nil, fmtValue{neg: sign < 0, hi: uint64(aUnsigned.Hi), lo: uint64(aUnsigned.Lo),
+		decimals: Fix128Decimals}, 3))
+}
+
+// fmtValue is a type-independent description of a fixed-point value for formatValue: its sign and
+// magnitude (hi, lo), its raw two's complement words, and the number of decimals of its type.
+type fmtValue struct {
+	neg          bool
+	hi, lo       uint64
+	rawHi, rawLo uint64
+	decimals     int
+	wide         bool // true for the 128-bit types
+}
+
+func formatValue(f fmt.State, verb rune, typeName string, v fmtValue) {
+	var buf [2 * maxDecimalLen]byte
+	body := buf[:0]
+	prec, hasPrec := f.Precision()
+	if !hasPrec {
+		prec = -1
+	}
+
+	switch verb {
+	case 'v', 's':
+		if verb == 'v' && f.Flag('#') {
+			f.Write(appendGoString(body, typeName, v))
+			return
+		}
+		body = appendDecimal(body, false, v.hi, v.lo, v.decimals, true)
+	case 'f', 'F':
+		if prec < 0 {
+			body = appendDecimal(body, false, v.hi, v.lo, v.decimals, true)
+			break
+		}
+
+		hi, lo := roundDecimal(v.hi, v.lo, v.decimals, prec, RoundNearestHalfEven)
+		body = appendDecimal(body, false, hi, lo, min(prec, v.decimals), false)
+
+		for n := v.decimals; n < prec; n++ {
+			body = append(body, '0')
+		}
+
+		// Don't print a negative zero if the value was rounded to zero.
+		if hi == 0 && lo == 0 {
+			v.neg = false
+		}
+	case 'e', 'E':
+		body = appendScientific(body, v.hi, v.lo, v.decimals, prec, byte(verb))
+	case 'x', 'X':
+		if f.Flag('#') {
+			body = append(body, '0', byte(verb))
+		}
+		if v.wide {
+			body = appendHexWord(body, v.rawHi, verb == 'X')
+		}
+		body = appendHexWord(body, v.rawLo, verb == 'X')
+		v.neg = false
+	default:
+		fmt.Fprintf(f, "%%!%c(fixedPoint.%s=%s)", verb, typeName, appendDecimal(body, v.neg, v.hi, v.lo, v.decimals, true))
+		return
+	}
+
+	var sign []byte
+	switch {
+	case v.neg:
+		sign = []byte{'-'}
+	case verb == 'x' || verb == 'X':
+	case f.Flag('+'):
+		sign = []byte{'+'}
+	case f.Flag(' '):
+		sign = []byte{' '}
+	}
+
+	padding := 0
+	if width, ok := f.Width(); ok {
+		padding = max(width-len(sign)-len(body), 0)
+	}
+
+	switch {
+	case f.Flag('-'):
+		f.Write(sign)
+		f.Write(body)
+		writeRepeated(f, ' ', padding)
+	case f.Flag('0'):
+		f.Write(sign)
+		writeRepeated(f, '0', padding)
+		f.Write(body)
+	default:
+		writeRepeated(f, ' ', padding)
+		f.Write(sign)
+		f.Write(body)
+	}
+}
+
+// appendRounded appends the value rounded to `prec` decimals (with exactly that many fractional
+// digits), or the exact value with trimmed trailing zeros if prec is negative. The sign is omitted
+// if the value rounds to zero.
+func appendRounded(dst []byte, v fmtValue, prec int, round RoundingMode) []byte {
+	if prec < 0 {
+		return appendDecimal(dst, v.neg, v.hi, v.lo, v.decimals, true)
+	}
+
+	sign := int64(1)
+	if v.neg {
+		sign = -1
+	}
+
+	hi, lo := roundDecimal(v.hi, v.lo, v.decimals, prec, round.forSign(sign))
+	dst = appendDecimal(dst, v.neg && (hi != 0 || lo != 0), hi, lo, min(prec, v.decimals), false)
+
+	for n := v.decimals; n < prec; n++ {
+		dst = append(dst, '0')
+	}
+
+	return dst
+}
+
+// formatGrouped implements FormatGrouped.
+func formatGrouped(v fmtValue, sep rune, prec int, round RoundingMode) (string, error) {
+	if !round.isValid() {
+		return "", InvalidRoundingModeError{}
+	}
+
+	return string(appendGrouped(nil, v, sep, prec, round)), nil
+}
+
+// appendGrouped appends the value rounded as for appendRounded, with sep inserted between each
+// group of three integer digits.
+func appendGrouped(dst []byte, v fmtValue, sep rune, prec int, round RoundingMode) []byte {
+	var buf [maxDecimalLen + 1]byte
+	body := appendRounded(buf[:0], v, prec, round)
+
+	if body[0] == '-' {
+		dst = append(dst, '-')
+		body = body[1:]
+	}
+
+	intLen := len(body)
+	for i, c := range body {
+		if c == '.' {
+			intLen = i
+			break
+		}
+	}
+
+	for i := 0; i < intLen; i++ {
+		if i > 0 && (intLen-i)%3 == 0 && sep != 0 {
+			dst = utf8.AppendRune(dst, sep)
+		}
+		dst = append(dst, body[i])
+	}
+
+	return append(dst, body[intLen:]...)
+}
+
+// formatCurrency implements FormatCurrency.
+func formatCurrency(v fmtValue, currency string, prec int, round RoundingMode) (string, error) {
+	if !round.isValid() {
+		return "", InvalidRoundingModeError{}
+	}
+
+	return string(appendCurrency(nil, v, currency, prec, round)), nil
+}
+
+// appendCurrency appends the value as for appendGrouped (with ',' as the separator), with the
+// currency symbol before it, or the currency code after it.
+func appendCurrency(dst []byte, v fmtValue, currency string, prec int, round RoundingMode) []byte {
+	amount := appendGrouped(nil, v, ',', prec, round)
+
+	r, size := utf8.DecodeRuneInString(currency)
+	if size == 0 || size < len(currency) || unicode.IsLetter(r) || unicode.IsDigit(r) {
+		dst = append(dst, amount...)
+		if currency != "" {
+			dst = append(dst, ' ')
+			dst = append(dst, currency...)
+		}
+		return dst
+	}
+
+	if amount[0] == '-' {
+		dst = append(dst, '-')
+		amount = amount[1:]
+	}
+
+	dst = append(dst, currency...)
+	return append(dst, amount...)
+}
+
+// appendGoString appends the Go syntax for the value, with the decimal value in a comment.
+func appendGoString(dst []byte, typeName string, v fmtValue) []byte {
+	dst = append(dst, "fixedPoint."...)
+	dst = append(dst, typeName...)
+
+	if v.wide {
+		dst = append(dst, "{Hi: 0x"...)
+		dst = appendHexWord(dst, v.rawHi, false)
+		dst = append(dst, ", Lo: 0x"...)
+		dst = appendHexWord(dst, v.rawLo, false)
+		dst = append(dst, '}')
+	} else {
+		dst = append(dst, "(0x"...)
+		dst = appendHexWord(dst, v.rawLo, false)
+		dst = append(dst, ')')
+	}
+
+	dst = append(dst, " /* "...)
+	dst = appendDecimal(dst, v.neg, v.hi, v.lo, v.decimals, true)
+	return append(dst, " */"...)
+}
+
+func writeRepeated(f fmt.State, c byte, n int) {
+	for ; n > 0; n-- {
+		f.Write([]byte{c})
+	}
+}
+
+// appendScientific appends the magnitude (hi, lo), which has the given number of decimals, in
+// scientific notation with the given exponent character ('e' or 'E'). If prec is negative, the
+// mantissa has as many digits as needed to represent the value exactly; otherwise it's rounded
+// to `prec` decimals, with ties rounded to even.
+func appendScientific(dst []byte, hi, lo uint64, decimals, prec int, e byte) []byte {
+	var buf [maxDigits]byte
+	digits := buf[putDigits(&buf, hi, lo):]
+
+	exp := 0
+	if hi != 0 || lo != 0 {
+		exp = len(digits) - 1 - decimals
+	}
+
+	if prec >= 0 && prec < len(digits)-1 {
+		// Round off the extra digits. If that carries into a new digit (e.g. 9.99 -> 10.0), the
+		// exponent goes up by one, and the last digit (which must be a zero) is dropped.
+		n := len(digits)
+		hi, lo = roundDecimal(hi, lo, n-1-prec, 0, RoundNearestHalfEven)
+		digits = buf[putDigits(&buf, hi, lo):]
+
+		if len(digits) > prec+1 {
+			exp++
+			digits = digits[:prec+1]
+		}
+	}
+
+	dst = append(dst, digits[0])
+	fraction := digits[1:]
+
+	if prec < 0 {
+		for len(fraction) > 0 && fraction[len(fraction)-1] == '0' {
+			fraction = fraction[:len(fraction)-1]
+		}
+	}
+
+	if len(fraction) > 0 || prec > 0 {
+		dst = append(dst, '.')
+		dst = append(dst, fraction...)
+
+		for n := len(fraction); n < prec; n++ {
+			dst = append(dst, '0')
+		}
+	}
+
+	dst = append(dst, e)
+	if exp < 0 {
+		dst = append(dst, '-')
+		exp = -exp
+	} else {
+		dst = append(dst, '+')
+	}
+
+	if exp < 10 {
+		dst = append(dst, '0')
+	}
+
+	return strconv.AppendInt(dst, int64(exp), 10)
+}
+
+// appendExponential appends the exact value with an exponent that is a multiple of `step` (1 for
+// scientific notation, 3 for engineering notation), and as few mantissa digits as possible.
+func appendExponential(dst []byte, v fmtValue, step int) []byte {
+	if v.hi == 0 && v.lo == 0 {
+		return append(dst, "0e+0"...)
+	}
+
+	var buf [maxDigits]byte
+	digits := buf[putDigits(&buf, v.hi, v.lo):]
+
+	// Round the exponent of the leading digit down to a multiple of step (towards negative
+	// infinity, so the integer part is never empty).
+	exp := len(digits) - 1 - v.decimals
+	intDigits := (exp%step+step)%step + 1
+	exp -= intDigits - 1
+
+	for len(digits) > intDigits && digits[len(digits)-1] == '0' {
+		digits = digits[:len(digits)-1]
+	}
+
+	if v.neg {
+		dst = append(dst, '-')
+	}
+
+	if len(digits) > intDigits {
+		dst = append(dst, digits[:intDigits]...)
+		dst = append(dst, '.')
+		dst = append(dst, digits[intDigits:]...)
+	} else {
+		dst = append(dst, digits...)
+		for n := len(digits); n < intDigits; n++ {
+			dst = append(dst, '0')
+		}
+	}
+
+	dst = append(dst, 'e')
+	if exp >= 0 {
+		dst = append(dst, '+')
+	}
+
+	return strconv.AppendInt(dst, int64(exp), 10)
+}
+
+// appendHexWord appends a 64-bit word as 16 hexadecimal digits.
+func appendHexWord(dst []byte, w uint64, upper bool) []byte {
+	digits := "0123456789abcdef"
+	if upper {
+		digits = "0123456789ABCDEF"
+	}
+
+	for shift := 60; shift >= 0; shift -= 4 {
+		dst = append(dst, digits[(w>>shift)&0xf])
+	}
+
+	return dst
+}
+
+// maxDecimalLen is the longest string appendDecimal can produce for any of the types: a sign, the
+// 39 digits of the largest 128-bit value, and a decimal point.
+const maxDecimalLen = 1 + maxDigits + 1
+
+// maxDigits is the number of decimal digits in the largest 128-bit value.
+const maxDigits = 39
+
+// putDigits writes the decimal digits of the 128-bit value (hi, lo) to the end of buf, and returns
+// the index of the first digit. Zero is written as a single '0'.
+func putDigits(buf *[maxDigits]byte, hi, lo uint64) int {
+	i := len(buf)
+
+	// Produce the digits from least to most significant, dividing the (hi, lo) by 10 each time.
+	for {
+		var r uint64
+		hi, r = bits.Div64(0, hi, 10)
+		lo, r = bits.Div64(r, lo, 10)
+
+		i--
+		buf[i] = '0' + byte(r)
+
+		if hi == 0 && lo == 0 {
+			return i
+		}
+	}
+}
+
+// maxDigits256 is the number of decimal digits in the largest 256-bit value, and maxDecimalLen256
+// is the longest string appendDecimal256 can produce.
+const (
+	maxDigits256     = 78
+	maxDecimalLen256 = 1 + maxDigits256 + 1
+)
+
+// putDigits256 is like putDigits, for the 256-bit value `a`.
+func putDigits256(buf *[maxDigits256]byte, a raw256) int {
+	i := len(buf)
+
+	// Produce the digits in chunks of 19, the most that fit in a uint64, so that the 256-bit
+	// division is only needed once per chunk.
+	chunk := raw256{Lo: raw128{0, 1e19}}
+	for {
+		var rem raw256
+		a, rem = div256(raw256Zero, a, chunk)
+
+		r := uint64(rem.Lo.Lo)
+		for n := 0; n < 19 && (r != 0 || !isZero256(a)); n++ {
+			i--
+			buf[i] = '0' + byte(r%10)
+			r /= 10
+		}
+
+		if isZero256(a) {
+			if i == len(buf) {
+				i--
+				buf[i] = '0'
+			}
+			return i
+		}
+	}
+}
+
+// appendDecimal appends the decimal representation of the 128-bit magnitude (hi, lo), divided by
+// 10^decimals, to dst. There is always at least one integer digit, so values less than one have a
+// leading zero. If trim is true, trailing zeros in the fraction are removed, except for the first
+// one; otherwise, there are always exactly `decimals` fractional digits.
+func appendDecimal(dst []byte, neg bool, hi, lo uint64, decimals int, trim bool) []byte {
+	var buf [maxDigits]byte
+	return appendDigits(dst, neg, buf[putDigits(&buf, hi, lo):], decimals, trim)
+}
+
+// appendDecimal256 is like appendDecimal with trim, for the 256-bit magnitude `a`.
+func appendDecimal256(dst []byte, neg bool, a raw256, decimals int) []byte {
+	var buf [maxDigits256]byte
+	return appendDigits(dst, neg, buf[putDigits256(&buf, a):], decimals, true)
+}
+
+// appendDigits implements appendDecimal, for the decimal digits of the magnitude.
+func appendDigits(dst []byte, neg bool, digits []byte, decimals int, trim bool) []byte {
+	if neg {
+		dst = append(dst, '-')
+	}
+
+	if len(digits) > decimals {
+		dst = append(dst, digits[:len(digits)-decimals]...)
+		digits = digits[len(digits)-decimals:]
+	} else {
+		dst = append(dst, '0')
+	}
+
+	if decimals == 0 {
+		return dst
+	}
+
+	zeros := decimals - len(digits)
+
+	if trim {
+		for len(digits) > 0 && digits[len(digits)-1] == '0' {
+			digits = digits[:len(digits)-1]
+		}
+
+		if len(digits) == 0 {
+			return append(dst, '.', '0')
+		}
+	}
+
+	dst = append(dst, '.')
+	for ; zeros > 0; zeros-- {
+		dst = append(dst, '0')
+	}
+
+	return append(dst, digits...)
+}
+
+// appendFixed appends the exact decimal representation of the 128-bit magnitude (hi, lo), divided
+// by 10^decimals, with at least minDecimals fractional digits.
+func appendFixed(dst []byte, neg bool, hi, lo uint64, decimals, minDecimals int) []byte {
+	dst = appendDecimal(dst, neg, hi, lo, decimals, false)
+
+	// appendDecimal produced exactly `decimals` fractional digits, so trim (or pad) from there.
+	point := len(dst) - decimals - 1
+	end := len(dst)
+	for end > point+1+max(minDecimals, 0) && dst[end-1] == '0' {
+		end--
+	}
+	if end == point+1 {
+		end = point
+	}
+	dst = dst[:end]
+
+	for n := decimals; n < minDecimals; n++ {
+		dst = append(dst, '0')
+	}
+
+	return dst
+}
+
+// roundDecimal rounds the 128-bit magnitude (hi, lo), which has the given number of decimals, to
+// `prec` decimals, using the given rounding mode. The result is scaled by 10^prec. If prec is at
+// least the number of decimals, the magnitude is returned unchanged. The rounding mode must have
+// been validated by the caller.
+func roundDecimal(hi, lo uint64, decimals, prec int, round RoundingMode) (uint64, uint64) {
+	if !round.isValid() {
+		// Unreachable, the public entry points reject invalid rounding modes. If one gets through,
+		// the result is truncated.
+		debugPanic("roundDecimal: invalid rounding mode")
+	}
+
+	if prec >= decimals || (hi == 0 && lo == 0) {
+		return hi, lo
+	}
+
+	// The quotient is at most 2^128/10, so rounding it up can't overflow.
+	scale := pow10Table128[decimals-prec]
+	quo, rem := div128(raw128Zero, raw128{raw64(hi), raw64(lo)}, scale)
+	if ushouldRound128(quo, rem, scale, round) {
+		quo, _ = add128(quo, raw128Zero, 1)
+	}
+
+	return uint64(quo.Hi), uint64(quo.Lo)
+}
@@ -25,15 +25,23 @@ import re
 
 # List of [from, to] pairs for replacement (word-delimited)
 replacements = [
+    [r"accum64", "accum128",],
     [r"add64", "add128",],
+    [r"AddFix64", "AddFix128",],
+    [r"AddUFix64", "AddUFix128",],
     [r"div64", "div128",],
+    [r"DivFix64", "DivFix128",],
+    [r"DivUFix64", "DivUFix128",],
     [r"Fix64", "Fix128",],
+    [r"Fix64Iota", "Fix128Iota",],
     [r"Fix64Max", "Fix128Max",],
     [r"Fix64Min", "Fix128Min",],
     [r"Fix64One", "Fix128One",],
     [r"Fix64OneLeadingZeros", "Fix128OneLeadingZeros",],
     [r"Fix64Scale", "Fix128Scale",],
     [r"Fix64Zero", "Fix128Zero",],
+    [r"FMDFix64", "FMDFix128",],
+    [r"FMDUFix64", "FMDUFix128",],
     [r"isEqual64", "isEqual128",],
     [r"isIota64", "isIota128",],
     [r"isNeg64", "isNeg128",],
@@ -44,7 +52,11 @@ replacements = [
     [r"maxLn64", "maxLn128",],
     [r"mod64", "mod128",],
     [r"mul64", "mul128",],
+    [r"MulFix64", "MulFix128",],
+    [r"MulUFix64", "MulUFix128",],
     [r"neg64", "neg128",],
+    [r"pow10Raw64", "pow10Raw128",],
+    [r"pow10Table64", "pow10Table128",],
     [r"raw64", "raw128",],
     [r"raw64Zero", "raw128Zero",],
     [r"result192ToFix64", "result192ToFix128",],
@@ -52,12 +64,22 @@ replacements = [
     [r"slt64", "slt128",],
     [r"sshiftRight64", "sshiftRight128",],
     [r"sub64", "sub128",],
+    [r"SubFix64", "SubFix128",],
+    [r"SubUFix64", "SubUFix128",],
+    [r"SumOfProductsFix64", "SumOfProductsFix128",],
+    [r"SumOfProductsUFix64", "SumOfProductsUFix128",],
     [r"toFix64", "toFix128",],
+    [r"ToFix64", "ToFix128",],
     [r"toUFix64", "toUFix128",],
+    [r"ToUFix64", "ToUFix128",],
     [r"trigResult64", "trigResult128",],
     [r"UFix64", "UFix128",],
+    [r"UFix64Iota", "UFix128Iota",],
+    [r"UFix64Max", "UFix128Max",],
     [r"UFix64One", "UFix128One",],
     [r"UFix64Zero", "UFix128Zero",],
+    [r"udivRound64", "udivRound128",],
+    [r"udivRoundCode64", "udivRoundCode128",],
     [r"ult64", "ult128",],
     [r"ushiftRight64", "ushiftRight128",],
     [r"ushouldRound64", "ushouldRound128",],
@@ -368,9 +368,12 @@ def main():
     print("const UFix64One = UFix64(1 * Fix64Scale) // 1 in fix64")
     print("const Fix64One = Fix64(1 * Fix64Scale) // 1 in fix64")
     print(f"const Fix64OneLeadingZeros = {64 - int(Fix64Scale).bit_length()} // Number of leading zero bits for Fix64One")    
+    print(f"const Fix64Decimals = {int(Fix64Scale.log10())} // Number of decimal places for Fix64 and UFix64")
     print("const UFix64Max = UFix64(0xffffffffffffffff) // Max value for UFix64")
     print("const Fix64Max = Fix64(0x7fffffffffffffff) // Max value for Fix64")
     print("const Fix64Min = Fix64(0x8000000000000000) // Min value for Fix64")
+    print("const UFix64Iota = UFix64(1) // Smallest positive value for UFix64 (1e-8)")
+    print("const Fix64Iota = Fix64(1) // Smallest positive value for Fix64 (1e-8)")
     print()
     print("// Basic constants for Fix128 and UFix128")
     print(f"const Fix128Scale = {Fix128Scale} // NOTE: Bigger than uint64! Mostly here as documentation...")
@@ -379,9 +382,12 @@ def main():
     print(go_const('UFix128One', Decimal(1), 'UFix128'))
     print(go_const('Fix128One', Decimal(1), 'Fix128'))
     print(f"const Fix128OneLeadingZeros = {128 - int(Fix128Scale).bit_length()} // Number of leading zero bits for Fix128One")    
+    print(f"const Fix128Decimals = {int(Fix128Scale.log10())} // Number of decimal places for Fix128 and UFix128")
     print(go_const('UFix128Max', UFix128Max, 'UFix128'))
     print(go_const('Fix128Max', Fix128Max, 'Fix128'))
     print(go_const('Fix128Min', Fix128Min, 'Fix128'))
+    print(go_const('UFix128Iota', fix128Epsilon, 'UFix128'))
+    print(go_const('Fix128Iota', fix128Epsilon, 'Fix128'))
     print()
     print("// Transcendental constants")
     print(go_const('Fix64Pi', pi, 'Fix64'))
@@ -255,7 +255,7 @@ def main():
             # The Decimal library treats 0^x differently than we want to, so we override
             # some of its behavior here
             if values[1] < 0:
-                err = "DivByZero"
+                err = "DomainError"
                 result = Decimal(0)
             elif values[1] == 0:
                 err = None
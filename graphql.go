@@ -0,0 +1,89 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// This file contains the GraphQL support for the fixed-point types, following the gqlgen
+// conventions for custom scalars (the Marshaler and Unmarshaler interfaces), so the types can be
+// bound to a scalar directly, e.g.:
+//
+//	scalar UFix64
+//
+//	models:
+//	  UFix64:
+//	    model: github.com/onflow/fixed-point.UFix64
+
+// MarshalGQL writes `a` to `w` as a GraphQL value, in the same form as MarshalJSON (a string,
+// unless DefaultJSONFormat is set to JSONNumber).
+func (a UFix64) MarshalGQL(w io.Writer) { w.Write(a.AppendJSON(nil, DefaultJSONFormat)) }
+
+// MarshalGQL writes `a` to `w` as a GraphQL value, see UFix64.MarshalGQL.
+func (a Fix64) MarshalGQL(w io.Writer) { w.Write(a.AppendJSON(nil, DefaultJSONFormat)) }
+
+// MarshalGQL writes `a` to `w` as a GraphQL value, see UFix64.MarshalGQL.
+func (a UFix128) MarshalGQL(w io.Writer) { w.Write(a.AppendJSON(nil, DefaultJSONFormat)) }
+
+// MarshalGQL writes `a` to `w` as a GraphQL value, see UFix64.MarshalGQL.
+func (a Fix128) MarshalGQL(w io.Writer) { w.Write(a.AppendJSON(nil, DefaultJSONFormat)) }
+
+// UnmarshalGQL sets `a` from a GraphQL input value. It accepts strings in the decimal form,
+// integers and json.Number (as produced when decoding variables with UseNumber). Floats are
+// rejected, since they may have already lost precision, as are values that can't be represented
+// exactly.
+func (a *UFix64) UnmarshalGQL(v any) error { return unmarshalGQL(a, v, textParser.ParseUFix64) }
+
+// UnmarshalGQL sets `a` from a GraphQL input value, see UFix64.UnmarshalGQL.
+func (a *Fix64) UnmarshalGQL(v any) error { return unmarshalGQL(a, v, textParser.ParseFix64) }
+
+// UnmarshalGQL sets `a` from a GraphQL input value, see UFix64.UnmarshalGQL.
+func (a *UFix128) UnmarshalGQL(v any) error { return unmarshalGQL(a, v, textParser.ParseUFix128) }
+
+// UnmarshalGQL sets `a` from a GraphQL input value, see UFix64.UnmarshalGQL.
+func (a *Fix128) UnmarshalGQL(v any) error { return unmarshalGQL(a, v, textParser.ParseFix128) }
+
+func unmarshalGQL[T any](dst *T, v any, parse func(string) (T, error)) error {
+	var s string
+
+	switch v := v.(type) {
+	case string:
+		s = v
+	case json.Number:
+		s = string(v)
+	case int:
+		s = strconv.Itoa(v)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	case uint64:
+		s = strconv.FormatUint(v, 10)
+	default:
+		return fmt.Errorf("cannot unmarshal %T into %T: %w", v, *dst, InvalidEncodingError{})
+	}
+
+	res, err := parse(s)
+	if err != nil {
+		return err
+	}
+
+	*dst = res
+	return nil
+}
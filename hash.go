@@ -0,0 +1,96 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"hash"
+	"io"
+)
+
+// This file contains the canonical hashing layout of the fixed-point types, so that hashes over
+// values (e.g. in state commitments and event hashes) are computed identically everywhere. A value
+// is hashed as a one-byte type tag followed by its canonical big-endian binary encoding (see
+// MarshalBinary), so values of different types never hash the same, even if their raw values are
+// equal.
+
+// Type tags of the canonical hashing layout. These are part of the format, and will never change.
+const (
+	HashTagUFix64  = 0x01
+	HashTagFix64   = 0x02
+	HashTagUFix128 = 0x03
+	HashTagFix128  = 0x04
+)
+
+var (
+	_ io.WriterTo = UFix64Zero
+	_ io.WriterTo = Fix64Zero
+	_ io.WriterTo = UFix128Zero
+	_ io.WriterTo = Fix128Zero
+)
+
+// WriteTo implements io.WriterTo, writing the canonical hashing layout of `a` to `w`: the type tag
+// followed by the 8-byte big-endian raw value.
+func (a UFix64) WriteTo(w io.Writer) (int64, error) { return writeBytes(w, a.AppendHash(nil)) }
+
+// WriteTo implements io.WriterTo, see UFix64.WriteTo.
+func (a Fix64) WriteTo(w io.Writer) (int64, error) { return writeBytes(w, a.AppendHash(nil)) }
+
+// WriteTo implements io.WriterTo, writing the type tag followed by the 16-byte big-endian raw
+// value.
+func (a UFix128) WriteTo(w io.Writer) (int64, error) { return writeBytes(w, a.AppendHash(nil)) }
+
+// WriteTo implements io.WriterTo, see UFix128.WriteTo.
+func (a Fix128) WriteTo(w io.Writer) (int64, error) { return writeBytes(w, a.AppendHash(nil)) }
+
+// HashInto writes the canonical hashing layout of `a` into h. Unlike WriteTo, it doesn't return
+// an error, since writing to a hash.Hash never fails.
+func (a UFix64) HashInto(h hash.Hash) { h.Write(a.AppendHash(nil)) }
+
+// HashInto writes the canonical hashing layout of `a` into h, see UFix64.HashInto.
+func (a Fix64) HashInto(h hash.Hash) { h.Write(a.AppendHash(nil)) }
+
+// HashInto writes the canonical hashing layout of `a` into h, see UFix64.HashInto.
+func (a UFix128) HashInto(h hash.Hash) { h.Write(a.AppendHash(nil)) }
+
+// HashInto writes the canonical hashing layout of `a` into h, see UFix64.HashInto.
+func (a Fix128) HashInto(h hash.Hash) { h.Write(a.AppendHash(nil)) }
+
+// AppendHash appends the canonical hashing layout of `a` to dst, and returns the extended buffer,
+// for building a larger pre-image before hashing it.
+func (a UFix64) AppendHash(dst []byte) []byte {
+	return BigEndian.AppendUFix64(append(dst, HashTagUFix64), a)
+}
+
+// AppendHash appends the canonical hashing layout of `a` to dst, see UFix64.AppendHash.
+func (a Fix64) AppendHash(dst []byte) []byte {
+	return BigEndian.AppendFix64(append(dst, HashTagFix64), a)
+}
+
+// AppendHash appends the canonical hashing layout of `a` to dst, see UFix64.AppendHash.
+func (a UFix128) AppendHash(dst []byte) []byte {
+	return BigEndian.AppendUFix128(append(dst, HashTagUFix128), a)
+}
+
+// AppendHash appends the canonical hashing layout of `a` to dst, see UFix64.AppendHash.
+func (a Fix128) AppendHash(dst []byte) []byte {
+	return BigEndian.AppendFix128(append(dst, HashTagFix128), a)
+}
+
+func writeBytes(w io.Writer, data []byte) (int64, error) {
+	n, err := w.Write(data)
+	return int64(n), err
+}
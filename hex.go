@@ -0,0 +1,131 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+// This file contains the raw hexadecimal form of the fixed-point types: "0x" followed by the raw
+// two's complement value as a fixed number of hex digits (16 for the 64-bit types, and 32 for the
+// 128-bit types), e.g. "0x0000000005f5e100" for UFix64One. It's intended for debugging, and for
+// storage systems that want a fixed-width text form without a decimal conversion.
+
+// ToHex returns the raw value of `a` in hexadecimal, e.g. "0x0000000005f5e100" for 1.0.
+func (a UFix64) ToHex() string { return string(appendHex(nil, 0, uint64(a), false)) }
+
+// ToHex returns the raw two's complement value of `a` in hexadecimal, e.g. "0xfffffffffa0a1f00"
+// for -1.0.
+func (a Fix64) ToHex() string { return string(appendHex(nil, 0, uint64(a), false)) }
+
+// ToHex returns the raw value of `a` in hexadecimal, with 32 digits.
+func (a UFix128) ToHex() string { return string(appendHex(nil, uint64(a.Hi), uint64(a.Lo), true)) }
+
+// ToHex returns the raw two's complement value of `a` in hexadecimal, with 32 digits.
+func (a Fix128) ToHex() string { return string(appendHex(nil, uint64(a.Hi), uint64(a.Lo), true)) }
+
+// UFix64FromHex parses the raw hexadecimal form produced by ToHex. The "0x" (or "0X") prefix is
+// optional, and both upper and lower case digits are accepted, but there must be exactly 16
+// digits; anything else returns a SyntaxError.
+func UFix64FromHex(s string) (UFix64, error) {
+	_, lo, ok := parseHex(s, false)
+	if !ok {
+		return UFix64Zero, SyntaxError{}
+	}
+
+	return UFix64(lo), nil
+}
+
+// Fix64FromHex parses the raw hexadecimal form produced by ToHex, see UFix64FromHex.
+func Fix64FromHex(s string) (Fix64, error) {
+	_, lo, ok := parseHex(s, false)
+	if !ok {
+		return Fix64Zero, SyntaxError{}
+	}
+
+	return Fix64(lo), nil
+}
+
+// UFix128FromHex parses the raw hexadecimal form produced by ToHex, which must have exactly 32
+// digits, see UFix64FromHex.
+func UFix128FromHex(s string) (UFix128, error) {
+	hi, lo, ok := parseHex(s, true)
+	if !ok {
+		return UFix128Zero, SyntaxError{}
+	}
+
+	return UFix128FromRaw(hi, lo), nil
+}
+
+// Fix128FromHex parses the raw hexadecimal form produced by ToHex, see UFix128FromHex.
+func Fix128FromHex(s string) (Fix128, error) {
+	hi, lo, ok := parseHex(s, true)
+	if !ok {
+		return Fix128Zero, SyntaxError{}
+	}
+
+	return Fix128FromRaw(hi, lo), nil
+}
+
+func appendHex(dst []byte, hi, lo uint64, wide bool) []byte {
+	dst = append(dst, '0', 'x')
+	if wide {
+		dst = appendHexWord(dst, hi, false)
+	}
+
+	return appendHexWord(dst, lo, false)
+}
+
+// parseHex parses 16 hex digits (or 32, if wide is true) with an optional 0x prefix.
+func parseHex(s string, wide bool) (hi, lo uint64, ok bool) {
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		s = s[2:]
+	}
+
+	if wide {
+		if len(s) != 32 {
+			return 0, 0, false
+		}
+		if hi, ok = parseHexWord(s[:16]); !ok {
+			return 0, 0, false
+		}
+		s = s[16:]
+	}
+
+	if len(s) != 16 {
+		return 0, 0, false
+	}
+	lo, ok = parseHexWord(s)
+	return hi, lo, ok
+}
+
+// parseHexWord parses exactly 16 hex digits.
+func parseHexWord(s string) (uint64, bool) {
+	var w uint64
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c -= 'a' - 10
+		case c >= 'A' && c <= 'F':
+			c -= 'A' - 10
+		default:
+			return 0, false
+		}
+		w = w<<4 | uint64(c)
+	}
+
+	return w, true
+}
@@ -0,0 +1,294 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+// This file contains the conversions between the fixed-point types and whole numbers of units.
+// Use these instead of scaling integers by hand (e.g. UFix64(n * Fix64Scale)), which silently
+// wraps around for large values.
+
+// UFix64FromUint64 returns n units as a UFix64, or a PositiveOverflowError if n is larger than
+// the maximum value of the type.
+func UFix64FromUint64(n uint64) (UFix64, error) {
+	return fromInt(n, 1, Fix64Decimals, ufix64FromMagnitude)
+}
+
+// UFix64FromInt64 returns n units as a UFix64, see UFix64FromUint64. Negative values return a
+// NegativeOverflowError.
+func UFix64FromInt64(n int64) (UFix64, error) {
+	mag, sign := absInt64(n)
+	return fromInt(mag, sign, Fix64Decimals, ufix64FromMagnitude)
+}
+
+// Fix64FromUint64 returns n units as a Fix64, see UFix64FromUint64.
+func Fix64FromUint64(n uint64) (Fix64, error) {
+	return fromInt(n, 1, Fix64Decimals, fix64FromMagnitude)
+}
+
+// Fix64FromInt64 returns n units as a Fix64, or an overflow error if n is outside of the range of
+// the type.
+func Fix64FromInt64(n int64) (Fix64, error) {
+	mag, sign := absInt64(n)
+	return fromInt(mag, sign, Fix64Decimals, fix64FromMagnitude)
+}
+
+// UFix128FromUint64 returns n units as a UFix128, see UFix64FromUint64.
+func UFix128FromUint64(n uint64) (UFix128, error) {
+	return fromInt(n, 1, Fix128Decimals, ufix128FromMagnitude)
+}
+
+// UFix128FromInt64 returns n units as a UFix128, see UFix64FromInt64.
+func UFix128FromInt64(n int64) (UFix128, error) {
+	mag, sign := absInt64(n)
+	return fromInt(mag, sign, Fix128Decimals, ufix128FromMagnitude)
+}
+
+// Fix128FromUint64 returns n units as a Fix128, see UFix64FromUint64.
+func Fix128FromUint64(n uint64) (Fix128, error) {
+	return fromInt(n, 1, Fix128Decimals, fix128FromMagnitude)
+}
+
+// Fix128FromInt64 returns n units as a Fix128, see Fix64FromInt64.
+func Fix128FromInt64(n int64) (Fix128, error) {
+	mag, sign := absInt64(n)
+	return fromInt(mag, sign, Fix128Decimals, fix128FromMagnitude)
+}
+
+// NewUFix64FromParts returns the UFix64 with the given integer part, and a fractional part of
+// fracPart / 10^fracDigits, e.g. NewUFix64FromParts(12, 5, 2) is 12.05. It returns a SyntaxError if
+// fracPart has more than fracDigits digits, an InexactError if the fractional part has more
+// non-zero decimals than the type supports, and a PositiveOverflowError if the value is too large.
+func NewUFix64FromParts(intPart, fracPart uint64, fracDigits uint8) (UFix64, error) {
+	return fromParts(intPart, 1, fracPart, fracDigits, Fix64Decimals, ufix64FromMagnitude)
+}
+
+// NewFix64FromParts returns the Fix64 with the given integer part, and a fractional part of
+// fracPart / 10^fracDigits, see NewUFix64FromParts. The fractional part has the same sign as the
+// integer part, e.g. NewFix64FromParts(-12, 5, 2) is -12.05. (Values between -1 and 0 have an
+// integer part of zero, so they must be negated afterwards.)
+func NewFix64FromParts(intPart int64, fracPart uint64, fracDigits uint8) (Fix64, error) {
+	mag, sign := absInt64(intPart)
+	return fromParts(mag, sign, fracPart, fracDigits, Fix64Decimals, fix64FromMagnitude)
+}
+
+// NewUFix128FromParts returns the UFix128 with the given integer and fractional parts, see
+// NewUFix64FromParts.
+func NewUFix128FromParts(intPart, fracPart uint64, fracDigits uint8) (UFix128, error) {
+	return fromParts(intPart, 1, fracPart, fracDigits, Fix128Decimals, ufix128FromMagnitude)
+}
+
+// NewFix128FromParts returns the Fix128 with the given integer and fractional parts, see
+// NewFix64FromParts.
+func NewFix128FromParts(intPart int64, fracPart uint64, fracDigits uint8) (Fix128, error) {
+	mag, sign := absInt64(intPart)
+	return fromParts(mag, sign, fracPart, fracDigits, Fix128Decimals, fix128FromMagnitude)
+}
+
+// UFix64FromMantExp returns the UFix64 with the value mant * 10^exp, which is how many external
+// decimal formats encode their values. The conversion is exact: it returns an InexactError if the
+// value has more than 8 decimals, and a PositiveOverflowError if it is too large.
+func UFix64FromMantExp(mant uint64, exp int32) (UFix64, error) {
+	return fromMantExp(mant, 1, exp, Fix64Decimals, ufix64FromMagnitude)
+}
+
+// Fix64FromMantExp returns the Fix64 with the value mant * 10^exp, see UFix64FromMantExp.
+func Fix64FromMantExp(mant int64, exp int32) (Fix64, error) {
+	mag, sign := absInt64(mant)
+	return fromMantExp(mag, sign, exp, Fix64Decimals, fix64FromMagnitude)
+}
+
+// UFix128FromMantExp returns the UFix128 with the value mant * 10^exp, see UFix64FromMantExp.
+func UFix128FromMantExp(mant uint64, exp int32) (UFix128, error) {
+	return fromMantExp(mant, 1, exp, Fix128Decimals, ufix128FromMagnitude)
+}
+
+// Fix128FromMantExp returns the Fix128 with the value mant * 10^exp, see UFix64FromMantExp.
+func Fix128FromMantExp(mant int64, exp int32) (Fix128, error) {
+	mag, sign := absInt64(mant)
+	return fromMantExp(mag, sign, exp, Fix128Decimals, fix128FromMagnitude)
+}
+
+// ToUint64 returns `a` rounded to a whole number of units, as specified. None of the types can
+// hold more than 2^63 units, so the result always fits; the only errors are an
+// InvalidRoundingModeError, and a NegativeOverflowError for negative results of the signed types.
+func (a UFix64) ToUint64(round RoundingMode) (uint64, error) {
+	return toUint64(0, uint64(a), 1, Fix64Decimals, round)
+}
+
+// ToInt64 returns `a` rounded to a whole number of units, as specified, see UFix64.ToUint64.
+func (a UFix64) ToInt64(round RoundingMode) (int64, error) {
+	return toInt64(0, uint64(a), 1, Fix64Decimals, round)
+}
+
+// ToUint64 returns `a` rounded to a whole number of units, see UFix64.ToUint64. Negative values
+// that don't round to zero return a NegativeOverflowError.
+func (a Fix64) ToUint64(round RoundingMode) (uint64, error) {
+	aUnsigned, sign := a.Abs()
+	return toUint64(0, uint64(aUnsigned), sign, Fix64Decimals, round)
+}
+
+// ToInt64 returns `a` rounded to a whole number of units, see UFix64.ToUint64.
+func (a Fix64) ToInt64(round RoundingMode) (int64, error) {
+	aUnsigned, sign := a.Abs()
+	return toInt64(0, uint64(aUnsigned), sign, Fix64Decimals, round)
+}
+
+// ToUint64 returns `a` rounded to a whole number of units, see UFix64.ToUint64.
+func (a UFix128) ToUint64(round RoundingMode) (uint64, error) {
+	return toUint64(uint64(a.Hi), uint64(a.Lo), 1, Fix128Decimals, round)
+}
+
+// ToInt64 returns `a` rounded to a whole number of units, see UFix64.ToUint64.
+func (a UFix128) ToInt64(round RoundingMode) (int64, error) {
+	return toInt64(uint64(a.Hi), uint64(a.Lo), 1, Fix128Decimals, round)
+}
+
+// ToUint64 returns `a` rounded to a whole number of units, see Fix64.ToUint64.
+func (a Fix128) ToUint64(round RoundingMode) (uint64, error) {
+	aUnsigned, sign := a.Abs()
+	return toUint64(uint64(aUnsigned.Hi), uint64(aUnsigned.Lo), sign, Fix128Decimals, round)
+}
+
+// ToInt64 returns `a` rounded to a whole number of units, see UFix64.ToUint64.
+func (a Fix128) ToInt64(round RoundingMode) (int64, error) {
+	aUnsigned, sign := a.Abs()
+	return toInt64(uint64(aUnsigned.Hi), uint64(aUnsigned.Lo), sign, Fix128Decimals, round)
+}
+
+func absInt64(n int64) (uint64, int64) {
+	if n < 0 {
+		// Negating as an unsigned value also handles math.MinInt64.
+		return -uint64(n), -1
+	}
+
+	return uint64(n), 1
+}
+
+// fromInt scales the magnitude n by 10^decimals, and converts it using fromMagnitude.
+func fromInt[T any](n uint64, sign int64, decimals int,
+	fromMagnitude func(hi, lo uint64, sign int64) (T, error)) (T, error) {
+	over, hi, lo := mul128By64(pow10Table128[decimals], raw64(n))
+	if over != 0 {
+		var zero T
+		return zero, applySign(PositiveOverflowError{}, sign)
+	}
+
+	return fromMagnitude(uint64(hi), uint64(lo), sign)
+}
+
+// fromParts combines the magnitude of the integer part with the fractional part (fracPart /
+// 10^fracDigits), scaled by 10^decimals, and converts it using fromMagnitude.
+func fromParts[T any](intPart uint64, sign int64, fracPart uint64, fracDigits uint8, decimals int,
+	fromMagnitude func(hi, lo uint64, sign int64) (T, error)) (T, error) {
+	var zero T
+
+	// Every uint64 is less than 10^20, so only fewer digits need checking.
+	digits := int(fracDigits)
+	if digits < len(pow10Table64) && fracPart >= uint64(pow10Table64[digits]) {
+		return zero, SyntaxError{}
+	}
+
+	// Decimals beyond the precision of the type are only allowed if they are zero.
+	if digits > decimals {
+		if excess := digits - decimals; excess < len(pow10Table64) {
+			if fracPart%uint64(pow10Table64[excess]) != 0 {
+				return zero, InexactError{}
+			}
+			fracPart /= uint64(pow10Table64[excess])
+		} else if fracPart != 0 {
+			return zero, InexactError{}
+		}
+		digits = decimals
+	}
+
+	// The fractional part is less than 10^decimals, so it can't overflow.
+	_, fracHi, fracLo := mul128By64(pow10Table128[decimals-digits], raw64(fracPart))
+	over, hi, lo := mul128By64(pow10Table128[decimals], raw64(intPart))
+	mag, carry := add128(raw128{hi, lo}, raw128{fracHi, fracLo}, 0)
+
+	if over != 0 || carry != 0 {
+		return zero, applySign(PositiveOverflowError{}, sign)
+	}
+
+	return fromMagnitude(uint64(mag.Hi), uint64(mag.Lo), sign)
+}
+
+// fromMantExp scales the magnitude `mant` by 10^(exp+decimals) to get the raw value, and converts
+// it using fromMagnitude.
+func fromMantExp[T any](mant uint64, sign int64, exp int32, decimals int,
+	fromMagnitude func(hi, lo uint64, sign int64) (T, error)) (T, error) {
+	var zero T
+
+	if mant == 0 {
+		return zero, nil
+	}
+
+	shift := int64(exp) + int64(decimals)
+
+	if shift < 0 {
+		// Every uint64 is less than 10^20, so a non-zero mantissa always has digits left over.
+		if -shift >= int64(len(pow10Table64)) || mant%uint64(pow10Table64[-shift]) != 0 {
+			return zero, InexactError{}
+		}
+
+		return fromMagnitude(0, mant/uint64(pow10Table64[-shift]), sign)
+	}
+
+	if shift >= int64(len(pow10Table128)) {
+		return zero, applySign(PositiveOverflowError{}, sign)
+	}
+
+	over, hi, lo := mul128By64(pow10Table128[shift], raw64(mant))
+
+	if over != 0 {
+		return zero, applySign(PositiveOverflowError{}, sign)
+	}
+
+	return fromMagnitude(uint64(hi), uint64(lo), sign)
+}
+
+// wholeUnits rounds the magnitude (hi, lo) with the given number of decimals to a whole number of
+// units. The largest magnitude is 2^128/10^24 < 2^49 units, so the result always fits in 64 bits.
+func wholeUnits(hi, lo uint64, sign int64, decimals int, round RoundingMode) (uint64, error) {
+	if !round.isValid() {
+		return 0, InvalidRoundingModeError{}
+	}
+
+	_, res := roundDecimal(hi, lo, decimals, 0, round.forSign(sign))
+	return res, nil
+}
+
+func toUint64(hi, lo uint64, sign int64, decimals int, round RoundingMode) (uint64, error) {
+	mag, err := wholeUnits(hi, lo, sign, decimals, round)
+	if err != nil {
+		return 0, err
+	}
+
+	if sign < 0 && mag != 0 {
+		return 0, NegativeOverflowError{}
+	}
+
+	return mag, nil
+}
+
+func toInt64(hi, lo uint64, sign int64, decimals int, round RoundingMode) (int64, error) {
+	mag, err := wholeUnits(hi, lo, sign, decimals, round)
+	if err != nil {
+		return 0, err
+	}
+
+	return sign * int64(mag), nil
+}
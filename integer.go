@@ -0,0 +1,578 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"encoding/binary"
+	"math/big"
+)
+
+// This file contains plain 128 and 256-bit integer types, for values that are naturally whole
+// numbers but don't fit in 64 bits (share counts, raw token units, etc.), with the same checked
+// arithmetic as the fixed-point types. They share their representation with the fixed-point types
+// of the same size, so addition and subtraction are implemented by the fixed-point code directly.
+//
+// The conversions to and from the fixed-point types treat the integer as a mantissa with a given
+// number of decimals, e.g. UInt128 1500000 with 6 decimals is the UFix128 value 1.5.
+
+// Exported integer types
+type UInt128 raw128
+type Int128 raw128
+
+type UInt256 raw256
+type Int256 raw256
+
+// NewUInt128 returns the UInt128 with the given high and low words.
+func NewUInt128(hi, lo uint64) UInt128 { return UInt128{raw64(hi), raw64(lo)} }
+
+// NewInt128 returns the Int128 with the given high and low words (in two's complement).
+func NewInt128(hi, lo uint64) Int128 { return Int128{raw64(hi), raw64(lo)} }
+
+// NewUInt256 returns the UInt256 with the given words, from most to least significant.
+func NewUInt256(w3, w2, w1, w0 uint64) UInt256 { return UInt256(NewUFix256(w3, w2, w1, w0)) }
+
+// NewInt256 returns the Int256 with the given words (in two's complement), from most to least
+// significant.
+func NewInt256(w3, w2, w1, w0 uint64) Int256 { return Int256(NewFix256(w3, w2, w1, w0)) }
+
+// Int128FromInt64 returns `n` as an Int128.
+func Int128FromInt64(n int64) Int128 { return Int128(NewFix128(uint64(n>>63), uint64(n))) }
+
+// Int256FromInt64 returns `n` as an Int256.
+func Int256FromInt64(n int64) Int256 {
+	ext := uint64(n >> 63)
+	return NewInt256(ext, ext, ext, uint64(n))
+}
+
+// UInt128FromBigInt converts `n` to a UInt128, or returns an error if it's out of range.
+func UInt128FromBigInt(n *big.Int) (UInt128, error) {
+	res, err := fromBigInt(n, ufix128FromMagnitude)
+	return UInt128(res), err
+}
+
+// Int128FromBigInt converts `n` to an Int128, or returns an error if it's out of range.
+func Int128FromBigInt(n *big.Int) (Int128, error) {
+	res, err := fromBigInt(n, fix128FromMagnitude)
+	return Int128(res), err
+}
+
+// UInt256FromBigInt converts `n` to a UInt256, or returns an error if it's out of range.
+func UInt256FromBigInt(n *big.Int) (UInt256, error) {
+	mag, ok := raw256FromBig(n)
+	if !ok {
+		return UInt256{}, applySign(PositiveOverflowError{}, int64(n.Sign()))
+	}
+	if n.Sign() < 0 {
+		return UInt256{}, NegativeOverflowError{}
+	}
+
+	return UInt256(mag), nil
+}
+
+// Int256FromBigInt converts `n` to an Int256, or returns an error if it's out of range.
+func Int256FromBigInt(n *big.Int) (Int256, error) {
+	sign := int64(n.Sign())
+	mag, ok := raw256FromBig(n)
+	if !ok {
+		return Int256{}, applySign(PositiveOverflowError{}, sign)
+	}
+
+	res, err := UFix256(mag).ApplySign(sign)
+	return Int256(res), err
+}
+
+// ToBigInt returns `a` as a big.Int.
+func (a UInt128) ToBigInt() *big.Int { return UFix128(a).ToBigInt() }
+func (a Int128) ToBigInt() *big.Int  { return Fix128(a).ToBigInt() }
+func (a UInt256) ToBigInt() *big.Int { return UFix256(a).ToBigInt() }
+func (a Int256) ToBigInt() *big.Int  { return Fix256(a).ToBigInt() }
+
+// String returns `a` in decimal.
+func (a UInt128) String() string {
+	return string(appendDecimal(nil, false, uint64(a.Hi), uint64(a.Lo), 0, true))
+}
+
+// String returns `a` in decimal.
+func (a Int128) String() string {
+	aUnsigned, sign := Fix128(a).Abs()
+	return string(appendDecimal(nil, sign < 0, uint64(aUnsigned.Hi), uint64(aUnsigned.Lo), 0, true))
+}
+
+// String returns `a` in decimal.
+func (a UInt256) String() string { return string(appendDecimal256(nil, false, raw256(a), 0)) }
+
+// String returns `a` in decimal.
+func (a Int256) String() string {
+	aUnsigned, sign := Fix256(a).Abs()
+	return string(appendDecimal256(nil, sign < 0, raw256(aUnsigned), 0))
+}
+
+// Cmp returns -1 if `a` < `b`, 0 if `a` == `b`, and 1 if `a` > `b`.
+func (a UInt128) Cmp(b UInt128) int { return cmpInt(UFix128(a), UFix128(b)) }
+func (a Int128) Cmp(b Int128) int   { return cmpInt(Fix128(a), Fix128(b)) }
+func (a UInt256) Cmp(b UInt256) int { return cmpInt(UFix256(a), UFix256(b)) }
+func (a Int256) Cmp(b Int256) int   { return cmpInt(Fix256(a), Fix256(b)) }
+
+// IsZero returns true if `a` is zero.
+func (a UInt128) IsZero() bool { return isZero128(raw128(a)) }
+func (a Int128) IsZero() bool  { return isZero128(raw128(a)) }
+func (a UInt256) IsZero() bool { return isZero256(raw256(a)) }
+func (a Int256) IsZero() bool  { return isZero256(raw256(a)) }
+
+// IsNeg returns true if `a` is negative.
+func (a Int128) IsNeg() bool { return isNeg128(raw128(a)) }
+func (a Int256) IsNeg() bool { return isNeg256(raw256(a)) }
+
+// Add returns `a + b`, or an error on overflow.
+func (a UInt128) Add(b UInt128) (UInt128, error) {
+	res, err := UFix128(a).Add(UFix128(b))
+	return UInt128(res), err
+}
+
+// Add returns `a + b`, or an error on overflow or negative overflow.
+func (a Int128) Add(b Int128) (Int128, error) {
+	res, err := Fix128(a).Add(Fix128(b))
+	return Int128(res), err
+}
+
+// Add returns `a + b`, or an error on overflow.
+func (a UInt256) Add(b UInt256) (UInt256, error) {
+	res, err := UFix256(a).Add(UFix256(b))
+	return UInt256(res), err
+}
+
+// Add returns `a + b`, or an error on overflow or negative overflow.
+func (a Int256) Add(b Int256) (Int256, error) {
+	res, err := Fix256(a).Add(Fix256(b))
+	return Int256(res), err
+}
+
+// Sub returns `a - b`, or an error on negative overflow.
+func (a UInt128) Sub(b UInt128) (UInt128, error) {
+	res, err := UFix128(a).Sub(UFix128(b))
+	return UInt128(res), err
+}
+
+// Sub returns `a - b`, or an error on overflow or negative overflow.
+func (a Int128) Sub(b Int128) (Int128, error) {
+	res, err := Fix128(a).Sub(Fix128(b))
+	return Int128(res), err
+}
+
+// Sub returns `a - b`, or an error on negative overflow.
+func (a UInt256) Sub(b UInt256) (UInt256, error) {
+	res, err := UFix256(a).Sub(UFix256(b))
+	return UInt256(res), err
+}
+
+// Sub returns `a - b`, or an error on overflow or negative overflow.
+func (a Int256) Sub(b Int256) (Int256, error) {
+	res, err := Fix256(a).Sub(Fix256(b))
+	return Int256(res), err
+}
+
+// Mul returns `a * b`, or an error on overflow.
+func (a UInt128) Mul(b UInt128) (UInt128, error) {
+	hi, lo := mul128(raw128(a), raw128(b))
+	if !isZero128(hi) {
+		return UInt128{}, PositiveOverflowError{}
+	}
+
+	return UInt128(lo), nil
+}
+
+// Mul returns `a * b`, or an error on overflow or negative overflow.
+func (a Int128) Mul(b Int128) (Int128, error) {
+	aUnsigned, aSign := Fix128(a).Abs()
+	bUnsigned, bSign := Fix128(b).Abs()
+	sign := aSign * bSign
+
+	mag, err := UInt128(aUnsigned).Mul(UInt128(bUnsigned))
+	if err != nil {
+		return Int128{}, applySign(err, sign)
+	}
+
+	res, err := UFix128(mag).ApplySign(sign)
+	return Int128(res), err
+}
+
+// Mul returns `a * b`, or an error on overflow.
+func (a UInt256) Mul(b UInt256) (UInt256, error) {
+	hi, lo := mul256(raw256(a), raw256(b))
+	if !isZero256(hi) {
+		return UInt256{}, PositiveOverflowError{}
+	}
+
+	return UInt256(lo), nil
+}
+
+// Mul returns `a * b`, or an error on overflow or negative overflow.
+func (a Int256) Mul(b Int256) (Int256, error) {
+	aUnsigned, aSign := Fix256(a).Abs()
+	bUnsigned, bSign := Fix256(b).Abs()
+	sign := aSign * bSign
+
+	mag, err := UInt256(aUnsigned).Mul(UInt256(bUnsigned))
+	if err != nil {
+		return Int256{}, applySign(err, sign)
+	}
+
+	res, err := UFix256(mag).ApplySign(sign)
+	return Int256(res), err
+}
+
+// Div returns `a / b`, rounded to an integer as specified, or an error if `b` is zero. Unlike the
+// fixed-point types, a quotient that rounds to zero isn't an error.
+func (a UInt128) Div(b UInt128, round RoundingMode) (UInt128, error) {
+	if !round.isValid() {
+		return UInt128{}, InvalidRoundingModeError{}
+	}
+	if b.IsZero() {
+		return UInt128{}, DivisionByZeroError{}
+	}
+
+	quo, rem := div128(raw128Zero, raw128(a), raw128(b))
+	if ushouldRound128(quo, rem, raw128(b), round) {
+		// The quotient can't be the largest value here, since rounding up requires b > 1.
+		quo, _ = add128(quo, raw128Zero, 1)
+	}
+
+	return UInt128(quo), nil
+}
+
+// Div returns `a / b`, rounded to an integer as specified, or an error if `b` is zero or on
+// overflow (which is only possible when dividing the smallest value by -1).
+func (a Int128) Div(b Int128, round RoundingMode) (Int128, error) {
+	aUnsigned, aSign := Fix128(a).Abs()
+	bUnsigned, bSign := Fix128(b).Abs()
+	sign := aSign * bSign
+
+	mag, err := UInt128(aUnsigned).Div(UInt128(bUnsigned), round.forSign(sign))
+	if err != nil {
+		return Int128{}, err
+	}
+
+	res, err := UFix128(mag).ApplySign(sign)
+	return Int128(res), err
+}
+
+// Div returns `a / b`, rounded to an integer as specified, see UInt128.Div.
+func (a UInt256) Div(b UInt256, round RoundingMode) (UInt256, error) {
+	if !round.isValid() {
+		return UInt256{}, InvalidRoundingModeError{}
+	}
+	if b.IsZero() {
+		return UInt256{}, DivisionByZeroError{}
+	}
+
+	quo, rem := div256(raw256Zero, raw256(a), raw256(b))
+	if ushouldRound256(quo, rem, raw256(b), round) {
+		quo, _ = add256(quo, raw256Zero, 1)
+	}
+
+	return UInt256(quo), nil
+}
+
+// Div returns `a / b`, rounded to an integer as specified, see Int128.Div.
+func (a Int256) Div(b Int256, round RoundingMode) (Int256, error) {
+	aUnsigned, aSign := Fix256(a).Abs()
+	bUnsigned, bSign := Fix256(b).Abs()
+	sign := aSign * bSign
+
+	mag, err := UInt256(aUnsigned).Div(UInt256(bUnsigned), round.forSign(sign))
+	if err != nil {
+		return Int256{}, err
+	}
+
+	res, err := UFix256(mag).ApplySign(sign)
+	return Int256(res), err
+}
+
+// ToUFix64 converts `a`, as a mantissa with the given number of decimals, to a UFix64, rounding as
+// specified. It returns the same errors as UFix64FromMantScale.
+func (a UInt128) ToUFix64(decimals uint8, round RoundingMode) (UFix64, error) {
+	return mantissaToFix(raw256{Lo: raw128(a)}, 1, int(decimals), Fix64Decimals, round, ufix64FromMagnitude)
+}
+
+// ToUFix128 converts `a`, as a mantissa with the given number of decimals, to a UFix128, see
+// UInt128.ToUFix64.
+func (a UInt128) ToUFix128(decimals uint8, round RoundingMode) (UFix128, error) {
+	return mantissaToFix(raw256{Lo: raw128(a)}, 1, int(decimals), Fix128Decimals, round, ufix128FromMagnitude)
+}
+
+// ToFix64 converts `a`, as a mantissa with the given number of decimals, to a Fix64, see
+// UInt128.ToUFix64.
+func (a Int128) ToFix64(decimals uint8, round RoundingMode) (Fix64, error) {
+	aUnsigned, sign := Fix128(a).Abs()
+	return mantissaToFix(raw256{Lo: raw128(aUnsigned)}, sign, int(decimals), Fix64Decimals, round, fix64FromMagnitude)
+}
+
+// ToFix128 converts `a`, as a mantissa with the given number of decimals, to a Fix128, see
+// UInt128.ToUFix64.
+func (a Int128) ToFix128(decimals uint8, round RoundingMode) (Fix128, error) {
+	aUnsigned, sign := Fix128(a).Abs()
+	return mantissaToFix(raw256{Lo: raw128(aUnsigned)}, sign, int(decimals), Fix128Decimals, round, fix128FromMagnitude)
+}
+
+// ToUFix64 converts `a`, as a mantissa with the given number of decimals, to a UFix64, see
+// UInt128.ToUFix64.
+func (a UInt256) ToUFix64(decimals uint8, round RoundingMode) (UFix64, error) {
+	return mantissaToFix(raw256(a), 1, int(decimals), Fix64Decimals, round, ufix64FromMagnitude)
+}
+
+// ToUFix128 converts `a`, as a mantissa with the given number of decimals, to a UFix128, see
+// UInt128.ToUFix64.
+func (a UInt256) ToUFix128(decimals uint8, round RoundingMode) (UFix128, error) {
+	return mantissaToFix(raw256(a), 1, int(decimals), Fix128Decimals, round, ufix128FromMagnitude)
+}
+
+// ToFix64 converts `a`, as a mantissa with the given number of decimals, to a Fix64, see
+// UInt128.ToUFix64.
+func (a Int256) ToFix64(decimals uint8, round RoundingMode) (Fix64, error) {
+	aUnsigned, sign := Fix256(a).Abs()
+	return mantissaToFix(raw256(aUnsigned), sign, int(decimals), Fix64Decimals, round, fix64FromMagnitude)
+}
+
+// ToFix128 converts `a`, as a mantissa with the given number of decimals, to a Fix128, see
+// UInt128.ToUFix64.
+func (a Int256) ToFix128(decimals uint8, round RoundingMode) (Fix128, error) {
+	aUnsigned, sign := Fix256(a).Abs()
+	return mantissaToFix(raw256(aUnsigned), sign, int(decimals), Fix128Decimals, round, fix128FromMagnitude)
+}
+
+// ToUInt128 returns `a` as a mantissa with the given number of decimals. If there are fewer than 8
+// decimals, `a` is rounded as specified, and non-zero values that round to zero return an
+// UnderflowError. Returns a PositiveOverflowError if the result doesn't fit.
+func (a UFix64) ToUInt128(decimals uint8, round RoundingMode) (UInt128, error) {
+	mag, err := rescale256(raw256{Lo: raw128{Lo: raw64(a)}}, Fix64Decimals, int(decimals), round)
+	if err != nil {
+		return UInt128{}, err
+	}
+	if !isZero128(mag.Hi) {
+		return UInt128{}, PositiveOverflowError{}
+	}
+
+	return UInt128(mag.Lo), nil
+}
+
+// ToUInt128 returns `a` as a mantissa with the given number of decimals, see UFix64.ToUInt128.
+func (a UFix128) ToUInt128(decimals uint8, round RoundingMode) (UInt128, error) {
+	mag, err := rescale256(raw256{Lo: raw128(a)}, Fix128Decimals, int(decimals), round)
+	if err != nil {
+		return UInt128{}, err
+	}
+	if !isZero128(mag.Hi) {
+		return UInt128{}, PositiveOverflowError{}
+	}
+
+	return UInt128(mag.Lo), nil
+}
+
+// ToInt128 returns `a` as a mantissa with the given number of decimals, see UFix64.ToUInt128.
+func (a Fix64) ToInt128(decimals uint8, round RoundingMode) (Int128, error) {
+	aUnsigned, sign := a.Abs()
+
+	mag, err := rescale256(raw256{Lo: raw128{Lo: raw64(aUnsigned)}}, Fix64Decimals, int(decimals), round.forSign(sign))
+	if err != nil {
+		return Int128{}, applySign(err, sign)
+	}
+	if !isZero128(mag.Hi) {
+		return Int128{}, applySign(PositiveOverflowError{}, sign)
+	}
+
+	res, err := UFix128(mag.Lo).ApplySign(sign)
+	return Int128(res), err
+}
+
+// ToInt128 returns `a` as a mantissa with the given number of decimals, see UFix64.ToUInt128.
+func (a Fix128) ToInt128(decimals uint8, round RoundingMode) (Int128, error) {
+	aUnsigned, sign := a.Abs()
+
+	mag, err := rescale256(raw256{Lo: raw128(aUnsigned)}, Fix128Decimals, int(decimals), round.forSign(sign))
+	if err != nil {
+		return Int128{}, applySign(err, sign)
+	}
+	if !isZero128(mag.Hi) {
+		return Int128{}, applySign(PositiveOverflowError{}, sign)
+	}
+
+	res, err := UFix128(mag.Lo).ApplySign(sign)
+	return Int128(res), err
+}
+
+// ToUInt256 returns `a` as a mantissa with the given number of decimals, see UFix64.ToUInt128.
+func (a UFix64) ToUInt256(decimals uint8, round RoundingMode) (UInt256, error) {
+	mag, err := rescale256(raw256{Lo: raw128{Lo: raw64(a)}}, Fix64Decimals, int(decimals), round)
+	if err != nil {
+		return UInt256{}, err
+	}
+
+	return UInt256(mag), nil
+}
+
+// ToUInt256 returns `a` as a mantissa with the given number of decimals, see UFix64.ToUInt128.
+func (a UFix128) ToUInt256(decimals uint8, round RoundingMode) (UInt256, error) {
+	mag, err := rescale256(raw256{Lo: raw128(a)}, Fix128Decimals, int(decimals), round)
+	if err != nil {
+		return UInt256{}, err
+	}
+
+	return UInt256(mag), nil
+}
+
+// ToInt256 returns `a` as a mantissa with the given number of decimals, see UFix64.ToUInt128.
+func (a Fix64) ToInt256(decimals uint8, round RoundingMode) (Int256, error) {
+	aUnsigned, sign := a.Abs()
+
+	mag, err := rescale256(raw256{Lo: raw128{Lo: raw64(aUnsigned)}}, Fix64Decimals, int(decimals), round.forSign(sign))
+	if err != nil {
+		return Int256{}, applySign(err, sign)
+	}
+
+	res, err := UFix256(mag).ApplySign(sign)
+	return Int256(res), err
+}
+
+// ToInt256 returns `a` as a mantissa with the given number of decimals, see UFix64.ToUInt128.
+func (a Fix128) ToInt256(decimals uint8, round RoundingMode) (Int256, error) {
+	aUnsigned, sign := a.Abs()
+
+	mag, err := rescale256(raw256{Lo: raw128(aUnsigned)}, Fix128Decimals, int(decimals), round.forSign(sign))
+	if err != nil {
+		return Int256{}, applySign(err, sign)
+	}
+
+	res, err := UFix256(mag).ApplySign(sign)
+	return Int256(res), err
+}
+
+// cmpInt compares two values of one of the fixed-point types, for the Cmp methods above.
+func cmpInt[T FixedPoint[T]](a, b T) int {
+	if a.Lt(b) {
+		return -1
+	} else if a.Gt(b) {
+		return 1
+	}
+
+	return 0
+}
+
+// mantissaToFix converts the magnitude of a mantissa with the given number of decimals, and its
+// sign, to one of the fixed-point types with typeDecimals decimals, rounding as specified. It
+// returns the same errors as the *FromMantScale functions.
+func mantissaToFix[T any](mag raw256, sign int64, decimals, typeDecimals int, round RoundingMode,
+	fromMagnitude func(hi, lo uint64, sign int64) (T, error)) (T, error) {
+	var zero T
+
+	res, err := rescale256(mag, decimals, typeDecimals, round.forSign(sign))
+	if err != nil {
+		return zero, applySign(err, sign)
+	}
+	if !isZero128(res.Hi) {
+		return zero, applySign(PositiveOverflowError{}, sign)
+	}
+
+	return fromMagnitude(uint64(res.Lo.Hi), uint64(res.Lo.Lo), sign)
+}
+
+// rescale256 returns the magnitude `mag`, which has `from` decimals, scaled to `to` decimals,
+// rounding as specified if there are fewer. Returns a PositiveOverflowError if the result doesn't
+// fit in 256 bits, or an UnderflowError if a non-zero magnitude rounds to zero.
+func rescale256(mag raw256, from, to int, round RoundingMode) (raw256, error) {
+	if !round.isValid() {
+		return raw256Zero, InvalidRoundingModeError{}
+	}
+
+	if isZero256(mag) {
+		return raw256Zero, nil
+	}
+
+	// More decimals: multiply by powers of ten that fit in 128 bits, until it's scaled or overflows.
+	for n := to - from; n > 0; {
+		step := min(n, len(pow10Table128)-1)
+		hi, lo := mul256(mag, raw256{Lo: pow10Table128[step]})
+		if !isZero256(hi) {
+			return raw256Zero, PositiveOverflowError{}
+		}
+		mag, n = lo, n-step
+	}
+
+	if to >= from {
+		return mag, nil
+	}
+
+	// Fewer decimals: divide by 10^n with a single rounding. The largest power of ten that fits in
+	// 256 bits is 10^77, so larger divisors are split, and the quotient of the first division keeps
+	// a sticky bit for its remainder. That quotient is below 2^256/10 < 10^77/2, so it can't be
+	// mistaken for a tie by the second division.
+	n := from - to
+	if n > maxPow10Exp256 {
+		rem := mag
+		if n-maxPow10Exp256 <= maxPow10Exp256 {
+			mag, rem = div256(raw256Zero, mag, pow10Raw256(n-maxPow10Exp256))
+		} else {
+			mag = raw256Zero
+		}
+		if !isZero256(rem) {
+			mag.Lo.Lo |= 1
+		}
+		n = maxPow10Exp256
+	}
+
+	den := pow10Raw256(n)
+	quo, rem := div256(raw256Zero, mag, den)
+	if ushouldRound256(quo, rem, den, round) {
+		// The quotient is below 2^256/10, so this can't overflow.
+		quo, _ = add256(quo, raw256Zero, 1)
+	}
+
+	if isZero256(quo) {
+		return raw256Zero, UnderflowError{}
+	}
+
+	return quo, nil
+}
+
+// maxPow10Exp256 is the exponent of the largest power of ten that fits in 256 bits.
+const maxPow10Exp256 = 77
+
+// pow10Raw256 returns 10^n, which must fit in 256 bits.
+func pow10Raw256(n int) raw256 {
+	res := raw256{Lo: raw128{Lo: 1}}
+	for n > 0 {
+		step := min(n, len(pow10Table128)-1)
+		_, res = mul256(res, raw256{Lo: pow10Table128[step]})
+		n -= step
+	}
+
+	return res
+}
+
+// raw256FromBig returns |n| as a raw256, and false if it doesn't fit.
+func raw256FromBig(n *big.Int) (raw256, bool) {
+	if n.BitLen() > 256 {
+		return raw256Zero, false
+	}
+
+	var buf [32]byte
+	n.FillBytes(buf[:])
+
+	return raw256{
+		Hi: raw128{raw64(binary.BigEndian.Uint64(buf[0:])), raw64(binary.BigEndian.Uint64(buf[8:]))},
+		Lo: raw128{raw64(binary.BigEndian.Uint64(buf[16:])), raw64(binary.BigEndian.Uint64(buf[24:]))},
+	}, true
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"math/big"
+	"slices"
+)
+
+// Mean returns the arithmetic mean of the values, computed from their exact sum with a single
+// rounding, as specified. The sum can't overflow, even when intermediate totals are beyond the
+// range of T. Returns a DivisionByZeroError if there are no values, or an UnderflowError if a
+// non-zero mean rounds to zero.
+func Mean[T calcValue](values []T, round RoundingMode) (T, error) {
+	return MeanSeq(slices.Values(values), round)
+}
+
+// WeightedMean returns the mean of the values weighted by the corresponding weights, i.e.
+// sum(values[i] * weights[i]) / sum(weights), computed exactly with a single rounding, as
+// specified. Returns an OutOfDomainErrorError if the slices have different lengths, a
+// DivisionByZeroError if the weights sum to zero (including when there are none), or an
+// UnderflowError if a non-zero mean rounds to zero.
+func WeightedMean[T calcValue](values, weights []T, round RoundingMode) (T, error) {
+	var zero T
+
+	if !round.isValid() {
+		return zero, InvalidRoundingModeError{}
+	}
+
+	if len(values) != len(weights) {
+		return zero, OutOfDomainErrorError{}
+	}
+
+	// The scale factors cancel out: with raw values v and w, the raw mean is sum(v*w) / sum(w).
+	var num, den, v, w, scratch big.Int
+	for i := range values {
+		setBigRaw(&v, &scratch, values[i])
+		setBigRaw(&w, &scratch, weights[i])
+
+		num.Add(&num, v.Mul(&v, &w))
+		den.Add(&den, &w)
+	}
+
+	if den.Sign() == 0 {
+		return zero, DivisionByZeroError{}
+	}
+
+	return meanFromBig[T](&num, &den, round)
+}
+
+// meanFromBig returns num / den rounded as specified, as the raw value of a T.
+func meanFromBig[T calcValue](num, den *big.Int, round RoundingMode) (T, error) {
+	var zero T
+
+	res := roundQuoBig(num, den, round)
+	if res.Sign() == 0 && num.Sign() != 0 {
+		return zero, UnderflowError{}
+	}
+
+	var out any
+	var err error
+
+	switch any(zero).(type) {
+	case UFix64:
+		out, err = fromBigInt(res, ufix64FromMagnitude)
+	case Fix64:
+		out, err = fromBigInt(res, fix64FromMagnitude)
+	case UFix128:
+		out, err = fromBigInt(res, ufix128FromMagnitude)
+	case Fix128:
+		out, err = fromBigInt(res, fix128FromMagnitude)
+	default:
+		// Unreachable, the type constraint only allows the types above
+		debugPanic("unsupported type")
+		return zero, nil
+	}
+
+	return out.(T), err
+}
+
+// setBigRaw sets z to the raw value of `a` and returns z, using scratch for the low word of
+// 128-bit values, so neither needs to be allocated per value.
+func setBigRaw[T calcValue](z, scratch *big.Int, a T) *big.Int {
+	switch a := any(a).(type) {
+	case UFix64:
+		return z.SetUint64(uint64(a))
+	case Fix64:
+		return z.SetInt64(int64(a))
+	case UFix128:
+		z.SetUint64(uint64(a.Hi))
+		return z.Lsh(z, 64).Add(z, scratch.SetUint64(uint64(a.Lo)))
+	case Fix128:
+		// The signed high word times 2^64, plus the unsigned low word, is the two's complement
+		// value.
+		z.SetInt64(int64(a.Hi))
+		return z.Lsh(z, 64).Add(z, scratch.SetUint64(uint64(a.Lo)))
+	}
+
+	// Unreachable, the type constraint only allows the types above
+	debugPanic("unsupported type")
+	return z.SetInt64(0)
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import "math/big"
+
+// This file contains methods that describe the fixed-point types themselves, rather than any
+// particular value. They ignore their receiver, and are intended for generic code (see the
+// FixedPoint interface) where the package constants can't be named directly.
+
+// Scale returns the scale factor of the type, i.e. the raw value that represents 1.0. A big.Int is
+// used because the 128-bit scale factor (10^24) doesn't fit in a uint64. The result is a new
+// value on each call, so it's safe for the caller to modify it.
+func (UFix64) Scale() *big.Int  { return pow10Big(Fix64Decimals) }
+func (Fix64) Scale() *big.Int   { return pow10Big(Fix64Decimals) }
+func (UFix128) Scale() *big.Int { return pow10Big(Fix128Decimals) }
+func (Fix128) Scale() *big.Int  { return pow10Big(Fix128Decimals) }
+func (UFix256) Scale() *big.Int { return pow10Big(Fix256Decimals) }
+func (Fix256) Scale() *big.Int  { return pow10Big(Fix256Decimals) }
+
+// Decimals returns the number of decimal places the type can represent.
+func (UFix64) Decimals() int  { return Fix64Decimals }
+func (Fix64) Decimals() int   { return Fix64Decimals }
+func (UFix128) Decimals() int { return Fix128Decimals }
+func (Fix128) Decimals() int  { return Fix128Decimals }
+func (UFix256) Decimals() int { return Fix256Decimals }
+func (Fix256) Decimals() int  { return Fix256Decimals }
+
+// MaxValue returns the largest value representable by the type.
+func (UFix64) MaxValue() UFix64   { return UFix64Max }
+func (Fix64) MaxValue() Fix64     { return Fix64Max }
+func (UFix128) MaxValue() UFix128 { return UFix128Max }
+func (Fix128) MaxValue() Fix128   { return Fix128Max }
+func (UFix256) MaxValue() UFix256 { return UFix256Max }
+func (Fix256) MaxValue() Fix256   { return Fix256Max }
+
+// MinValue returns the smallest (i.e. most negative) value representable by the type, which is
+// zero for the unsigned types.
+func (UFix64) MinValue() UFix64   { return UFix64Zero }
+func (Fix64) MinValue() Fix64     { return Fix64Min }
+func (UFix128) MinValue() UFix128 { return UFix128Zero }
+func (Fix128) MinValue() Fix128   { return Fix128Min }
+func (UFix256) MinValue() UFix256 { return UFix256Zero }
+func (Fix256) MinValue() Fix256   { return Fix256Min }
+
+// One returns the value 1.0 in the type.
+func (UFix64) One() UFix64   { return UFix64One }
+func (Fix64) One() Fix64     { return Fix64One }
+func (UFix128) One() UFix128 { return UFix128One }
+func (Fix128) One() Fix128   { return Fix128One }
+func (UFix256) One() UFix256 { return UFix256One }
+func (Fix256) One() Fix256   { return Fix256One }
+
+func pow10Big(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+// This file contains the bulk converters between the 64-bit and 128-bit types, for migrating
+// stored values (e.g. account balances) from Fix64 to Fix128 in one pass. The widening converters
+// inline the scaling, rather than calling ToUFix128 for each value, and the Append variants let
+// callers convert a stream of values in chunks, reusing the same buffer.
+
+// ToUFix128Slice returns each value in src converted to a UFix128, which is always exact.
+func ToUFix128Slice(src []UFix64) []UFix128 {
+	return AppendUFix128Slice(make([]UFix128, 0, len(src)), src)
+}
+
+// ToFix128Slice returns each value in src converted to a Fix128, which is always exact.
+func ToFix128Slice(src []Fix64) []Fix128 {
+	return AppendFix128Slice(make([]Fix128, 0, len(src)), src)
+}
+
+// AppendUFix128Slice appends each value in src, converted to a UFix128, to dst and returns the
+// extended slice.
+func AppendUFix128Slice(dst []UFix128, src []UFix64) []UFix128 {
+	for _, a := range src {
+		hi, lo := mul64(raw64(a), scaleFactor64To128)
+		dst = append(dst, UFix128{Hi: hi, Lo: lo})
+	}
+
+	return dst
+}
+
+// AppendFix128Slice appends each value in src, converted to a Fix128, to dst and returns the
+// extended slice.
+func AppendFix128Slice(dst []Fix128, src []Fix64) []Fix128 {
+	for _, a := range src {
+		// The unsigned product of the two's complement value, with the high word corrected for
+		// negative values, is the two's complement of the signed product.
+		hi, lo := mul64(raw64(a), scaleFactor64To128)
+		if isNeg64(raw64(a)) {
+			hi, _ = sub64(hi, scaleFactor64To128, 0)
+		}
+		dst = append(dst, Fix128{Hi: hi, Lo: lo})
+	}
+
+	return dst
+}
+
+// ToUFix64Slice converts each value in src to a UFix64 as for UFix128.ToUFix64, rounding as
+// specified. The error slice is nil if every value was converted; otherwise it has the same length
+// as the results, with a nil error for each converted value, and the result is zero for each
+// failed one (see ParseUFix64Column).
+func ToUFix64Slice(src []UFix128, round RoundingMode) ([]UFix64, []error) {
+	c := column[UFix64]{values: make([]UFix64, 0, len(src))}
+	for _, a := range src {
+		c.add(a.ToUFix64(round))
+	}
+
+	return c.values, c.errs
+}
+
+// ToFix64Slice converts each value in src to a Fix64 as for Fix128.ToFix64, see ToUFix64Slice.
+func ToFix64Slice(src []Fix128, round RoundingMode) ([]Fix64, []error) {
+	c := column[Fix64]{values: make([]Fix64, 0, len(src))}
+	for _, a := range src {
+		c.add(a.ToFix64(round))
+	}
+
+	return c.values, c.errs
+}
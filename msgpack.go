@@ -0,0 +1,94 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+// This file implements MessagePack encoding for the fixed-point types, as extension types whose
+// data is the canonical big-endian binary encoding (see MarshalBinary): a fixext 8 for the 64-bit
+// types, and a fixext 16 for the 128-bit types. The methods match the Marshaler and Unmarshaler
+// interfaces of github.com/vmihailenco/msgpack, so the types can be used directly with that
+// library, but the encoding is implemented here to avoid the dependency.
+
+// The MessagePack extension type codes for each of the fixed-point types.
+const (
+	MsgpackExtUFix64  int8 = 0x10
+	MsgpackExtFix64   int8 = 0x11
+	MsgpackExtUFix128 int8 = 0x12
+	MsgpackExtFix128  int8 = 0x13
+)
+
+const (
+	msgpackFixExt8  = 0xd7
+	msgpackFixExt16 = 0xd8
+)
+
+// MarshalMsgpack encodes `a` as a MessagePack fixext 8 with type MsgpackExtUFix64.
+func (a UFix64) MarshalMsgpack() ([]byte, error) {
+	data, _ := a.MarshalBinary()
+	return append([]byte{msgpackFixExt8, byte(MsgpackExtUFix64)}, data...), nil
+}
+
+// MarshalMsgpack encodes `a` as a MessagePack fixext 8 with type MsgpackExtFix64.
+func (a Fix64) MarshalMsgpack() ([]byte, error) {
+	data, _ := a.MarshalBinary()
+	return append([]byte{msgpackFixExt8, byte(MsgpackExtFix64)}, data...), nil
+}
+
+// MarshalMsgpack encodes `a` as a MessagePack fixext 16 with type MsgpackExtUFix128.
+func (a UFix128) MarshalMsgpack() ([]byte, error) {
+	data, _ := a.MarshalBinary()
+	return append([]byte{msgpackFixExt16, byte(MsgpackExtUFix128)}, data...), nil
+}
+
+// MarshalMsgpack encodes `a` as a MessagePack fixext 16 with type MsgpackExtFix128.
+func (a Fix128) MarshalMsgpack() ([]byte, error) {
+	data, _ := a.MarshalBinary()
+	return append([]byte{msgpackFixExt16, byte(MsgpackExtFix128)}, data...), nil
+}
+
+// UnmarshalMsgpack decodes `a` from the encoding produced by MarshalMsgpack. It returns an
+// InvalidEncodingError, leaving `a` unchanged, if the data isn't a single extension value of the
+// right type and size.
+func (a *UFix64) UnmarshalMsgpack(data []byte) error {
+	return unmarshalMsgpack(data, msgpackFixExt8, MsgpackExtUFix64, a.UnmarshalBinary)
+}
+
+// UnmarshalMsgpack decodes `a` from the encoding produced by MarshalMsgpack, see
+// UFix64.UnmarshalMsgpack.
+func (a *Fix64) UnmarshalMsgpack(data []byte) error {
+	return unmarshalMsgpack(data, msgpackFixExt8, MsgpackExtFix64, a.UnmarshalBinary)
+}
+
+// UnmarshalMsgpack decodes `a` from the encoding produced by MarshalMsgpack, see
+// UFix64.UnmarshalMsgpack.
+func (a *UFix128) UnmarshalMsgpack(data []byte) error {
+	return unmarshalMsgpack(data, msgpackFixExt16, MsgpackExtUFix128, a.UnmarshalBinary)
+}
+
+// UnmarshalMsgpack decodes `a` from the encoding produced by MarshalMsgpack, see
+// UFix64.UnmarshalMsgpack.
+func (a *Fix128) UnmarshalMsgpack(data []byte) error {
+	return unmarshalMsgpack(data, msgpackFixExt16, MsgpackExtFix128, a.UnmarshalBinary)
+}
+
+func unmarshalMsgpack(data []byte, format byte, extType int8, unmarshalBinary func([]byte) error) error {
+	if len(data) < 2 || data[0] != format || int8(data[1]) != extType {
+		return InvalidEncodingError{}
+	}
+
+	// UnmarshalBinary checks the length of the payload.
+	return unmarshalBinary(data[2:])
+}
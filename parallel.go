@@ -0,0 +1,101 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// parallelChunkSize is the number of values each worker of MapParallel processes at a time. It's
+// large enough to make the synchronization negligible, and small enough to balance the load when
+// some values are much slower to process than others (e.g. Pow with large exponents).
+const parallelChunkSize = 1024
+
+// MapParallel applies f to each of the values, using up to `workers` goroutines (or GOMAXPROCS if
+// workers isn't positive), e.g. to recompute a column of historical balances:
+//
+//	balances, errs := MapParallel(amounts, func(a UFix128) (UFix128, error) {
+//		return a.FMD(price, scale, RoundHalfEven)
+//	}, 0)
+//
+// The results are in the same order as the values, whatever the number of workers, so the output
+// is deterministic as long as f is. As for the batch parsers, the error slice is nil if f
+// succeeded for every value; otherwise it has the same length as the results, with a nil error for
+// each successful value (and the result for a failed value is whatever f returned with the
+// error). f must be safe to call concurrently.
+func MapParallel[T, U any](values []T, f func(T) (U, error), workers int) ([]U, []error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	chunks := (len(values) + parallelChunkSize - 1) / parallelChunkSize
+	workers = min(workers, chunks)
+
+	results := make([]U, len(values))
+
+	// The errors are collected per chunk, so they're only allocated for chunks with an error, and
+	// the workers don't have to share a slice that's allocated lazily.
+	chunkErrs := make([][]error, chunks)
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	wg.Add(workers)
+
+	for range workers {
+		go func() {
+			defer wg.Done()
+
+			for {
+				chunk := int(next.Add(1) - 1)
+				if chunk >= chunks {
+					return
+				}
+
+				start := chunk * parallelChunkSize
+				end := min(start+parallelChunkSize, len(values))
+
+				for i := start; i < end; i++ {
+					res, err := f(values[i])
+					results[i] = res
+					if err != nil {
+						if chunkErrs[chunk] == nil {
+							chunkErrs[chunk] = make([]error, end-start)
+						}
+						chunkErrs[chunk][i-start] = err
+					}
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	var errs []error
+	for chunk, e := range chunkErrs {
+		if e == nil {
+			continue
+		}
+		if errs == nil {
+			errs = make([]error, len(values))
+		}
+		copy(errs[chunk*parallelChunkSize:], e)
+	}
+
+	return results, errs
+}
@@ -0,0 +1,489 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"flag"
+	"fmt"
+	"math/bits"
+	"strings"
+)
+
+// This file contains the conversions from decimal strings to fixed-point values. Like the
+// formatting functions, parsing is exact: the digits are accumulated with integer arithmetic,
+// and any digits beyond the precision of the type are rounded exactly once.
+
+// ParseUFix64 parses a decimal string such as "12.5", "+0.00000001", or "1.5e-3" into a UFix64.
+// Inputs with more than 8 fractional digits (after applying the exponent) are rounded using the
+// given rounding mode, and a nonzero input that rounds to zero is an UnderflowError, e.g.
+// "0.000000001" with RoundTowardZero. That includes negative inputs: "-0.000000001" is also an
+// UnderflowError, while "-0.00000001" (or "-0.000000001" with RoundFloor) is a
+// NegativeOverflowError. A negative zero such as "-0.0" is just zero. Surrounding whitespace is
+// ignored; use ParseOptions for strict parsing.
+func ParseUFix64(s string, round RoundingMode) (UFix64, error) {
+	return ParseOptions{Rounding: round}.ParseUFix64(s)
+}
+
+// ParseFix64 parses a decimal string such as "-12.5" into a Fix64. Inputs with more than 8
+// fractional digits are rounded using the given rounding mode, and a nonzero input that rounds to
+// zero is an UnderflowError, e.g. "1e-30" with RoundTowardZero.
+func ParseFix64(s string, round RoundingMode) (Fix64, error) {
+	return ParseOptions{Rounding: round}.ParseFix64(s)
+}
+
+// ParseUFix128 parses a decimal string into a UFix128. Inputs with more than 24 fractional digits
+// are rounded using the given rounding mode, and a nonzero input that rounds to zero is an
+// UnderflowError, whatever its sign. Other nonzero negative inputs are a NegativeOverflowError, see
+// ParseUFix64.
+func ParseUFix128(s string, round RoundingMode) (UFix128, error) {
+	return ParseOptions{Rounding: round}.ParseUFix128(s)
+}
+
+// ParseFix128 parses a decimal string into a Fix128. Inputs with more than 24 fractional digits
+// are rounded using the given rounding mode, and a nonzero input that rounds to zero is an
+// UnderflowError.
+func ParseFix128(s string, round RoundingMode) (Fix128, error) {
+	return ParseOptions{Rounding: round}.ParseFix128(s)
+}
+
+// ParseUFix256 parses a decimal string into a UFix256, see ParseUFix128.
+func ParseUFix256(s string, round RoundingMode) (UFix256, error) {
+	return ParseOptions{Rounding: round}.ParseUFix256(s)
+}
+
+// ParseFix256 parses a decimal string into a Fix256, see ParseFix128.
+func ParseFix256(s string, round RoundingMode) (Fix256, error) {
+	return ParseOptions{Rounding: round}.ParseFix256(s)
+}
+
+// MustParseUFix64 parses an exact decimal string into a UFix64, and panics if it's invalid, out of
+// range, or would need to be rounded. It's intended for package-level variables and test
+// fixtures, e.g. var minStake = fixedPoint.MustParseUFix64("1.25"); don't use it on untrusted
+// inputs!
+func MustParseUFix64(s string) UFix64 { return mustParse(s, textParser.ParseUFix64) }
+
+// MustParseFix64 parses an exact decimal string into a Fix64, see MustParseUFix64.
+func MustParseFix64(s string) Fix64 { return mustParse(s, textParser.ParseFix64) }
+
+// MustParseUFix128 parses an exact decimal string into a UFix128, see MustParseUFix64.
+func MustParseUFix128(s string) UFix128 { return mustParse(s, textParser.ParseUFix128) }
+
+// MustParseFix128 parses an exact decimal string into a Fix128, see MustParseUFix64.
+func MustParseFix128(s string) Fix128 { return mustParse(s, textParser.ParseFix128) }
+
+// MustParseUFix256 parses an exact decimal string into a UFix256, see MustParseUFix64.
+func MustParseUFix256(s string) UFix256 { return mustParse(s, textParser.ParseUFix256) }
+
+// MustParseFix256 parses an exact decimal string into a Fix256, see MustParseUFix64.
+func MustParseFix256(s string) Fix256 { return mustParse(s, textParser.ParseFix256) }
+
+// ParseOptions controls how strings are parsed into fixed-point values. The zero value is a
+// lenient parser that rounds toward zero.
+type ParseOptions struct {
+	// Rounding is used for inputs with more fractional digits than the type supports. It's ignored
+	// in strict mode.
+	Rounding RoundingMode
+
+	// Strict rejects inputs with surrounding whitespace (with a SyntaxError), and inputs that
+	// would need to be rounded (with an InexactError), instead of rounding them. This is
+	// intended for validating user input, where silently changing the value isn't acceptable.
+	Strict bool
+
+	// Separators accepts '_' and ',' as digit group separators, e.g. "1,234,567.5" or
+	// "1_000_000". A separator must be between two digits, but the size of the groups isn't
+	// checked. With DecimalComma, the group separators are '_' and '.' instead.
+	Separators bool
+
+	// DecimalComma uses ',' as the decimal separator instead of '.', e.g. "1.234,5" (with
+	// Separators) or "0,25".
+	DecimalComma bool
+}
+
+// ParseUFix64 parses a decimal string into a UFix64, see the ParseUFix64 function for details.
+func (opts ParseOptions) ParseUFix64(s string) (UFix64, error) {
+	hi, lo, sign, err := opts.parseDecimal(s, Fix64Decimals)
+	if err != nil {
+		return UFix64Zero, err
+	}
+
+	return ufix64FromMagnitude(hi, lo, sign)
+}
+
+// ParseFix64 parses a decimal string into a Fix64, see the ParseFix64 function for details.
+func (opts ParseOptions) ParseFix64(s string) (Fix64, error) {
+	hi, lo, sign, err := opts.parseDecimal(s, Fix64Decimals)
+	if err != nil {
+		return Fix64Zero, err
+	}
+
+	return fix64FromMagnitude(hi, lo, sign)
+}
+
+// ParseUFix128 parses a decimal string into a UFix128, see the ParseUFix128 function for details.
+func (opts ParseOptions) ParseUFix128(s string) (UFix128, error) {
+	hi, lo, sign, err := opts.parseDecimal(s, Fix128Decimals)
+	if err != nil {
+		return UFix128Zero, err
+	}
+
+	return ufix128FromMagnitude(hi, lo, sign)
+}
+
+// ParseFix128 parses a decimal string into a Fix128, see the ParseFix128 function for details.
+func (opts ParseOptions) ParseFix128(s string) (Fix128, error) {
+	hi, lo, sign, err := opts.parseDecimal(s, Fix128Decimals)
+	if err != nil {
+		return Fix128Zero, err
+	}
+
+	return fix128FromMagnitude(hi, lo, sign)
+}
+
+// ParseUFix256 parses a decimal string into a UFix256, see the ParseUFix256 function for details.
+func (opts ParseOptions) ParseUFix256(s string) (UFix256, error) {
+	mag, sign, err := opts.parseDecimal256(s, Fix256Decimals)
+	if err != nil {
+		return UFix256Zero, err
+	}
+
+	if sign < 0 && !isZero256(mag) {
+		return UFix256Zero, NegativeOverflowError{}
+	}
+
+	return UFix256(mag), nil
+}
+
+// ParseFix256 parses a decimal string into a Fix256, see the ParseFix256 function for details.
+func (opts ParseOptions) ParseFix256(s string) (Fix256, error) {
+	mag, sign, err := opts.parseDecimal256(s, Fix256Decimals)
+	if err != nil {
+		return Fix256Zero, err
+	}
+
+	return UFix256(mag).ApplySign(sign)
+}
+
+// The functions below convert a raw 128-bit magnitude (hi, lo) and a sign into each type,
+// reporting an overflow error if it's out of range. A negative zero is just zero.
+
+func ufix64FromMagnitude(hi, lo uint64, sign int64) (UFix64, error) {
+	if hi != 0 {
+		return UFix64Zero, applySign(PositiveOverflowError{}, sign)
+	}
+
+	if sign < 0 && lo != 0 {
+		return UFix64Zero, NegativeOverflowError{}
+	}
+
+	return UFix64(lo), nil
+}
+
+func fix64FromMagnitude(hi, lo uint64, sign int64) (Fix64, error) {
+	if hi != 0 {
+		return Fix64Zero, applySign(PositiveOverflowError{}, sign)
+	}
+
+	return UFix64(lo).ApplySign(sign)
+}
+
+func ufix128FromMagnitude(hi, lo uint64, sign int64) (UFix128, error) {
+	if sign < 0 && (hi != 0 || lo != 0) {
+		return UFix128Zero, NegativeOverflowError{}
+	}
+
+	return UFix128{raw64(hi), raw64(lo)}, nil
+}
+
+func fix128FromMagnitude(hi, lo uint64, sign int64) (Fix128, error) {
+	return UFix128{raw64(hi), raw64(lo)}.ApplySign(sign)
+}
+
+// Set implements flag.Value, so values can be used directly as command line flags, e.g. with
+// flag.Var(&minStake, "min-stake", "..."). The value is parsed leniently (i.e. ignoring
+// surrounding whitespace), but must be exact; an input that would need to be rounded is
+// rejected with an InexactError, which the flag package reports along with the flag name.
+func (a *UFix64) Set(s string) error { return setValue(a, s, flagParser.ParseUFix64) }
+
+// Set implements flag.Value, see UFix64.Set.
+func (a *Fix64) Set(s string) error { return setValue(a, s, flagParser.ParseFix64) }
+
+// Set implements flag.Value, see UFix64.Set.
+func (a *UFix128) Set(s string) error { return setValue(a, s, flagParser.ParseUFix128) }
+
+// Set implements flag.Value, see UFix64.Set.
+func (a *Fix128) Set(s string) error { return setValue(a, s, flagParser.ParseFix128) }
+
+var (
+	_ flag.Value = (*UFix64)(nil)
+	_ flag.Value = (*Fix64)(nil)
+	_ flag.Value = (*UFix128)(nil)
+	_ flag.Value = (*Fix128)(nil)
+)
+
+// flagParser parses flag values. They're never rounded, but setValue trims any whitespace first.
+var flagParser = ParseOptions{Strict: true}
+
+func setValue[T any](dst *T, s string, parse func(string) (T, error)) error {
+	res, err := parse(strings.TrimSpace(s))
+	if err != nil {
+		return err
+	}
+
+	*dst = res
+	return nil
+}
+
+// mustParse is like must, but the panic includes the input, since it's usually a constant that
+// the caller wants to find.
+func mustParse[T any](s string, parse func(string) (T, error)) T {
+	v, err := parse(s)
+	if err != nil {
+		panic(fmt.Errorf("fixedPoint: cannot parse %q: %w", s, err))
+	}
+
+	return v
+}
+
+// parseDecimal parses a decimal string with an optional sign, an optional decimal point, and an
+// optional exponent, e.g. "-12.345", "0.5", ".5", "5.", or "1.5e-10", and returns its magnitude
+// scaled by 10^decimals as a 128-bit integer (hi, lo), along with its sign. Any digits beyond the
+// given number of decimals (after applying the exponent) are rounded using the given rounding
+// mode. The magnitude is rounded towards the sign, so that the directed rounding modes work as
+// expected for negative values.
+func (opts ParseOptions) parseDecimal(s string, decimals int) (hi, lo uint64, sign int64, err error) {
+	mag, sign, err := opts.parseDecimalBits(s, decimals, 128)
+	return uint64(mag.Lo.Hi), uint64(mag.Lo.Lo), sign, err
+}
+
+// parseDecimal256 is like parseDecimal, but returns a 256-bit magnitude.
+func (opts ParseOptions) parseDecimal256(s string, decimals int) (raw256, int64, error) {
+	return opts.parseDecimalBits(s, decimals, 256)
+}
+
+// parseDecimalBits implements parseDecimal and parseDecimal256, reporting an overflow if the
+// magnitude doesn't fit in the given number of bits (128 or 256).
+func (opts ParseOptions) parseDecimalBits(s string, decimals int, size int) (mag raw256, sign int64, err error) {
+	round := opts.Rounding
+
+	if !opts.Strict {
+		if !round.isValid() {
+			return raw256Zero, 1, InvalidRoundingModeError{}
+		}
+
+		s = strings.TrimSpace(s)
+	}
+
+	sign = 1
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = s[1:]
+	}
+
+	// The exponent just moves the decimal point, so it's applied by changing which digits are
+	// kept, rather than by scaling the value after the fact.
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var ok bool
+		if exp, ok = parseExponent(s[i+1:]); !ok {
+			return raw256Zero, sign, SyntaxError{}
+		}
+		s = s[:i]
+	}
+
+	point, group := byte('.'), byte(',')
+	if opts.DecimalComma {
+		point, group = ',', '.'
+	}
+
+	intDigits := 0
+	for i := 0; i < len(s) && s[i] != point; i++ {
+		if isDigit(s[i]) {
+			intDigits++
+		}
+	}
+
+	// The first `keep` digits are at or above the precision of the type, and are accumulated in
+	// mag. The rest are accumulated as the remainder r of a division by b, up to the number
+	// of digits that fit in a uint64. Any digits after that only matter if they're non-zero, which
+	// is tracked in the sticky flag. If keep is negative, the remainder starts with that many
+	// implicit zeros.
+	keep := intDigits + exp + decimals
+
+	var r, b uint64 = 0, 1
+	var sticky, overflow, seenPoint bool
+	digits := 0
+
+	for n := keep; n < 0 && b <= maxPow10Uint64/10; n++ {
+		b *= 10
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		if c == point && !seenPoint {
+			seenPoint = true
+			continue
+		}
+
+		// Separators are only allowed between two digits.
+		if opts.Separators && (c == '_' || c == group) {
+			if i == 0 || i == len(s)-1 || !isDigit(s[i-1]) || !isDigit(s[i+1]) {
+				return raw256Zero, sign, SyntaxError{}
+			}
+			continue
+		}
+
+		if !isDigit(c) {
+			return raw256Zero, sign, SyntaxError{}
+		}
+
+		d := uint64(c - '0')
+
+		if digits < keep {
+			var carry bool
+			// The 128-bit case is kept separate, so that it can be inlined.
+			if size == 128 {
+				mag.Lo, carry = mulAdd10(mag.Lo, d)
+			} else {
+				mag, carry = mulAdd10Wide(mag, d)
+			}
+			overflow = overflow || carry
+		} else if b <= maxPow10Uint64/10 {
+			r = r*10 + d
+			b *= 10
+		} else {
+			sticky = sticky || d != 0
+		}
+
+		digits++
+	}
+
+	if digits == 0 {
+		return raw256Zero, sign, SyntaxError{}
+	}
+
+	// Scale up the value if it had fewer digits than the precision of the type. This stops as
+	// soon as it overflows, so huge exponents don't take forever, and it's skipped for zero.
+	for n := digits; n < keep && !overflow && !isZero256(mag); n++ {
+		var carry bool
+		if size == 128 {
+			mag.Lo, carry = mulAdd10(mag.Lo, 0)
+		} else {
+			mag, carry = mulAdd10Wide(mag, 0)
+		}
+		overflow = overflow || carry
+	}
+
+	if overflow {
+		return raw256Zero, sign, applySign(PositiveOverflowError{}, sign)
+	}
+
+	if r == 0 && !sticky {
+		return mag, sign, nil
+	}
+
+	if opts.Strict {
+		return raw256Zero, sign, InexactError{}
+	}
+
+	// Doubling the remainder and the divisor leaves room for the sticky bit, which moves the
+	// remainder off of an exact tie (or off of zero) without changing anything else.
+	r, b = 2*r, 2*b
+	if sticky {
+		r |= 1
+	}
+
+	if ushouldRound64(mag.Lo.Lo, raw64(r), raw64(b), round.forSign(sign)) {
+		var carry uint64
+		mag, carry = add256(mag, raw256Zero, 1)
+
+		if carry != 0 || (size == 128 && !isZero128(mag.Hi)) {
+			return raw256Zero, sign, applySign(PositiveOverflowError{}, sign)
+		}
+	}
+
+	if isZero256(mag) && r != 0 {
+		return raw256Zero, sign, UnderflowError{}
+	}
+
+	return mag, sign, nil
+}
+
+// parseExponent parses the exponent of a number in scientific notation, i.e. the part after the
+// 'e', with an optional sign. Exponents beyond maxExponent are clamped to maxExponent+1, so that
+// they can't overflow an int, while callers that can't ignore them can still tell them apart.
+func parseExponent(s string) (int, bool) {
+	neg := false
+	if len(s) > 0 && (s[0] == '+' || s[0] == '-') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	if len(s) == 0 {
+		return 0, false
+	}
+
+	exp := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return 0, false
+		}
+		exp = min(exp*10+int(s[i]-'0'), maxExponent+1)
+	}
+
+	if neg {
+		return -exp, true
+	}
+	return exp, true
+}
+
+// maxExponent is the largest exponent magnitude parseExponent returns exactly. For the fixed types,
+// any larger exponent either overflows, or rounds every value as if it were an infinitesimal, so
+// clamping doesn't change the result.
+const maxExponent = 1 << 20
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// maxPow10Uint64 is the largest power of ten that fits in a uint64, with room to double it.
+const maxPow10Uint64 = 1e18
+
+// mulAdd10 returns a * 10 + d, and true if the result overflows 128 bits.
+func mulAdd10(a raw128, d uint64) (raw128, bool) {
+	hiCarry, hiLo := bits.Mul64(uint64(a.Hi), 10)
+	loHi, loLo := bits.Mul64(uint64(a.Lo), 10)
+
+	lo, carry := bits.Add64(loLo, d, 0)
+	hi, carry := bits.Add64(hiLo, loHi, carry)
+
+	return raw128{raw64(hi), raw64(lo)}, hiCarry != 0 || carry != 0
+}
+
+// mulAdd10Wide returns mag * 10 + d, and true if the result overflows 256 bits.
+func mulAdd10Wide(mag raw256, d uint64) (raw256, bool) {
+	loHi, loMid, loLo := mul128By64(mag.Lo, 10)
+	hiHi, hiMid, hiLo := mul128By64(mag.Hi, 10)
+
+	var carry uint64
+	mag.Lo, carry = add128(raw128{loMid, loLo}, raw128{0, raw64(d)}, 0)
+	mag.Hi, carry = add128(raw128{hiMid, hiLo}, raw128{0, loHi}, carry)
+
+	return mag, hiHi != 0 || carry != 0
+}
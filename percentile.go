@@ -0,0 +1,261 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"errors"
+	"slices"
+)
+
+// Interpolation modes for Percentile, used when the requested rank falls between two values.
+type PercentileMode int
+
+const (
+	// PercentileLinear interpolates linearly between the two values on either side of the rank,
+	// rounding the interpolated value as specified.
+	PercentileLinear PercentileMode = iota
+	// PercentileLower returns the value just below the rank.
+	PercentileLower
+	// PercentileHigher returns the value just above the rank.
+	PercentileHigher
+	// PercentileNearest returns the value closest to the rank, picking the one with the even index
+	// (in sorted order) if the rank is exactly halfway between two values.
+	PercentileNearest
+	// PercentileMidpoint returns the mean of the two values on either side of the rank, rounded as
+	// specified.
+	PercentileMidpoint
+)
+
+// percentileRankScale is the denominator of the rank computed from a percentage: 100 (percent)
+// times the scale of UFix64.
+const percentileRankScale = 100 * 1e8
+
+// Median returns the middle value of the values, or the mean of the two middle values (rounded as
+// specified) if there's an even number of them. The values don't need to be sorted, and the slice
+// isn't modified. Returns an OutOfDomainErrorError if there are no values.
+func Median[T calcValue](values []T, round RoundingMode) (T, error) {
+	return Percentile(values, UFix64(50e8), PercentileLinear, round)
+}
+
+// Percentile returns the p-th percentile of the values, where p is a percentage between 0 and
+// 100 (inclusive), e.g. 50 for the median. The rank of the percentile is p/100 * (len-1) in the
+// sorted values, and if it falls between two values, the result is chosen or interpolated
+// according to mode. The interpolation is done with FMD, so the result is rounded only once, as
+// specified. The values don't need to be sorted, and the slice isn't modified.
+//
+// Returns an OutOfDomainErrorError if there are no values, p is greater than 100, or the mode is
+// invalid.
+func Percentile[T calcValue](values []T, p UFix64, mode PercentileMode, round RoundingMode) (T, error) {
+	var zero T
+
+	if !round.isValid() {
+		return zero, InvalidRoundingModeError{}
+	}
+
+	if len(values) == 0 || p > UFix64(100e8) || mode < PercentileLinear || mode > PercentileMidpoint {
+		return zero, OutOfDomainErrorError{}
+	}
+
+	sorted := slices.Clone(values)
+	slices.SortFunc(sorted, calcCmp)
+
+	// The rank is i + r/percentileRankScale. p <= 100e8 and len-1 < 2^63, but their product can
+	// still exceed 64 bits, so it's split into a quotient and remainder in 128 bits.
+	hi, lo := mul64(raw64(p), raw64(len(sorted)-1))
+	q, r := div64(hi, lo, percentileRankScale)
+	i := int(q)
+
+	if r == 0 {
+		return sorted[i], nil
+	}
+
+	switch mode {
+	case PercentileLower:
+		return sorted[i], nil
+	case PercentileHigher:
+		return sorted[i+1], nil
+	case PercentileNearest:
+		if 2*r > percentileRankScale || (2*r == percentileRankScale && i%2 == 1) {
+			return sorted[i+1], nil
+		}
+		return sorted[i], nil
+	case PercentileMidpoint:
+		return calcLerp(sorted[i], sorted[i+1], 1, 2, round)
+	}
+
+	return calcLerp(sorted[i], sorted[i+1], uint64(r), percentileRankScale, round)
+}
+
+// calcCmp compares two values, returning -1, 0 or 1 like cmp.Compare.
+func calcCmp[T calcValue](a, b T) int {
+	switch a := any(a).(type) {
+	case UFix64:
+		return compare(a, any(b).(UFix64))
+	case Fix64:
+		return compare(a, any(b).(Fix64))
+	case UFix128:
+		return compare(a, any(b).(UFix128))
+	case Fix128:
+		return compare(a, any(b).(Fix128))
+	}
+
+	// Unreachable, the type constraint only allows the types above
+	debugPanic("unsupported type")
+	return 0
+}
+
+// calcLerp returns a + (b-a) * num/den, for a <= b and 0 < num < den, rounded as specified, or an
+// UnderflowError if a non-zero result rounds to zero.
+func calcLerp[T calcValue](a, b T, num, den uint64, round RoundingMode) (T, error) {
+	var res any
+	var err error
+
+	switch a := any(a).(type) {
+	case UFix64:
+		var r raw64
+		r, err = lerp64(raw64(a), raw64(any(b).(UFix64)), false, num, den, round)
+		res = UFix64(r)
+	case Fix64:
+		var r raw64
+		r, err = lerp64(raw64(a), raw64(any(b).(Fix64)), true, num, den, round)
+		res = Fix64(r)
+	case UFix128:
+		var r raw128
+		r, err = lerp128(raw128(a), raw128(any(b).(UFix128)), false, num, den, round)
+		res = UFix128(r)
+	case Fix128:
+		var r raw128
+		r, err = lerp128(raw128(a), raw128(any(b).(Fix128)), true, num, den, round)
+		res = Fix128(r)
+	default:
+		// Unreachable, the type constraint only allows the types above
+		debugPanic("unsupported type")
+		return b, nil
+	}
+
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return res.(T), nil
+}
+
+// lerp64 implements calcLerp for the raw values of the 64-bit types. The difference b-a always
+// fits in a UFix64, even for signed values, and the result is between a and b, so the step can be
+// computed with an unsigned FMD and added with wrapping arithmetic. Since `a` is representable,
+// rounding the result is the same as rounding the step, with the rounding mode adjusted by
+// lerpRound.
+func lerp64(a, b raw64, signed bool, num, den uint64, round RoundingMode) (raw64, error) {
+	diff := UFix64(b - a)
+
+	negative := false
+	if signed && isNeg64(a) {
+		// The result is negative if b is, or if the step is smaller than the magnitude of a.
+		negative = isNeg64(b) || b == 0
+		if !negative {
+			t, _ := lerpStep(diff.FMDX(UFix64(num), UFix64(den), RoundTowardZero))
+			negative = isNeg64(a + raw64(t))
+		}
+	}
+
+	step, exact := lerpStep(diff.FMDX(UFix64(num), UFix64(den), lerpRound(round, negative, a&1 == 1)))
+
+	res := a + raw64(step)
+	if res == 0 && !exact {
+		return 0, UnderflowError{}
+	}
+
+	return res, nil
+}
+
+// lerp128 implements calcLerp for the raw values of the 128-bit types, like lerp64.
+func lerp128(a, b raw128, signed bool, num, den uint64, round RoundingMode) (raw128, error) {
+	diff, _ := sub128(b, a, 0)
+	numFix, denFix := UFix128{Lo: raw64(num)}, UFix128{Lo: raw64(den)}
+
+	negative := false
+	if signed && isNeg64(a.Hi) {
+		// The result is negative if b is, or if the step is smaller than the magnitude of a.
+		negative = isNeg64(b.Hi) || b == raw128{}
+		if !negative {
+			t, _ := lerpStep(UFix128(diff).FMDX(numFix, denFix, RoundTowardZero))
+			sum, _ := add128(a, raw128(t), 0)
+			negative = isNeg64(sum.Hi)
+		}
+	}
+
+	step, exact := lerpStep(UFix128(diff).FMDX(numFix, denFix, lerpRound(round, negative, a.Lo&1 == 1)))
+
+	res, _ := add128(a, raw128(step), 0)
+	if res == (raw128{}) && !exact {
+		return raw128{}, UnderflowError{}
+	}
+
+	return res, nil
+}
+
+// lerpStep returns the result of FMDX for lerp64 and lerp128, treating an underflow as an inexact
+// zero step. The step can't overflow, since it's at most the difference it's computed from, and
+// the divisor is never zero.
+func lerpStep[T UFix64 | UFix128](step T, exact bool, err error) (T, bool) {
+	if errors.Is(err, ErrUnderflow) {
+		var zero T
+		return zero, false
+	}
+
+	return step, exact
+}
+
+// lerpRound returns the rounding mode for the (non-negative) step added to a representable value
+// that gives a result rounded as specified. The directed modes are the same for the step and the
+// result, the symmetric ones flip direction if the result is negative, and the even and odd tie
+// breaks swap if the representable value is odd.
+func lerpRound(round RoundingMode, negative, odd bool) RoundingMode {
+	switch round {
+	case RoundFloor:
+		return RoundTowardZero
+	case RoundCeil:
+		return RoundAwayFromZero
+	case RoundTowardZero:
+		if negative {
+			return RoundAwayFromZero
+		}
+	case RoundAwayFromZero:
+		if negative {
+			return RoundTowardZero
+		}
+	case RoundNearestHalfAway:
+		if negative {
+			return RoundNearestHalfTowardZero
+		}
+	case RoundNearestHalfTowardZero:
+		if negative {
+			return RoundNearestHalfAway
+		}
+	case RoundNearestHalfEven:
+		if odd {
+			return RoundNearestHalfOdd
+		}
+	case RoundNearestHalfOdd:
+		if odd {
+			return RoundNearestHalfEven
+		}
+	}
+
+	return round
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"strconv"
+)
+
+// This file contains the PostgreSQL binary NUMERIC codec, for use in custom types of drivers that
+// support the binary protocol (e.g. a pgx Codec), so values can be sent and received without
+// going through the text form.
+//
+// The binary NUMERIC format is a header of four 16-bit big-endian integers (the number of digits,
+// the weight of the first digit, the sign and the display scale), followed by the digits in base
+// 10000, most significant first. The weight is the power of 10000 of the first digit, and the
+// display scale is the number of decimal digits after the decimal point.
+
+// Signs of the binary NUMERIC format
+const (
+	pgNumericPos  = 0x0000
+	pgNumericNeg  = 0x4000
+	pgNumericNaN  = 0xc000
+	pgNumericPInf = 0xd000
+	pgNumericNInf = 0xf000
+)
+
+// AppendPgNumeric appends `a` in the PostgreSQL binary NUMERIC format to dst, and returns the
+// extended buffer. The display scale is the number of decimals of the type (i.e. 8), like a
+// NUMERIC(20, 8) column.
+func (a UFix64) AppendPgNumeric(dst []byte) []byte {
+	return appendPgNumeric(dst, false, 0, uint64(a), Fix64Decimals)
+}
+
+// AppendPgNumeric appends `a` in the PostgreSQL binary NUMERIC format to dst, see
+// UFix64.AppendPgNumeric.
+func (a Fix64) AppendPgNumeric(dst []byte) []byte {
+	aUnsigned, sign := a.Abs()
+	return appendPgNumeric(dst, sign < 0, 0, uint64(aUnsigned), Fix64Decimals)
+}
+
+// AppendPgNumeric appends `a` in the PostgreSQL binary NUMERIC format to dst, see
+// UFix64.AppendPgNumeric. The display scale is 24.
+func (a UFix128) AppendPgNumeric(dst []byte) []byte {
+	return appendPgNumeric(dst, false, uint64(a.Hi), uint64(a.Lo), Fix128Decimals)
+}
+
+// AppendPgNumeric appends `a` in the PostgreSQL binary NUMERIC format to dst, see
+// UFix64.AppendPgNumeric. The display scale is 24.
+func (a Fix128) AppendPgNumeric(dst []byte) []byte {
+	aUnsigned, sign := a.Abs()
+	return appendPgNumeric(dst, sign < 0, uint64(aUnsigned.Hi), uint64(aUnsigned.Lo), Fix128Decimals)
+}
+
+// UnmarshalPgNumeric sets `a` from a value in the PostgreSQL binary NUMERIC format. Any display
+// scale is accepted, but values that can't be represented exactly return an InexactError (or an
+// overflow error), and NaN returns an InvalidEncodingError. In all error cases `a` is unchanged.
+func (a *UFix64) UnmarshalPgNumeric(data []byte) error {
+	return unmarshalPgNumeric(a, data, textParser.ParseUFix64)
+}
+
+// UnmarshalPgNumeric sets `a` from a value in the PostgreSQL binary NUMERIC format, see
+// UFix64.UnmarshalPgNumeric.
+func (a *Fix64) UnmarshalPgNumeric(data []byte) error {
+	return unmarshalPgNumeric(a, data, textParser.ParseFix64)
+}
+
+// UnmarshalPgNumeric sets `a` from a value in the PostgreSQL binary NUMERIC format, see
+// UFix64.UnmarshalPgNumeric.
+func (a *UFix128) UnmarshalPgNumeric(data []byte) error {
+	return unmarshalPgNumeric(a, data, textParser.ParseUFix128)
+}
+
+// UnmarshalPgNumeric sets `a` from a value in the PostgreSQL binary NUMERIC format, see
+// UFix64.UnmarshalPgNumeric.
+func (a *Fix128) UnmarshalPgNumeric(data []byte) error {
+	return unmarshalPgNumeric(a, data, textParser.ParseFix128)
+}
+
+// appendPgNumeric encodes the 128-bit magnitude (hi, lo), divided by 10^decimals. The decimals
+// must be a multiple of four, so each base 10000 digit of the raw value is also a digit of the
+// result.
+func appendPgNumeric(dst []byte, neg bool, hi, lo uint64, decimals int) []byte {
+	// The base 10000 digits, from least to most significant
+	var buf [10]uint16
+	n := 0
+	for hi != 0 || lo != 0 {
+		var r uint64
+		hi, r = bits.Div64(0, hi, 10000)
+		lo, r = bits.Div64(r, lo, 10000)
+		buf[n] = uint16(r)
+		n++
+	}
+
+	weight := n - 1 - decimals/4
+
+	// Trailing zeros aren't stored.
+	digits := buf[:n]
+	for len(digits) > 0 && digits[0] == 0 {
+		digits = digits[1:]
+	}
+
+	sign := uint16(pgNumericPos)
+	if neg {
+		sign = pgNumericNeg
+	}
+	if len(digits) == 0 {
+		weight = 0
+	}
+
+	dst = binary.BigEndian.AppendUint16(dst, uint16(len(digits)))
+	dst = binary.BigEndian.AppendUint16(dst, uint16(int16(weight)))
+	dst = binary.BigEndian.AppendUint16(dst, sign)
+	dst = binary.BigEndian.AppendUint16(dst, uint16(decimals))
+	for i := len(digits) - 1; i >= 0; i-- {
+		dst = binary.BigEndian.AppendUint16(dst, digits[i])
+	}
+
+	return dst
+}
+
+func unmarshalPgNumeric[T any](dst *T, data []byte, parse func(string) (T, error)) error {
+	if len(data) < 8 {
+		return InvalidEncodingError{}
+	}
+
+	ndigits := int(binary.BigEndian.Uint16(data))
+	weight := int(int16(binary.BigEndian.Uint16(data[2:])))
+	sign := binary.BigEndian.Uint16(data[4:])
+	data = data[8:]
+
+	if len(data) != 2*ndigits {
+		return InvalidEncodingError{}
+	}
+
+	// Build the equivalent decimal string, and parse it, so the range and exactness checks are
+	// the same as for the text form.
+	s := make([]byte, 0, 4*ndigits+16)
+
+	switch sign {
+	case pgNumericPos:
+	case pgNumericNeg:
+		s = append(s, '-')
+	case pgNumericPInf:
+		return PositiveOverflowError{}
+	case pgNumericNInf:
+		return NegativeOverflowError{}
+	case pgNumericNaN:
+		return InvalidEncodingError{}
+	default:
+		return InvalidEncodingError{}
+	}
+
+	if ndigits == 0 {
+		s = append(s, '0')
+	}
+	for i := 0; i < ndigits; i++ {
+		d := binary.BigEndian.Uint16(data[2*i:])
+		if d >= 10000 {
+			return InvalidEncodingError{}
+		}
+		s = append(s, '0'+byte(d/1000), '0'+byte(d/100%10), '0'+byte(d/10%10), '0'+byte(d%10))
+	}
+
+	s = append(s, 'e')
+	s = strconv.AppendInt(s, int64(4*(weight-ndigits+1)), 10)
+
+	res, err := parse(string(s))
+	if err != nil {
+		return err
+	}
+
+	*dst = res
+	return nil
+}
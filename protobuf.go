@@ -0,0 +1,166 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import "encoding/binary"
+
+// This file implements the canonical protobuf representation of the fixed-point types, as
+// defined in proto/fixedpoint.proto. The encoding is implemented here directly, so it can be
+// used without generated code (e.g. for a bytes field, or with a custom codec), and is identical
+// to what the generated code for those messages produces.
+
+// MarshalProto encodes `a` as the onflow.fixedpoint.UFix64 protobuf message.
+func (a UFix64) MarshalProto() ([]byte, error) {
+	return appendProtoVarint(nil, 1, uint64(a)), nil
+}
+
+// MarshalProto encodes `a` as the onflow.fixedpoint.Fix64 protobuf message.
+func (a Fix64) MarshalProto() ([]byte, error) {
+	// sint64 uses the ZigZag encoding, so small negative values are small.
+	raw := int64(a)
+	return appendProtoVarint(nil, 1, uint64(raw<<1)^uint64(raw>>63)), nil
+}
+
+// MarshalProto encodes `a` as the onflow.fixedpoint.UFix128 protobuf message.
+func (a UFix128) MarshalProto() ([]byte, error) {
+	return appendProtoVarint(appendProtoVarint(nil, 1, uint64(a.Hi)), 2, uint64(a.Lo)), nil
+}
+
+// MarshalProto encodes `a` as the onflow.fixedpoint.Fix128 protobuf message.
+func (a Fix128) MarshalProto() ([]byte, error) {
+	return appendProtoVarint(appendProtoVarint(nil, 1, uint64(a.Hi)), 2, uint64(a.Lo)), nil
+}
+
+// UnmarshalProto decodes the onflow.fixedpoint.UFix64 protobuf message into `a`. Like generated
+// code, it ignores unknown fields. It returns an InvalidEncodingError, leaving `a` unchanged, if
+// the message is malformed.
+func (a *UFix64) UnmarshalProto(data []byte) error {
+	fields, err := readProtoFields(data)
+	if err != nil {
+		return err
+	}
+
+	*a = UFix64(fields[0])
+	return nil
+}
+
+// UnmarshalProto decodes the onflow.fixedpoint.Fix64 protobuf message into `a`, see
+// UFix64.UnmarshalProto.
+func (a *Fix64) UnmarshalProto(data []byte) error {
+	fields, err := readProtoFields(data)
+	if err != nil {
+		return err
+	}
+
+	*a = Fix64(fields[0]>>1 ^ -(fields[0] & 1))
+	return nil
+}
+
+// UnmarshalProto decodes the onflow.fixedpoint.UFix128 protobuf message into `a`, see
+// UFix64.UnmarshalProto.
+func (a *UFix128) UnmarshalProto(data []byte) error {
+	fields, err := readProtoFields(data)
+	if err != nil {
+		return err
+	}
+
+	*a = UFix128FromRaw(fields[0], fields[1])
+	return nil
+}
+
+// UnmarshalProto decodes the onflow.fixedpoint.Fix128 protobuf message into `a`, see
+// UFix64.UnmarshalProto.
+func (a *Fix128) UnmarshalProto(data []byte) error {
+	fields, err := readProtoFields(data)
+	if err != nil {
+		return err
+	}
+
+	*a = Fix128FromRaw(fields[0], fields[1])
+	return nil
+}
+
+// Protobuf wire types
+const (
+	protoVarint  = 0
+	protoFixed64 = 1
+	protoBytes   = 2
+	protoFixed32 = 5
+)
+
+// appendProtoVarint appends a varint field. As in proto3, zero values aren't encoded at all.
+func appendProtoVarint(dst []byte, field int, v uint64) []byte {
+	if v == 0 {
+		return dst
+	}
+
+	dst = binary.AppendUvarint(dst, uint64(field<<3|protoVarint))
+	return binary.AppendUvarint(dst, v)
+}
+
+// readProtoFields reads the varint fields 1 and 2 of a message, skipping any other fields. Missing
+// fields are zero, and if a field is repeated the last value wins, as in proto3.
+func readProtoFields(data []byte) ([2]uint64, error) {
+	var fields [2]uint64
+
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return fields, InvalidEncodingError{}
+		}
+		data = data[n:]
+
+		field, wireType := key>>3, key&7
+		if field == 0 {
+			return fields, InvalidEncodingError{}
+		}
+
+		switch wireType {
+		case protoVarint:
+			v, n := binary.Uvarint(data)
+			if n <= 0 {
+				return fields, InvalidEncodingError{}
+			}
+			data = data[n:]
+
+			if field <= 2 {
+				fields[field-1] = v
+			}
+			continue
+		case protoFixed64:
+			n = 8
+		case protoFixed32:
+			n = 4
+		case protoBytes:
+			length, m := binary.Uvarint(data)
+			if m <= 0 || length > uint64(len(data)-m) {
+				return fields, InvalidEncodingError{}
+			}
+			n = m + int(length)
+		default:
+			return fields, InvalidEncodingError{}
+		}
+
+		// Fields 1 and 2 must be varints, anything else is skipped.
+		if field <= 2 || n > len(data) {
+			return fields, InvalidEncodingError{}
+		}
+		data = data[n:]
+	}
+
+	return fields, nil
+}
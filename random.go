@@ -0,0 +1,82 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import "math/rand/v2"
+
+// The source of random bits used by RoundStochastic. Defaults to the global source from
+// math/rand/v2, which is safe for concurrent use and randomly seeded.
+var stochasticUint64 = rand.Uint64
+
+// SetStochasticSource sets the source of randomness used by RoundStochastic. Passing nil restores
+// the default source (the global source from math/rand/v2). Tests can pass a deterministic source
+// (e.g. rand.NewPCG(1, 2)) to get reproducible results.
+//
+// NOTE: The source is shared by all operations in the package, so this function must not be called
+// concurrently with any operation using RoundStochastic. Likewise, the source itself must be safe
+// for concurrent use if RoundStochastic is used from multiple goroutines.
+func SetStochasticSource(src rand.Source) {
+	if src == nil {
+		stochasticUint64 = rand.Uint64
+	} else {
+		stochasticUint64 = src.Uint64
+	}
+}
+
+// randBelow64 returns a uniformly distributed random value in the range [0, b), b must be non-zero.
+func randBelow64(b raw64) raw64 {
+	// Simple rejection sampling: generate random values with the same bit length as b, and
+	// discard any that are too large. This takes less than two attempts on average.
+	mask := ushiftRight64(^raw64Zero, leadingZeroBits64(b))
+
+	for {
+		x := raw64(stochasticUint64()) & mask
+
+		if ult64(x, b) {
+			return x
+		}
+	}
+}
+
+// randBelow128 returns a uniformly distributed random value in the range [0, b), b must be non-zero.
+func randBelow128(b raw128) raw128 {
+	// Same approach as randBelow64
+	mask := ushiftRight128(raw128{^raw64Zero, ^raw64Zero}, leadingZeroBits128(b))
+
+	for {
+		x := raw128{raw64(stochasticUint64()) & mask.Hi, raw64(stochasticUint64()) & mask.Lo}
+
+		if ult128(x, b) {
+			return x
+		}
+	}
+}
+
+// randBelow256 returns a uniformly distributed random value in the range [0, b), b must be non-zero.
+func randBelow256(b raw256) raw256 {
+	// Same approach as randBelow64
+	mask := ushiftRight256(raw256{raw128{^raw64Zero, ^raw64Zero}, raw128{^raw64Zero, ^raw64Zero}}, leadingZeroBits256(b))
+	for {
+		x := raw256{
+			raw128{raw64(stochasticUint64()) & mask.Hi.Hi, raw64(stochasticUint64()) & mask.Hi.Lo},
+			raw128{raw64(stochasticUint64()) & mask.Lo.Hi, raw64(stochasticUint64()) & mask.Lo.Lo},
+		}
+		if ult256(x, b) {
+			return x
+		}
+	}
+}
@@ -0,0 +1,266 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+var raw256Zero = raw256{}
+
+// This file contains the raw256 primitives used by UFix256 and Fix256. They're built out of the
+// raw128 functions in raw128.go, treating each raw128 as a single "digit", in the same way that
+// the raw128 functions are built out of raw64 values.
+
+func add256(a, b raw256, carry uint64) (sum raw256, carryOut uint64) {
+	sum.Lo, carry = add128(a.Lo, b.Lo, carry)
+	sum.Hi, carryOut = add128(a.Hi, b.Hi, carry)
+	return
+}
+
+func sub256(a, b raw256, borrow uint64) (diff raw256, borrowOut uint64) {
+	diff.Lo, borrow = sub128(a.Lo, b.Lo, borrow)
+	diff.Hi, borrowOut = sub128(a.Hi, b.Hi, borrow)
+	return
+}
+
+// A utility function to perform 256x256 multiplication with a 512-bit result.
+func mul256(a, b raw256) (hi, lo raw256) {
+	// If both operands fit into 128 bits, a single 128x128 multiplication is enough.
+	if isZero128(a.Hi) && isZero128(b.Hi) {
+		lo.Hi, lo.Lo = mul128(a.Lo, b.Lo)
+		return
+	}
+
+	// Same long multiplication as mul128, with a base of 2^128:
+	//   a * b = (aH * bH) * B^2 + ((aH * bL) + (aL * bH)) * B + (aL * bL)
+	var u, v1, v2, w raw256
+	u.Hi, u.Lo = mul128(a.Hi, b.Hi)
+	v1.Hi, v1.Lo = mul128(a.Hi, b.Lo)
+	v2.Hi, v2.Lo = mul128(a.Lo, b.Hi)
+	w.Hi, w.Lo = mul128(a.Lo, b.Lo)
+
+	v, vCarry := add256(v1, v2, 0)
+
+	var midCarry, hiCarry uint64
+	lo.Lo = w.Lo
+	lo.Hi, midCarry = add128(v.Lo, w.Hi, 0)
+	hi.Lo, hiCarry = add128(u.Lo, v.Hi, midCarry)
+	hi.Hi, _ = add128(u.Hi, raw128{0, raw64(vCarry)}, hiCarry)
+
+	return
+}
+
+// div256 divides the 512-bit value (hi, lo) by `y`. The caller must make sure that hi < y, so that
+// the quotient fits in 256 bits.
+func div256(hi, lo, y raw256) (quo raw256, rem raw256) {
+	if isZero256(y) {
+		debugPanic("div256: division by zero")
+		return raw256Zero, raw256Zero
+	}
+
+	if !ult256(hi, y) {
+		debugPanic("div256: overflow")
+		return raw256Zero, raw256Zero
+	}
+
+	// Special case: the denominator fits in 128 bits, so the numerator has at most three
+	// significant 128-bit words, and two "3 by 1" divisions are enough (the same approach as
+	// div192by64, one level up).
+	if isZero128(y.Hi) {
+		var r raw128
+		quo.Hi, r = div128(hi.Lo, lo.Hi, y.Lo)
+		quo.Lo, r = div128(r, lo.Lo, y.Lo)
+		return quo, raw256{raw128Zero, r}
+	}
+
+	// Otherwise, fall back to binary long division. This is much slower, but it's only needed for
+	// dividing by values of 2^128 or more, e.g. in Div and FMD with very large divisors.
+	rem = hi
+	for i := 0; i < 256; i++ {
+		// Shift the next bit of the numerator into the remainder. If a bit falls off the top of
+		// the remainder, it's at least 2^256, which is larger than y.
+		top := uint64(rem.Hi.Hi >> 63)
+		rem = shiftLeft256(rem, 1)
+		rem.Lo.Lo |= lo.Hi.Hi >> 63
+		lo = shiftLeft256(lo, 1)
+		quo = shiftLeft256(quo, 1)
+
+		if top != 0 || !ult256(rem, y) {
+			rem, _ = sub256(rem, y, 0)
+			quo.Lo.Lo |= 1
+		}
+	}
+
+	return quo, rem
+}
+
+// usqrt512 returns the integer square root s of the 512-bit value (hi, lo), i.e. the largest s
+// with s*s <= (hi, lo), and the remainder (hi, lo) - s*s. The remainder is at most 2s, so it's
+// returned as its low 256 bits and the bit above them. The caller must make sure that the value is
+// below 2^511, so that the divisions below can't overflow.
+func usqrt512(hi, lo raw256) (s, rem raw256, remTop uint64) {
+	if isZero256(hi) && isZero256(lo) {
+		return raw256Zero, raw256Zero, 0
+	}
+
+	// Start with a power of two that's at least the root, and use Newton-Raphson steps, rounded
+	// down, which decrease monotonically until they reach it.
+	n := leadingZeroBits256(hi)
+	if isZero256(hi) {
+		n += leadingZeroBits256(lo)
+	}
+
+	s = raw256(UFix256Max)
+	if half := (512 - n + 1) / 2; half < 256 {
+		s = shiftLeft256(raw256{Lo: raw128{Lo: 1}}, half)
+	}
+
+	for {
+		// s is at least the root, which is more than hi for values below 2^511, so the quotient
+		// fits in 256 bits.
+		quo, _ := div256(hi, lo, s)
+
+		// The sum can carry out of 256 bits, so the carry is shifted back in as the top bit.
+		sum, carry := add256(s, quo, 0)
+		next := ushiftRight256(sum, 1)
+		next.Hi.Hi |= raw64(carry << 63)
+
+		if !ult256(next, s) {
+			break
+		}
+		s = next
+	}
+
+	sqHi, sqLo := mul256(s, s)
+	rem, borrow := sub256(lo, sqLo, 0)
+	remHi, _ := sub256(hi, sqHi, borrow)
+
+	return s, rem, uint64(remHi.Lo.Lo)
+}
+
+func mod256(a, b raw256) raw256 {
+	// Compute the modulus of two raw256 values, treating them as unsigned integers.
+	if isZero256(b) {
+		debugPanic("mod256: division by zero")
+		return raw256Zero
+	}
+
+	_, rem := div256(raw256Zero, a, b)
+	return rem
+}
+
+func neg256(a raw256) raw256 {
+	res, _ := sub256(raw256Zero, a, 0)
+	return res
+}
+
+// ushouldRound256 is the 256-bit version of ushouldRound128.
+func ushouldRound256(q, r, b raw256, round RoundingMode) bool {
+	switch round {
+	case RoundTowardZero, RoundFloor:
+		return false
+	case RoundAwayFromZero, RoundCeil:
+		return !isZero256(r)
+	case RoundNearestHalfAway, RoundNearestHalfEven, RoundNearestHalfTowardZero, RoundNearestHalfOdd:
+		// See ushouldRound128 for why the top bit is checked before doubling.
+		if isNeg256(r) {
+			return true
+		}
+
+		doubleR := shiftLeft256(r, 1)
+
+		if ult256(b, doubleR) {
+			return true
+		} else if ult256(doubleR, b) {
+			return false
+		} else {
+			switch round {
+			case RoundNearestHalfAway:
+				return true
+			case RoundNearestHalfTowardZero:
+				return false
+			case RoundNearestHalfEven:
+				return q.Lo.Lo&1 == 1
+			default:
+				return q.Lo.Lo&1 == 0
+			}
+		}
+	case RoundStochastic:
+		// Round up with a probability of r/b.
+		return !isZero256(r) && ult256(randBelow256(b), r)
+	default:
+		// See ushouldRound64
+		return false
+	}
+}
+
+func leadingZeroBits256(a raw256) uint64 {
+	if isZero128(a.Hi) {
+		return leadingZeroBits128(a.Lo) + 128
+	} else {
+		return leadingZeroBits128(a.Hi)
+	}
+}
+
+func isZero256(a raw256) bool {
+	return isZero128(a.Hi) && isZero128(a.Lo)
+}
+
+func isNeg256(a raw256) bool {
+	return isNeg128(a.Hi)
+}
+
+func ult256(a, b raw256) bool {
+	if isEqual128(a.Hi, b.Hi) {
+		return ult128(a.Lo, b.Lo)
+	} else {
+		return ult128(a.Hi, b.Hi)
+	}
+}
+
+func slt256(a, b raw256) bool {
+	if isEqual128(a.Hi, b.Hi) {
+		return ult128(a.Lo, b.Lo)
+	} else {
+		return slt128(a.Hi, b.Hi)
+	}
+}
+
+func isEqual256(a, b raw256) bool {
+	return isEqual128(a.Hi, b.Hi) && isEqual128(a.Lo, b.Lo)
+}
+
+func shiftLeft256(a raw256, shift uint64) raw256 {
+	if shift >= 128 {
+		return raw256{Hi: shiftLeft128(a.Lo, shift-128), Lo: raw128Zero}
+	} else if shift == 0 {
+		return a
+	}
+
+	hi := shiftLeft128(a.Hi, shift)
+	carry := ushiftRight128(a.Lo, 128-shift)
+	return raw256{Hi: raw128{hi.Hi | carry.Hi, hi.Lo | carry.Lo}, Lo: shiftLeft128(a.Lo, shift)}
+}
+
+func ushiftRight256(a raw256, shift uint64) raw256 {
+	if shift >= 128 {
+		return raw256{Hi: raw128Zero, Lo: ushiftRight128(a.Hi, shift-128)}
+	} else if shift == 0 {
+		return a
+	}
+
+	lo := ushiftRight128(a.Lo, shift)
+	carry := shiftLeft128(a.Hi, 128-shift)
+	return raw256{Hi: ushiftRight128(a.Hi, shift), Lo: raw128{lo.Hi | carry.Hi, lo.Lo | carry.Lo}}
+}
@@ -78,11 +78,11 @@ func neg64(a raw64) raw64 {
 
 func ushouldRound64(q, r, b raw64, round RoundingMode) bool {
 	switch round {
-	case RoundTowardZero:
+	case RoundTowardZero, RoundFloor:
 		return false // Always truncate towards zero, no rounding.
-	case RoundAwayFromZero:
+	case RoundAwayFromZero, RoundCeil:
 		return r != 0 // Round away from zero, so if there's any remainder, round up.
-	case RoundNearestHalfAway, RoundNearestHalfEven:
+	case RoundNearestHalfAway, RoundNearestHalfEven, RoundNearestHalfTowardZero, RoundNearestHalfOdd:
 		// Determing if a particular remainder results in rounding isn't as simple
 		// as just checking if r >= b/2, because dividing b by two *loses precision*.
 		// A more accurate solution would be to multiply the remainder by 2 and compare
@@ -107,16 +107,25 @@ func ushouldRound64(q, r, b raw64, round RoundingMode) bool {
 			// The remainder is strictly smaller than half b, so we round down.
 			return false
 		} else {
-			// If doubleR == b, we have to round away from zero for RoundNearestHalfAway,
-			// and round to the nearest even number for RoundNearestHalfEven.
-			if round == RoundNearestHalfAway {
+			// If doubleR == b, we have a tie, which is broken differently by each mode.
+			switch round {
+			case RoundNearestHalfAway:
 				return true
-			} else {
+			case RoundNearestHalfTowardZero:
+				return false
+			case RoundNearestHalfEven:
 				return q&1 == 1
+			default:
+				return q&1 == 0
 			}
 		}
+	case RoundStochastic:
+		// Round up with a probability of r/b.
+		return r != 0 && ult64(randBelow64(b), r)
 	default:
-		panic("unsupported rounding mode")
+		// Public entry points validate the rounding mode, the only way to get here is from an
+		// operation that can't report an error, which treats invalid modes as RoundTowardZero.
+		return false
 	}
 }
 
@@ -174,3 +183,23 @@ func sshiftRight64(a raw64, shift uint64) raw64 {
 	// Shift right by a number of bits, treating it as an signed integer.
 	return raw64(int64(a) >> shift)
 }
+
+// pow10Table64 holds every power of ten that fits in 64 bits, i.e. 10^0 through 10^19.
+var pow10Table64 = func() []raw64 {
+	table := []raw64{1}
+	for {
+		hi, lo := mul64(table[len(table)-1], 10)
+		if hi != 0 {
+			return table
+		}
+		table = append(table, lo)
+	}
+}()
+
+func pow10Raw64(n uint64) (raw64, bool) {
+	// Return 10^n, or false if it doesn't fit in 64 bits.
+	if n >= uint64(len(pow10Table64)) {
+		return raw64Zero, false
+	}
+	return pow10Table64[n], true
+}
@@ -0,0 +1,190 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"io"
+	"math/big"
+	"strconv"
+)
+
+// This file contains RLP (Ethereum's Recursive Length Prefix encoding) helpers for bridging
+// amounts to and from the EVM. An amount is encoded as a two-item list: the value as a scaled,
+// non-negative integer, and the number of decimals it's scaled by. For example, 1.5 with 18
+// decimals (the usual scale of ERC-20 tokens) is [1500000000000000000, 18].
+//
+// Only the unsigned types are supported, since RLP has no representation for negative integers.
+
+// EncodeRLP writes the RLP encoding of `a` to w, using 8 decimals. It matches the Encoder
+// interface of github.com/ethereum/go-ethereum/rlp, so values can be passed to that package
+// directly. Use AppendRLP to encode with a different number of decimals.
+func (a UFix64) EncodeRLP(w io.Writer) error {
+	data, _ := a.AppendRLP(nil, Fix64Decimals)
+	_, err := w.Write(data)
+	return err
+}
+
+// EncodeRLP writes the RLP encoding of `a` to w, using 24 decimals, see UFix64.EncodeRLP.
+func (a UFix128) EncodeRLP(w io.Writer) error {
+	data, _ := a.AppendRLP(nil, Fix128Decimals)
+	_, err := w.Write(data)
+	return err
+}
+
+// AppendRLP appends the RLP encoding of `a`, scaled to the given number of decimals, to dst. It
+// returns an InexactError if `a` has non-zero digits beyond that many decimals.
+func (a UFix64) AppendRLP(dst []byte, decimals uint8) ([]byte, error) {
+	return appendRLP(dst, new(big.Int).SetUint64(uint64(a)), Fix64Decimals, decimals)
+}
+
+// AppendRLP appends the RLP encoding of `a`, scaled to the given number of decimals, to dst, see
+// UFix64.AppendRLP.
+func (a UFix128) AppendRLP(dst []byte, decimals uint8) ([]byte, error) {
+	value := new(big.Int).SetUint64(uint64(a.Hi))
+	value.Lsh(value, 64).Or(value, new(big.Int).SetUint64(uint64(a.Lo)))
+	return appendRLP(dst, value, Fix128Decimals, decimals)
+}
+
+// DecodeRLPUFix64 decodes an RLP encoded amount with any number of decimals into a UFix64. It
+// returns an InvalidEncodingError if the data isn't a single canonical RLP amount, and the usual
+// errors (e.g. overflow, or InexactError) if the amount can't be represented exactly.
+func DecodeRLPUFix64(data []byte) (UFix64, error) {
+	s, err := decodeRLP(data)
+	if err != nil {
+		return UFix64Zero, err
+	}
+	return textParser.ParseUFix64(s)
+}
+
+// DecodeRLPUFix128 decodes an RLP encoded amount with any number of decimals into a UFix128, see
+// DecodeRLPUFix64.
+func DecodeRLPUFix128(data []byte) (UFix128, error) {
+	s, err := decodeRLP(data)
+	if err != nil {
+		return UFix128Zero, err
+	}
+	return textParser.ParseUFix128(s)
+}
+
+func appendRLP(dst []byte, value *big.Int, typeDecimals int, decimals uint8) ([]byte, error) {
+	shift := int64(decimals) - int64(typeDecimals)
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(max(shift, -shift)), nil)
+
+	if shift >= 0 {
+		value.Mul(value, pow)
+	} else if _, rem := value.QuoRem(value, pow, new(big.Int)); rem.Sign() != 0 {
+		return dst, InexactError{}
+	}
+
+	var payload []byte
+	payload = appendRLPString(payload, value.Bytes())
+	payload = appendRLPString(payload, new(big.Int).SetUint64(uint64(decimals)).Bytes())
+
+	dst = appendRLPHeader(dst, 0xc0, len(payload))
+	return append(dst, payload...), nil
+}
+
+// appendRLPString appends an RLP string item. Integers are encoded as their big-endian bytes
+// without leading zeros, so zero is the empty string.
+func appendRLPString(dst []byte, s []byte) []byte {
+	if len(s) == 1 && s[0] < 0x80 {
+		return append(dst, s[0])
+	}
+
+	dst = appendRLPHeader(dst, 0x80, len(s))
+	return append(dst, s...)
+}
+
+// appendRLPHeader appends the header of a string (offset 0x80) or a list (offset 0xc0).
+func appendRLPHeader(dst []byte, offset byte, n int) []byte {
+	if n <= 55 {
+		return append(dst, offset+byte(n))
+	}
+
+	length := new(big.Int).SetInt64(int64(n)).Bytes()
+	dst = append(dst, offset+55+byte(len(length)))
+	return append(dst, length...)
+}
+
+// decodeRLP decodes an RLP amount into a decimal string in scientific notation, e.g. "15e-1",
+// which the parser can convert exactly to any of the types.
+func decodeRLP(data []byte) (string, error) {
+	list, rest, ok := readRLPItem(data, 0xc0)
+	if !ok || len(rest) != 0 {
+		return "", InvalidEncodingError{}
+	}
+
+	value, list, ok := readRLPInteger(list)
+	if !ok {
+		return "", InvalidEncodingError{}
+	}
+
+	decimals, list, ok := readRLPInteger(list)
+	if !ok || len(list) != 0 || !decimals.IsUint64() || decimals.Uint64() > maxExponent {
+		return "", InvalidEncodingError{}
+	}
+
+	return value.String() + "e-" + strconv.FormatUint(decimals.Uint64(), 10), nil
+}
+
+// readRLPInteger reads a canonical RLP integer, i.e. a string without leading zeros.
+func readRLPInteger(data []byte) (*big.Int, []byte, bool) {
+	if len(data) > 0 && data[0] < 0x80 {
+		return new(big.Int).SetUint64(uint64(data[0])), data[1:], true
+	}
+
+	s, rest, ok := readRLPItem(data, 0x80)
+	if !ok || (len(s) > 0 && s[0] == 0) || (len(s) == 1 && s[0] < 0x80) {
+		return nil, nil, false
+	}
+
+	return new(big.Int).SetBytes(s), rest, true
+}
+
+// readRLPItem reads the header of a string (offset 0x80) or a list (offset 0xc0), and returns its
+// payload and the remaining data. Non-canonical headers are rejected.
+func readRLPItem(data []byte, offset byte) ([]byte, []byte, bool) {
+	if len(data) == 0 || data[0] < offset || data[0]-offset >= 0x40 {
+		return nil, nil, false
+	}
+
+	n := uint64(data[0] - offset)
+	data = data[1:]
+
+	if n > 55 {
+		lenLen := int(n - 55)
+		if len(data) < lenLen || lenLen > 8 || data[0] == 0 {
+			return nil, nil, false
+		}
+
+		n = 0
+		for _, b := range data[:lenLen] {
+			n = n<<8 | uint64(b)
+		}
+		data = data[lenLen:]
+
+		if n <= 55 {
+			return nil, nil, false
+		}
+	}
+
+	if n > uint64(len(data)) {
+		return nil, nil, false
+	}
+
+	return data[:n], data[n:], true
+}
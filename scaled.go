@@ -0,0 +1,293 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import "math/big"
+
+// This file contains Fix, a signed 128-bit fixed-point type whose number of decimals is a type
+// parameter, for applications that need a scale other than 8 or 24 (e.g. 6 decimals for USDC, or
+// 18 for ETH). All of the arithmetic is delegated to Fix128: the raw values are the same 128-bit
+// integers, and only the multiplication and division need to know the scale, which FMD handles
+// without intermediate rounding. For raw values at scales that aren't known at compile time,
+// RescaleRaw, MulWithScales, and DivWithScales do the same on plain 128-bit integers.
+
+// Scale defines the number of decimals of a Fix type. Implementations should be empty structs
+// whose Decimals method returns a constant between 0 and 38, e.g.:
+//
+//	type Scale4 struct{}
+//
+//	func (Scale4) Decimals() int { return 4 }
+type Scale interface {
+	Decimals() int
+}
+
+// Predefined scales for common token and currency precisions.
+type (
+	Scale2  struct{} // Cents, e.g. fiat currencies
+	Scale6  struct{} // e.g. USDC and USDT
+	Scale9  struct{} // e.g. SOL and nanoseconds
+	Scale18 struct{} // e.g. ETH and most ERC-20 tokens
+)
+
+func (Scale2) Decimals() int  { return 2 }
+func (Scale6) Decimals() int  { return 6 }
+func (Scale9) Decimals() int  { return 9 }
+func (Scale18) Decimals() int { return 18 }
+
+// Fix is a signed fixed-point number with the number of decimals given by S, stored as a 128-bit
+// raw value, e.g. Fix[Scale6]. The range is ±2^127 / 10^decimals.
+type Fix[S Scale] raw128
+
+var _ FixedPoint[Fix[Scale6]] = Fix[Scale6]{}
+
+// NewFix returns the Fix with the given raw 128-bit value, see NewFix128.
+func NewFix[S Scale](hi, lo uint64) Fix[S] {
+	return Fix[S]{
+		Hi: raw64(hi),
+		Lo: raw64(lo),
+	}
+}
+
+// ParseFix parses a decimal string into a Fix, rounding any extra decimals as specified, see
+// ParseFix128.
+func ParseFix[S Scale](s string, round RoundingMode) (Fix[S], error) {
+	return parseFix[S](ParseOptions{Rounding: round}, s)
+}
+
+// MustParseFix parses an exact decimal string into a Fix, see MustParseUFix64.
+func MustParseFix[S Scale](s string) Fix[S] {
+	return mustParse(s, func(s string) (Fix[S], error) { return parseFix[S](textParser, s) })
+}
+
+// FixFromFix128 converts a Fix128 to a Fix, rounding as specified if S has fewer than 24
+// decimals. It returns an overflow error if the value is out of range, and an UnderflowError if
+// a non-zero value rounds to zero.
+func FixFromFix128[S Scale](a Fix128, round RoundingMode) (Fix[S], error) {
+	res, err := a.FMD(Fix128(scaleOne[S]()), Fix128(pow10Table128[Fix128Decimals]), round)
+	return Fix[S](res), err
+}
+
+// ToFix128 converts `a` to a Fix128, rounding as specified if S has more than 24 decimals, see
+// FixFromFix128.
+func (a Fix[S]) ToFix128(round RoundingMode) (Fix128, error) {
+	return Fix128(a).FMD(Fix128(pow10Table128[Fix128Decimals]), Fix128(scaleOne[S]()), round)
+}
+
+// Raw returns the raw 128-bit value of `a`, i.e. `a` scaled by 10^decimals, as a Fix128.
+func (a Fix[S]) Raw() Fix128 { return Fix128(a) }
+
+// Comparisons, see Fix128.
+func (a Fix[S]) Eq(b Fix[S]) bool  { return Fix128(a).Eq(Fix128(b)) }
+func (a Fix[S]) Lt(b Fix[S]) bool  { return Fix128(a).Lt(Fix128(b)) }
+func (a Fix[S]) Gt(b Fix[S]) bool  { return Fix128(a).Gt(Fix128(b)) }
+func (a Fix[S]) Lte(b Fix[S]) bool { return Fix128(a).Lte(Fix128(b)) }
+func (a Fix[S]) Gte(b Fix[S]) bool { return Fix128(a).Gte(Fix128(b)) }
+
+// IsZero returns true if `a` is zero.
+func (a Fix[S]) IsZero() bool { return Fix128(a).IsZero() }
+
+// IsInteger returns true if `a` is a whole number, i.e. it has no fractional part.
+func (a Fix[S]) IsInteger() bool {
+	aUnsigned, _ := Fix128(a).Abs()
+	return isZero128(mod128(raw128(aUnsigned), scaleOne[S]()))
+}
+
+// Iota returns the smallest positive value representable by the type of `a`.
+func (Fix[S]) Iota() Fix[S] { return Fix[S](Fix128Iota) }
+
+// Scale returns the scale factor of the type, i.e. 10^decimals, see UFix64.Scale.
+func (Fix[S]) Scale() *big.Int { return pow10Big(int64(scaleDecimals[S]())) }
+
+// Decimals returns the number of decimal places the type can represent.
+func (Fix[S]) Decimals() int { return scaleDecimals[S]() }
+
+// MaxValue returns the largest value representable by the type.
+func (Fix[S]) MaxValue() Fix[S] { return Fix[S](Fix128Max) }
+
+// MinValue returns the smallest (i.e. most negative) value representable by the type.
+func (Fix[S]) MinValue() Fix[S] { return Fix[S](Fix128Min) }
+
+// One returns the value 1.0 in the type.
+func (Fix[S]) One() Fix[S] { return Fix[S](scaleOne[S]()) }
+
+// Add returns the sum of `a` and `b`, or an error on overflow.
+func (a Fix[S]) Add(b Fix[S]) (Fix[S], error) {
+	res, err := Fix128(a).Add(Fix128(b))
+	return Fix[S](res), err
+}
+
+// Sub returns the difference of `a` and `b`, or an error on overflow.
+func (a Fix[S]) Sub(b Fix[S]) (Fix[S], error) {
+	res, err := Fix128(a).Sub(Fix128(b))
+	return Fix[S](res), err
+}
+
+// Mul returns `a*b`, rounded as specified, see Fix128.Mul.
+func (a Fix[S]) Mul(b Fix[S], round RoundingMode) (Fix[S], error) {
+	return a.FMD(b, a.One(), round)
+}
+
+// Div returns `a/b`, rounded as specified, see Fix128.Div.
+func (a Fix[S]) Div(b Fix[S], round RoundingMode) (Fix[S], error) {
+	return a.FMD(a.One(), b, round)
+}
+
+// FMD returns `a*b/c` without intermediate rounding, see Fix128.FMD. The scales cancel out, so
+// this is the same as FMD on the raw values.
+func (a Fix[S]) FMD(b, c Fix[S], round RoundingMode) (Fix[S], error) {
+	res, err := Fix128(a).FMD(Fix128(b), Fix128(c), round)
+	return Fix[S](res), err
+}
+
+// Mod returns the remainder of `a/b`, see Fix128.Mod.
+func (a Fix[S]) Mod(b Fix[S]) (Fix[S], error) {
+	res, err := Fix128(a).Mod(Fix128(b))
+	return Fix[S](res), err
+}
+
+// Neg returns `-a`, or an error on overflow.
+func (a Fix[S]) Neg() (Fix[S], error) {
+	res, err := Fix128(a).Neg()
+	return Fix[S](res), err
+}
+
+// Abs returns the absolute value of `a` as the raw magnitude, along with its sign, see
+// Fix128.Abs.
+func (a Fix[S]) Abs() (UFix128, int64) { return Fix128(a).Abs() }
+
+// String returns `a` in the canonical decimal form, see Fix128.String.
+func (a Fix[S]) String() string { return string(a.Append(nil)) }
+
+// Append appends the same text as String to dst, and returns the extended buffer.
+func (a Fix[S]) Append(dst []byte) []byte {
+	aUnsigned, sign := Fix128(a).Abs()
+	return appendDecimal(dst, sign < 0, uint64(aUnsigned.Hi), uint64(aUnsigned.Lo), scaleDecimals[S](), true)
+}
+
+// MarshalText implements encoding.TextMarshaler, using the same format as String.
+func (a Fix[S]) MarshalText() ([]byte, error) { return a.Append(nil), nil }
+
+// UnmarshalText implements encoding.TextUnmarshaler, see UFix64.UnmarshalText.
+func (a *Fix[S]) UnmarshalText(text []byte) error {
+	res, err := parseFix[S](textParser, string(text))
+	if err != nil {
+		return err
+	}
+
+	*a = res
+	return nil
+}
+
+func parseFix[S Scale](opts ParseOptions, s string) (Fix[S], error) {
+	hi, lo, sign, err := opts.parseDecimal(s, scaleDecimals[S]())
+	if err != nil {
+		return Fix[S]{}, err
+	}
+
+	res, err := fix128FromMagnitude(hi, lo, sign)
+	return Fix[S](res), err
+}
+
+// RescaleRaw converts the raw value `a`, with aDecimals decimals, to a raw value with outDecimals
+// decimals, rounding as specified. Raw values are 128-bit integers passed as a Fix128, e.g. the
+// Raw of a Fix, or an amount received from another system at its own scale. It returns an overflow
+// error if the result doesn't fit, and an UnderflowError if a non-zero value rounds to zero.
+func RescaleRaw(a Fix128, aDecimals, outDecimals uint8, round RoundingMode) (Fix128, error) {
+	return MulWithScales(a, aDecimals, Fix128Iota, 0, outDecimals, round)
+}
+
+// MulWithScales returns the raw value of `a*b` with outDecimals decimals, where `a` and `b` are raw
+// values with aDecimals and bDecimals decimals respectively, see RescaleRaw. The product is
+// rounded exactly once, so this is more accurate than converting the arguments first.
+func MulWithScales(a Fix128, aDecimals uint8, b Fix128, bDecimals uint8, outDecimals uint8,
+	round RoundingMode) (Fix128, error) {
+	// res = a * b * 10^shift
+	shift := int(outDecimals) - int(aDecimals) - int(bDecimals)
+
+	if shift <= 0 && -shift < len(pow10Table128) {
+		return a.FMD(b, Fix128(pow10Table128[-shift]), round)
+	}
+
+	if shift > 0 && shift < len(pow10Table128) {
+		// There's no division, so neither step rounds, and if the first one overflows, so does
+		// the result.
+		prod, err := a.FMD(b, Fix128Iota, round)
+		if err != nil {
+			return Fix128Zero, err
+		}
+
+		return prod.FMD(Fix128(pow10Table128[shift]), Fix128Iota, round)
+	}
+
+	num := new(big.Int).Mul(a.ToBigInt(), b.ToBigInt())
+	return quoWithShift(num, big.NewInt(1), shift, round)
+}
+
+// DivWithScales returns the raw value of `a/b` with outDecimals decimals, where `a` and `b` are
+// raw values with aDecimals and bDecimals decimals respectively, see MulWithScales.
+func DivWithScales(a Fix128, aDecimals uint8, b Fix128, bDecimals uint8, outDecimals uint8,
+	round RoundingMode) (Fix128, error) {
+	if b.IsZero() {
+		return Fix128Zero, DivisionByZeroError{}
+	}
+
+	// res = a * 10^shift / b
+	shift := int(outDecimals) - int(aDecimals) + int(bDecimals)
+
+	if shift >= 0 && shift < len(pow10Table128) {
+		return a.FMD(Fix128(pow10Table128[shift]), b, round)
+	}
+
+	if shift < 0 && -shift < len(pow10Table128) {
+		// If b * 10^-shift overflows, the result is less than 1 in magnitude, but still needs to
+		// be rounded correctly, so that case falls through to the exact calculation.
+		if den, err := b.FMD(Fix128(pow10Table128[-shift]), Fix128Iota, round); err == nil {
+			return a.FMD(Fix128Iota, den, round)
+		}
+	}
+
+	return quoWithShift(a.ToBigInt(), b.ToBigInt(), shift, round)
+}
+
+// quoWithShift returns num * 10^shift / den, rounded as specified, for the cases where the power
+// of ten doesn't fit in 128 bits.
+func quoWithShift(num, den *big.Int, shift int, round RoundingMode) (Fix128, error) {
+	if shift > 0 {
+		num.Mul(num, pow10Big(int64(shift)))
+	} else {
+		den.Mul(den, pow10Big(int64(-shift)))
+	}
+
+	return fromBigRat(new(big.Rat).SetFrac(num, den), 0, round, fix128FromMagnitude)
+}
+
+// scaleDecimals returns the number of decimals of S. Production builds clamp invalid scales
+// rather than panicking, see debugPanic.
+func scaleDecimals[S Scale]() int {
+	var s S
+
+	decimals := s.Decimals()
+	if decimals < 0 || decimals >= len(pow10Table128) {
+		debugPanic("scaleDecimals: scale out of range")
+		decimals = min(max(decimals, 0), len(pow10Table128)-1)
+	}
+
+	return decimals
+}
+
+// scaleOne returns the raw value of 1.0 with the scale S.
+func scaleOne[S Scale]() raw128 { return pow10Table128[scaleDecimals[S]()] }
@@ -0,0 +1,86 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"iter"
+	"math/big"
+)
+
+// This file contains aggregations over iterators, so values from a streaming source (e.g. rows
+// of a database query, or lines of a file) can be aggregated without collecting them in a slice.
+
+// SumSeq returns the sum of the values in the sequence. The running total is kept with extra
+// range, like the total of an Accumulator, so it only fails if the final sum is out of the range
+// of T (including negative sums for the unsigned types).
+func SumSeq[T calcValue](seq iter.Seq[T]) (T, error) {
+	var acc Accumulator[T]
+	for a := range seq {
+		acc.Add(a)
+	}
+
+	// The sum of representable values is representable at the same scale, so it's never rounded.
+	return acc.Value(RoundTowardZero)
+}
+
+// MeanSeq returns the arithmetic mean of the values in the sequence, computed from their exact
+// sum with a single rounding, as specified. Returns a DivisionByZeroError if the sequence is
+// empty, or an UnderflowError if a non-zero mean rounds to zero.
+func MeanSeq[T calcValue](seq iter.Seq[T], round RoundingMode) (T, error) {
+	var zero T
+
+	if !round.isValid() {
+		return zero, InvalidRoundingModeError{}
+	}
+
+	var sum, v, scratch big.Int
+	var n int64
+	for a := range seq {
+		sum.Add(&sum, setBigRaw(&v, &scratch, a))
+		n++
+	}
+
+	if n == 0 {
+		return zero, DivisionByZeroError{}
+	}
+
+	return meanFromBig[T](&sum, big.NewInt(n), round)
+}
+
+// MinMaxSeq returns the smallest and largest values in the sequence, or an OutOfDomainErrorError
+// if it's empty.
+func MinMaxSeq[T calcValue](seq iter.Seq[T]) (T, T, error) {
+	var lo, hi T
+	empty := true
+
+	for a := range seq {
+		if empty {
+			lo, hi = a, a
+			empty = false
+		} else if calcCmp(a, lo) < 0 {
+			lo = a
+		} else if calcCmp(a, hi) > 0 {
+			hi = a
+		}
+	}
+
+	if empty {
+		return lo, hi, OutOfDomainErrorError{}
+	}
+
+	return lo, hi, nil
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package shopspring converts between the fixedPoint types and decimal.Decimal from
+// github.com/shopspring/decimal. It's a separate module, so the fixedPoint package itself doesn't
+// depend on the decimal library.
+//
+// Conversions to decimal.Decimal are always exact. Conversions from decimal.Decimal are exact
+// whenever the value fits, and otherwise round explicitly, with the given rounding mode, if it
+// has more fractional digits than the target type (8 for UFix64 and Fix64, 24 for UFix128 and
+// Fix128).
+package shopspring
+
+import (
+	"math/big"
+
+	fixedPoint "github.com/onflow/fixed-point"
+	"github.com/shopspring/decimal"
+)
+
+// Value is the set of fixed-point types that can be converted to and from decimal.Decimal.
+type Value interface {
+	fixedPoint.UFix64 | fixedPoint.Fix64 | fixedPoint.UFix128 | fixedPoint.Fix128
+
+	MantScale() (*big.Int, int)
+}
+
+// ToDecimal converts `a` to a decimal.Decimal with the same value. The result has the exponent
+// -8 or -24 (i.e. the decimals of the type), so it keeps any trailing zeros.
+func ToDecimal[T Value](a T) decimal.Decimal {
+	mant, scale := a.MantScale()
+
+	return decimal.NewFromBigInt(mant, -int32(scale))
+}
+
+// FromDecimal converts d to a T, rounding as specified if it has more fractional digits than T
+// supports. It returns the same errors as the *FromMantScale constructors in fixedPoint: an
+// overflow error if d is out of the range of T (including negative values for the unsigned
+// types), an UnderflowError if a non-zero d rounds to zero, or an InvalidRoundingModeError.
+func FromDecimal[T Value](d decimal.Decimal, round fixedPoint.RoundingMode) (T, error) {
+	mant, scale := d.Coefficient(), -int(d.Exponent())
+
+	var res any
+	var err error
+
+	var zero T
+	switch any(zero).(type) {
+	case fixedPoint.UFix64:
+		res, err = fixedPoint.UFix64FromMantScale(mant, scale, round)
+	case fixedPoint.Fix64:
+		res, err = fixedPoint.Fix64FromMantScale(mant, scale, round)
+	case fixedPoint.UFix128:
+		res, err = fixedPoint.UFix128FromMantScale(mant, scale, round)
+	case fixedPoint.Fix128:
+		res, err = fixedPoint.Fix128FromMantScale(mant, scale, round)
+	}
+
+	if err != nil {
+		return zero, err
+	}
+
+	return res.(T), nil
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package shopspring
+
+import (
+	"testing"
+
+	fixedPoint "github.com/onflow/fixed-point"
+	"github.com/shopspring/decimal"
+)
+
+func TestRoundTrip(t *testing.T) {
+
+	t.Parallel()
+
+	checkRoundTrip(t, []fixedPoint.UFix64{
+		fixedPoint.UFix64Zero, fixedPoint.UFix64Iota, fixedPoint.UFix64One, fixedPoint.UFix64Max,
+		fixedPoint.MustParseUFix64("1234.5678"),
+	})
+	checkRoundTrip(t, []fixedPoint.Fix64{
+		fixedPoint.Fix64Zero, fixedPoint.Fix64Iota, fixedPoint.Fix64Min, fixedPoint.Fix64Max,
+		fixedPoint.MustParseFix64("-1234.5678"),
+	})
+	checkRoundTrip(t, []fixedPoint.UFix128{
+		fixedPoint.UFix128Zero, fixedPoint.UFix128Iota, fixedPoint.UFix128One, fixedPoint.UFix128Max,
+		fixedPoint.MustParseUFix128("1234.567890123456789012345678"),
+	})
+	checkRoundTrip(t, []fixedPoint.Fix128{
+		fixedPoint.Fix128Zero, fixedPoint.Fix128Iota, fixedPoint.Fix128Min, fixedPoint.Fix128Max,
+		fixedPoint.MustParseFix128("-0.000000000000000000000001"),
+	})
+}
+
+// checkRoundTrip checks that each value converts to a decimal.Decimal with the same value, and
+// back again.
+func checkRoundTrip[T interface {
+	Value
+	String() string
+}](t *testing.T, values []T) {
+	for _, a := range values {
+		d := ToDecimal(a)
+		if want := decimal.RequireFromString(a.String()); !d.Equal(want) {
+			t.Errorf("ToDecimal(%v) = %v; want %v", a, d, want)
+		}
+
+		res, err := FromDecimal[T](d, fixedPoint.RoundHalfEven)
+		if err != nil || res != a {
+			t.Errorf("FromDecimal(ToDecimal(%v)) = %v, %v", a, res, err)
+		}
+	}
+}
+
+func TestFromDecimal(t *testing.T) {
+
+	t.Parallel()
+
+	tests := []struct {
+		in    string
+		round fixedPoint.RoundingMode
+		want  string
+		err   error
+	}{
+		// Values with up to 24 decimals are exact, whatever the exponent.
+		{"1.5", fixedPoint.RoundTowardZero, "1.5", nil},
+		{"15e-1", fixedPoint.RoundTowardZero, "1.5", nil},
+		{"1.500000000000000000000000000000", fixedPoint.RoundTowardZero, "1.5", nil},
+		{"-12e3", fixedPoint.RoundTowardZero, "-12000", nil},
+		{"0.000000000000000000000001", fixedPoint.RoundTowardZero, "0.000000000000000000000001", nil},
+
+		// Beyond 24 decimals, the value is rounded as specified.
+		{"1.0000000000000000000000005", fixedPoint.RoundTowardZero, "1", nil},
+		{"1.0000000000000000000000005", fixedPoint.RoundAwayFromZero, "1.000000000000000000000001", nil},
+		{"1.0000000000000000000000005", fixedPoint.RoundNearestHalfEven, "1", nil},
+		{"1.0000000000000000000000015", fixedPoint.RoundNearestHalfEven, "1.000000000000000000000002", nil},
+		{"-1.0000000000000000000000005", fixedPoint.RoundFloor, "-1.000000000000000000000001", nil},
+		{"-1.0000000000000000000000005", fixedPoint.RoundNearestHalfAway, "-1.000000000000000000000001", nil},
+
+		// Errors
+		{"0.0000000000000000000000004", fixedPoint.RoundNearestHalfEven, "", fixedPoint.UnderflowError{}},
+		{"1e-1000000", fixedPoint.RoundTowardZero, "", fixedPoint.UnderflowError{}},
+		{"1e1000000", fixedPoint.RoundTowardZero, "", fixedPoint.PositiveOverflowError{}},
+		{"-170141183460469.231731687303715884105729", fixedPoint.RoundTowardZero, "", fixedPoint.NegativeOverflowError{}},
+		{"1.5", fixedPoint.RoundingMode(-1), "", fixedPoint.InvalidRoundingModeError{}},
+	}
+
+	for _, tt := range tests {
+		res, err := FromDecimal[fixedPoint.Fix128](decimal.RequireFromString(tt.in), tt.round)
+		if err != tt.err || (err == nil && res != fixedPoint.MustParseFix128(tt.want)) {
+			t.Errorf("FromDecimal(%s, %v) = %v, %v; want %s, %v", tt.in, tt.round, res, err, tt.want, tt.err)
+		}
+	}
+
+	// Negative values don't fit in the unsigned types, and the 64-bit types round at 8 decimals.
+	if _, err := FromDecimal[fixedPoint.UFix128](decimal.RequireFromString("-1"), fixedPoint.RoundTowardZero); err != (fixedPoint.NegativeOverflowError{}) {
+		t.Errorf("FromDecimal[UFix128](-1) = %v; want NegativeOverflowError", err)
+	}
+	if res, err := FromDecimal[fixedPoint.UFix64](decimal.RequireFromString("2.000000015"), fixedPoint.RoundNearestHalfEven); err != nil || res != fixedPoint.MustParseUFix64("2.00000002") {
+		t.Errorf("FromDecimal[UFix64](2.000000015) = %v, %v; want 2.00000002", res, err)
+	}
+	if res, err := FromDecimal[fixedPoint.Fix64](decimal.Decimal{}, fixedPoint.RoundNearestHalfEven); err != nil || res != fixedPoint.Fix64Zero {
+		t.Errorf("FromDecimal[Fix64](zero value) = %v, %v; want 0", res, err)
+	}
+}
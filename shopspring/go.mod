@@ -0,0 +1,10 @@
+module github.com/onflow/fixed-point/shopspring
+
+go 1.23.2
+
+require (
+	github.com/onflow/fixed-point v0.0.0-00010101000000-000000000000
+	github.com/shopspring/decimal v1.4.0
+)
+
+replace github.com/onflow/fixed-point => ../
@@ -0,0 +1,197 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+)
+
+// This file contains the database/sql support for the fixed-point types. Values are stored in
+// their decimal text form, which maps directly onto SQL DECIMAL/NUMERIC columns (e.g. a UFix64
+// fits in DECIMAL(20, 8), a Fix128 in DECIMAL(39, 24), and a Fix256 in DECIMAL(78, 24)), and never
+// goes through a float.
+
+var (
+	_ driver.Valuer = UFix64Zero
+	_ driver.Valuer = Fix64Zero
+	_ driver.Valuer = UFix128Zero
+	_ driver.Valuer = Fix128Zero
+	_ driver.Valuer = UFix256Zero
+	_ driver.Valuer = Fix256Zero
+	_ sql.Scanner   = (*UFix64)(nil)
+	_ sql.Scanner   = (*Fix64)(nil)
+	_ sql.Scanner   = (*UFix128)(nil)
+	_ sql.Scanner   = (*Fix128)(nil)
+	_ sql.Scanner   = (*UFix256)(nil)
+	_ sql.Scanner   = (*Fix256)(nil)
+
+	_ driver.Valuer = NullUFix64{}
+	_ driver.Valuer = NullFix64{}
+	_ driver.Valuer = NullUFix128{}
+	_ driver.Valuer = NullFix128{}
+	_ driver.Valuer = NullUFix256{}
+	_ driver.Valuer = NullFix256{}
+	_ sql.Scanner   = (*NullUFix64)(nil)
+	_ sql.Scanner   = (*NullFix64)(nil)
+	_ sql.Scanner   = (*NullUFix128)(nil)
+	_ sql.Scanner   = (*NullFix128)(nil)
+	_ sql.Scanner   = (*NullUFix256)(nil)
+	_ sql.Scanner   = (*NullFix256)(nil)
+)
+
+// Value implements driver.Valuer, returning the decimal form of `a` as a string.
+func (a UFix64) Value() (driver.Value, error)  { return a.String(), nil }
+func (a Fix64) Value() (driver.Value, error)   { return a.String(), nil }
+func (a UFix128) Value() (driver.Value, error) { return a.String(), nil }
+func (a Fix128) Value() (driver.Value, error)  { return a.String(), nil }
+func (a UFix256) Value() (driver.Value, error) { return a.String(), nil }
+func (a Fix256) Value() (driver.Value, error)  { return a.String(), nil }
+
+// Scan implements sql.Scanner. It accepts the decimal text form (as a string or []byte, which is
+// how most drivers return DECIMAL columns) and integers. Floats and NULL are rejected, as are
+// values that can't be represented exactly.
+func (a *UFix64) Scan(src any) error { return scanInto(a, src, textParser.ParseUFix64) }
+
+// Scan implements sql.Scanner, see UFix64.Scan.
+func (a *Fix64) Scan(src any) error { return scanInto(a, src, textParser.ParseFix64) }
+
+// Scan implements sql.Scanner, see UFix64.Scan.
+func (a *UFix128) Scan(src any) error { return scanInto(a, src, textParser.ParseUFix128) }
+
+// Scan implements sql.Scanner, see UFix64.Scan.
+func (a *Fix128) Scan(src any) error { return scanInto(a, src, textParser.ParseFix128) }
+
+// Scan implements sql.Scanner, see UFix64.Scan.
+func (a *UFix256) Scan(src any) error { return scanInto(a, src, textParser.ParseUFix256) }
+
+// Scan implements sql.Scanner, see UFix64.Scan.
+func (a *Fix256) Scan(src any) error { return scanInto(a, src, textParser.ParseFix256) }
+
+// NullUFix64 is a UFix64 that may be NULL, for use with nullable columns. It works like the
+// sql.Null* types, i.e. Valid is false for NULL.
+type NullUFix64 struct {
+	UFix64 UFix64
+	Valid  bool
+}
+
+// NullFix64 is a Fix64 that may be NULL, see NullUFix64.
+type NullFix64 struct {
+	Fix64 Fix64
+	Valid bool
+}
+
+// NullUFix128 is a UFix128 that may be NULL, see NullUFix64.
+type NullUFix128 struct {
+	UFix128 UFix128
+	Valid   bool
+}
+
+// NullFix128 is a Fix128 that may be NULL, see NullUFix64.
+type NullFix128 struct {
+	Fix128 Fix128
+	Valid  bool
+}
+
+// NullUFix256 is a UFix256 that may be NULL, see NullUFix64.
+type NullUFix256 struct {
+	UFix256 UFix256
+	Valid   bool
+}
+
+// NullFix256 is a Fix256 that may be NULL, see NullUFix64.
+type NullFix256 struct {
+	Fix256 Fix256
+	Valid  bool
+}
+
+// Value implements driver.Valuer, returning nil for NULL.
+func (n NullUFix64) Value() (driver.Value, error)  { return nullValue(n.UFix64, n.Valid) }
+func (n NullFix64) Value() (driver.Value, error)   { return nullValue(n.Fix64, n.Valid) }
+func (n NullUFix128) Value() (driver.Value, error) { return nullValue(n.UFix128, n.Valid) }
+func (n NullFix128) Value() (driver.Value, error)  { return nullValue(n.Fix128, n.Valid) }
+func (n NullUFix256) Value() (driver.Value, error) { return nullValue(n.UFix256, n.Valid) }
+func (n NullFix256) Value() (driver.Value, error)  { return nullValue(n.Fix256, n.Valid) }
+
+// Scan implements sql.Scanner. NULL sets Valid to false, anything else is scanned as a UFix64.
+func (n *NullUFix64) Scan(src any) error { return scanNull(&n.UFix64, &n.Valid, src) }
+
+// Scan implements sql.Scanner, see NullUFix64.Scan.
+func (n *NullFix64) Scan(src any) error { return scanNull(&n.Fix64, &n.Valid, src) }
+
+// Scan implements sql.Scanner, see NullUFix64.Scan.
+func (n *NullUFix128) Scan(src any) error { return scanNull(&n.UFix128, &n.Valid, src) }
+
+// Scan implements sql.Scanner, see NullUFix64.Scan.
+func (n *NullFix128) Scan(src any) error { return scanNull(&n.Fix128, &n.Valid, src) }
+
+// Scan implements sql.Scanner, see NullUFix64.Scan.
+func (n *NullUFix256) Scan(src any) error { return scanNull(&n.UFix256, &n.Valid, src) }
+
+// Scan implements sql.Scanner, see NullUFix64.Scan.
+func (n *NullFix256) Scan(src any) error { return scanNull(&n.Fix256, &n.Valid, src) }
+
+func scanInto[T any](dst *T, src any, parse func(string) (T, error)) error {
+	var s string
+
+	switch src := src.(type) {
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	case int64:
+		s = strconv.FormatInt(src, 10)
+	default:
+		return fmt.Errorf("cannot scan %T into %T: %w", src, *dst, InvalidEncodingError{})
+	}
+
+	res, err := parse(s)
+	if err != nil {
+		return err
+	}
+
+	*dst = res
+	return nil
+}
+
+func nullValue(v driver.Valuer, valid bool) (driver.Value, error) {
+	if !valid {
+		return nil, nil
+	}
+
+	return v.Value()
+}
+
+func scanNull[T any, P interface {
+	*T
+	sql.Scanner
+}](dst P, valid *bool, src any) error {
+	if src == nil {
+		var zero T
+		*dst, *valid = zero, false
+		return nil
+	}
+
+	if err := dst.Scan(src); err != nil {
+		return err
+	}
+
+	*valid = true
+	return nil
+}
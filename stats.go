@@ -0,0 +1,177 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import "math/big"
+
+// RunningStats computes the count, mean, variance and standard deviation of a stream of values
+// in a single pass, e.g. for volatility metrics over a price feed:
+//
+//	var stats RunningStats[UFix64]
+//	for _, price := range prices {
+//		stats.Add(price)
+//	}
+//	vol, err := stats.Stddev(RoundHalfEven)
+//
+// The sum of the values and the sum of their squares are kept exactly, as big.Int totals of the
+// raw values, so adding a value can't overflow, and there's no cancellation when the variance is
+// small compared to the mean. Each statistic is computed exactly from the totals when it's read,
+// and rounded once, to T.
+//
+// The zero value has no values.
+type RunningStats[T calcValue] struct {
+	n     uint64
+	sum   *big.Int // The sum of the raw values
+	sumSq *big.Int // The sum of the squares of the raw values
+}
+
+// Add adds `x` to the stream.
+func (s *RunningStats[T]) Add(x T) {
+	if s.sum == nil {
+		s.sum, s.sumSq = new(big.Int), new(big.Int)
+	}
+
+	var v, scratch big.Int
+	setBigRaw(&v, &scratch, x)
+
+	s.n++
+	s.sum.Add(s.sum, &v)
+	s.sumSq.Add(s.sumSq, v.Mul(&v, &v))
+}
+
+// Reset removes all of the values.
+func (s *RunningStats[T]) Reset() { *s = RunningStats[T]{} }
+
+// Count returns the number of values added.
+func (s *RunningStats[T]) Count() uint64 { return s.n }
+
+// Mean returns the mean of the values, rounded as specified, or a DivisionByZeroError if there
+// are none.
+func (s *RunningStats[T]) Mean(round RoundingMode) (T, error) {
+	var zero T
+
+	if !round.isValid() {
+		return zero, InvalidRoundingModeError{}
+	}
+
+	if s.n == 0 {
+		return zero, DivisionByZeroError{}
+	}
+
+	return meanFromBig[T](s.sum, new(big.Int).SetUint64(s.n), round)
+}
+
+// Variance returns the population variance of the values (the mean squared deviation from the
+// mean), rounded as specified, or a DivisionByZeroError if there are none.
+func (s *RunningStats[T]) Variance(round RoundingMode) (T, error) {
+	return s.variance(round, 0)
+}
+
+// SampleVariance returns the sample variance of the values (with Bessel's correction, i.e.
+// dividing by n-1), rounded as specified, or a DivisionByZeroError if there are fewer than two.
+func (s *RunningStats[T]) SampleVariance(round RoundingMode) (T, error) {
+	return s.variance(round, 1)
+}
+
+// Stddev returns the population standard deviation of the values, the square root of Variance.
+// It's computed from the exact variance, and rounded once, as specified.
+func (s *RunningStats[T]) Stddev(round RoundingMode) (T, error) {
+	return s.stddev(round, 0)
+}
+
+// SampleStddev returns the sample standard deviation of the values, the square root of
+// SampleVariance, see Stddev.
+func (s *RunningStats[T]) SampleStddev(round RoundingMode) (T, error) {
+	return s.stddev(round, 1)
+}
+
+// deviations returns n times the sum of squared deviations from the mean, n*sumSq - sum^2, and
+// n * (n - correction), which it's divided by (along with the scale) to give the variance. Returns
+// a DivisionByZeroError if there are no more than `correction` values.
+func (s *RunningStats[T]) deviations(correction uint64) (num, den *big.Int, err error) {
+	if s.n <= correction {
+		return nil, nil, DivisionByZeroError{}
+	}
+
+	n := new(big.Int).SetUint64(s.n)
+
+	num = new(big.Int).Mul(n, s.sumSq)
+	num.Sub(num, new(big.Int).Mul(s.sum, s.sum))
+
+	den = new(big.Int).SetUint64(s.n - correction)
+	den.Mul(den, n)
+
+	return num, den, nil
+}
+
+func (s *RunningStats[T]) variance(round RoundingMode, correction uint64) (T, error) {
+	var zero T
+
+	if !round.isValid() {
+		return zero, InvalidRoundingModeError{}
+	}
+
+	num, den, err := s.deviations(correction)
+	if err != nil {
+		return zero, err
+	}
+
+	// The totals are of raw values, so they're scaled by the square of the scale factor, and the
+	// raw variance needs one more division by it.
+	var scale, scratch big.Int
+	setBigRaw(&scale, &scratch, calcOne[T]())
+
+	return meanFromBig[T](num, den.Mul(den, &scale), round)
+}
+
+func (s *RunningStats[T]) stddev(round RoundingMode, correction uint64) (T, error) {
+	var zero T
+
+	if !round.isValid() {
+		return zero, InvalidRoundingModeError{}
+	}
+
+	num, den, err := s.deviations(correction)
+	if err != nil {
+		return zero, err
+	}
+
+	// The scale factors cancel out: the raw standard deviation is sqrt(num / den).
+	return sqrtQuoToCalc[T](num, den, round)
+}
+
+// sqrtQuoToCalc returns sqrt(num / den), for non-negative num and positive den, rounded as
+// specified, as the raw value of a T.
+func sqrtQuoToCalc[T calcValue](num, den *big.Int, round RoundingMode) (T, error) {
+	// sqrt(num / den) = sqrt(4 * num * den) / (2 * den). With t = isqrt(4 * num * den), the root
+	// is either exactly t / (2 * den), or strictly between t / (2 * den) and (t + 1) / (2 * den).
+	// Every rounding boundary is a multiple of den / (2 * den), so none of them is strictly between
+	// those two, and the root rounds the same way as (2t + 1) / (4 * den). That's within
+	// 1 / (4 * den) of the root, so it's also a precise remainder for RoundStochastic.
+	p := new(big.Int).Mul(num, den)
+	p.Lsh(p, 2)
+
+	t := new(big.Int).Sqrt(p)
+	rootDen := new(big.Int).Lsh(den, 1)
+	if new(big.Int).Mul(t, t).Cmp(p) != 0 {
+		t.Lsh(t, 1).Add(t, big.NewInt(1))
+		rootDen.Lsh(rootDen, 1)
+	}
+
+	// The root is only zero if num is.
+	return meanFromBig[T](t, rootDen, round)
+}
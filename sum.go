@@ -0,0 +1,314 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+// This file contains the overflow-checked sums and products over slices, for aggregating balances
+// and compounding growth factors. Each returns the result along with an index, which is -1 if it
+// succeeded, and otherwise points at the value where it went out of range.
+
+// SumUFix64 returns the sum of the values, or a PositiveOverflowError and the index of the first
+// value that made the running total overflow.
+func SumUFix64(values []UFix64) (UFix64, int, error) {
+	var sum raw64
+
+	for i, v := range values {
+		var carry uint64
+		if sum, carry = add64(sum, raw64(v), 0); carry != 0 {
+			return UFix64Zero, i, PositiveOverflowError{}
+		}
+	}
+
+	return UFix64(sum), -1, nil
+}
+
+// SumUFix128 returns the sum of the values, or a PositiveOverflowError and the index of the first
+// value that made the running total overflow.
+func SumUFix128(values []UFix128) (UFix128, int, error) {
+	var sum raw128
+
+	for i, v := range values {
+		var carry uint64
+		if sum, carry = add128(sum, raw128(v), 0); carry != 0 {
+			return UFix128Zero, i, PositiveOverflowError{}
+		}
+	}
+
+	return UFix128(sum), -1, nil
+}
+
+// CumSumUFix64 returns the running totals of the values, where the i-th total is the sum of the
+// values up to and including the i-th, e.g. for checkpoints of cumulative rewards. On overflow, it
+// returns the totals before the value that overflowed, along with a PositiveOverflowError and the
+// index of that value.
+func CumSumUFix64(values []UFix64) ([]UFix64, int, error) {
+	totals := make([]UFix64, 0, len(values))
+	var sum raw64
+
+	for i, v := range values {
+		var carry uint64
+		if sum, carry = add64(sum, raw64(v), 0); carry != 0 {
+			return totals, i, PositiveOverflowError{}
+		}
+		totals = append(totals, UFix64(sum))
+	}
+
+	return totals, -1, nil
+}
+
+// CumSumUFix128 returns the running totals of the values, like CumSumUFix64.
+func CumSumUFix128(values []UFix128) ([]UFix128, int, error) {
+	totals := make([]UFix128, 0, len(values))
+	var sum raw128
+
+	for i, v := range values {
+		var carry uint64
+		if sum, carry = add128(sum, raw128(v), 0); carry != 0 {
+			return totals, i, PositiveOverflowError{}
+		}
+		totals = append(totals, UFix128(sum))
+	}
+
+	return totals, -1, nil
+}
+
+// SumFix64 returns the sum of the values. The running total is kept with extra range, so values
+// that cancel out are handled correctly (e.g. {Fix64Max, Fix64One, Fix64One.Neg()}), and the sum
+// only fails if the final total is out of range. In that case, the index is that of the value
+// after which the running total stayed out of range.
+func SumFix64(values []Fix64) (Fix64, int, error) {
+	// The total is a 128-bit two's complement value, which can't overflow for any slice that
+	// fits in memory.
+	var hi, lo raw64
+	out := -1
+
+	for i, v := range values {
+		var carry uint64
+		lo, carry = add64(lo, raw64(v), 0)
+		hi, _ = add64(hi, signExtend64(raw64(v)), carry)
+
+		if hi == signExtend64(lo) {
+			out = -1
+		} else if out < 0 {
+			out = i
+		}
+	}
+
+	if out >= 0 {
+		return Fix64Zero, out, applySign(PositiveOverflowError{}, sign64(hi))
+	}
+
+	return Fix64(lo), -1, nil
+}
+
+// SumFix128 returns the sum of the values, handling cancellation as for SumFix64.
+func SumFix128(values []Fix128) (Fix128, int, error) {
+	// The total is a 192-bit two's complement value, with ext as the top word.
+	var sum raw128
+	var ext raw64
+	out := -1
+
+	for i, v := range values {
+		var carry uint64
+		sum, carry = add128(sum, raw128(v), 0)
+		ext, _ = add64(ext, signExtend64(v.Hi), carry)
+
+		if ext == signExtend64(sum.Hi) {
+			out = -1
+		} else if out < 0 {
+			out = i
+		}
+	}
+
+	if out >= 0 {
+		return Fix128Zero, out, applySign(PositiveOverflowError{}, sign64(ext))
+	}
+
+	return Fix128(sum), -1, nil
+}
+
+// ProdUFix64 returns the product of the values, multiplying them in order and rounding each
+// product as specified (as chaining Mul would). It stops at the first error, and returns it with
+// the index of the value that caused it. The product of no values is one.
+func ProdUFix64(values []UFix64, round RoundingMode) (UFix64, int, error) {
+	return prod(values, UFix64One, round)
+}
+
+// ProdUFix128 returns the product of the values, see ProdUFix64.
+func ProdUFix128(values []UFix128, round RoundingMode) (UFix128, int, error) {
+	return prod(values, UFix128One, round)
+}
+
+// ProdFusedUFix128 returns the product of the values like ProdUFix128, except that the running
+// product is kept with 64 extra bits of precision. Each intermediate product is truncated to those
+// extra bits, and only the final conversion back to UFix128 is rounded as specified, so the result
+// isn't always the correctly rounded product of the values. The truncation error is far below the
+// last decimal, though, so this avoids accumulating rounding errors over long series, e.g. a year
+// of daily growth factors.
+//
+// Overflow is reported as soon as the running product is out of range. Underflow is reported as
+// soon as it is too small for the extended precision, i.e. long before it would recover, and
+// otherwise by the final rounding, with the index of the last value.
+func ProdFusedUFix128(values []UFix128, round RoundingMode) (UFix128, int, error) {
+	if !round.isValid() {
+		return UFix128Zero, -1, InvalidRoundingModeError{}
+	}
+
+	acc := fix192One
+
+	for i, v := range values {
+		// A zero factor makes the product exactly zero, whatever follows.
+		if v.IsZero() {
+			return UFix128Zero, -1, nil
+		}
+
+		var err error
+		if acc, err = acc.umul(v.toFix192()); err != nil {
+			return UFix128Zero, i, err
+		}
+		if acc.isZero() {
+			return UFix128Zero, i, UnderflowError{}
+		}
+	}
+
+	res, err := acc.toUFix128(round)
+	if err != nil {
+		return UFix128Zero, len(values) - 1, err
+	}
+
+	return res, -1, nil
+}
+
+func prod[T FixedPoint[T]](values []T, one T, round RoundingMode) (T, int, error) {
+	var zero T
+
+	if !round.isValid() {
+		return zero, -1, InvalidRoundingModeError{}
+	}
+
+	res := one
+
+	for i, v := range values {
+		var err error
+		if res, err = res.Mul(v, round); err != nil {
+			return zero, i, err
+		}
+	}
+
+	return res, -1, nil
+}
+
+// DotUFix64 returns the dot product of `a` and `b`, i.e. the sum of a[i]*b[i], with a single
+// rounding at the end, like SumOfProductsUFix64. Returns an OutOfDomainErrorError if the slices
+// have different lengths, or an error on overflow or underflow.
+func DotUFix64(a, b []UFix64, round RoundingMode) (UFix64, error) {
+	if len(a) != len(b) {
+		return UFix64Zero, OutOfDomainErrorError{}
+	}
+
+	var acc accum64
+
+	for i := range a {
+		acc.addProduct(a[i], b[i], 1)
+	}
+
+	res, _, err := acc.value(round)
+
+	return res, err
+}
+
+// DotFix64 returns the dot product of `a` and `b` with a single rounding at the end, like
+// SumOfProductsFix64, see DotUFix64.
+func DotFix64(a, b []Fix64, round RoundingMode) (Fix64, error) {
+	if len(a) != len(b) {
+		return Fix64Zero, OutOfDomainErrorError{}
+	}
+
+	var acc accum64
+
+	for i := range a {
+		aUnsigned, aSign := a[i].Abs()
+		bUnsigned, bSign := b[i].Abs()
+
+		acc.addProduct(aUnsigned, bUnsigned, aSign*bSign)
+	}
+
+	res, sign, err := acc.value(round)
+
+	if err != nil {
+		return Fix64Zero, applySign(err, sign)
+	}
+
+	return res.ApplySign(sign)
+}
+
+// DotUFix128 returns the dot product of `a` and `b`, e.g. the value of a portfolio from its prices
+// and quantities. The full 256-bit products are accumulated, and only scaled down and rounded
+// once at the end, so the result doesn't drift with the number of terms as it would when rounding
+// each product. See DotUFix64.
+func DotUFix128(a, b []UFix128, round RoundingMode) (UFix128, error) {
+	if len(a) != len(b) {
+		return UFix128Zero, OutOfDomainErrorError{}
+	}
+
+	var acc accum128
+
+	for i := range a {
+		acc.addProduct(a[i], b[i], 1)
+	}
+
+	res, _, err := acc.value(round)
+
+	return res, err
+}
+
+// DotFix128 returns the dot product of `a` and `b` with a single rounding at the end, like
+// SumOfProductsFix128, see DotUFix128.
+func DotFix128(a, b []Fix128, round RoundingMode) (Fix128, error) {
+	if len(a) != len(b) {
+		return Fix128Zero, OutOfDomainErrorError{}
+	}
+
+	var acc accum128
+
+	for i := range a {
+		aUnsigned, aSign := a[i].Abs()
+		bUnsigned, bSign := b[i].Abs()
+
+		acc.addProduct(aUnsigned, bUnsigned, aSign*bSign)
+	}
+
+	res, sign, err := acc.value(round)
+
+	if err != nil {
+		return Fix128Zero, applySign(err, sign)
+	}
+
+	return res.ApplySign(sign)
+}
+
+// signExtend64 returns the word that extends `a` as a two's complement value, i.e. all ones if
+// `a` is negative, and zero otherwise.
+func signExtend64(a raw64) raw64 { return raw64(int64(a) >> 63) }
+
+// sign64 returns -1 if the two's complement value `a` is negative, and 1 otherwise.
+func sign64(a raw64) int64 {
+	if isNeg64(a) {
+		return -1
+	}
+
+	return 1
+}
@@ -0,0 +1,162 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+import (
+	"math/big"
+	"slices"
+)
+
+// TWAP computes time-weighted averages of a value that changes over time, e.g. a price reported
+// by an oracle:
+//
+//	twap := NewTWAP(MustParseUFix64("3600"))
+//	twap.Observe(price, timestamp)
+//	...
+//	avg, err := twap.Average(MustParseUFix64("600"), RoundHalfEven)
+//
+// Each observed value holds from its timestamp until the timestamp of the next observation, and
+// Average integrates this step function over the window ending at the latest observation. The
+// integral is kept exactly (as the cumulative sum of value * duration at each observation, like
+// the accumulators of on-chain TWAP oracles), so the average is rounded only once.
+//
+// Timestamps are UFix64 values, in whatever unit the caller chooses (e.g. the seconds of a Flow
+// block timestamp), and must be observed in non-decreasing order.
+type TWAP struct {
+	maxWindow UFix64
+	obs       []twapObservation
+}
+
+// twapObservation is a value observed at a given time, along with the raw integral of all the
+// values before it (the sum of raw value * raw duration), from the first observation.
+type twapObservation struct {
+	timestamp UFix64
+	value     Fix128
+	cum       *big.Int
+}
+
+// NewTWAP returns a TWAP that keeps enough observations to compute averages over windows of up to
+// maxWindow, discarding older ones as new values are observed. A maxWindow of UFix64Max keeps all
+// observations.
+func NewTWAP(maxWindow UFix64) *TWAP {
+	return &TWAP{maxWindow: maxWindow}
+}
+
+// Observe records that the value changed to `value` at the given time. Observing a value at the
+// same time as the previous observation replaces it. Returns an OutOfDomainErrorError if the
+// timestamp is before the previous one.
+func (t *TWAP) Observe(value Fix128, timestamp UFix64) error {
+	if len(t.obs) == 0 {
+		t.obs = append(t.obs, twapObservation{timestamp, value, new(big.Int)})
+		return nil
+	}
+
+	last := &t.obs[len(t.obs)-1]
+	if timestamp.Lt(last.timestamp) {
+		return OutOfDomainErrorError{}
+	}
+
+	if timestamp == last.timestamp {
+		last.value = value
+		return nil
+	}
+
+	t.obs = append(t.obs, twapObservation{timestamp, value, last.integral(timestamp)})
+
+	// Drop the observations that can't be reached by any window, keeping the one that covers
+	// the start of the largest window.
+	if timestamp.Gte(t.maxWindow) {
+		start := timestamp - t.maxWindow
+		if k := t.find(start); k > 0 {
+			t.obs = slices.Delete(t.obs, 0, k)
+		}
+	}
+
+	return nil
+}
+
+// Latest returns the most recently observed value and its timestamp, or false if nothing has been
+// observed.
+func (t *TWAP) Latest() (Fix128, UFix64, bool) {
+	if len(t.obs) == 0 {
+		return Fix128Zero, UFix64Zero, false
+	}
+
+	last := t.obs[len(t.obs)-1]
+
+	return last.value, last.timestamp, true
+}
+
+// Average returns the time-weighted average of the observed values over the window (which must
+// not be zero) ending at the latest observation, rounded as specified. Returns an
+// OutOfDomainErrorError if the observations don't cover the whole window (i.e. the window starts
+// before the first observation, or before the observations discarded for being older than
+// maxWindow), a DivisionByZeroError if the window is zero, or an UnderflowError if a non-zero
+// average rounds to zero.
+func (t *TWAP) Average(window UFix64, round RoundingMode) (Fix128, error) {
+	if !round.isValid() {
+		return Fix128Zero, InvalidRoundingModeError{}
+	}
+
+	if window.IsZero() {
+		return Fix128Zero, DivisionByZeroError{}
+	}
+
+	if len(t.obs) == 0 {
+		return Fix128Zero, OutOfDomainErrorError{}
+	}
+
+	last := t.obs[len(t.obs)-1]
+	if window.Gt(last.timestamp) {
+		return Fix128Zero, OutOfDomainErrorError{}
+	}
+
+	start := last.timestamp - window
+	k := t.find(start)
+	if k < 0 {
+		return Fix128Zero, OutOfDomainErrorError{}
+	}
+
+	// The raw integral has the scale of Fix128 times the scale of UFix64, which cancels with the
+	// scale of the raw window.
+	num := new(big.Int).Sub(last.cum, t.obs[k].integral(start))
+
+	return meanFromBig[Fix128](num, new(big.Int).SetUint64(uint64(window)), round)
+}
+
+// find returns the index of the last observation at or before the given time, or -1 if there's
+// none.
+func (t *TWAP) find(timestamp UFix64) int {
+	k, found := slices.BinarySearchFunc(t.obs, timestamp, func(o twapObservation, ts UFix64) int {
+		return compare(o.timestamp, ts)
+	})
+	if !found {
+		k--
+	}
+
+	return k
+}
+
+// integral returns the raw integral of the observed values up to the given time, which must not
+// be before the observation, assuming the value doesn't change after it.
+func (o twapObservation) integral(timestamp UFix64) *big.Int {
+	var v, scratch big.Int
+	setBigRaw(&v, &scratch, o.value)
+	v.Mul(&v, new(big.Int).SetUint64(uint64(timestamp-o.timestamp)))
+
+	return v.Add(&v, o.cum)
+}
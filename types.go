@@ -16,6 +16,8 @@
 
 package fixedPoint
 
+import "math/big"
+
 // Exported fixed-point types
 type UFix64 raw64
 type Fix64 raw64
@@ -23,11 +25,74 @@ type Fix64 raw64
 type UFix128 raw128
 type Fix128 raw128
 
+type UFix256 raw256
+type Fix256 raw256
+
+// Fix192 is the extended-precision type used internally by the transcendental functions and Calc,
+// exported so that callers can build their own formulas and round only once at the end, e.g.:
+//
+//	lnA, err := a.ToFix192().Ln()
+//	...
+//	prod, err := lnA.Mul(b.ToFix192())
+//	...
+//	exp, err := prod.Exp()
+//	...
+//	res, err := exp.ToUFix128(RoundHalfEven)
+//
+// Fix192 is a signed value with a scale factor of 10**24 * 2**64: the top 128 bits are a Fix128
+// value, and the bottom 64 bits extend the fractional part with additional binary precision.
+// Converting from any of the other 64 or 128-bit types is exact.
+//
+// The API is deliberately smaller than that of the other types. Mul and Div always round to the
+// nearest Fix192 value (ties away from zero), and the rounding mode is only chosen when converting
+// back to one of the other types. Fix192 doesn't implement FixedPoint, and has no text form; to
+// print a value, convert it first.
+type Fix192 fix192
+
+// FixedPoint is the set of methods shared by all of the fixed-point types (UFix64, Fix64, UFix128,
+// Fix128, UFix256, Fix256, and Fix), for writing generic code over them, e.g.:
+//
+//	func Sum[T FixedPoint[T]](values []T) (T, error)
+//
+// None of these methods panic; all failures are reported through the returned error.
+type FixedPoint[T any] interface {
+	Eq(b T) bool
+	Lt(b T) bool
+	Gt(b T) bool
+	Lte(b T) bool
+	Gte(b T) bool
+	IsZero() bool
+	IsInteger() bool
+	Iota() T
+
+	Scale() *big.Int
+	Decimals() int
+	MaxValue() T
+	MinValue() T
+	One() T
+
+	Add(b T) (T, error)
+	Sub(b T) (T, error)
+	Mul(b T, round RoundingMode) (T, error)
+	Div(b T, round RoundingMode) (T, error)
+	FMD(b, c T, round RoundingMode) (T, error)
+	Mod(b T) (T, error)
+	Neg() (T, error)
+}
+
+var _ FixedPoint[UFix64] = UFix64Zero
+var _ FixedPoint[Fix64] = Fix64Zero
+var _ FixedPoint[UFix128] = UFix128Zero
+var _ FixedPoint[Fix128] = Fix128Zero
+var _ FixedPoint[UFix256] = UFix256Zero
+var _ FixedPoint[Fix256] = Fix256Zero
+
 // Rounding modes
 type RoundingMode int
 
 const (
-	// The Div, Mul, and FMD functions support four rounding modes:
+	// All operations that can round (Mul, Div, FMD, Sqrt, the narrowing conversions, and the
+	// *Round variants of the transcendental functions) support the following rounding modes:
 	//    RoundTowardZero: Returns the closest representable fixed-point value that has a magnitude
 	//      less than or equal to the magnitude of the real result, effectively truncating the
 	//      fractional part. e.g. 5e-8 / 2 = 2e-8, -5e-8 / 2 = -2e-8
@@ -44,40 +109,172 @@ const (
 	//      unrepresentable portion is greater than or less than one half the difference between two
 	//      available values. If two representable values are equally close, the value with an even
 	//      digit in the smallest decimal place will be chosen. e.g. 7e-8 / 2 = 4e-8, 5e-8 / 2 = 2e-8
+	//    RoundNearestHalfTowardZero: Returns the closest representable fixed-point value to the real
+	//      result, same as RoundNearestHalfAway, except that if two representable values are equally
+	//      close, the value will be rounded toward zero. e.g. 7e-8 / 2 = 4e-8, 5e-8 / 2 = 2e-8
+	//    RoundNearestHalfOdd: Returns the closest representable fixed-point value to the real result,
+	//      same as RoundNearestHalfEven, except that if two representable values are equally close,
+	//      the value with an odd digit in the smallest decimal place will be chosen. This avoids
+	//      double-rounding errors when a result is later rounded again to fewer decimal places.
+	//      e.g. 7e-8 / 2 = 4e-8, 5e-8 / 2 = 3e-8, 3e-8 / 2 = 1e-8
+	//    RoundStochastic: Rounds away from zero with a probability equal to the fraction of the
+	//      difference between two available values that was truncated, and toward zero otherwise.
+	//      The result is unbiased on average, which is useful for simulations that accumulate a large
+	//      number of rounded results. The source of randomness can be set with SetStochasticSource.
+	//      e.g. 7e-8 / 4 = 1e-8 (25% of the time) or 2e-8 (75% of the time)
+	//    RoundFloor: Returns the closest representable fixed-point value that is less than or equal
+	//      to the real result (i.e. rounds toward negative infinity). e.g. 5e-8 / 2 = 2e-8,
+	//      -5e-8 / 2 = -3e-8
+	//    RoundCeil: Returns the closest representable fixed-point value that is greater than or equal
+	//      to the real result (i.e. rounds toward positive infinity). e.g. 5e-8 / 2 = 3e-8,
+	//      -5e-8 / 2 = -2e-8
+	//
+	// Note that for all rounding modes EXCEPT RoundFloor and RoundCeil (and RoundStochastic, whose
+	// result is random), when using signed inputs, the absolute value of the result will be the same
+	// regardless of the sign of the inputs.
 	//
-	// Note that for ALL rounding modes when using signed inputs, the absolute value of the result
-	// will be the same regardless of the sign of the inputs.
+	// In other words, for all symmetric rounding modes: abs(x / y) == abs(-x / y) == abs(x / -y) == abs(-x / -y)
 	//
-	// In other words, for all rounding modes: abs(x / y) == abs(-x / y) == abs(x / -y) == abs(-x / -y)
+	// For unsigned values, RoundFloor is identical to RoundTowardZero, and RoundCeil is identical
+	// to RoundAwayFromZero.
 	RoundTowardZero RoundingMode = iota
 	RoundAwayFromZero
 	RoundNearestHalfAway
 	RoundNearestHalfEven
+	RoundFloor
+	RoundCeil
+	RoundNearestHalfTowardZero
+	RoundNearestHalfOdd
+	RoundStochastic
 
 	RoundTruncate = RoundTowardZero
 	RoundDown     = RoundTowardZero
 	RoundUp       = RoundAwayFromZero
 	RoundHalfUp   = RoundNearestHalfAway
 	RoundHalfEven = RoundNearestHalfEven
+	RoundHalfDown = RoundNearestHalfTowardZero
+	RoundHalfOdd  = RoundNearestHalfOdd
 )
 
+// isValid returns true if `round` is one of the defined rounding modes.
+func (round RoundingMode) isValid() bool {
+	return round >= RoundTowardZero && round <= RoundStochastic
+}
+
+// forSign converts the directed rounding modes (RoundFloor and RoundCeil) into the equivalent
+// symmetric rounding mode for a result with the given sign, so they can be used when rounding the
+// magnitude of a signed value. Other rounding modes are returned unchanged.
+func (round RoundingMode) forSign(sign int64) RoundingMode {
+	switch round {
+	case RoundFloor:
+		if sign < 0 {
+			return RoundAwayFromZero
+		}
+		return RoundTowardZero
+	case RoundCeil:
+		if sign < 0 {
+			return RoundTowardZero
+		}
+		return RoundAwayFromZero
+	default:
+		return round
+	}
+}
+
 // Internal types
 type raw64 uint64
 type raw128 struct {
 	Hi raw64
 	Lo raw64
 }
+type raw256 struct {
+	Hi raw128
+	Lo raw128
+}
 
-func NewFix128(hi, lo uint64) Fix128 {
-	return Fix128{
+// The raw value of a fixed-point type is the integer that represents it, i.e. the value
+// multiplied by its Scale(). Signed types use two's complement, so the raw value of Fix64(-1e-8)
+// is 0xffffffffffffffff. The accessors and constructors below are the supported way to get at the
+// raw value (e.g. for serialization), rather than relying on the layout of the structs.
+
+// Raw returns the raw value of `a`. UFix64FromRaw is the inverse.
+func (a UFix64) Raw() uint64 { return uint64(a) }
+
+// Raw returns the raw two's complement value of `a`. Fix64FromRaw is the inverse.
+func (a Fix64) Raw() uint64 { return uint64(a) }
+
+// UFix64FromRaw returns the UFix64 with the given raw value.
+func UFix64FromRaw(raw uint64) UFix64 { return UFix64(raw) }
+
+// Fix64FromRaw returns the Fix64 with the given raw two's complement value.
+func Fix64FromRaw(raw uint64) Fix64 { return Fix64(raw) }
+
+// Words returns the raw 128-bit value of `a` as two 64-bit words, from most to least
+// significant. UFix128FromRaw is the inverse.
+func (a UFix128) Words() (hi, lo uint64) { return uint64(a.Hi), uint64(a.Lo) }
+
+// Words returns the raw 128-bit two's complement value of `a` as two 64-bit words, from most to
+// least significant. Fix128FromRaw is the inverse.
+func (a Fix128) Words() (hi, lo uint64) { return uint64(a.Hi), uint64(a.Lo) }
+
+// UFix128FromRaw returns the UFix128 with the given raw value, as two 64-bit words from most to
+// least significant.
+func UFix128FromRaw(hi, lo uint64) UFix128 {
+	return UFix128{
 		Hi: raw64(hi),
 		Lo: raw64(lo),
 	}
 }
 
-func NewUFix128(hi, lo uint64) UFix128 {
-	return UFix128{
+// Fix128FromRaw returns the Fix128 with the given raw two's complement value, as two 64-bit
+// words from most to least significant.
+func Fix128FromRaw(hi, lo uint64) Fix128 {
+	return Fix128{
 		Hi: raw64(hi),
 		Lo: raw64(lo),
 	}
 }
+
+// NewFix128 is the same as Fix128FromRaw.
+func NewFix128(hi, lo uint64) Fix128 { return Fix128FromRaw(hi, lo) }
+
+// NewUFix128 is the same as UFix128FromRaw.
+func NewUFix128(hi, lo uint64) UFix128 { return UFix128FromRaw(hi, lo) }
+
+// NewFix192 returns the Fix192 with the given raw value, as three 64-bit words from most to least
+// significant.
+func NewFix192(hi, mid, lo uint64) Fix192 {
+	return Fix192{
+		Hi:  raw64(hi),
+		Mid: raw64(mid),
+		Lo:  raw64(lo),
+	}
+}
+
+// NewFix256 returns the Fix256 with the given raw value, as four 64-bit words from most to least
+// significant.
+func NewFix256(w3, w2, w1, w0 uint64) Fix256 {
+	return Fix256{
+		Hi: raw128{raw64(w3), raw64(w2)},
+		Lo: raw128{raw64(w1), raw64(w0)},
+	}
+}
+
+// Words returns the raw 256-bit two's complement value of `a` as four 64-bit words, from most to
+// least significant. NewFix256 is the inverse.
+func (a Fix256) Words() (w3, w2, w1, w0 uint64) {
+	return uint64(a.Hi.Hi), uint64(a.Hi.Lo), uint64(a.Lo.Hi), uint64(a.Lo.Lo)
+}
+
+// Words returns the raw 256-bit value of `a` as four 64-bit words, see Fix256.Words.
+func (a UFix256) Words() (w3, w2, w1, w0 uint64) {
+	return uint64(a.Hi.Hi), uint64(a.Hi.Lo), uint64(a.Lo.Hi), uint64(a.Lo.Lo)
+}
+
+// NewUFix256 returns the UFix256 with the given raw value, see NewFix256.
+func NewUFix256(w3, w2, w1, w0 uint64) UFix256 {
+	return UFix256{
+		Hi: raw128{raw64(w3), raw64(w2)},
+		Lo: raw128{raw64(w1), raw64(w0)},
+	}
+}
@@ -36,9 +36,41 @@ func (a Fix64) ToFix128() Fix128 {
 	return res
 }
 
+// The cross-width operators below take a 64-bit operand and promote it to 128 bits (which is always
+// exact) before performing the operation, so the result is only rounded once.
+
+// AddUFix64 returns the sum of `a` and `b`, or an error on overflow.
+func (a UFix128) AddUFix64(b UFix64) (UFix128, error) { return a.Add(b.ToUFix128()) }
+func (a Fix128) AddFix64(b Fix64) (Fix128, error)     { return a.Add(b.ToFix128()) }
+
+// SubUFix64 returns the difference of `a` and `b`, or an error on overflow or negative overflow.
+func (a UFix128) SubUFix64(b UFix64) (UFix128, error) { return a.Sub(b.ToUFix128()) }
+func (a Fix128) SubFix64(b Fix64) (Fix128, error)     { return a.Sub(b.ToFix128()) }
+
+// MulUFix64 returns the product of `a` and `b`, or an error on overflow or underflow.
+func (a UFix128) MulUFix64(b UFix64, round RoundingMode) (UFix128, error) {
+	return a.Mul(b.ToUFix128(), round)
+}
+func (a Fix128) MulFix64(b Fix64, round RoundingMode) (Fix128, error) {
+	return a.Mul(b.ToFix128(), round)
+}
+
+// DivUFix64 returns the quotient of `a` and `b`, or an error on division by zero, overflow, or
+// underflow.
+func (a UFix128) DivUFix64(b UFix64, round RoundingMode) (UFix128, error) {
+	return a.Div(b.ToUFix128(), round)
+}
+func (a Fix128) DivFix64(b Fix64, round RoundingMode) (Fix128, error) {
+	return a.Div(b.ToFix128(), round)
+}
+
 // ToUFix64 converts a UFix128 to a UFix64, returns an error if the value can't be represented in UFix64,
 // including overflow and underflow cases.
 func (a UFix128) ToUFix64(round RoundingMode) (UFix64, error) {
+	if !round.isValid() {
+		return UFix64Zero, InvalidRoundingModeError{}
+	}
+
 	// Return zero immediately when possible.
 	if a.IsZero() {
 		return UFix64Zero, nil
@@ -77,7 +109,7 @@ func (a UFix128) ToUFix64(round RoundingMode) (UFix64, error) {
 func (a Fix128) ToFix64(round RoundingMode) (Fix64, error) {
 	unsignedX, sign := a.Abs()
 
-	res, err := unsignedX.ToUFix64(round)
+	res, err := unsignedX.ToUFix64(round.forSign(sign))
 
 	if err != nil {
 		return Fix64Zero, applySign(err, sign)
@@ -85,3 +117,109 @@ func (a Fix128) ToFix64(round RoundingMode) (Fix64, error) {
 
 	return res.ApplySign(sign)
 }
+
+// ToUFix64Sat converts a UFix128 to a UFix64 like ToUFix64, except that values too large to be
+// represented are clamped to UFix64Max, and values too small to be represented are returned as
+// zero, instead of returning an error. Useful for display and telemetry, where a clamped value is
+// more useful than an error. An invalid rounding mode is treated as RoundTowardZero, and panics in
+// debug builds.
+func (a UFix128) ToUFix64Sat(round RoundingMode) UFix64 {
+	if !round.isValid() {
+		debugPanic("ToUFix64Sat: invalid rounding mode")
+		round = RoundTowardZero
+	}
+
+	res, err := a.ToUFix64(round)
+
+	switch err.(type) {
+	case PositiveOverflowError:
+		return UFix64Max
+	case UnderflowError:
+		return UFix64Zero
+	}
+
+	return res
+}
+
+// ToFix64Sat converts a Fix128 to a Fix64 like ToFix64, except that values too large to be
+// represented are clamped to Fix64Max or Fix64Min, and values too small to be represented are
+// returned as zero, instead of returning an error. An invalid rounding mode is treated as
+// RoundTowardZero, see UFix128.ToUFix64Sat.
+func (a Fix128) ToFix64Sat(round RoundingMode) Fix64 {
+	if !round.isValid() {
+		debugPanic("ToFix64Sat: invalid rounding mode")
+		round = RoundTowardZero
+	}
+
+	res, err := a.ToFix64(round)
+
+	switch err.(type) {
+	case PositiveOverflowError:
+		return Fix64Max
+	case NegativeOverflowError:
+		return Fix64Min
+	case UnderflowError:
+		return Fix64Zero
+	}
+
+	return res
+}
+
+// The cross-type comparisons below return -1 if `a` is less than `b`, 0 if they are equal, and +1
+// if `a` is greater than `b`, taking the sign and scale of both values into account (a plain type
+// conversion before comparing gives the wrong answer for negative or out of range values).
+
+// CmpFix64 compares a UFix64 with a Fix64.
+func (a UFix64) CmpFix64(b Fix64) int {
+	if b.IsNeg() {
+		return 1
+	}
+
+	return compare(a, UFix64(b))
+}
+
+// CmpUFix64 compares a Fix64 with a UFix64.
+func (a Fix64) CmpUFix64(b UFix64) int { return -b.CmpFix64(a) }
+
+// CmpFix128 compares a UFix128 with a Fix128.
+func (a UFix128) CmpFix128(b Fix128) int {
+	if b.IsNeg() {
+		return 1
+	}
+
+	return compare(a, UFix128(b))
+}
+
+// CmpUFix128 compares a Fix128 with a UFix128.
+func (a Fix128) CmpUFix128(b UFix128) int { return -b.CmpFix128(a) }
+
+// CmpFix64 compares a Fix128 with a Fix64.
+func (a Fix128) CmpFix64(b Fix64) int { return compare(a, b.ToFix128()) }
+
+// CmpFix128 compares a Fix64 with a Fix128.
+func (a Fix64) CmpFix128(b Fix128) int { return compare(a.ToFix128(), b) }
+
+// CmpUFix64 compares a UFix128 with a UFix64.
+func (a UFix128) CmpUFix64(b UFix64) int { return compare(a, b.ToUFix128()) }
+
+// CmpUFix128 compares a UFix64 with a UFix128.
+func (a UFix64) CmpUFix128(b UFix128) int { return compare(a.ToUFix128(), b) }
+
+// compare returns -1, 0, or +1 depending on whether `a` is less than, equal to, or greater than `b`.
+func compare[T interface{ Lt(T) bool }](a, b T) int {
+	if a.Lt(b) {
+		return -1
+	} else if b.Lt(a) {
+		return 1
+	}
+
+	return 0
+}
+
+// WithinUlps returns true if `a` and `b` differ by at most `n` units in the last place (i.e. `n`
+// times the smallest representable increment). Useful when validating results against other
+// implementations, which might round differently in some edge cases.
+func (a UFix64) WithinUlps(b UFix64, n uint64) bool   { return a.ApproxEq(b, UFix64(n)) }
+func (a Fix64) WithinUlps(b Fix64, n uint64) bool     { return a.ApproxEq(b, UFix64(n)) }
+func (a UFix128) WithinUlps(b UFix128, n uint64) bool { return a.ApproxEq(b, UFix128{Lo: raw64(n)}) }
+func (a Fix128) WithinUlps(b Fix128, n uint64) bool   { return a.ApproxEq(b, UFix128{Lo: raw64(n)}) }
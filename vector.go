@@ -0,0 +1,111 @@
+/*
+ * Copyright Flow Foundation
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fixedPoint
+
+// This file contains the element-wise operations over slices. Each writes its results to dst,
+// which may be the same slice as one of the inputs, and returns -1 if it succeeded, or the index
+// of the first element that failed along with its error. In that case, the elements of dst before
+// the index hold their results, and the rest are unchanged. If dst is shorter than the inputs, or
+// the inputs have different lengths, it returns an OutOfDomainErrorError with an index of -1,
+// without changing dst.
+
+// AddSlices sets dst[i] to a[i] + b[i].
+func AddSlices[T FixedPoint[T]](dst, a, b []T) (int, error) {
+	return elementwise(dst, a, b, T.Add)
+}
+
+// SubSlices sets dst[i] to a[i] - b[i].
+func SubSlices[T FixedPoint[T]](dst, a, b []T) (int, error) {
+	return elementwise(dst, a, b, T.Sub)
+}
+
+// MulSlices sets dst[i] to a[i] * b[i], rounded as specified.
+func MulSlices[T FixedPoint[T]](dst, a, b []T, round RoundingMode) (int, error) {
+	if !round.isValid() {
+		return -1, InvalidRoundingModeError{}
+	}
+
+	return elementwise(dst, a, b, func(x, y T) (T, error) { return x.Mul(y, round) })
+}
+
+// DivSlices sets dst[i] to a[i] / b[i], rounded as specified.
+func DivSlices[T FixedPoint[T]](dst, a, b []T, round RoundingMode) (int, error) {
+	if !round.isValid() {
+		return -1, InvalidRoundingModeError{}
+	}
+
+	return elementwise(dst, a, b, func(x, y T) (T, error) { return x.Div(y, round) })
+}
+
+// ScaleSlice sets dst[i] to a[i] * k, rounded as specified.
+func ScaleSlice[T FixedPoint[T]](dst, a []T, k T, round RoundingMode) (int, error) {
+	if !round.isValid() {
+		return -1, InvalidRoundingModeError{}
+	}
+
+	if len(dst) < len(a) {
+		return -1, OutOfDomainErrorError{}
+	}
+
+	for i, x := range a {
+		res, err := x.Mul(k, round)
+		if err != nil {
+			return i, err
+		}
+		dst[i] = res
+	}
+
+	return -1, nil
+}
+
+// ScaleSliceFrac sets dst[i] to a[i] * num / den, without intermediate rounding, e.g. to apply a
+// ratio of two amounts to every element.
+func ScaleSliceFrac[T FixedPoint[T]](dst, a []T, num, den T, round RoundingMode) (int, error) {
+	if !round.isValid() {
+		return -1, InvalidRoundingModeError{}
+	}
+
+	if len(dst) < len(a) {
+		return -1, OutOfDomainErrorError{}
+	}
+
+	for i, x := range a {
+		res, err := x.FMD(num, den, round)
+		if err != nil {
+			return i, err
+		}
+		dst[i] = res
+	}
+
+	return -1, nil
+}
+
+func elementwise[T any](dst, a, b []T, op func(x, y T) (T, error)) (int, error) {
+	if len(a) != len(b) || len(dst) < len(a) {
+		return -1, OutOfDomainErrorError{}
+	}
+
+	for i := range a {
+		res, err := op(a[i], b[i])
+		if err != nil {
+			return i, err
+		}
+		dst[i] = res
+	}
+
+	return -1, nil
+}